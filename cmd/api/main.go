@@ -1,12 +1,30 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/victor-lima-142/oak-bank/internal/api/handlers"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/security"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+	"github.com/victor-lima-142/oak-bank/internal/events"
+	"github.com/victor-lima-142/oak-bank/internal/notifications"
+	"github.com/victor-lima-142/oak-bank/internal/outbox"
+	"github.com/victor-lima-142/oak-bank/internal/postal"
+	"github.com/victor-lima-142/oak-bank/internal/retention"
+	"github.com/victor-lima-142/oak-bank/internal/storage"
 	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/fieldcrypt"
 )
 
 func main() {
@@ -14,11 +32,28 @@ func main() {
 		log.Printf("warning: no .env file loaded: %v", err)
 	}
 
-	_, err := config.OpenDB()
+	db, err := config.OpenDB()
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
 
+	if err := config.Migrate(db); err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	keyProvider, err := fieldcrypt.NewKeyProviderFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure field encryption: %v", err)
+	}
+	keyring, err := fieldcrypt.NewKeyring(ctx, db, keyProvider)
+	if err != nil {
+		log.Fatalf("failed to load encryption keys: %v", err)
+	}
+	fieldcrypt.Use(keyring)
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = os.Getenv("APP_PORT")
@@ -27,6 +62,114 @@ func main() {
 		port = "8080"
 	}
 
+	jwtService := security.NewJwtService(nil)
+	passwordService, err := security.NewPasswordServiceFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure password hashing: %v", err)
+	}
+
+	senders, err := notifications.NewSendersFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure notification providers: %v", err)
+	}
+
+	eventRegistry, err := events.LoadRegistry()
+	if err != nil {
+		log.Fatalf("failed to load event schema registry: %v", err)
+	}
+	eventPublisher, err := events.NewPublisherFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure event publisher: %v", err)
+	}
+	defer eventPublisher.Close()
+
+	documentStore, err := storage.NewObjectStoreFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure document store: %v", err)
+	}
+	documentCipher, err := storage.NewCipherFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure document encryption: %v", err)
+	}
+
+	retentionPolicies, err := retention.LoadPolicies(config.GetEnv("RETENTION_POLICIES_FILE", ""))
+	if err != nil {
+		log.Fatalf("failed to load retention policies: %v", err)
+	}
+
+	postalProvider, err := postal.NewProviderFromEnv(db)
+	if err != nil {
+		log.Fatalf("failed to configure postal code providers: %v", err)
+	}
+
+	// services
+	transactionService := services.NewMakeTransactionService(db)
+	paymentBatchService := services.NewPaymentBatchService(db, transactionService, nil)
+	categoryService := services.NewTransactionCategoryService(db)
+	insightsService := services.NewSpendingInsightsService(db)
+	budgetService := services.NewBudgetService(db)
+	notificationService := services.NewNotificationService(db, senders, nil)
+	accountService := services.NewAccountService(db)
+	webhookService := services.NewWebhookService(db, nil)
+	domainEventService := services.NewDomainEventService()
+	twoFactorService := services.NewTwoFactorService(db, jwtService, passwordService, notificationService, nil)
+	authService := services.NewAuthService(db, jwtService, passwordService, twoFactorService, nil)
+	fraudService := services.NewFraudService(db, transactionService, &services.FraudConfig{
+		// o step-up é concluído em POST /2fa/step-up
+		StepUpEnabled: true,
+	})
+	amlService := services.NewAmlService(db, nil)
+	screeningService := services.NewScreeningService(db, nil)
+	kycService := services.NewKYCService(db, screeningService, nil)
+	documentService := services.NewDocumentService(db, documentStore, documentCipher, nil)
+	riskService := services.NewRiskService(db, nil)
+	addressService := services.NewAddressService(db, postalProvider, passwordService, notificationService)
+	profileService := services.NewProfileService(db, passwordService, screeningService, notificationService, nil)
+	auditService := services.NewAuditService(db)
+	privacyService := services.NewPrivacyService(db, documentStore, documentCipher, passwordService, notificationService, nil)
+	onboardingService := services.NewOnboardingService(db, passwordService, addressService, screeningService, notificationService, nil)
+	retentionPurger := retention.NewPurger(db, documentStore, documentCipher, retentionPolicies, nil)
+	outboxRelay := outbox.NewRelay(db, events.NewOutboxPublisher(eventPublisher, eventRegistry), nil)
+
+	transactionService.AddGuard(screeningService.GuardTransfer)
+	transactionService.AddGuard(kycService.GuardLimits)
+	transactionService.AddGuard(fraudService.Evaluate)
+
+	// a avaliação de orçamentos depende da categoria gravada pelo listener anterior
+	transactionService.AddCompletedListener(categoryService.Categorize)
+	transactionService.AddCompletedListener(budgetService.Evaluate)
+	transactionService.AddCompletedListener(notificationService.OnTransactionCompleted)
+	transactionService.AddCompletedListener(amlService.OnTransactionCompleted)
+	transactionService.AddCompletedListener(riskService.OnTransactionCompleted)
+	transactionService.AddStatusListener(webhookService.OnTransactionStatusChanged)
+	transactionService.AddStatusListener(domainEventService.OnTransactionStatusChanged)
+	accountService.AddStatusListener(webhookService.OnAccountStatusChanged)
+	accountService.AddStatusListener(domainEventService.OnAccountStatusChanged)
+	accountService.AddStatusListener(riskService.OnAccountStatusChanged)
+	kycService.AddStatusListener(webhookService.OnKYCStatusChanged)
+	kycService.AddStatusListener(domainEventService.OnKYCStatusChanged)
+	kycService.AddStatusListener(riskService.OnKYCStatusChanged)
+	profileService.AddChangeListener(domainEventService.OnProfileChanged)
+	profileService.AddChangeListener(riskService.OnProfileChanged)
+	profileService.AddChangeListener(amlService.OnProfileChanged)
+	authService.AddLoginListener(domainEventService.OnUserLoggedIn)
+
+	// workers
+	go keyring.Run(ctx, config.GetEnvDuration("FIELD_ENCRYPTION_RELOAD_INTERVAL", 5*time.Minute))
+	go paymentBatchService.Run(ctx, config.GetEnvDuration("PAYMENT_BATCH_POLL_INTERVAL", 5*time.Second))
+	go notificationService.Run(ctx, config.GetEnvDuration("NOTIFICATION_POLL_INTERVAL", 5*time.Second))
+	go webhookService.Run(ctx, config.GetEnvDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second))
+	go outboxRelay.Run(ctx, config.GetEnvDuration("OUTBOX_POLL_INTERVAL", time.Second))
+	go screeningService.Run(ctx, config.GetEnvDuration("SCREENING_RESCREEN_POLL_INTERVAL", 5*time.Minute))
+	go documentService.Run(ctx, config.GetEnvDuration("DOCUMENT_PURGE_POLL_INTERVAL", time.Hour))
+	go riskService.Run(ctx, config.GetEnvDuration("RISK_POLL_INTERVAL", 30*time.Second))
+	go onboardingService.Run(ctx, config.GetEnvDuration("ONBOARDING_PURGE_POLL_INTERVAL", time.Hour))
+	go privacyService.Run(ctx, config.GetEnvDuration("PRIVACY_REQUEST_POLL_INTERVAL", time.Minute))
+	// a remoção começa desligada; RETENTION_DRY_RUN=true apenas registra no log o que seria alterado
+	if config.GetEnvBool("RETENTION_PURGE_ENABLED", false) {
+		go retentionPurger.Run(ctx, config.GetEnvDuration("RETENTION_PURGE_INTERVAL", 6*time.Hour), config.GetEnvBool("RETENTION_DRY_RUN", false))
+	}
+
 	router := gin.Default()
 
 	router.GET("/", func(c *gin.Context) {
@@ -37,8 +180,50 @@ func main() {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	public := router.Group("/api/v1")
+	handlers.NewAuthHandler(authService).RegisterRoutes(public)
+	handlers.NewOnboardingHandler(onboardingService).RegisterRoutes(public)
+	addressHandler := handlers.NewAddressHandler(addressService)
+	addressHandler.RegisterPublicRoutes(public)
+
+	// o token de step-up, quando enviado, libera as operações sensíveis sem nova senha
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	api := router.Group("/api/v1", middlewares.AuthMiddleware(jwtService), twoFactorHandler.VerifyStepUp)
+	handlers.NewMakeTransactionHandler(transactionService).RegisterRoutes(api)
+	handlers.NewPaymentBatchHandler(paymentBatchService).RegisterRoutes(api)
+	handlers.NewTransactionCategoryHandler(categoryService, insightsService).RegisterRoutes(api)
+	handlers.NewBudgetHandler(budgetService).RegisterRoutes(api)
+	handlers.NewNotificationHandler(notificationService).RegisterRoutes(api)
+	handlers.NewAccountHandler(accountService).RegisterRoutes(api)
+	handlers.NewWebhookHandler(webhookService).RegisterRoutes(api)
+	handlers.NewFraudHandler(fraudService).RegisterRoutes(api)
+	handlers.NewAmlHandler(amlService).RegisterRoutes(api)
+	handlers.NewScreeningHandler(screeningService).RegisterRoutes(api)
+	handlers.NewKYCHandler(kycService).RegisterRoutes(api)
+	handlers.NewDocumentHandler(documentService).RegisterRoutes(api)
+	handlers.NewRiskHandler(riskService).RegisterRoutes(api)
+	addressHandler.RegisterRoutes(api)
+	handlers.NewProfileHandler(profileService).RegisterRoutes(api)
+	handlers.NewAuditHandler(auditService).RegisterRoutes(api)
+	handlers.NewPrivacyHandler(privacyService).RegisterRoutes(api)
+	twoFactorHandler.RegisterRoutes(api)
+
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: router,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("server shutdown error: %v", err)
+		}
+	}()
+
 	log.Printf("starting server on :%s", port)
-	if err := router.Run(":" + port); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("server error: %v", err)
 	}
 }
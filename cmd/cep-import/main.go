@@ -0,0 +1,62 @@
+// Command cep-import carrega um arquivo CSV de CEPs na base local consultada
+// pelo provedor "dataset". CEPs já existentes são atualizados.
+//
+//	go run ./cmd/cep-import ceps.csv
+//
+// O arquivo tem cabeçalho, separado por vírgula ou ponto e vírgula, com as
+// colunas cep, logradouro, bairro, cidade e uf.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"github.com/victor-lima-142/oak-bank/internal/postal"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 1000, "linhas por lote")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "uso: %s [-batch N] ARQUIVO\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *batchSize <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("warning: no .env file loaded: %v", err)
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("failed to open postal code file: %v", err)
+	}
+	defer file.Close()
+
+	db, err := config.OpenDB()
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	if err := config.Migrate(db); err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	imported, err := postal.NewDatasetProvider(db).Import(ctx, file, *batchSize)
+	if err != nil {
+		log.Fatalf("failed to import postal codes after %d rows: %v", imported, err)
+	}
+	log.Printf("imported %d postal codes", imported)
+}
@@ -0,0 +1,87 @@
+// Command password-audit relata os esquemas de hash das senhas dos usuários,
+// para acompanhar a migração das senhas cifradas pela versão anterior. As
+// senhas legadas e as que usam outro algoritmo ou custo são refeitas no
+// próximo login bem-sucedido de cada usuário.
+//
+//	go run ./cmd/password-audit
+//	go run ./cmd/password-audit -list   # também lista os usuários com senha legada
+//
+// A configuração atual vem das mesmas variáveis da API
+// (PASSWORD_HASH_ALGORITHM, PASSWORD_ARGON2_*, PASSWORD_BCRYPT_COST).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"github.com/victor-lima-142/oak-bank/internal/api/security"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+)
+
+func main() {
+	list := flag.Bool("list", false, "lista os ids dos usuários com senha legada")
+	batchSize := flag.Int("batch", 1000, "usuários por lote")
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("warning: no .env file loaded: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := config.OpenDB()
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	passwordService, err := security.NewPasswordServiceFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure password hashing: %v", err)
+	}
+
+	counts := map[string]int{}
+	var total, needsRehash int
+	var users []models.User
+	err = db.WithContext(ctx).Model(&models.User{}).
+		Select("user_id", "password_hash").
+		FindInBatches(&users, *batchSize, func(tx *gorm.DB, batch int) error {
+			for i := range users {
+				scheme := security.PasswordScheme(users[i].PasswordHash)
+				counts[scheme]++
+				total++
+				if passwordService.NeedsRehash(users[i].PasswordHash) {
+					needsRehash++
+				}
+				if *list && scheme == security.PasswordSchemeLegacy {
+					fmt.Printf("legacy %s\n", users[i].UserID)
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		log.Fatalf("failed to scan users: %v", err)
+	}
+
+	fmt.Printf("users:        %d\n", total)
+	for _, scheme := range []string{
+		security.PasswordSchemeArgon2id,
+		security.PasswordSchemeBcrypt,
+		security.PasswordSchemeLegacy,
+		security.PasswordSchemeUnusable,
+	} {
+		fmt.Printf("%-13s %d\n", scheme+":", counts[scheme])
+	}
+	fmt.Printf("needs rehash: %d (legacy included)\n", needsRehash)
+}
@@ -0,0 +1,123 @@
+// Command privacy-request registra e atende na hora um pedido do titular
+// (LGPD) recebido fora do aplicativo. O pedido fica registrado como os
+// abertos pela API, com prazo e status.
+//
+//	go run ./cmd/privacy-request -customer ID -type EXPORT -reason "pedido por email" -out dados.zip
+//	go run ./cmd/privacy-request -customer ID -type ANONYMIZATION -reason "pedido à ouvidoria"
+//
+// A anonimização de quem ainda está no prazo de guarda dos registros fica
+// agendada e é concluída pelo worker da API.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/security"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+	"github.com/victor-lima-142/oak-bank/internal/notifications"
+	"github.com/victor-lima-142/oak-bank/internal/storage"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"github.com/victor-lima-142/oak-bank/pkg/fieldcrypt"
+)
+
+func main() {
+	customerID := flag.String("customer", "", "id do cliente")
+	requestType := flag.String("type", models.DataSubjectRequestExport, "EXPORT ou ANONYMIZATION")
+	reason := flag.String("reason", "", "origem e motivo do pedido")
+	out := flag.String("out", "", "arquivo zip de destino da exportação")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "uso: %s -customer ID -type EXPORT|ANONYMIZATION -reason TEXTO [-out ARQUIVO]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *customerID == "" || *reason == "" || flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("warning: no .env file loaded: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := config.OpenDB()
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	if err := config.Migrate(db); err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
+	}
+
+	keyProvider, err := fieldcrypt.NewKeyProviderFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure field encryption: %v", err)
+	}
+	keyring, err := fieldcrypt.NewKeyring(ctx, db, keyProvider)
+	if err != nil {
+		log.Fatalf("failed to load encryption keys: %v", err)
+	}
+	fieldcrypt.Use(keyring)
+
+	passwordService, err := security.NewPasswordServiceFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure password hashing: %v", err)
+	}
+	senders, err := notifications.NewSendersFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure notification providers: %v", err)
+	}
+	documentStore, err := storage.NewObjectStoreFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure document store: %v", err)
+	}
+	documentCipher, err := storage.NewCipherFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure document encryption: %v", err)
+	}
+
+	// as notificações são apenas enfileiradas; o envio fica com a API
+	notificationService := services.NewNotificationService(db, senders, nil)
+	privacyService := services.NewPrivacyService(db, documentStore, documentCipher, passwordService, notificationService, nil)
+
+	request, err := privacyService.CreateForCustomer(ctx, "", *customerID, &dtos.CreateCustomerPrivacyRequestDTO{
+		Type:   *requestType,
+		Reason: *reason,
+	})
+	if err != nil {
+		log.Fatalf("failed to register request: %v", err)
+	}
+	log.Printf("registered %s request %s due at %s", request.Type, request.RequestID, request.DueAt.Format("2006-01-02"))
+
+	requestID := request.RequestID
+	request, err = privacyService.Process(ctx, requestID)
+	if err != nil {
+		log.Fatalf("failed to process request %s: %v", requestID, err)
+	}
+	log.Printf("request %s is %s", request.RequestID, request.Status)
+	if request.ScheduledFor != nil {
+		log.Printf("anonymization scheduled for %s", request.ScheduledFor.Format("2006-01-02"))
+	}
+
+	if *out == "" || request.Type != models.DataSubjectRequestExport || request.Status != models.DataSubjectRequestCompleted {
+		return
+	}
+	_, data, err := privacyService.DownloadAny(ctx, request.RequestID)
+	if err != nil {
+		log.Fatalf("failed to read export: %v", err)
+	}
+	if err := os.WriteFile(*out, data, 0o600); err != nil {
+		log.Fatalf("failed to write export file: %v", err)
+	}
+	log.Printf("wrote %d bytes to %s (sha256 %s)", len(data), *out, request.ArtifactSHA256)
+}
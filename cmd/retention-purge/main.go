@@ -0,0 +1,98 @@
+// Command retention-purge aplica uma vez as políticas de retenção, fora do
+// agendamento da API.
+//
+//	go run ./cmd/retention-purge -dry-run              # apenas relata o que seria alterado
+//	go run ./cmd/retention-purge -policy audit-log-archive
+//	go run ./cmd/retention-purge -policies politicas.json
+//
+// As políticas padrão estão em internal/retention/policies.json;
+// RETENTION_POLICIES_FILE ou -policies apontam para outro arquivo. Os
+// arquivos das políticas archive_delete vão para o object store de
+// documentos, sob RETENTION_ARCHIVE_PREFIX, cifrados com as chaves dos
+// documentos.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"github.com/victor-lima-142/oak-bank/internal/retention"
+	"github.com/victor-lima-142/oak-bank/internal/storage"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "relata as linhas vencidas sem alterar nada")
+	only := flag.String("policy", "", "nomes das políticas a aplicar, separados por vírgula (padrão: todas)")
+	policiesFile := flag.String("policies", "", "arquivo de políticas (padrão: RETENTION_POLICIES_FILE ou as políticas embutidas)")
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("warning: no .env file loaded: %v", err)
+	}
+
+	path := *policiesFile
+	if path == "" {
+		path = config.GetEnv("RETENTION_POLICIES_FILE", "")
+	}
+	policies, err := retention.LoadPolicies(path)
+	if err != nil {
+		log.Fatalf("failed to load retention policies: %v", err)
+	}
+	if *only != "" {
+		var selected []retention.Policy
+		for _, policy := range policies {
+			for _, name := range strings.Split(*only, ",") {
+				if strings.TrimSpace(name) == policy.Name {
+					selected = append(selected, policy)
+				}
+			}
+		}
+		if len(selected) == 0 {
+			log.Fatalf("no retention policy named %q", *only)
+		}
+		policies = selected
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := config.OpenDB()
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	if err := config.Migrate(db); err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
+	}
+
+	store, err := storage.NewObjectStoreFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure archive store: %v", err)
+	}
+	cipher, err := storage.NewCipherFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure archive encryption: %v", err)
+	}
+
+	reports, err := retention.NewPurger(db, store, cipher, policies, nil).Purge(ctx, *dryRun)
+	for i := range reports {
+		fmt.Println(reports[i].String())
+		for _, key := range reports[i].Archives {
+			fmt.Printf("  archived to %s\n", key)
+		}
+	}
+	if err != nil {
+		log.Fatalf("retention purge failed: %v", err)
+	}
+}
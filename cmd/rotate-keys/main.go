@@ -0,0 +1,101 @@
+// Command rotate-keys gira as chaves da criptografia de campos e recifra as
+// linhas em lotes, com a API em funcionamento.
+//
+//	go run ./cmd/rotate-keys -rewrap            # após trocar FIELD_ENCRYPTION_MASTER_KEY
+//	go run ./cmd/rotate-keys -rotate-data-key   # nova chave de dados e recifragem
+//	go run ./cmd/rotate-keys                    # apenas recifra valores legados ou com chaves antigas
+//
+// Ao trocar a chave mestra, a anterior deve continuar em
+// FIELD_ENCRYPTION_PREVIOUS_MASTER_KEYS até o fim do -rewrap. As instâncias da
+// API passam a gravar com a nova chave de dados em até
+// FIELD_ENCRYPTION_RELOAD_INTERVAL; rode o comando novamente depois disso
+// para recifrar o que foi gravado no intervalo.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"github.com/victor-lima-142/oak-bank/pkg/fieldcrypt"
+)
+
+func main() {
+	rewrap := flag.Bool("rewrap", false, "cifra as chaves de dados com a chave mestra atual")
+	rotateDataKey := flag.Bool("rotate-data-key", false, "cria uma nova chave de dados ativa antes da recifragem")
+	batchSize := flag.Int("batch", 500, "linhas por lote")
+	pause := flag.Duration("pause", 100*time.Millisecond, "pausa entre lotes")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("warning: no .env file loaded: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := config.OpenDB()
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	if err := config.Migrate(db); err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
+	}
+
+	provider, err := fieldcrypt.NewKeyProviderFromEnv()
+	if err != nil {
+		log.Fatalf("failed to configure field encryption: %v", err)
+	}
+	keyring, err := fieldcrypt.NewKeyring(ctx, db, provider)
+	if err != nil {
+		log.Fatalf("failed to load encryption keys: %v", err)
+	}
+	fieldcrypt.Use(keyring)
+
+	if *rewrap {
+		n, err := keyring.Rewrap(ctx)
+		if err != nil {
+			log.Fatalf("failed to rewrap data keys: %v", err)
+		}
+		log.Printf("rewrapped %d data keys with master key %s", n, provider.MasterKeyID())
+	}
+	if *rotateDataKey {
+		id, err := keyring.RotateDataKey(ctx)
+		if err != nil {
+			log.Fatalf("failed to rotate data key: %v", err)
+		}
+		log.Printf("data key %s is now active", id)
+	}
+
+	targets := []struct {
+		name  string
+		model any
+		extra []string
+	}{
+		{"customers", &models.Customer{}, []string{"tax_id_hash", "phone_hash", "email_hash"}},
+		{"customer_legal_representatives", &models.CustomerLegalRepresentative{}, []string{"tax_id_hash"}},
+		{"onboarding_sessions", &models.OnboardingSession{}, nil},
+		{"users", &models.User{}, nil},
+		{"profile_change_requests", &models.ProfileChangeRequest{}, nil},
+		{"customer_profile_versions", &models.CustomerProfileVersion{}, nil},
+		{"one_time_codes", &models.OneTimeCode{}, nil},
+	}
+	for _, target := range targets {
+		n, err := fieldcrypt.Reencrypt(ctx, db, target.model, fieldcrypt.ReencryptOptions{
+			BatchSize:    *batchSize,
+			Pause:        *pause,
+			ExtraColumns: target.extra,
+		})
+		if err != nil {
+			log.Fatalf("failed to re-encrypt %s after %d rows: %v", target.name, n, err)
+		}
+		log.Printf("re-encrypted %d %s rows with data key %s", n, target.name, keyring.ActiveKeyID())
+	}
+}
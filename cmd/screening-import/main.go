@@ -0,0 +1,78 @@
+// Command screening-import importa um arquivo local de lista restritiva (CSV
+// ou XML). A retriagem dos clientes é feita pelo worker da API.
+//
+//	go run ./cmd/screening-import -code UN -name "ONU - Lista Consolidada" -type SANCTIONS consolidated.xml
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+)
+
+func main() {
+	code := flag.String("code", "", "código da lista (ex.: UN, OFAC, PEP-BR)")
+	name := flag.String("name", "", "nome da lista")
+	listType := flag.String("type", "SANCTIONS", "tipo da lista: SANCTIONS ou PEP")
+	format := flag.String("format", "", "formato do arquivo: csv ou xml (padrão: extensão do arquivo)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "uso: %s -code CODIGO -name NOME [-type SANCTIONS|PEP] [-format csv|xml] ARQUIVO\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *code == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("warning: no .env file loaded: %v", err)
+	}
+
+	path := flag.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	if *name == "" {
+		*name = *code
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("failed to open list file: %v", err)
+	}
+	defer file.Close()
+
+	db, err := config.OpenDB()
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+	if err := config.Migrate(db); err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
+	}
+
+	list, err := services.NewScreeningService(db, nil).Import(context.Background(), "", &dtos.ImportSanctionsListDTO{
+		ListCode: *code,
+		ListName: *name,
+		ListType: strings.ToUpper(*listType),
+		Format:   *format,
+	}, file)
+	if err != nil {
+		log.Fatalf("failed to import list: %v", err)
+	}
+
+	if list.Unchanged {
+		log.Printf("list %s unchanged (%d entries)", list.ListCode, list.EntryCount)
+		return
+	}
+	log.Printf("list %s imported: %d entries, version %s", list.ListCode, list.EntryCount, list.ContentHash[:12])
+}
@@ -0,0 +1,18 @@
+package dtos
+
+import "time"
+
+type ChangeAccountStatusDTO struct {
+	Status string `json:"status" validate:"required,oneof=ACTIVE BLOCKED CLOSED"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type AccountStatusHistoryDTO struct {
+	HistoryID       string    `json:"history_id"`
+	AccountID       string    `json:"account_id"`
+	PreviousStatus  string    `json:"previous_status,omitempty"`
+	NewStatus       string    `json:"new_status"`
+	ChangeReason    string    `json:"change_reason,omitempty"`
+	ChangedByUserID string    `json:"changed_by_user_id,omitempty"`
+	ChangedAt       time.Time `json:"changed_at"`
+}
@@ -0,0 +1,52 @@
+package dtos
+
+import "time"
+
+type CustomerAddressDTO struct {
+	AddressID     string     `json:"address_id"`
+	AddressType   string     `json:"address_type"`
+	IsPrimary     bool       `json:"is_primary"`
+	AddressLine1  string     `json:"address_line_1"`
+	AddressLine2  string     `json:"address_line_2,omitempty"`
+	Neighborhood  string     `json:"neighborhood,omitempty"`
+	City          string     `json:"city"`
+	State         string     `json:"state"`
+	Country       string     `json:"country"`
+	PostalCode    string     `json:"postal_code"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+}
+
+type CustomerAddressListDTO struct {
+	Addresses []CustomerAddressDTO `json:"addresses"`
+}
+
+// AddressHistoryRequestDTO filtra o histórico de endereços. Com At são
+// listados os endereços vigentes em algum momento da data; sem At, todo o
+// histórico.
+type AddressHistoryRequestDTO struct {
+	At          string `form:"at" validate:"omitempty,datetime=2006-01-02"`
+	AddressType string `form:"address_type" validate:"omitempty,oneof=residential commercial billing"`
+}
+
+// ChangeAddressDTO inclui um endereço. Com Primary o endereço passa a ser o
+// principal do tipo e o principal anterior deixa de valer (mudança de
+// endereço). CurrentPassword confirma a identidade do usuário.
+type ChangeAddressDTO struct {
+	Address           CreateAddressDTO `json:"address"`
+	Primary           bool             `json:"primary"`
+	ReplacesAddressID string           `json:"replaces_address_id,omitempty" validate:"omitempty,uuid"`
+	CurrentPassword   string           `json:"current_password" validate:"required"`
+}
+
+type ChangeAddressResponseDTO struct {
+	Address CustomerAddressDTO `json:"address"`
+	// Warnings traz as divergências entre o CEP e o endereço informado
+	Warnings map[string]string `json:"warnings,omitempty"`
+}
+
+// ConfirmAddressChangeDTO confirma com a senha atual a troca do endereço
+// principal ou o encerramento de um endereço
+type ConfirmAddressChangeDTO struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
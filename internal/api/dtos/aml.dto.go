@@ -0,0 +1,80 @@
+package dtos
+
+import "time"
+
+type AmlCaseListRequestDTO struct {
+	Status           string `form:"status" validate:"omitempty,oneof=OPEN IN_REVIEW ESCALATED CLOSED"`
+	Disposition      string `form:"disposition" validate:"omitempty,oneof=CONFIRMED_SUSPICIOUS FALSE_POSITIVE INSUFFICIENT_EVIDENCE"`
+	AssignedToUserID string `form:"assigned_to" validate:"omitempty,uuid"`
+	CustomerID       string `form:"customer_id" validate:"omitempty,uuid"`
+	Limit            int    `form:"limit" validate:"omitempty,min=1,max=200"`
+	Offset           int    `form:"offset" validate:"omitempty,min=0"`
+}
+
+type AssignAmlCaseDTO struct {
+	AssignedToUserID string `json:"assigned_to_user_id" validate:"required,uuid"`
+}
+
+type ChangeAmlCaseStatusDTO struct {
+	Status string `json:"status" validate:"required,oneof=OPEN IN_REVIEW ESCALATED"`
+	Note   string `json:"note,omitempty" validate:"omitempty,max=2000"`
+}
+
+type CloseAmlCaseDTO struct {
+	Disposition string `json:"disposition" validate:"required,oneof=CONFIRMED_SUSPICIOUS FALSE_POSITIVE INSUFFICIENT_EVIDENCE"`
+	Reason      string `json:"reason" validate:"required,max=4000"`
+}
+
+type AddAmlCaseNoteDTO struct {
+	Note string `json:"note" validate:"required,max=4000"`
+}
+
+type AmlReportRequestDTO struct {
+	// CaseIDs vazio exporta todos os casos confirmados ainda não comunicados
+	CaseIDs []string `json:"case_ids,omitempty" validate:"omitempty,max=500,dive,uuid"`
+}
+
+type AmlAlertDTO struct {
+	AlertID        string    `json:"alert_id"`
+	RuleCode       string    `json:"rule_code"`
+	Description    string    `json:"description"`
+	TotalAmount    float64   `json:"total_amount"`
+	WindowStart    time.Time `json:"window_start"`
+	WindowEnd      time.Time `json:"window_end"`
+	TransactionIDs []string  `json:"transaction_ids"`
+	Details        any       `json:"details,omitempty"`
+	StrictProfile  bool      `json:"strict_profile"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type AmlCaseNoteDTO struct {
+	NoteID       string    `json:"note_id"`
+	AuthorUserID string    `json:"author_user_id,omitempty"`
+	NoteType     string    `json:"note_type"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type AmlCaseDTO struct {
+	CaseID            string           `json:"case_id"`
+	CaseNumber        int64            `json:"case_number"`
+	CustomerID        string           `json:"customer_id"`
+	CaseStatus        string           `json:"case_status"`
+	AssignedToUserID  string           `json:"assigned_to_user_id,omitempty"`
+	Disposition       string           `json:"disposition,omitempty"`
+	DispositionReason string           `json:"disposition_reason,omitempty"`
+	ClosedByUserID    string           `json:"closed_by_user_id,omitempty"`
+	ClosedAt          *time.Time       `json:"closed_at,omitempty"`
+	ReportedAt        *time.Time       `json:"reported_at,omitempty"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+	Alerts            []AmlAlertDTO    `json:"alerts,omitempty"`
+	Notes             []AmlCaseNoteDTO `json:"notes,omitempty"`
+}
+
+type AmlCaseListDTO struct {
+	Cases      []AmlCaseDTO `json:"cases"`
+	TotalCount int          `json:"total_count"`
+	Limit      int          `json:"limit"`
+	Offset     int          `json:"offset"`
+}
@@ -0,0 +1,37 @@
+package dtos
+
+import "time"
+
+// AuditLogListRequestDTO filtra o registro de auditoria. From e To são datas
+// em UTC, ambas incluídas no período.
+type AuditLogListRequestDTO struct {
+	Table    string `form:"table" validate:"omitempty,max=100"`
+	RecordID string `form:"record_id" validate:"omitempty,max=100"`
+	UserID   string `form:"user_id" validate:"omitempty,uuid"`
+	From     string `form:"from" validate:"omitempty,datetime=2006-01-02"`
+	To       string `form:"to" validate:"omitempty,datetime=2006-01-02"`
+	Limit    int    `form:"limit" validate:"omitempty,min=1,max=200"`
+	Offset   int    `form:"offset" validate:"omitempty,min=0"`
+}
+
+type AuditLogDTO struct {
+	AuditID            string    `json:"audit_id"`
+	Table              string    `json:"table"`
+	RecordID           string    `json:"record_id"`
+	OperationType      string    `json:"operation_type"`
+	UserID             string    `json:"user_id,omitempty"`
+	OperationTimestamp time.Time `json:"operation_timestamp"`
+	IPAddress          string    `json:"ip_address,omitempty"`
+	UserAgent          string    `json:"user_agent,omitempty"`
+	OldValues          any       `json:"old_values,omitempty"`
+	NewValues          any       `json:"new_values,omitempty"`
+	OperationResult    string    `json:"operation_result,omitempty"`
+	AdditionalContext  any       `json:"additional_context,omitempty"`
+}
+
+type AuditLogListDTO struct {
+	Entries    []AuditLogDTO `json:"entries"`
+	TotalCount int           `json:"total_count"`
+	Limit      int           `json:"limit"`
+	Offset     int           `json:"offset"`
+}
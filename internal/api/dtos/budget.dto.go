@@ -0,0 +1,39 @@
+package dtos
+
+import "time"
+
+type CreateBudgetDTO struct {
+	CategoryCode string  `json:"category_code,omitempty" validate:"omitempty,max=30"`
+	MonthlyLimit float64 `json:"monthly_limit" validate:"required,gt=0"`
+}
+
+type UpdateBudgetDTO struct {
+	MonthlyLimit float64 `json:"monthly_limit" validate:"required,gt=0"`
+	IsActive     *bool   `json:"is_active,omitempty"`
+}
+
+type BudgetResponseDTO struct {
+	BudgetID     string  `json:"budget_id"`
+	CategoryCode string  `json:"category_code,omitempty"`
+	MonthlyLimit float64 `json:"monthly_limit"`
+	IsActive     bool    `json:"is_active"`
+	PeriodMonth  string  `json:"period_month"`
+	Spent        float64 `json:"spent"`
+	Remaining    float64 `json:"remaining"`
+	UsagePercent float64 `json:"usage_percent"`
+}
+
+type BudgetAlertDTO struct {
+	AlertID      string    `json:"alert_id"`
+	BudgetID     string    `json:"budget_id"`
+	CategoryCode string    `json:"category_code,omitempty"`
+	PeriodMonth  string    `json:"period_month"`
+	Threshold    int       `json:"threshold"`
+	SpentAmount  float64   `json:"spent_amount"`
+	LimitAmount  float64   `json:"limit_amount"`
+	TriggeredAt  time.Time `json:"triggered_at"`
+}
+
+type BudgetSettingsDTO struct {
+	Timezone string `json:"timezone" validate:"required,timezone"`
+}
@@ -1,8 +1,8 @@
 package dtos
 
+// CreateAccountDTO abre a conta no onboarding. A conta nasce com saldo zero e
+// sem limite; AgencyNumber vazio usa a agência padrão.
 type CreateAccountDTO struct {
-	AccountTypeCode string  `json:"account_type_code" validate:"required"`
-	AgencyNumber    string  `json:"agency_number" validate:"required"`
-	InitialDeposit  float64 `json:"initial_deposit" validate:"gte=0"`
-	OverdraftLimit  float64 `json:"overdraft_limit,omitempty"`
+	AccountTypeCode string `json:"account_type_code" validate:"required,max=20"`
+	AgencyNumber    string `json:"agency_number,omitempty" validate:"omitempty,numeric,max=10"`
 }
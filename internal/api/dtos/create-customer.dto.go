@@ -2,27 +2,56 @@ package dtos
 
 import "time"
 
+// CreateCustomerDTO cadastra pessoa física (PF, padrão) ou jurídica (PJ).
+// TaxID é CPF para PF e CNPJ para PJ; PJ exige Company e não tem data de
+// nascimento.
 type CreateCustomerDTO struct {
-	TaxID         string    `json:"tax_id" validate:"required,len=11"`
-	CustomerName  string    `json:"customer_name" validate:"required"`
-	DateOfBirth   time.Time `json:"date_of_birth" validate:"required"`
-	CustomerPhone string    `json:"customer_phone,omitempty"`
-	CustomerEmail string    `json:"customer_email,omitempty"`
-	IsPep         bool      `json:"is_pep"`
+	CustomerType  string            `json:"customer_type,omitempty" validate:"omitempty,oneof=PF PJ"`
+	TaxID         string            `json:"tax_id" validate:"required,tax_id=CustomerType"`
+	CustomerName  string            `json:"customer_name" validate:"required,max=200"`
+	DateOfBirth   time.Time         `json:"date_of_birth" validate:"required_unless=CustomerType PJ"`
+	CustomerPhone string            `json:"customer_phone,omitempty" validate:"omitempty,max=20"`
+	CustomerEmail string            `json:"customer_email,omitempty" validate:"omitempty,email,max=100"`
+	IsPep         bool              `json:"is_pep"`
+	Company       *CreateCompanyDTO `json:"company,omitempty" validate:"required_if=CustomerType PJ,excluded_unless=CustomerType PJ"`
 }
 
+type CreateCompanyDTO struct {
+	LegalName            string                         `json:"legal_name" validate:"required,max=200"`
+	TradeName            string                         `json:"trade_name,omitempty" validate:"omitempty,max=200"`
+	IncorporationDate    time.Time                      `json:"incorporation_date" validate:"required"`
+	LegalRepresentatives []CreateLegalRepresentativeDTO `json:"legal_representatives" validate:"required,min=1,max=10,dive"`
+}
+
+type CreateLegalRepresentativeDTO struct {
+	RepresentativeName string    `json:"representative_name" validate:"required,max=200"`
+	TaxID              string    `json:"tax_id" validate:"required,cpf"`
+	DateOfBirth        time.Time `json:"date_of_birth" validate:"required"`
+	Role               string    `json:"role" validate:"required,oneof=ADMINISTRATOR PARTNER ATTORNEY"`
+	IsPep              bool      `json:"is_pep"`
+	Email              string    `json:"email,omitempty" validate:"omitempty,email"`
+}
+
+// CreateAddressDTO recebe o endereço. No Brasil o CEP é validado e completa
+// logradouro, bairro, cidade e UF quando omitidos; State aceita a sigla ou o
+// nome do estado e é gravado como sigla.
 type CreateAddressDTO struct {
-	AddressLine1 string `json:"address_line_1" validate:"required"`
-	AddressLine2 string `json:"address_line_2,omitempty"`
-	City         string `json:"city" validate:"required"`
-	State        string `json:"state" validate:"required"`
-	Country      string `json:"country" validate:"required"`
-	PostalCode   string `json:"postal_code" validate:"required"`
+	AddressLine1 string `json:"address_line_1" validate:"required,max=200"`
+	AddressLine2 string `json:"address_line_2,omitempty" validate:"omitempty,max=200"`
+	Neighborhood string `json:"neighborhood,omitempty" validate:"omitempty,max=100"`
+	City         string `json:"city" validate:"required,max=100"`
+	State        string `json:"state" validate:"required,max=50,uf=Country"`
+	Country      string `json:"country" validate:"required,max=50"`
+	PostalCode   string `json:"postal_code" validate:"required,max=20,cep=Country"`
 	AddressType  string `json:"address_type" validate:"required,oneof=residential commercial billing"`
 }
 
+// CustomerKycDTO traz os dados declarados no onboarding, gravados em
+// Customer.OtherDetails. O status de KYC e a classificação de risco não são
+// informados pelo cliente: o cadastro nasce PENDING e o risco é calculado.
 type CustomerKycDTO struct {
-	KYCStatus    string `json:"kyc_status" validate:"required,oneof=PENDING APPROVED REJECTED"`
-	RiskRating   string `json:"risk_rating,omitempty"`
-	OtherDetails any    `json:"other_details,omitempty"`
+	Occupation    string         `json:"occupation,omitempty" validate:"omitempty,max=100"`
+	MonthlyIncome float64        `json:"monthly_income,omitempty" validate:"gte=0"`
+	Nationality   string         `json:"nationality,omitempty" validate:"omitempty,max=50"`
+	OtherDetails  map[string]any `json:"other_details,omitempty"`
 }
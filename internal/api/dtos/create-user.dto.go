@@ -1,8 +1,11 @@
 package dtos
 
+// UserRegistrationDTO cria o usuário de acesso no onboarding, sempre com o
+// papel customer. A senha pode ser omitida ao retomar um cadastro que já a
+// tenha salvo.
 type UserRegistrationDTO struct {
 	Username string `json:"username" validate:"required,min=3,max=50"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
-	UserRole string `json:"user_role" validate:"required,oneof=admin customer"`
+	UserRole string `json:"user_role,omitempty" validate:"omitempty,oneof=customer"`
 }
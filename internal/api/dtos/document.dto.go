@@ -0,0 +1,20 @@
+package dtos
+
+import "time"
+
+type UploadDocumentDTO struct {
+	DocumentType string `form:"document_type" validate:"required,oneof=RG CNH PASSPORT RNE PROOF_OF_ADDRESS PROOF_OF_INCOME SELFIE"`
+}
+
+type CustomerDocumentDTO struct {
+	DocumentID   string     `json:"document_id"`
+	CustomerID   string     `json:"customer_id"`
+	DocumentType string     `json:"document_type"`
+	FileName     string     `json:"file_name"`
+	ContentType  string     `json:"content_type"`
+	SizeBytes    int64      `json:"size_bytes"`
+	SHA256       string     `json:"sha256"`
+	RetainUntil  time.Time  `json:"retain_until"`
+	PurgedAt     *time.Time `json:"purged_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
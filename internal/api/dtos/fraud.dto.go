@@ -0,0 +1,49 @@
+package dtos
+
+import "time"
+
+type FraudRuleHitDTO struct {
+	Rule   string `json:"rule"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail"`
+}
+
+type FraudDecisionListRequestDTO struct {
+	Action       string `form:"action" validate:"omitempty,oneof=ALLOW STEP_UP HOLD BLOCK"`
+	ReviewStatus string `form:"review_status" validate:"omitempty,oneof=PENDING APPROVED REJECTED"`
+	CustomerID   string `form:"customer_id" validate:"omitempty,uuid"`
+	Limit        int    `form:"limit" validate:"omitempty,min=1,max=200"`
+	Offset       int    `form:"offset" validate:"omitempty,min=0"`
+}
+
+type FraudDecisionDTO struct {
+	DecisionID        string            `json:"decision_id"`
+	TransactionID     string            `json:"transaction_id,omitempty"`
+	IdempotencyKey    string            `json:"idempotency_key"`
+	CustomerID        string            `json:"customer_id"`
+	UserID            string            `json:"user_id,omitempty"`
+	AccountIDOrigin   string            `json:"account_id_origin"`
+	AccountIDDest     string            `json:"account_id_dest,omitempty"`
+	Amount            float64           `json:"amount"`
+	Score             int               `json:"score"`
+	Action            string            `json:"action"`
+	RulesFired        []FraudRuleHitDTO `json:"rules_fired"`
+	DeviceFingerprint string            `json:"device_fingerprint,omitempty"`
+	StepUpVerified    bool              `json:"step_up_verified"`
+	ReviewStatus      string            `json:"review_status,omitempty"`
+	ReviewedByUserID  string            `json:"reviewed_by_user_id,omitempty"`
+	ReviewedAt        *time.Time        `json:"reviewed_at,omitempty"`
+	ReviewNote        string            `json:"review_note,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+}
+
+type FraudDecisionListDTO struct {
+	Decisions  []FraudDecisionDTO `json:"decisions"`
+	TotalCount int                `json:"total_count"`
+	Limit      int                `json:"limit"`
+	Offset     int                `json:"offset"`
+}
+
+type FraudReviewDTO struct {
+	Note string `json:"note" validate:"required,max=500"`
+}
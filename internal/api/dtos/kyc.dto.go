@@ -0,0 +1,93 @@
+package dtos
+
+import "time"
+
+type KYCDocumentDTO struct {
+	// DocumentID referencia um arquivo enviado em /documents
+	DocumentID       string `json:"document_id,omitempty" validate:"omitempty,uuid"`
+	DocumentType     string `json:"document_type" validate:"required,oneof=RG CNH PASSPORT RNE PROOF_OF_ADDRESS PROOF_OF_INCOME SELFIE"`
+	DocumentNumber   string `json:"document_number,omitempty" validate:"omitempty,max=50"`
+	IssuingAuthority string `json:"issuing_authority,omitempty" validate:"omitempty,max=50"`
+}
+
+type SubmitKYCDTO struct {
+	Occupation    string           `json:"occupation" validate:"required,max=100"`
+	MonthlyIncome float64          `json:"monthly_income" validate:"gte=0"`
+	Nationality   string           `json:"nationality,omitempty" validate:"omitempty,max=50"`
+	Documents     []KYCDocumentDTO `json:"documents" validate:"required,min=1,max=10,dive"`
+}
+
+type KYCCheckResultDTO struct {
+	Check  string `json:"check"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type KYCSubmissionDTO struct {
+	SubmissionID      string              `json:"submission_id"`
+	CustomerID        string              `json:"customer_id"`
+	SubmissionStatus  string              `json:"submission_status"`
+	SubmittedData     any                 `json:"submitted_data,omitempty"`
+	Documents         []KYCDocumentDTO    `json:"documents"`
+	CheckResults      []KYCCheckResultDTO `json:"check_results"`
+	ChecksPassed      bool                `json:"checks_passed"`
+	ChecksRunAt       *time.Time          `json:"checks_run_at,omitempty"`
+	AssignedToUserID  string              `json:"assigned_to_user_id,omitempty"`
+	DecidedByUserID   string              `json:"decided_by_user_id,omitempty"`
+	DecidedAt         *time.Time          `json:"decided_at,omitempty"`
+	DecisionReason    string              `json:"decision_reason,omitempty"`
+	SubmittedAt       time.Time           `json:"submitted_at"`
+	SubmittedByUserID string              `json:"submitted_by_user_id,omitempty"`
+}
+
+type KYCSubmissionListRequestDTO struct {
+	Status           string `form:"status" validate:"omitempty,oneof=PENDING APPROVED REJECTED NEEDS_MORE_INFO"`
+	AssignedToUserID string `form:"assigned_to" validate:"omitempty,uuid"`
+	Unassigned       bool   `form:"unassigned"`
+	CustomerID       string `form:"customer_id" validate:"omitempty,uuid"`
+	Limit            int    `form:"limit" validate:"omitempty,min=1,max=200"`
+	Offset           int    `form:"offset" validate:"omitempty,min=0"`
+}
+
+type KYCSubmissionListDTO struct {
+	Submissions []KYCSubmissionDTO `json:"submissions"`
+	TotalCount  int                `json:"total_count"`
+	Limit       int                `json:"limit"`
+	Offset      int                `json:"offset"`
+}
+
+type AssignKYCSubmissionDTO struct {
+	// AssignedToUserID vazio atribui ao próprio analista
+	AssignedToUserID string `json:"assigned_to_user_id,omitempty" validate:"omitempty,uuid"`
+}
+
+type KYCDecisionDTO struct {
+	Status string `json:"status" validate:"required,oneof=APPROVED REJECTED NEEDS_MORE_INFO"`
+	Reason string `json:"reason" validate:"required,max=1000"`
+}
+
+type KYCStatusHistoryDTO struct {
+	HistoryID       string    `json:"history_id"`
+	SubmissionID    string    `json:"submission_id,omitempty"`
+	PreviousStatus  string    `json:"previous_status"`
+	NewStatus       string    `json:"new_status"`
+	Reason          string    `json:"reason,omitempty"`
+	ChangedByUserID string    `json:"changed_by_user_id,omitempty"`
+	ChangedAt       time.Time `json:"changed_at"`
+}
+
+type KYCLimitsDTO struct {
+	MaxTransactionAmount float64 `json:"max_transaction_amount"`
+	DailyLimit           float64 `json:"daily_limit"`
+	MonthlyLimit         float64 `json:"monthly_limit"`
+}
+
+type KYCStatusDTO struct {
+	CustomerID       string                `json:"customer_id"`
+	KYCStatus        string                `json:"kyc_status"`
+	KYCVerifiedAt    *time.Time            `json:"kyc_verified_at,omitempty"`
+	LatestSubmission *KYCSubmissionDTO     `json:"latest_submission,omitempty"`
+	History          []KYCStatusHistoryDTO `json:"history"`
+	// Limits são os limites de movimentação enquanto o cliente não é aprovado
+	Limits *KYCLimitsDTO `json:"limits,omitempty"`
+}
@@ -7,10 +7,17 @@ type LoginRequestDTO struct {
 	Password        string `json:"password" validate:"required,min=8"`
 }
 
+// LoginResponseDTO traz os tokens do login. Para usuários com verificação em
+// duas etapas, a primeira etapa traz apenas MFARequired, MFAMethod e
+// MFAToken, que só é aceito na verificação do código; ExpiresAt é então a
+// validade do MFAToken. Com os métodos EMAIL e SMS o código já foi enviado.
 type LoginResponseDTO struct {
-	AccessToken  string    `json:"access_token"`
+	AccessToken  string    `json:"access_token,omitempty"`
 	RefreshToken string    `json:"refresh_token,omitempty"`
+	MFARequired  bool      `json:"mfa_required,omitempty"`
+	MFAToken     string    `json:"mfa_token,omitempty"`
+	MFAMethod    string    `json:"mfa_method,omitempty"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	UserID       string    `json:"user_id"`
-	UserRole     string    `json:"user_role"`
+	UserRole     string    `json:"user_role,omitempty"`
 }
@@ -0,0 +1,32 @@
+package dtos
+
+import "time"
+
+type NotificationPreferenceDTO struct {
+	EventType    string `json:"event_type" validate:"required,max=50"`
+	EmailEnabled bool   `json:"email_enabled"`
+	SMSEnabled   bool   `json:"sms_enabled"`
+	PushEnabled  bool   `json:"push_enabled"`
+}
+
+type NotificationPreferencesDTO struct {
+	Locale      string                      `json:"locale,omitempty" validate:"omitempty,oneof=pt-BR en"`
+	Preferences []NotificationPreferenceDTO `json:"preferences" validate:"dive"`
+}
+
+type RegisterPushDeviceDTO struct {
+	Token    string `json:"token" validate:"required,max=500"`
+	Platform string `json:"platform" validate:"required,oneof=android ios web"`
+}
+
+type NotificationDTO struct {
+	NotificationID string     `json:"notification_id"`
+	Channel        string     `json:"channel"`
+	Recipient      string     `json:"recipient"`
+	TemplateKey    string     `json:"template_key"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+}
@@ -0,0 +1,49 @@
+package dtos
+
+import "time"
+
+type PaymentBatchSummaryDTO struct {
+	BatchID         string     `json:"batch_id"`
+	FileName        string     `json:"file_name"`
+	Status          string     `json:"status"`
+	TotalRows       int        `json:"total_rows"`
+	ValidRows       int        `json:"valid_rows"`
+	InvalidRows     int        `json:"invalid_rows"`
+	ProcessedRows   int        `json:"processed_rows"`
+	SucceededRows   int        `json:"succeeded_rows"`
+	FailedRows      int        `json:"failed_rows"`
+	TotalAmount     float64    `json:"total_amount"`
+	ProgressPercent float64    `json:"progress_percent"`
+	CreatedAt       time.Time  `json:"created_at"`
+	ApprovedAt      *time.Time `json:"approved_at,omitempty"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+}
+
+type PaymentBatchRowDTO struct {
+	LineNumber          int               `json:"line_number"`
+	Status              string            `json:"status"`
+	TransactionTypeCode string            `json:"transaction_type_code"`
+	AccountIDOrigin     string            `json:"account_id_origin"`
+	AccountIDDest       string            `json:"account_id_dest,omitempty"`
+	Amount              float64           `json:"amount"`
+	Description         string            `json:"description,omitempty"`
+	IdempotencyKey      string            `json:"idempotency_key"`
+	Errors              map[string]string `json:"errors,omitempty"`
+	TransactionID       string            `json:"transaction_id,omitempty"`
+	ErrorMessage        string            `json:"error_message,omitempty"`
+}
+
+type PaymentBatchPreviewDTO struct {
+	Batch      PaymentBatchSummaryDTO `json:"batch"`
+	Rows       []PaymentBatchRowDTO   `json:"rows"`
+	TotalCount int                    `json:"total_count"`
+	Limit      int                    `json:"limit"`
+	Offset     int                    `json:"offset"`
+}
+
+type PaymentBatchRowsRequestDTO struct {
+	OnlyInvalid bool `form:"only_invalid"`
+	Limit       int  `form:"limit" validate:"omitempty,min=1,max=500"`
+	Offset      int  `form:"offset" validate:"omitempty,min=0"`
+}
@@ -0,0 +1,19 @@
+package dtos
+
+type PostalCodeDTO struct {
+	PostalCode   string `json:"postal_code"`
+	Street       string `json:"street"`
+	Neighborhood string `json:"neighborhood"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	StateName    string `json:"state_name"`
+}
+
+type AddressNormalizationDTO struct {
+	Address *CreateAddressDTO `json:"address"`
+	// Found indica se o CEP foi encontrado na consulta
+	Found bool `json:"found"`
+	// Warnings aponta divergências entre o CEP e os dados informados, como
+	// cidade ou UF diferentes. Não impedem o cadastro.
+	Warnings map[string]string `json:"warnings,omitempty"`
+}
@@ -0,0 +1,63 @@
+package dtos
+
+import "time"
+
+// CreatePrivacyRequestDTO abre um pedido do titular com base na LGPD: EXPORT
+// gera um arquivo com os dados do cliente e ANONYMIZATION remove os dados
+// pessoais depois do prazo de guarda dos registros. CurrentPassword confirma
+// a identidade do usuário.
+type CreatePrivacyRequestDTO struct {
+	Type            string `json:"type" validate:"required,oneof=EXPORT ANONYMIZATION"`
+	Reason          string `json:"reason,omitempty" validate:"omitempty,max=500"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+// CreateCustomerPrivacyRequestDTO registra um pedido recebido por outro canal
+// (atendimento, ouvidoria, encarregado de dados)
+type CreateCustomerPrivacyRequestDTO struct {
+	Type   string `json:"type" validate:"required,oneof=EXPORT ANONYMIZATION"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type RejectPrivacyRequestDTO struct {
+	Note string `json:"note" validate:"required,max=1000"`
+}
+
+type PrivacyRequestDTO struct {
+	RequestID         string     `json:"request_id"`
+	CustomerID        string     `json:"customer_id"`
+	Type              string     `json:"type"`
+	Status            string     `json:"status"`
+	Reason            string     `json:"reason,omitempty"`
+	DueAt             time.Time  `json:"due_at"`
+	Overdue           bool       `json:"overdue"`
+	ScheduledFor      *time.Time `json:"scheduled_for,omitempty"`
+	Attempts          int        `json:"attempts,omitempty"`
+	LastError         string     `json:"last_error,omitempty"`
+	ArtifactSHA256    string     `json:"artifact_sha256,omitempty"`
+	ArtifactSizeBytes int64      `json:"artifact_size_bytes,omitempty"`
+	ArtifactExpiresAt *time.Time `json:"artifact_expires_at,omitempty"`
+	RequestedByUserID string     `json:"requested_by_user_id,omitempty"`
+	HandledByUserID   string     `json:"handled_by_user_id,omitempty"`
+	ResolutionNote    string     `json:"resolution_note,omitempty"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// PrivacyRequestListRequestDTO filtra os pedidos. Overdue traz apenas os
+// pedidos ainda sem resposta com o prazo legal vencido.
+type PrivacyRequestListRequestDTO struct {
+	Status     string `form:"status" validate:"omitempty,oneof=PENDING SCHEDULED COMPLETED REJECTED FAILED"`
+	Type       string `form:"type" validate:"omitempty,oneof=EXPORT ANONYMIZATION"`
+	CustomerID string `form:"customer_id" validate:"omitempty,uuid"`
+	Overdue    bool   `form:"overdue"`
+	Limit      int    `form:"limit" validate:"omitempty,min=1,max=200"`
+	Offset     int    `form:"offset" validate:"omitempty,min=0"`
+}
+
+type PrivacyRequestListDTO struct {
+	Requests   []PrivacyRequestDTO `json:"requests"`
+	TotalCount int                 `json:"total_count"`
+	Limit      int                 `json:"limit"`
+	Offset     int                 `json:"offset"`
+}
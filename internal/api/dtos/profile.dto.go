@@ -0,0 +1,75 @@
+package dtos
+
+import "time"
+
+// ProfileChangeRequestDTO pede a alteração de um campo do cadastro. Email e
+// telefone são confirmados por código enviado ao novo contato; o nome exige
+// documentos enviados em /documents e passa pela análise do compliance.
+// CurrentPassword confirma a identidade do usuário.
+type ProfileChangeRequestDTO struct {
+	Field           string   `json:"field" validate:"required,oneof=customer_name customer_email customer_phone"`
+	Value           string   `json:"value" validate:"required,max=200"`
+	Reason          string   `json:"reason,omitempty" validate:"omitempty,max=500"`
+	DocumentIDs     []string `json:"document_ids,omitempty" validate:"omitempty,max=5,dive,uuid"`
+	CurrentPassword string   `json:"current_password" validate:"required"`
+}
+
+type VerifyProfileChangeDTO struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type ProfileChangeDTO struct {
+	RequestID         string     `json:"request_id"`
+	CustomerID        string     `json:"customer_id"`
+	Field             string     `json:"field"`
+	OldValue          string     `json:"old_value,omitempty"`
+	NewValue          string     `json:"new_value"`
+	Reason            string     `json:"reason,omitempty"`
+	DocumentIDs       []string   `json:"document_ids,omitempty"`
+	Status            string     `json:"status"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	RequestedByUserID string     `json:"requested_by_user_id,omitempty"`
+	ReviewedByUserID  string     `json:"reviewed_by_user_id,omitempty"`
+	ReviewedAt        *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNote        string     `json:"review_note,omitempty"`
+	AppliedAt         *time.Time `json:"applied_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+type ProfileChangeListRequestDTO struct {
+	Status     string `form:"status" validate:"omitempty,oneof=PENDING_VERIFICATION PENDING_REVIEW APPLIED REJECTED CANCELLED EXPIRED"`
+	Field      string `form:"field" validate:"omitempty,oneof=customer_name customer_email customer_phone"`
+	CustomerID string `form:"customer_id" validate:"omitempty,uuid"`
+	Limit      int    `form:"limit" validate:"omitempty,min=1,max=200"`
+	Offset     int    `form:"offset" validate:"omitempty,min=0"`
+}
+
+type ProfileChangeListDTO struct {
+	Requests   []ProfileChangeDTO `json:"requests"`
+	TotalCount int                `json:"total_count"`
+	Limit      int                `json:"limit"`
+	Offset     int                `json:"offset"`
+}
+
+type ReviewProfileChangeDTO struct {
+	Decision string `json:"decision" validate:"required,oneof=APPROVED REJECTED"`
+	Note     string `json:"note" validate:"required,max=1000"`
+}
+
+type ProfileHistoryRequestDTO struct {
+	Field string `form:"field" validate:"omitempty,oneof=customer_name customer_email customer_phone"`
+}
+
+type ProfileVersionDTO struct {
+	Field           string     `json:"field"`
+	Version         int        `json:"version"`
+	Value           string     `json:"value,omitempty"`
+	ValidFrom       time.Time  `json:"valid_from"`
+	ValidTo         *time.Time `json:"valid_to,omitempty"`
+	ChangeRequestID string     `json:"change_request_id,omitempty"`
+	ChangedByUserID string     `json:"changed_by_user_id,omitempty"`
+}
+
+type ProfileHistoryDTO struct {
+	Versions []ProfileVersionDTO `json:"versions"`
+}
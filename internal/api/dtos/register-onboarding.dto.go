@@ -1,5 +1,10 @@
 package dtos
 
+import "time"
+
+// AccountOnboardingDTO reúne as seções do cadastro. No envio único todas as
+// seções, exceto KYC, são obrigatórias; nas sessões de onboarding cada envio
+// salva apenas as seções presentes.
 type AccountOnboardingDTO struct {
 	User     *UserRegistrationDTO `json:"user,omitempty"`
 	Customer *CreateCustomerDTO   `json:"customer,omitempty"`
@@ -11,8 +16,41 @@ type AccountOnboardingDTO struct {
 type AccountOnboardingResponseDTO struct {
 	UserID      string `json:"user_id"`
 	CustomerID  string `json:"customer_id"`
+	AddressID   string `json:"address_id"`
 	AccountID   string `json:"account_id"`
 	KYCStatus   string `json:"kyc_status"`
 	AccountType string `json:"account_type"`
+	AgencyNum   string `json:"agency_number"`
 	AccountNum  string `json:"account_number"`
+	// ScreeningStatus é CLEAR, PENDING ou CONFIRMED. Com PENDING a conta
+	// fica aberta, mas sem movimentação até a análise do compliance.
+	ScreeningStatus string `json:"screening_status"`
+	// Warnings traz as divergências entre o CEP e o endereço informado
+	Warnings map[string]string `json:"warnings,omitempty"`
+}
+
+type OnboardingSectionDTO struct {
+	Saved    bool              `json:"saved"`
+	Complete bool              `json:"complete"`
+	Errors   map[string]string `json:"errors,omitempty"`
+	// Warnings não impedem a conclusão do cadastro
+	Warnings map[string]string `json:"warnings,omitempty"`
+}
+
+type OnboardingSessionDTO struct {
+	SessionID string `json:"session_id"`
+	// ResumeToken só é devolvido na criação da sessão; deve ser enviado no
+	// header X-Onboarding-Token para continuar o cadastro
+	ResumeToken string                          `json:"resume_token,omitempty"`
+	Status      string                          `json:"status"`
+	Sections    map[string]OnboardingSectionDTO `json:"sections"`
+	// Draft traz as seções salvas, sem a senha
+	Draft     *AccountOnboardingDTO         `json:"draft,omitempty"`
+	Result    *AccountOnboardingResponseDTO `json:"result,omitempty"`
+	ExpiresAt time.Time                     `json:"expires_at"`
+	UpdatedAt time.Time                     `json:"updated_at"`
+}
+
+type ResumeOnboardingDTO struct {
+	Email string `json:"email" validate:"required,email"`
 }
@@ -0,0 +1,72 @@
+package dtos
+
+import "time"
+
+type RiskFactorDTO struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Points      int    `json:"points"`
+	// MinRating eleva a classificação a pelo menos este nível, independente da pontuação
+	MinRating string `json:"min_rating,omitempty"`
+}
+
+type RiskAssessmentDTO struct {
+	AssessmentID     string          `json:"assessment_id"`
+	CustomerID       string          `json:"customer_id"`
+	RiskScore        int             `json:"risk_score"`
+	ComputedRating   string          `json:"computed_rating"`
+	RiskRating       string          `json:"risk_rating"`
+	Factors          []RiskFactorDTO `json:"factors"`
+	TriggerType      string          `json:"trigger_type"`
+	OverrideID       string          `json:"override_id,omitempty"`
+	AssessedByUserID string          `json:"assessed_by_user_id,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
+
+type RiskOverrideDTO struct {
+	OverrideID       string     `json:"override_id"`
+	CustomerID       string     `json:"customer_id"`
+	RiskRating       string     `json:"risk_rating"`
+	Justification    string     `json:"justification"`
+	CreatedByUserID  string     `json:"created_by_user_id"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	RevokedByUserID  string     `json:"revoked_by_user_id,omitempty"`
+	RevocationReason string     `json:"revocation_reason,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+type CustomerRiskDTO struct {
+	CustomerID     string             `json:"customer_id"`
+	RiskRating     string             `json:"risk_rating,omitempty"`
+	RiskScore      *int               `json:"risk_score,omitempty"`
+	RiskAssessedAt *time.Time         `json:"risk_assessed_at,omitempty"`
+	LastAssessment *RiskAssessmentDTO `json:"last_assessment,omitempty"`
+	ActiveOverride *RiskOverrideDTO   `json:"active_override,omitempty"`
+}
+
+type OverrideRiskRatingDTO struct {
+	RiskRating    string     `json:"risk_rating" validate:"required,oneof=LOW MEDIUM HIGH"`
+	Justification string     `json:"justification" validate:"required,min=20,max=2000"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+type RevokeRiskOverrideDTO struct {
+	Reason string `json:"reason" validate:"required,max=1000"`
+}
+
+type RiskAssessmentListRequestDTO struct {
+	Limit  int `form:"limit" validate:"omitempty,min=1,max=200"`
+	Offset int `form:"offset" validate:"omitempty,min=0"`
+}
+
+type RiskAssessmentListDTO struct {
+	Assessments []RiskAssessmentDTO `json:"assessments"`
+	TotalCount  int                 `json:"total_count"`
+	Limit       int                 `json:"limit"`
+	Offset      int                 `json:"offset"`
+}
+
+type RiskOverrideListDTO struct {
+	Overrides []RiskOverrideDTO `json:"overrides"`
+}
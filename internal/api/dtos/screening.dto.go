@@ -0,0 +1,73 @@
+package dtos
+
+import "time"
+
+type ImportSanctionsListDTO struct {
+	ListCode string `form:"list_code" validate:"required,max=50"`
+	ListName string `form:"list_name" validate:"required,max=200"`
+	ListType string `form:"list_type" validate:"required,oneof=SANCTIONS PEP"`
+	// Format vazio detecta o formato pelo conteúdo
+	Format string `form:"format" validate:"omitempty,oneof=csv xml"`
+}
+
+type SanctionsListDTO struct {
+	ListID       string     `json:"list_id"`
+	ListCode     string     `json:"list_code"`
+	ListName     string     `json:"list_name"`
+	ListType     string     `json:"list_type"`
+	ContentHash  string     `json:"content_hash"`
+	EntryCount   int        `json:"entry_count"`
+	ImportedAt   time.Time  `json:"imported_at"`
+	RescreenedAt *time.Time `json:"rescreened_at,omitempty"`
+	// Unchanged indica uma importação com o mesmo conteúdo da versão atual
+	Unchanged bool `json:"unchanged,omitempty"`
+}
+
+type ScreeningReviewListRequestDTO struct {
+	Status     string `form:"status" validate:"omitempty,oneof=PENDING CLEARED CONFIRMED"`
+	Context    string `form:"context" validate:"omitempty,oneof=ONBOARDING TRANSFER RESCREEN MANUAL"`
+	CustomerID string `form:"customer_id" validate:"omitempty,uuid"`
+	Limit      int    `form:"limit" validate:"omitempty,min=1,max=200"`
+	Offset     int    `form:"offset" validate:"omitempty,min=0"`
+}
+
+type ResolveScreeningReviewDTO struct {
+	Note string `json:"note" validate:"required,max=1000"`
+}
+
+type ScreeningMatchDTO struct {
+	EntryID     string  `json:"entry_id"`
+	ListCode    string  `json:"list_code"`
+	MatchedName string  `json:"matched_name"`
+	Score       float64 `json:"score"`
+}
+
+type ScreeningReviewDTO struct {
+	ReviewID              string              `json:"review_id"`
+	CustomerID            string              `json:"customer_id"`
+	ScreeningContext      string              `json:"screening_context"`
+	ScreenedName          string              `json:"screened_name"`
+	RequestedByCustomerID string              `json:"requested_by_customer_id,omitempty"`
+	CounterpartyAccountID string              `json:"counterparty_account_id,omitempty"`
+	TopScore              float64             `json:"top_score"`
+	ReviewStatus          string              `json:"review_status"`
+	ReviewedByUserID      string              `json:"reviewed_by_user_id,omitempty"`
+	ReviewedAt            *time.Time          `json:"reviewed_at,omitempty"`
+	ReviewNote            string              `json:"review_note,omitempty"`
+	CreatedAt             time.Time           `json:"created_at"`
+	Matches               []ScreeningMatchDTO `json:"matches,omitempty"`
+}
+
+type ScreeningReviewListDTO struct {
+	Reviews    []ScreeningReviewDTO `json:"reviews"`
+	TotalCount int                  `json:"total_count"`
+	Limit      int                  `json:"limit"`
+	Offset     int                  `json:"offset"`
+}
+
+type ScreeningResultDTO struct {
+	CustomerID string `json:"customer_id"`
+	// Status é CLEAR, PENDING ou CONFIRMED
+	Status string              `json:"status"`
+	Review *ScreeningReviewDTO `json:"review,omitempty"`
+}
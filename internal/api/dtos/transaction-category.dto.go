@@ -0,0 +1,74 @@
+package dtos
+
+import "time"
+
+type TransactionCategoryDTO struct {
+	CategoryCode string `json:"category_code"`
+	Description  string `json:"description"`
+	Direction    string `json:"direction"`
+}
+
+type UpdateTransactionCategoryDTO struct {
+	CategoryCode string `json:"category_code" validate:"required,max=30"`
+	Remember     bool   `json:"remember"`
+}
+
+type TransactionCategoryResponseDTO struct {
+	TransactionID    string    `json:"transaction_id"`
+	CategoryCode     string    `json:"category_code"`
+	CategorySource   string    `json:"category_source"`
+	Direction        string    `json:"direction"`
+	Amount           float64   `json:"amount"`
+	CounterpartyName string    `json:"counterparty_name,omitempty"`
+	CategorizedAt    time.Time `json:"categorized_at"`
+}
+
+type CategoryRuleDTO struct {
+	CategoryCode        string `json:"category_code" validate:"required,max=30"`
+	Priority            int    `json:"priority" validate:"gte=0"`
+	Direction           string `json:"direction" validate:"required,oneof=IN OUT"`
+	DescriptionPattern  string `json:"description_pattern,omitempty" validate:"max=200"`
+	CounterpartyKey     string `json:"counterparty_key,omitempty" validate:"max=200"`
+	TransactionTypeCode string `json:"transaction_type_code,omitempty" validate:"omitempty,oneof=PIX TED TRANSFER INTERNAL"`
+	IsActive            *bool  `json:"is_active,omitempty"`
+}
+
+type SpendingInsightsRequestDTO struct {
+	Months int `form:"months" validate:"omitempty,min=1,max=24"`
+	Top    int `form:"top" validate:"omitempty,min=1,max=50"`
+}
+
+type SpendingInsightsResponseDTO struct {
+	From              time.Time                 `json:"from"`
+	To                time.Time                 `json:"to"`
+	Months            []MonthlySpendingDTO      `json:"months"`
+	TopCounterparties []CounterpartySpendingDTO `json:"top_counterparties"`
+	MonthOverMonth    []CategoryChangeDTO       `json:"month_over_month"`
+}
+
+type MonthlySpendingDTO struct {
+	Month      string                `json:"month"`
+	Total      float64               `json:"total"`
+	Categories []CategorySpendingDTO `json:"categories"`
+}
+
+type CategorySpendingDTO struct {
+	CategoryCode string  `json:"category_code"`
+	Description  string  `json:"description"`
+	Amount       float64 `json:"amount"`
+	Count        int     `json:"count"`
+}
+
+type CounterpartySpendingDTO struct {
+	Counterparty string  `json:"counterparty"`
+	Amount       float64 `json:"amount"`
+	Count        int     `json:"count"`
+}
+
+type CategoryChangeDTO struct {
+	CategoryCode  string   `json:"category_code"`
+	CurrentMonth  float64  `json:"current_month"`
+	PreviousMonth float64  `json:"previous_month"`
+	Change        float64  `json:"change"`
+	ChangePercent *float64 `json:"change_percent,omitempty"`
+}
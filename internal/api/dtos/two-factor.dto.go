@@ -1,10 +1,91 @@
 package dtos
 
+import "time"
+
+// TwoFactorVerificationDTO conclui o login de um usuário com verificação em
+// duas etapas. Code é o código do aplicativo autenticador, o código enviado
+// por email ou SMS ou um código de recuperação.
 type TwoFactorVerificationDTO struct {
-	UserID string `json:"user_id" validate:"required,uuid4"`
-	Code   string `json:"code" validate:"required,len=6"`
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,min=6,max=20"`
 }
 
+// TwoFactorVerificationSenderDTO pede o envio de um código de verificação.
+// O código vai sempre ao contato cadastrado; sem Channel é usado o método do
+// usuário ou, sem verificação em duas etapas, o email.
 type TwoFactorVerificationSenderDTO struct {
-	Email string `json:"email" validate:"required,email"`
+	Channel string `json:"channel" validate:"omitempty,oneof=EMAIL SMS"`
+}
+
+// MFAResendDTO pede um novo código por email ou SMS na segunda etapa do login
+type MFAResendDTO struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+// OneTimeCodeSentDTO informa o envio de um código. ResendAfter é quando um
+// novo código pode ser pedido.
+type OneTimeCodeSentDTO struct {
+	Channel     string    `json:"channel"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	ResendAfter time.Time `json:"resend_after"`
+}
+
+// StepUpDTO confirma a identidade antes de uma operação sensível com o
+// código do aplicativo autenticador ou o código enviado por email ou SMS
+type StepUpDTO struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// StepUpTokenDTO traz o token enviado no header X-Step-Up-Token das
+// operações sensíveis até ExpiresAt
+type StepUpTokenDTO struct {
+	StepUpToken string    `json:"step_up_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// TwoFactorEnrollDTO inicia a configuração do aplicativo autenticador
+type TwoFactorEnrollDTO struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+// TwoFactorEnrollmentDTO traz o segredo para o aplicativo autenticador, como
+// texto, URI otpauth:// e imagem QR em PNG (base64). A verificação só é
+// ativada após a confirmação com um código gerado pelo aplicativo.
+type TwoFactorEnrollmentDTO struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+	QRCodePNG       string `json:"qr_code_png"`
+}
+
+// TwoFactorEnrollOTPDTO inicia a verificação em duas etapas por código
+// enviado ao email ou ao telefone cadastrado
+type TwoFactorEnrollOTPDTO struct {
+	Channel         string `json:"channel" validate:"required,oneof=EMAIL SMS"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+// TwoFactorConfirmDTO ativa a verificação em duas etapas com o primeiro
+// código gerado pelo aplicativo autenticador ou enviado por email ou SMS
+type TwoFactorConfirmDTO struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// TwoFactorReauthenticateDTO confirma a desativação da verificação em duas
+// etapas e a troca dos códigos de recuperação com a senha e um código
+type TwoFactorReauthenticateDTO struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	Code            string `json:"code" validate:"required,min=6,max=20"`
+}
+
+// TwoFactorRecoveryCodesDTO traz os códigos de recuperação, exibidos apenas
+// uma vez
+type TwoFactorRecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorStatusDTO struct {
+	Enabled                bool   `json:"enabled"`
+	Method                 string `json:"method,omitempty"`
+	PendingEnrollment      bool   `json:"pending_enrollment"`
+	RecoveryCodesRemaining int    `json:"recovery_codes_remaining"`
 }
@@ -0,0 +1,72 @@
+package dtos
+
+import "time"
+
+type CreateWebhookSubscriptionDTO struct {
+	URL         string   `json:"url" validate:"required,url,max=500"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1,dive,oneof=transaction.status_changed account.status_changed customer.kyc_status_changed"`
+	Description string   `json:"description,omitempty" validate:"max=200"`
+	// PartnerName é usado apenas nas assinaturas de parceiro criadas por administradores
+	PartnerName string `json:"partner_name,omitempty" validate:"max=100"`
+}
+
+type UpdateWebhookSubscriptionDTO struct {
+	URL         string   `json:"url,omitempty" validate:"omitempty,url,max=500"`
+	EventTypes  []string `json:"event_types,omitempty" validate:"omitempty,min=1,dive,oneof=transaction.status_changed account.status_changed customer.kyc_status_changed"`
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=200"`
+	// IsActive=true reativa um endpoint desativado por falhas e zera o contador
+	IsActive *bool `json:"is_active,omitempty"`
+}
+
+type WebhookSubscriptionDTO struct {
+	SubscriptionID      string     `json:"subscription_id"`
+	CustomerID          string     `json:"customer_id,omitempty"`
+	PartnerName         string     `json:"partner_name,omitempty"`
+	URL                 string     `json:"url"`
+	EventTypes          []string   `json:"event_types"`
+	Description         string     `json:"description,omitempty"`
+	IsActive            bool       `json:"is_active"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	// Secret só é retornado na criação e na rotação
+	Secret string `json:"secret,omitempty"`
+}
+
+type WebhookDeliveryListRequestDTO struct {
+	Status string `form:"status" validate:"omitempty,oneof=PENDING SENDING SUCCEEDED FAILED"`
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" validate:"omitempty,min=0"`
+}
+
+type WebhookDeliveryListDTO struct {
+	Deliveries []WebhookDeliveryDTO `json:"deliveries"`
+	TotalCount int                  `json:"total_count"`
+	Limit      int                  `json:"limit"`
+	Offset     int                  `json:"offset"`
+}
+
+type WebhookDeliveryDTO struct {
+	DeliveryID     string                      `json:"delivery_id"`
+	SubscriptionID string                      `json:"subscription_id"`
+	EventID        string                      `json:"event_id"`
+	EventType      string                      `json:"event_type"`
+	Status         string                      `json:"status"`
+	Attempts       int                         `json:"attempts"`
+	NextAttemptAt  *time.Time                  `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int                        `json:"last_status_code,omitempty"`
+	LastError      string                      `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time                  `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time                   `json:"created_at"`
+	AttemptLog     []WebhookDeliveryAttemptDTO `json:"attempt_log,omitempty"`
+}
+
+type WebhookDeliveryAttemptDTO struct {
+	AttemptNumber int       `json:"attempt_number"`
+	StatusCode    *int      `json:"status_code,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	DurationMs    int64     `json:"duration_ms"`
+	AttemptedAt   time.Time `json:"attempted_at"`
+}
@@ -1 +1,59 @@
 package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type AccountHandler struct {
+	service services.AccountService
+}
+
+func NewAccountHandler(service services.AccountService) *AccountHandler {
+	return &AccountHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas administrativas de contas no grupo autenticado
+func (h *AccountHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	admin := rg.Group("/admin/accounts", middlewares.RequireRole("admin"))
+	admin.PUT("/:id/status", h.ChangeStatus)
+	admin.GET("/:id/status-history", h.StatusHistory)
+}
+
+// ChangeStatus bloqueia, desbloqueia ou encerra uma conta
+func (h *AccountHandler) ChangeStatus(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.ChangeAccountStatusDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	history, err := h.service.ChangeStatus(c.Request.Context(), userID, c.Param("id"), c.ClientIP(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// StatusHistory lista o histórico de status da conta
+func (h *AccountHandler) StatusHistory(c *gin.Context) {
+	history, err := h.service.StatusHistory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
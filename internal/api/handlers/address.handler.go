@@ -1 +1,189 @@
 package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type AddressHandler struct {
+	service services.AddressService
+}
+
+func NewAddressHandler(service services.AddressService) *AddressHandler {
+	return &AddressHandler{
+		service: service,
+	}
+}
+
+// RegisterPublicRoutes registra as consultas de CEP no grupo público, usadas
+// no cadastro antes do login
+func (h *AddressHandler) RegisterPublicRoutes(rg *gin.RouterGroup) {
+	rg.GET("/postal-codes/:cep", h.LookupPostalCode)
+	rg.POST("/addresses/normalize", h.Normalize)
+}
+
+// LookupPostalCode retorna logradouro, bairro, cidade e UF do CEP
+func (h *AddressHandler) LookupPostalCode(c *gin.Context) {
+	response, err := h.service.LookupPostalCode(c.Request.Context(), c.Param("cep"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Normalize completa o endereço pelo CEP e aponta divergências com a cidade e a UF
+func (h *AddressHandler) Normalize(c *gin.Context) {
+	var req dtos.CreateAddressDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Corpo da requisição inválido",
+		})
+		return
+	}
+
+	response, err := h.service.Normalize(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RegisterRoutes registra as rotas de endereços do cliente no grupo autenticado
+func (h *AddressHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	addresses := rg.Group("/addresses")
+	addresses.GET("", h.List)
+	addresses.GET("/history", h.History)
+	addresses.POST("", h.Change)
+	addresses.POST("/:id/primary", h.SetPrimary)
+	addresses.POST("/:id/end", h.End)
+
+	admin := rg.Group("/admin", middlewares.RequireRole("admin", "compliance"))
+	admin.GET("/customers/:id/addresses", h.CustomerHistory)
+}
+
+// List retorna os endereços vigentes do cliente
+func (h *AddressHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// History lista o histórico de endereços, opcionalmente os vigentes em uma data
+func (h *AddressHandler) History(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.AddressHistoryRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.service.History(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CustomerHistory lista o histórico de endereços de um cliente para o compliance
+func (h *AddressHandler) CustomerHistory(c *gin.Context) {
+	var req dtos.AddressHistoryRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.service.CustomerHistory(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Change inclui um endereço, substituindo o anterior quando indicado
+func (h *AddressHandler) Change(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// o endereço é completado pelo CEP antes da validação, feita pelo serviço
+	var req dtos.ChangeAddressDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Corpo da requisição inválido",
+		})
+		return
+	}
+
+	response, err := h.service.Change(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// SetPrimary torna o endereço o principal do seu tipo
+func (h *AddressHandler) SetPrimary(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.ConfirmAddressChangeDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.SetPrimary(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// End encerra a vigência do endereço, mantendo-o no histórico
+func (h *AddressHandler) End(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.ConfirmAddressChangeDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.End(c.Request.Context(), userID, c.Param("id"), &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
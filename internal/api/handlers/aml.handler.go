@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type AmlHandler struct {
+	service services.AmlService
+}
+
+func NewAmlHandler(service services.AmlService) *AmlHandler {
+	return &AmlHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de compliance de AML no grupo autenticado
+func (h *AmlHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	admin := rg.Group("/admin/aml", middlewares.RequireRole("admin", "compliance"))
+	admin.GET("/cases", h.ListCases)
+	admin.GET("/cases/:id", h.GetCase)
+	admin.PUT("/cases/:id/assignee", h.Assign)
+	admin.PUT("/cases/:id/status", h.ChangeStatus)
+	admin.POST("/cases/:id/notes", h.AddNote)
+	admin.POST("/cases/:id/close", h.Close)
+	admin.POST("/reports", h.ExportReport)
+}
+
+// ListCases lista os casos de AML
+func (h *AmlHandler) ListCases(c *gin.Context) {
+	var req dtos.AmlCaseListRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.service.ListCases(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCase retorna o caso com os alertas e notas
+func (h *AmlHandler) GetCase(c *gin.Context) {
+	response, err := h.service.GetCase(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Assign atribui o caso a um analista
+func (h *AmlHandler) Assign(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.AssignAmlCaseDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.Assign(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ChangeStatus altera o status de um caso em andamento
+func (h *AmlHandler) ChangeStatus(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.ChangeAmlCaseStatusDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.ChangeStatus(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AddNote registra uma nota no caso
+func (h *AmlHandler) AddNote(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.AddAmlCaseNoteDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.AddNote(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// Close encerra o caso com o parecer
+func (h *AmlHandler) Close(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.CloseAmlCaseDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.Close(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportReport devolve o lote de comunicação ao COAF em XML
+func (h *AmlHandler) ExportReport(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.AmlReportRequestDTO
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+
+	report, err := h.service.ExportReport(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "coaf-"+time.Now().Format("20060102150405")+".xml"))
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", report)
+}
@@ -1 +1,42 @@
 package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type AuditHandler struct {
+	service services.AuditService
+}
+
+func NewAuditHandler(service services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra a consulta do registro de auditoria no grupo autenticado
+func (h *AuditHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	admin := rg.Group("/admin/audit-logs", middlewares.RequireRole("admin", "compliance"))
+	admin.GET("", h.List)
+}
+
+// List consulta o registro de auditoria por tabela, registro, usuário e período
+func (h *AuditHandler) List(c *gin.Context) {
+	var req dtos.AuditLogListRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.service.List(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
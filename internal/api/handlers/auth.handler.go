@@ -1 +1,94 @@
 package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type AuthHandler struct {
+	service services.AuthService
+}
+
+func NewAuthHandler(service services.AuthService) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de autenticação no grupo público
+func (h *AuthHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	auth := rg.Group("/auth")
+	auth.POST("/login", h.Login)
+	auth.POST("/2fa/verify", h.VerifyMFA)
+	auth.POST("/2fa/resend", h.ResendMFACode)
+}
+
+// Login autentica o usuário e retorna os tokens de acesso e de renovação
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req dtos.LoginRequestDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.Login(authRequestContext(c), &req)
+	respondLogin(c, response, err)
+}
+
+// VerifyMFA conclui o login com o código da verificação em duas etapas
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req dtos.TwoFactorVerificationDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.VerifyMFA(authRequestContext(c), &req)
+	respondLogin(c, response, err)
+}
+
+// ResendMFACode envia um novo código da segunda etapa por email ou SMS
+func (h *AuthHandler) ResendMFACode(c *gin.Context) {
+	var req dtos.MFAResendDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.ResendMFACode(authRequestContext(c), &req)
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// authRequestContext anexa ao contexto a origem e o dispositivo gravados no
+// registro de acesso
+func authRequestContext(c *gin.Context) context.Context {
+	ctx := auditContext(c)
+	if fingerprint := c.GetHeader(DeviceFingerprintHeader); fingerprint != "" {
+		ctx = services.WithDeviceFingerprint(ctx, fingerprint)
+	}
+	return ctx
+}
+
+func respondLogin(c *gin.Context, response *dtos.LoginResponseDTO, err error) {
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+)
+
+// bindJSON faz o bind do corpo da requisição e valida as tags `validate` do DTO.
+// Em caso de erro a resposta já é escrita e false é retornado.
+func bindJSON(c *gin.Context, dto any) bool {
+	if err := c.ShouldBindJSON(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Corpo da requisição inválido",
+		})
+		return false
+	}
+
+	if err := validation.Struct(dto); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Dados inválidos",
+			"fields": validation.FieldErrors(err),
+		})
+		return false
+	}
+
+	return true
+}
+
+// bindQuery faz o bind da query string e valida as tags `validate` do DTO.
+// Em caso de erro a resposta já é escrita e false é retornado.
+func bindQuery(c *gin.Context, dto any) bool {
+	if err := c.ShouldBindQuery(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Parâmetros inválidos",
+		})
+		return false
+	}
+
+	if err := validation.Struct(dto); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Dados inválidos",
+			"fields": validation.FieldErrors(err),
+		})
+		return false
+	}
+
+	return true
+}
+
+// currentUserID retorna o ID do usuário autenticado ou responde 401
+func currentUserID(c *gin.Context) (string, bool) {
+	userID, ok := middlewares.GetUserIDAsString(c)
+	if !ok || userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Usuário não autenticado",
+		})
+		return "", false
+	}
+	return userID, true
+}
+
+// auditContext anexa ao contexto da requisição o IP e o user agent gravados
+// no registro de auditoria
+func auditContext(c *gin.Context) context.Context {
+	return services.WithAuditRequest(c.Request.Context(), c.ClientIP(), c.Request.UserAgent())
+}
+
+// respondError traduz os erros dos serviços para o status HTTP correspondente
+func respondError(c *gin.Context, err error) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Dados inválidos",
+			"fields": validationErr.Fields,
+		})
+	case errors.Is(err, services.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro interno"})
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type BudgetHandler struct {
+	service services.BudgetService
+}
+
+func NewBudgetHandler(service services.BudgetService) *BudgetHandler {
+	return &BudgetHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de orçamentos no grupo autenticado
+func (h *BudgetHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	budgets := rg.Group("/budgets")
+	budgets.GET("", h.List)
+	budgets.POST("", h.Create)
+	budgets.GET("/alerts", h.ListAlerts)
+	budgets.PUT("/settings", h.UpdateSettings)
+	budgets.PUT("/:id", h.Update)
+	budgets.DELETE("/:id", h.Delete)
+}
+
+// List retorna os orçamentos do usuário com o uso no mês corrente
+func (h *BudgetHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	budgets, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, budgets)
+}
+
+// Create cria um orçamento por categoria ou para o total de saídas
+func (h *BudgetHandler) Create(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.CreateBudgetDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	budget, err := h.service.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, budget)
+}
+
+// Update altera o limite ou ativa/desativa um orçamento
+func (h *BudgetHandler) Update(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.UpdateBudgetDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	budget, err := h.service.Update(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// Delete remove um orçamento
+func (h *BudgetHandler) Delete(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListAlerts retorna os alertas de uso de orçamento
+func (h *BudgetHandler) ListAlerts(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	alerts, err := h.service.ListAlerts(c.Request.Context(), userID, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+// UpdateSettings altera o fuso horário usado nos períodos dos orçamentos
+func (h *BudgetHandler) UpdateSettings(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.BudgetSettingsDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
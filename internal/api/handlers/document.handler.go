@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+// maxDocumentRequestSize limita o corpo do envio (32 MB); o tamanho do
+// arquivo é conferido pelo serviço conforme DOCUMENT_MAX_SIZE_BYTES
+const maxDocumentRequestSize = 32 << 20
+
+type DocumentHandler struct {
+	service services.DocumentService
+}
+
+func NewDocumentHandler(service services.DocumentService) *DocumentHandler {
+	return &DocumentHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de documentos do cliente e de consulta pela análise de KYC
+func (h *DocumentHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	documents := rg.Group("/documents")
+	documents.POST("", h.Upload)
+	documents.GET("", h.List)
+	documents.GET("/:id/content", h.Download)
+
+	admin := rg.Group("/admin", middlewares.RequireRole("admin", "compliance"))
+	admin.GET("/customers/:id/documents", h.ListForCustomer)
+	admin.GET("/documents/:id/content", h.DownloadAny)
+}
+
+// Upload recebe o arquivo (campo multipart "file") com document_type
+func (h *DocumentHandler) Upload(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxDocumentRequestSize)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Arquivo ausente ou maior que o permitido",
+		})
+		return
+	}
+
+	var req dtos.UploadDocumentDTO
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Parâmetros inválidos",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	response, err := h.service.Upload(c.Request.Context(), userID, &req, fileHeader.Filename, file)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// List lista os documentos do cliente autenticado
+func (h *DocumentHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Download retorna o arquivo original de um documento do cliente autenticado
+func (h *DocumentHandler) Download(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	document, data, err := h.service.Download(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	writeDocument(c, document, data)
+}
+
+// ListForCustomer lista os documentos de um cliente
+func (h *DocumentHandler) ListForCustomer(c *gin.Context) {
+	response, err := h.service.ListForCustomer(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadAny retorna o arquivo original de qualquer documento
+func (h *DocumentHandler) DownloadAny(c *gin.Context) {
+	document, data, err := h.service.DownloadAny(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	writeDocument(c, document, data)
+}
+
+func writeDocument(c *gin.Context, document *dtos.CustomerDocumentDTO, data []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", document.FileName))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, document.ContentType, data)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type FraudHandler struct {
+	service services.FraudService
+}
+
+func NewFraudHandler(service services.FraudService) *FraudHandler {
+	return &FraudHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas administrativas de fraude no grupo autenticado
+func (h *FraudHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	admin := rg.Group("/admin/fraud/decisions", middlewares.RequireRole("admin"))
+	admin.GET("", h.List)
+	admin.GET("/:id", h.Get)
+	admin.POST("/:id/approve", h.Approve)
+	admin.POST("/:id/reject", h.Reject)
+}
+
+// List lista as decisões do motor de fraude, filtrando por ação e status de análise
+func (h *FraudHandler) List(c *gin.Context) {
+	var req dtos.FraudDecisionListRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.service.ListDecisions(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get retorna uma decisão com as regras disparadas
+func (h *FraudHandler) Get(c *gin.Context) {
+	response, err := h.service.GetDecision(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Approve libera a transação retida para análise
+func (h *FraudHandler) Approve(c *gin.Context) {
+	h.review(c, h.service.Approve)
+}
+
+// Reject cancela a transação retida para análise
+func (h *FraudHandler) Reject(c *gin.Context) {
+	h.review(c, h.service.Reject)
+}
+
+func (h *FraudHandler) review(c *gin.Context, action func(ctx context.Context, reviewerUserID, decisionID string, req *dtos.FraudReviewDTO) (*dtos.FraudDecisionDTO, error)) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.FraudReviewDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := action(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type KYCHandler struct {
+	service services.KYCService
+}
+
+func NewKYCHandler(service services.KYCService) *KYCHandler {
+	return &KYCHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de KYC do cliente e da fila de análise no grupo autenticado
+func (h *KYCHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	kyc := rg.Group("/kyc")
+	kyc.GET("", h.Status)
+	kyc.POST("/submissions", h.Submit)
+
+	admin := rg.Group("/admin/kyc", middlewares.RequireRole("admin", "compliance"))
+	admin.GET("/submissions", h.ListSubmissions)
+	admin.GET("/submissions/:id", h.GetSubmission)
+	admin.PUT("/submissions/:id/assignee", h.Assign)
+	admin.POST("/submissions/:id/checks", h.RunChecks)
+	admin.POST("/submissions/:id/decision", h.Decide)
+	admin.GET("/customers/:id/history", h.StatusHistory)
+}
+
+// Status retorna a situação de KYC do cliente autenticado
+func (h *KYCHandler) Status(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.Status(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Submit envia dados e documentos para verificação
+func (h *KYCHandler) Submit(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.SubmitKYCDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.Submit(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListSubmissions lista a fila de envios de KYC
+func (h *KYCHandler) ListSubmissions(c *gin.Context) {
+	var req dtos.KYCSubmissionListRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.service.ListSubmissions(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSubmission retorna o envio com o resultado das verificações
+func (h *KYCHandler) GetSubmission(c *gin.Context) {
+	response, err := h.service.GetSubmission(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Assign atribui o envio a um analista
+func (h *KYCHandler) Assign(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.AssignKYCSubmissionDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.Assign(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RunChecks executa novamente as verificações automáticas
+func (h *KYCHandler) RunChecks(c *gin.Context) {
+	response, err := h.service.RunChecks(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Decide aprova, reprova ou pede complemento do envio
+func (h *KYCHandler) Decide(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.KYCDecisionDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.Decide(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// StatusHistory lista as mudanças de status de KYC do cliente
+func (h *KYCHandler) StatusHistory(c *gin.Context) {
+	response, err := h.service.StatusHistory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -1 +1,60 @@
 package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+)
+
+// DeviceFingerprintHeader identifica o dispositivo do cliente para a análise de fraude
+const DeviceFingerprintHeader = "X-Device-Fingerprint"
+
+type MakeTransactionHandler struct {
+	service services.MakeTransactionService
+}
+
+func NewMakeTransactionHandler(service services.MakeTransactionService) *MakeTransactionHandler {
+	return &MakeTransactionHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de transação no grupo autenticado
+func (h *MakeTransactionHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.POST("/transactions", h.Create)
+}
+
+// Create efetiva uma nova transação para o usuário autenticado
+func (h *MakeTransactionHandler) Create(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.TransactionRequestDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if fingerprint := c.GetHeader(DeviceFingerprintHeader); fingerprint != "" {
+		ctx = services.WithDeviceFingerprint(ctx, fingerprint)
+	}
+
+	response, err := h.service.Execute(ctx, userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// transações retidas para análise foram aceitas, mas ainda não concluídas
+	if response.TransactionStatus == models.TransactionStatusOnHold {
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
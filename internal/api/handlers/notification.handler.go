@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type NotificationHandler struct {
+	service services.NotificationService
+}
+
+func NewNotificationHandler(service services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de notificações no grupo autenticado
+func (h *NotificationHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	notifications := rg.Group("/notifications")
+	notifications.GET("/preferences", h.GetPreferences)
+	notifications.PUT("/preferences", h.UpdatePreferences)
+	notifications.POST("/devices", h.RegisterDevice)
+	notifications.DELETE("/devices/:token", h.UnregisterDevice)
+
+	admin := rg.Group("/admin/notifications", middlewares.RequireRole("admin"))
+	admin.GET("/dead-letters", h.ListDeadLetters)
+	admin.POST("/:id/requeue", h.Requeue)
+}
+
+// GetPreferences retorna o idioma e os canais habilitados por evento
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.service.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences grava as preferências informadas. Eventos omitidos não mudam.
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.NotificationPreferencesDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	preferences, err := h.service.UpdatePreferences(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// RegisterDevice registra o token de push do dispositivo do usuário
+func (h *NotificationHandler) RegisterDevice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.RegisterPushDeviceDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.RegisterDevice(c.Request.Context(), userID, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UnregisterDevice remove o token de push do usuário
+func (h *NotificationHandler) UnregisterDevice(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.UnregisterDevice(c.Request.Context(), userID, c.Param("token")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeadLetters lista as notificações que esgotaram as tentativas
+func (h *NotificationHandler) ListDeadLetters(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	dead, err := h.service.ListDeadLetters(c.Request.Context(), limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dead)
+}
+
+// Requeue devolve uma notificação da dead-letter para a fila
+func (h *NotificationHandler) Requeue(c *gin.Context) {
+	if err := h.service.Requeue(c.Request.Context(), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+// onboardingTokenHeader leva o token de retomada da sessão
+const onboardingTokenHeader = "X-Onboarding-Token"
+
+type OnboardingHandler struct {
+	service services.OnboardingService
+}
+
+func NewOnboardingHandler(service services.OnboardingService) *OnboardingHandler {
+	return &OnboardingHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de cadastro no grupo público
+func (h *OnboardingHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	onboarding := rg.Group("/onboarding")
+	onboarding.POST("", h.Register)
+	onboarding.POST("/sessions", h.CreateSession)
+	onboarding.POST("/sessions/resume", h.RequestResume)
+	onboarding.GET("/sessions/:id", h.GetSession)
+	onboarding.PATCH("/sessions/:id", h.SaveSession)
+	onboarding.POST("/sessions/:id/complete", h.CompleteSession)
+}
+
+// bindOnboarding faz apenas o bind do corpo: as seções são validadas pelo
+// serviço, que reporta os erros de cada uma
+func bindOnboarding(c *gin.Context, dto *dtos.AccountOnboardingDTO) bool {
+	if err := c.ShouldBindJSON(dto); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Corpo da requisição inválido",
+		})
+		return false
+	}
+	return true
+}
+
+// Register faz o cadastro completo em um único envio
+func (h *OnboardingHandler) Register(c *gin.Context) {
+	var req dtos.AccountOnboardingDTO
+	if !bindOnboarding(c, &req) {
+		return
+	}
+
+	response, err := h.service.Register(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// CreateSession inicia um cadastro em etapas e devolve o token de retomada
+func (h *OnboardingHandler) CreateSession(c *gin.Context) {
+	var req dtos.AccountOnboardingDTO
+	if !bindOnboarding(c, &req) {
+		return
+	}
+
+	response, err := h.service.CreateSession(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetSession retorna as seções salvas e os erros de cada uma
+func (h *OnboardingHandler) GetSession(c *gin.Context) {
+	response, err := h.service.GetSession(c.Request.Context(), c.Param("id"), c.GetHeader(onboardingTokenHeader))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SaveSession grava as seções enviadas
+func (h *OnboardingHandler) SaveSession(c *gin.Context) {
+	var req dtos.AccountOnboardingDTO
+	if !bindOnboarding(c, &req) {
+		return
+	}
+
+	response, err := h.service.SaveSession(c.Request.Context(), c.Param("id"), c.GetHeader(onboardingTokenHeader), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CompleteSession conclui o cadastro com as seções salvas
+func (h *OnboardingHandler) CompleteSession(c *gin.Context) {
+	response, err := h.service.CompleteSession(c.Request.Context(), c.Param("id"), c.GetHeader(onboardingTokenHeader))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// RequestResume envia um novo token ao email do cadastro em andamento. A
+// resposta é a mesma exista ou não uma sessão para o email.
+func (h *OnboardingHandler) RequestResume(c *gin.Context) {
+	var req dtos.ResumeOnboardingDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.RequestResume(c.Request.Context(), &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+// maxBatchFileSize limita o tamanho do CSV enviado (10 MB)
+const maxBatchFileSize = 10 << 20
+
+type PaymentBatchHandler struct {
+	service services.PaymentBatchService
+}
+
+func NewPaymentBatchHandler(service services.PaymentBatchService) *PaymentBatchHandler {
+	return &PaymentBatchHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de lotes de pagamento no grupo autenticado
+func (h *PaymentBatchHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	batches := rg.Group("/payment-batches")
+	batches.POST("", h.Upload)
+	batches.GET("/:id", h.Get)
+	batches.GET("/:id/rows", h.ListRows)
+	batches.POST("/:id/approve", h.Approve)
+	batches.POST("/:id/cancel", h.Cancel)
+	batches.GET("/:id/results.csv", h.DownloadResults)
+}
+
+// Upload recebe o CSV (campo multipart "file") e retorna a prévia validada
+func (h *PaymentBatchHandler) Upload(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchFileSize)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Arquivo CSV ausente ou maior que o permitido",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	preview, err := h.service.Upload(c.Request.Context(), userID, filepath.Base(fileHeader.Filename), file)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, preview)
+}
+
+// Get retorna o resumo e progresso do lote
+func (h *PaymentBatchHandler) Get(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.Get(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// ListRows retorna as linhas do lote com erros por linha
+func (h *PaymentBatchHandler) ListRows(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.PaymentBatchRowsRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	preview, err := h.service.ListRows(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// Approve libera o lote para execução
+func (h *PaymentBatchHandler) Approve(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.Approve(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, summary)
+}
+
+// Cancel cancela o lote
+func (h *PaymentBatchHandler) Cancel(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.Cancel(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// DownloadResults devolve o resultado por linha em CSV
+func (h *PaymentBatchHandler) DownloadResults(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	batchID := c.Param("id")
+	if _, err := h.service.Get(c.Request.Context(), userID, batchID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "batch-"+batchID+"-results.csv"))
+	c.Status(http.StatusOK)
+	if err := h.service.WriteResultsCSV(c.Request.Context(), userID, batchID, c.Writer); err != nil {
+		_ = c.Error(err)
+	}
+}
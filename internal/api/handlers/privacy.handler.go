@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type PrivacyHandler struct {
+	service services.PrivacyService
+}
+
+func NewPrivacyHandler(service services.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas dos pedidos do titular (LGPD) no grupo autenticado
+func (h *PrivacyHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	privacy := rg.Group("/privacy/requests")
+	privacy.POST("", h.CreateRequest)
+	privacy.GET("", h.ListOwn)
+	privacy.GET("/:id", h.GetOwn)
+	privacy.GET("/:id/export", h.DownloadExport)
+
+	admin := rg.Group("/admin", middlewares.RequireRole("admin", "compliance"))
+	admin.GET("/privacy-requests", h.ListRequests)
+	admin.GET("/privacy-requests/:id", h.GetRequest)
+	admin.POST("/privacy-requests/:id/reject", h.Reject)
+	admin.POST("/privacy-requests/:id/retry", h.Retry)
+	admin.GET("/privacy-requests/:id/export", h.DownloadAny)
+	admin.POST("/customers/:id/privacy-requests", h.CreateForCustomer)
+}
+
+// CreateRequest abre um pedido de exportação ou anonimização dos dados do cliente
+func (h *PrivacyHandler) CreateRequest(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.CreatePrivacyRequestDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.CreateRequest(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListOwn lista os pedidos do cliente autenticado
+func (h *PrivacyHandler) ListOwn(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.ListOwn(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PrivacyHandler) GetOwn(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetOwn(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadExport retorna o arquivo zip de uma exportação do cliente autenticado
+func (h *PrivacyHandler) DownloadExport(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	request, data, err := h.service.DownloadExport(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	writePrivacyExport(c, request, data)
+}
+
+// ListRequests lista os pedidos por status, tipo, cliente ou prazo vencido
+func (h *PrivacyHandler) ListRequests(c *gin.Context) {
+	var req dtos.PrivacyRequestListRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.service.ListRequests(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PrivacyHandler) GetRequest(c *gin.Context) {
+	response, err := h.service.GetRequest(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateForCustomer registra um pedido recebido fora do aplicativo
+func (h *PrivacyHandler) CreateForCustomer(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.CreateCustomerPrivacyRequestDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.CreateForCustomer(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// Reject encerra um pedido em aberto com a justificativa informada
+func (h *PrivacyHandler) Reject(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.RejectPrivacyRequestDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.Reject(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Retry devolve à fila um pedido que falhou
+func (h *PrivacyHandler) Retry(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.Retry(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadAny retorna o arquivo de qualquer exportação
+func (h *PrivacyHandler) DownloadAny(c *gin.Context) {
+	request, data, err := h.service.DownloadAny(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	writePrivacyExport(c, request, data)
+}
+
+func writePrivacyExport(c *gin.Context, request *dtos.PrivacyRequestDTO, data []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "oak-bank-dados-"+request.RequestID+".zip"))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", data)
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type ProfileHandler struct {
+	service services.ProfileService
+}
+
+func NewProfileHandler(service services.ProfileService) *ProfileHandler {
+	return &ProfileHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de alteração do cadastro no grupo autenticado
+func (h *ProfileHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	profile := rg.Group("/profile")
+	profile.GET("/history", h.History)
+	profile.GET("/changes", h.ListChanges)
+	profile.POST("/changes", h.RequestChange)
+	profile.POST("/changes/:id/verify", h.Verify)
+	profile.POST("/changes/:id/resend", h.ResendCode)
+	profile.POST("/changes/:id/cancel", h.Cancel)
+
+	admin := rg.Group("/admin", middlewares.RequireRole("admin", "compliance"))
+	admin.GET("/profile-changes", h.ListRequests)
+	admin.GET("/profile-changes/:id", h.GetRequest)
+	admin.POST("/profile-changes/:id/review", h.Review)
+	admin.GET("/customers/:id/profile-history", h.CustomerHistory)
+}
+
+// RequestChange abre um pedido de alteração de nome, email ou telefone
+func (h *ProfileHandler) RequestChange(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.ProfileChangeRequestDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.RequestChange(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListChanges lista os pedidos de alteração do cliente
+func (h *ProfileHandler) ListChanges(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.ListChanges(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Verify confirma o novo email ou telefone com o código recebido
+func (h *ProfileHandler) Verify(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.VerifyProfileChangeDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.Verify(auditContext(c), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResendCode envia um novo código de confirmação
+func (h *ProfileHandler) ResendCode(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.ResendCode(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Cancel desiste de um pedido em aberto
+func (h *ProfileHandler) Cancel(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.Cancel(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// History lista as versões dos campos do cadastro
+func (h *ProfileHandler) History(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.ProfileHistoryRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.service.History(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListRequests lista os pedidos de alteração para análise
+func (h *ProfileHandler) ListRequests(c *gin.Context) {
+	var req dtos.ProfileChangeListRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.service.ListRequests(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ProfileHandler) GetRequest(c *gin.Context) {
+	response, err := h.service.GetRequest(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Review aprova ou rejeita uma alteração de nome
+func (h *ProfileHandler) Review(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.ReviewProfileChangeDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.Review(auditContext(c), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CustomerHistory lista as versões do cadastro de um cliente
+func (h *ProfileHandler) CustomerHistory(c *gin.Context) {
+	var req dtos.ProfileHistoryRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.service.CustomerHistory(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
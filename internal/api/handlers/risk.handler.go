@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type RiskHandler struct {
+	service services.RiskService
+}
+
+func NewRiskHandler(service services.RiskService) *RiskHandler {
+	return &RiskHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de classificação de risco no grupo autenticado
+func (h *RiskHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	admin := rg.Group("/admin/risk/customers/:id", middlewares.RequireRole("admin", "compliance"))
+	admin.GET("", h.Get)
+	admin.GET("/assessments", h.History)
+	admin.POST("/assessments", h.Assess)
+	admin.GET("/overrides", h.Overrides)
+	admin.POST("/overrides", h.Override)
+	admin.POST("/overrides/revoke", h.RevokeOverride)
+}
+
+// Get retorna a classificação atual, o último cálculo e a revisão manual em vigor
+func (h *RiskHandler) Get(c *gin.Context) {
+	response, err := h.service.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// History lista os cálculos da classificação com os fatores
+func (h *RiskHandler) History(c *gin.Context) {
+	var req dtos.RiskAssessmentListRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.service.History(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Assess recalcula a classificação imediatamente
+func (h *RiskHandler) Assess(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.Assess(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Overrides lista as revisões manuais do cliente
+func (h *RiskHandler) Overrides(c *gin.Context) {
+	response, err := h.service.Overrides(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Override define a classificação manualmente com justificativa
+func (h *RiskHandler) Override(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.OverrideRiskRatingDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.Override(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// RevokeOverride revoga a revisão manual e volta ao cálculo automático
+func (h *RiskHandler) RevokeOverride(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.RevokeRiskOverrideDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.RevokeOverride(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
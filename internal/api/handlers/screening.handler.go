@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+)
+
+// maxSanctionsListFileSize limita o tamanho do arquivo de lista enviado (100 MB)
+const maxSanctionsListFileSize = 100 << 20
+
+type ScreeningHandler struct {
+	service services.ScreeningService
+}
+
+func NewScreeningHandler(service services.ScreeningService) *ScreeningHandler {
+	return &ScreeningHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de triagem de sanções no grupo autenticado
+func (h *ScreeningHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	admin := rg.Group("/admin/screening", middlewares.RequireRole("admin", "compliance"))
+	admin.GET("/lists", h.ListLists)
+	admin.POST("/lists", h.Import)
+	admin.GET("/reviews", h.ListReviews)
+	admin.GET("/reviews/:id", h.GetReview)
+	admin.POST("/reviews/:id/clear", h.Clear)
+	admin.POST("/reviews/:id/confirm", h.Confirm)
+	admin.POST("/customers/:id", h.ScreenCustomer)
+}
+
+// ListLists lista as listas restritivas importadas
+func (h *ScreeningHandler) ListLists(c *gin.Context) {
+	lists, err := h.service.ListLists(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lists)
+}
+
+// Import recebe o arquivo da lista (campo multipart "file") com list_code,
+// list_name, list_type e, opcionalmente, format
+func (h *ScreeningHandler) Import(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSanctionsListFileSize)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Arquivo da lista ausente ou maior que o permitido",
+		})
+		return
+	}
+
+	var req dtos.ImportSanctionsListDTO
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Parâmetros inválidos",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	list, err := h.service.Import(c.Request.Context(), userID, &req, file)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// ListReviews lista a fila de revisões de triagem
+func (h *ScreeningHandler) ListReviews(c *gin.Context) {
+	var req dtos.ScreeningReviewListRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.service.ListReviews(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetReview retorna a revisão com os acertos
+func (h *ScreeningHandler) GetReview(c *gin.Context) {
+	response, err := h.service.GetReview(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Clear descarta a revisão como falso positivo, liberando o cliente
+func (h *ScreeningHandler) Clear(c *gin.Context) {
+	h.resolve(c, h.service.Clear)
+}
+
+// Confirm confirma o acerto, mantendo o cliente bloqueado
+func (h *ScreeningHandler) Confirm(c *gin.Context) {
+	h.resolve(c, h.service.Confirm)
+}
+
+func (h *ScreeningHandler) resolve(c *gin.Context, action func(ctx context.Context, reviewerUserID, reviewID string, req *dtos.ResolveScreeningReviewDTO) (*dtos.ScreeningReviewDTO, error)) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.ResolveScreeningReviewDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := action(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ScreenCustomer refaz a triagem de um cliente sob demanda
+func (h *ScreeningHandler) ScreenCustomer(c *gin.Context) {
+	result, err := h.service.ScreenCustomer(c.Request.Context(), nil, c.Param("id"), models.ScreeningContextManual)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
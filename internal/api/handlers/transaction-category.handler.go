@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type TransactionCategoryHandler struct {
+	categories services.TransactionCategoryService
+	insights   services.SpendingInsightsService
+}
+
+func NewTransactionCategoryHandler(categories services.TransactionCategoryService, insights services.SpendingInsightsService) *TransactionCategoryHandler {
+	return &TransactionCategoryHandler{
+		categories: categories,
+		insights:   insights,
+	}
+}
+
+// RegisterRoutes registra as rotas de categorização e insights no grupo autenticado
+func (h *TransactionCategoryHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/categories", h.ListCategories)
+	rg.PUT("/transactions/:id/category", h.Recategorize)
+	rg.GET("/insights/spending", h.Spending)
+
+	admin := rg.Group("/admin/category-rules", middlewares.RequireRole("admin"))
+	admin.GET("", h.ListRules)
+	admin.POST("", h.CreateRule)
+	admin.PUT("/:id", h.UpdateRule)
+	admin.DELETE("/:id", h.DeleteRule)
+}
+
+// ListCategories retorna as categorias disponíveis
+func (h *TransactionCategoryHandler) ListCategories(c *gin.Context) {
+	categories, err := h.categories.ListCategories(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// Recategorize altera a categoria de uma transação do usuário
+func (h *TransactionCategoryHandler) Recategorize(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.UpdateTransactionCategoryDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.categories.Recategorize(c.Request.Context(), userID, c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Spending retorna os insights de gastos do usuário
+func (h *TransactionCategoryHandler) Spending(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.SpendingInsightsRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	response, err := h.insights.Spending(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListRules lista as regras de categorização
+func (h *TransactionCategoryHandler) ListRules(c *gin.Context) {
+	rules, err := h.categories.ListRules(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateRule cria uma regra de categorização
+func (h *TransactionCategoryHandler) CreateRule(c *gin.Context) {
+	var req dtos.CategoryRuleDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	rule, err := h.categories.CreateRule(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule atualiza uma regra de categorização
+func (h *TransactionCategoryHandler) UpdateRule(c *gin.Context) {
+	var req dtos.CategoryRuleDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	rule, err := h.categories.UpdateRule(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule remove uma regra de categorização. Categorias já gravadas não mudam.
+func (h *TransactionCategoryHandler) DeleteRule(c *gin.Context) {
+	if err := h.categories.DeleteRule(c.Request.Context(), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
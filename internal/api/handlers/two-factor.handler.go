@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+// StepUpHeader traz o token retornado por POST /2fa/step-up nas operações
+// sensíveis
+const StepUpHeader = "X-Step-Up-Token"
+
+type TwoFactorHandler struct {
+	service services.TwoFactorService
+}
+
+func NewTwoFactorHandler(service services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas da verificação em duas etapas no grupo autenticado
+func (h *TwoFactorHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	twoFactor := rg.Group("/2fa")
+	twoFactor.GET("", h.Status)
+	twoFactor.POST("/enroll", h.Enroll)
+	twoFactor.POST("/enroll/otp", h.EnrollOTP)
+	twoFactor.POST("/confirm", h.Confirm)
+	twoFactor.POST("/disable", h.Disable)
+	twoFactor.POST("/recovery-codes", h.RegenerateRecoveryCodes)
+	twoFactor.POST("/code", h.SendCode)
+	twoFactor.POST("/step-up", h.StepUp)
+}
+
+// VerifyStepUp é o middleware do grupo autenticado que, com um token válido
+// no header X-Step-Up-Token, marca a requisição como verificada para as
+// operações sensíveis. O token é consumido: cada verificação adicional vale
+// para uma requisição. Um token inválido, já usado ou de outro usuário recusa
+// a requisição.
+func (h *TwoFactorHandler) VerifyStepUp(c *gin.Context) {
+	token := c.GetHeader(StepUpHeader)
+	if token == "" {
+		c.Next()
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.Abort()
+		return
+	}
+	if err := h.service.ConsumeStepUpToken(c.Request.Context(), userID, token); err != nil {
+		respondError(c, err)
+		c.Abort()
+		return
+	}
+
+	c.Request = c.Request.WithContext(services.WithStepUpVerified(c.Request.Context()))
+	c.Next()
+}
+
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.Status(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Enroll gera o segredo e o QR code para o aplicativo autenticador
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.TwoFactorEnrollDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.Enroll(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// EnrollOTP escolhe o envio de códigos por email ou SMS e envia o código
+// que confirma a configuração
+func (h *TwoFactorHandler) EnrollOTP(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.TwoFactorEnrollOTPDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.EnrollOTP(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// Confirm ativa a verificação e retorna os códigos de recuperação
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.TwoFactorConfirmDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.Confirm(auditContext(c), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.TwoFactorReauthenticateDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.Disable(auditContext(c), userID, &req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes substitui os códigos de recuperação
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.TwoFactorReauthenticateDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.RegenerateRecoveryCodes(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SendCode envia o código usado em step-up, disable e recovery-codes
+func (h *TwoFactorHandler) SendCode(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.TwoFactorVerificationSenderDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.SendCode(c.Request.Context(), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+// StepUp confere o código e retorna o token do header X-Step-Up-Token
+func (h *TwoFactorHandler) StepUp(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.StepUpDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	response, err := h.service.StepUp(authRequestContext(c), userID, &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
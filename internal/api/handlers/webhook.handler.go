@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/middlewares"
+	"github.com/victor-lima-142/oak-bank/internal/api/services"
+)
+
+type WebhookHandler struct {
+	service services.WebhookService
+}
+
+func NewWebhookHandler(service services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registra as rotas de webhooks do cliente e as de parceiros,
+// restritas a administradores, com os mesmos handlers
+func (h *WebhookHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	h.registerSubscriptionRoutes(rg.Group("/webhooks", h.customerScope))
+	h.registerSubscriptionRoutes(rg.Group("/admin/webhooks", middlewares.RequireRole("admin"), h.partnerScope))
+}
+
+func (h *WebhookHandler) registerSubscriptionRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.List)
+	rg.POST("", h.Create)
+	rg.PUT("/:id", h.Update)
+	rg.DELETE("/:id", h.Delete)
+	rg.POST("/:id/rotate-secret", h.RotateSecret)
+	rg.GET("/:id/deliveries", h.ListDeliveries)
+	rg.GET("/:id/deliveries/:deliveryId", h.GetDelivery)
+	rg.POST("/:id/deliveries/:deliveryId/redeliver", h.Redeliver)
+}
+
+const webhookScopeKey = "webhook_scope"
+
+// customerScope restringe as rotas às assinaturas do cliente do usuário
+func (h *WebhookHandler) customerScope(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.Abort()
+		return
+	}
+
+	scope, err := h.service.CustomerScope(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, err)
+		c.Abort()
+		return
+	}
+
+	c.Set(webhookScopeKey, scope)
+	c.Next()
+}
+
+// partnerScope restringe as rotas às assinaturas de parceiros
+func (h *WebhookHandler) partnerScope(c *gin.Context) {
+	c.Set(webhookScopeKey, services.PartnerScope)
+	c.Next()
+}
+
+func webhookScope(c *gin.Context) services.WebhookScope {
+	scope, _ := c.MustGet(webhookScopeKey).(services.WebhookScope)
+	return scope
+}
+
+// List lista as assinaturas do escopo
+func (h *WebhookHandler) List(c *gin.Context) {
+	subscriptions, err := h.service.ListSubscriptions(c.Request.Context(), webhookScope(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// Create cria uma assinatura. O segredo só é retornado nesta resposta.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req dtos.CreateWebhookSubscriptionDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	subscription, err := h.service.CreateSubscription(c.Request.Context(), webhookScope(c), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// Update altera URL, eventos ou estado da assinatura
+func (h *WebhookHandler) Update(c *gin.Context) {
+	var req dtos.UpdateWebhookSubscriptionDTO
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	subscription, err := h.service.UpdateSubscription(c.Request.Context(), webhookScope(c), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// Delete remove a assinatura e seu histórico de entregas
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	if err := h.service.DeleteSubscription(c.Request.Context(), webhookScope(c), c.Param("id")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RotateSecret gera um novo segredo de assinatura
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	subscription, err := h.service.RotateSecret(c.Request.Context(), webhookScope(c), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// ListDeliveries lista o log de entregas da assinatura
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	var req dtos.WebhookDeliveryListRequestDTO
+	if !bindQuery(c, &req) {
+		return
+	}
+
+	deliveries, err := h.service.ListDeliveries(c.Request.Context(), webhookScope(c), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// GetDelivery retorna a entrega com todas as tentativas e códigos de resposta
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	delivery, err := h.service.GetDelivery(c.Request.Context(), webhookScope(c), c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Redeliver agenda o reenvio manual do evento
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	delivery, err := h.service.Redeliver(c.Request.Context(), webhookScope(c), c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -38,13 +38,30 @@ type JwtService interface {
 
 	// ExtractTokenFromHeader extrai o token do header Authorization
 	ExtractTokenFromHeader(c *gin.Context) (string, error)
+
+	// CreateMFAToken cria o token da etapa pendente de verificação em duas
+	// etapas. É assinado com outra chave, então não vale como token de acesso.
+	CreateMFAToken(payload map[string]interface{}) (string, error)
+
+	// ValidateMFAToken valida um token criado por CreateMFAToken
+	ValidateMFAToken(tokenString string) (jwt.MapClaims, error)
+
+	// CreateStepUpToken cria o token que comprova a verificação adicional de
+	// identidade para operações sensíveis. Também não vale como token de acesso.
+	CreateStepUpToken(payload map[string]interface{}) (string, error)
+
+	// ValidateStepUpToken valida um token criado por CreateStepUpToken
+	ValidateStepUpToken(tokenString string) (jwt.MapClaims, error)
 }
 
 type jwtService struct {
 	secretKey         []byte
 	refreshSecretKey  []byte
+	mfaSecretKey      []byte
 	defaultExpiration time.Duration
 	refreshExpiration time.Duration
+	mfaExpiration     time.Duration
+	stepUpExpiration  time.Duration
 	issuer            string
 }
 
@@ -52,8 +69,11 @@ type jwtService struct {
 type JwtConfig struct {
 	SecretKey         string
 	RefreshSecretKey  string
+	MFASecretKey      string
 	DefaultExpiration time.Duration
 	RefreshExpiration time.Duration
+	MFAExpiration     time.Duration
+	StepUpExpiration  time.Duration
 	Issuer            string
 }
 
@@ -70,12 +90,21 @@ func NewJwtService(config *JwtConfig) JwtService {
 	if config.RefreshSecretKey == "" {
 		config.RefreshSecretKey = getEnv("JWT_REFRESH_SECRET_KEY", "")
 	}
+	if config.MFASecretKey == "" {
+		config.MFASecretKey = getEnv("JWT_MFA_SECRET_KEY", "")
+	}
 	if config.DefaultExpiration == 0 {
 		config.DefaultExpiration = getEnvDuration("JWT_DEFAULT_EXPIRATION", 15*time.Minute)
 	}
 	if config.RefreshExpiration == 0 {
 		config.RefreshExpiration = getEnvDuration("JWT_REFRESH_EXPIRATION", 7*24*time.Hour)
 	}
+	if config.MFAExpiration == 0 {
+		config.MFAExpiration = getEnvDuration("JWT_MFA_EXPIRATION", 5*time.Minute)
+	}
+	if config.StepUpExpiration == 0 {
+		config.StepUpExpiration = getEnvDuration("JWT_STEP_UP_EXPIRATION", 5*time.Minute)
+	}
 	if config.Issuer == "" {
 		config.Issuer = getEnv("JWT_ISSUER", "my-app")
 	}
@@ -88,11 +117,18 @@ func NewJwtService(config *JwtConfig) JwtService {
 		config.RefreshSecretKey = config.SecretKey + "-refresh"
 	}
 
+	if config.MFASecretKey == "" {
+		config.MFASecretKey = config.SecretKey + "-mfa"
+	}
+
 	return &jwtService{
 		secretKey:         []byte(config.SecretKey),
 		refreshSecretKey:  []byte(config.RefreshSecretKey),
+		mfaSecretKey:      []byte(config.MFASecretKey),
 		defaultExpiration: config.DefaultExpiration,
 		refreshExpiration: config.RefreshExpiration,
+		mfaExpiration:     config.MFAExpiration,
+		stepUpExpiration:  config.StepUpExpiration,
 		issuer:            config.Issuer,
 	}
 }
@@ -261,3 +297,65 @@ func (j *jwtService) RefreshToken(refreshToken string) (string, error) {
 
 	return j.CreateToken(payload, j.defaultExpiration)
 }
+
+func (j *jwtService) CreateMFAToken(payload map[string]interface{}) (string, error) {
+	return j.createTypedToken("mfa_pending", j.mfaExpiration, payload)
+}
+
+func (j *jwtService) ValidateMFAToken(tokenString string) (jwt.MapClaims, error) {
+	return j.validateTypedToken("mfa_pending", tokenString)
+}
+
+func (j *jwtService) CreateStepUpToken(payload map[string]interface{}) (string, error) {
+	return j.createTypedToken("step_up", j.stepUpExpiration, payload)
+}
+
+func (j *jwtService) ValidateStepUpToken(tokenString string) (jwt.MapClaims, error) {
+	return j.validateTypedToken("step_up", tokenString)
+}
+
+// createTypedToken assina com a chave dos tokens de verificação um token com
+// o claim type, conferido por validateTypedToken
+func (j *jwtService) createTypedToken(tokenType string, expiration time.Duration, payload map[string]interface{}) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": j.issuer,
+		"iat": now.Unix(),
+		"exp": now.Add(expiration).Unix(),
+	}
+
+	for key, value := range payload {
+		claims[key] = value
+	}
+	claims["type"] = tokenType
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(j.mfaSecretKey)
+}
+
+func (j *jwtService) validateTypedToken(tokenType, tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
+		}
+		return j.mfaSecretKey, nil
+	})
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if claimType, _ := claims["type"].(string); claimType != tokenType {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
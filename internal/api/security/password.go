@@ -4,64 +4,256 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
 	"errors"
-	"io"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algoritmos aceitos em PASSWORD_HASH_ALGORITHM
+const (
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+)
+
+// Esquemas reconhecidos em User.PasswordHash, retornados por PasswordScheme.
+// Legacy é a senha cifrada com AES pela versão anterior do serviço e
+// Unusable é o valor que impede o login (ex.: "!" de usuários anonimizados).
+const (
+	PasswordSchemeArgon2id = "argon2id"
+	PasswordSchemeBcrypt   = "bcrypt"
+	PasswordSchemeLegacy   = "legacy"
+	PasswordSchemeUnusable = "unusable"
+)
+
+var (
+	ErrInvalidPasswordHash = errors.New("hash de senha inválido")
+	ErrLegacyPasswordKey   = errors.New("PASSWORD_ENCRYPTION_KEY é necessária para verificar senhas legadas")
 )
 
 type PasswordService interface {
-	// Encrypt criptografa uma senha em texto plano e retorna a versão criptografada
-	Encrypt(plainPassword string) (string, error)
+	// Hash gera o hash da senha no formato PHC com o algoritmo e os custos
+	// configurados
+	Hash(plainPassword string) (string, error)
 
-	// Decrypt descriptografa uma senha criptografada e retorna o texto plano
-	Decrypt(encryptedPassword string) (string, error)
+	// Verify compara a senha com o hash guardado em tempo constante.
+	// needsRehash indica que a senha confere mas o hash deve ser refeito com
+	// a configuração atual (algoritmo ou custo diferentes, ou valor legado).
+	Verify(plainPassword, storedHash string) (ok bool, needsRehash bool, err error)
 
-	// Compare compara uma senha em texto plano com uma senha criptografada
-	// Retorna true se corresponderem, false caso contrário
-	Compare(plainPassword, encryptedPassword string) (bool, error)
+	// NeedsRehash indica se o hash guardado difere da configuração atual
+	NeedsRehash(storedHash string) bool
+}
+
+// PasswordConfig contém o algoritmo e os custos do hash de senhas
+type PasswordConfig struct {
+	Algorithm string
+	// Argon2MemoryKiB, Argon2Iterations e Argon2Parallelism são os custos do
+	// argon2id
+	Argon2MemoryKiB   uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	Argon2SaltLength  uint32
+	Argon2KeyLength   uint32
+	BcryptCost        int
+	// LegacyKey é a chave AES das senhas cifradas pela versão anterior. Sem
+	// ela as senhas legadas não podem ser verificadas nem migradas.
+	LegacyKey []byte
 }
 
 type passwordService struct {
-	key []byte
+	config PasswordConfig
+}
+
+// NewPasswordServiceFromEnv lê a configuração de PASSWORD_HASH_ALGORITHM,
+// PASSWORD_ARGON2_MEMORY_KIB, PASSWORD_ARGON2_ITERATIONS,
+// PASSWORD_ARGON2_PARALLELISM e PASSWORD_BCRYPT_COST. PASSWORD_ENCRYPTION_KEY,
+// com 16, 24 ou 32 bytes, é opcional e só serve às senhas legadas.
+func NewPasswordServiceFromEnv() (PasswordService, error) {
+	return NewPasswordService(&PasswordConfig{
+		Algorithm:         config.GetEnv("PASSWORD_HASH_ALGORITHM", PasswordAlgorithmArgon2id),
+		Argon2MemoryKiB:   uint32(config.GetEnvInt("PASSWORD_ARGON2_MEMORY_KIB", 64*1024)),
+		Argon2Iterations:  uint32(config.GetEnvInt("PASSWORD_ARGON2_ITERATIONS", 3)),
+		Argon2Parallelism: uint8(config.GetEnvInt("PASSWORD_ARGON2_PARALLELISM", 2)),
+		BcryptCost:        config.GetEnvInt("PASSWORD_BCRYPT_COST", 12),
+		LegacyKey:         []byte(os.Getenv("PASSWORD_ENCRYPTION_KEY")),
+	})
 }
 
-func NewPasswordService(key []byte) PasswordService {
+// NewPasswordService cria o serviço; os campos zerados de cfg recebem os
+// valores padrão
+func NewPasswordService(cfg *PasswordConfig) (PasswordService, error) {
+	if cfg == nil {
+		cfg = &PasswordConfig{}
+	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = PasswordAlgorithmArgon2id
+	}
+	if cfg.Argon2MemoryKiB == 0 {
+		cfg.Argon2MemoryKiB = 64 * 1024
+	}
+	if cfg.Argon2Iterations == 0 {
+		cfg.Argon2Iterations = 3
+	}
+	if cfg.Argon2Parallelism == 0 {
+		cfg.Argon2Parallelism = 2
+	}
+	if cfg.Argon2SaltLength == 0 {
+		cfg.Argon2SaltLength = 16
+	}
+	if cfg.Argon2KeyLength == 0 {
+		cfg.Argon2KeyLength = 32
+	}
+	if cfg.BcryptCost == 0 {
+		cfg.BcryptCost = 12
+	}
+
+	switch cfg.Algorithm {
+	case PasswordAlgorithmArgon2id, PasswordAlgorithmBcrypt:
+	default:
+		return nil, fmt.Errorf("PASSWORD_HASH_ALGORITHM desconhecido: %q", cfg.Algorithm)
+	}
+	if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("PASSWORD_BCRYPT_COST deve estar entre %d e %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	if cfg.Argon2MemoryKiB < 8*uint32(cfg.Argon2Parallelism) {
+		return nil, fmt.Errorf("PASSWORD_ARGON2_MEMORY_KIB deve ter ao menos 8 KiB por grau de paralelismo")
+	}
+	switch len(cfg.LegacyKey) {
+	case 0, 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("PASSWORD_ENCRYPTION_KEY deve ter 16, 24 ou 32 bytes, tem %d", len(cfg.LegacyKey))
+	}
+
 	return &passwordService{
-		key: key,
+		config: *cfg,
+	}, nil
+}
+
+// PasswordScheme identifica o esquema de um hash guardado, sem verificá-lo
+func PasswordScheme(storedHash string) string {
+	switch {
+	case storedHash == "" || strings.HasPrefix(storedHash, "!"):
+		return PasswordSchemeUnusable
+	case strings.HasPrefix(storedHash, "$argon2id$"):
+		return PasswordSchemeArgon2id
+	case strings.HasPrefix(storedHash, "$2a$"), strings.HasPrefix(storedHash, "$2b$"), strings.HasPrefix(storedHash, "$2y$"):
+		return PasswordSchemeBcrypt
+	case strings.HasPrefix(storedHash, "$"):
+		return PasswordSchemeUnusable
 	}
+	return PasswordSchemeLegacy
 }
 
-func (p *passwordService) Encrypt(plainPassword string) (string, error) {
-	block, err := aes.NewCipher(p.key)
-	if err != nil {
-		return "", err
+func (p *passwordService) Hash(plainPassword string) (string, error) {
+	if p.config.Algorithm == PasswordAlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(plainPassword), p.config.BcryptCost)
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return "", errors.New("a senha deve ter no máximo 72 bytes")
+		}
+		return string(hash), err
 	}
 
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
+	salt := make([]byte, p.config.Argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", err
 	}
+	key := argon2.IDKey([]byte(plainPassword), salt,
+		p.config.Argon2Iterations, p.config.Argon2MemoryKiB, p.config.Argon2Parallelism, p.config.Argon2KeyLength)
+	return encodeArgon2id(&argon2Params{
+		memory:      p.config.Argon2MemoryKiB,
+		iterations:  p.config.Argon2Iterations,
+		parallelism: p.config.Argon2Parallelism,
+		salt:        salt,
+		key:         key,
+	}), nil
+}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
+func (p *passwordService) Verify(plainPassword, storedHash string) (bool, bool, error) {
+	switch PasswordScheme(storedHash) {
+	case PasswordSchemeArgon2id:
+		params, err := decodeArgon2id(storedHash)
+		if err != nil {
+			return false, false, err
+		}
+		key := argon2.IDKey([]byte(plainPassword), params.salt,
+			params.iterations, params.memory, params.parallelism, uint32(len(params.key)))
+		if subtle.ConstantTimeCompare(key, params.key) != 1 {
+			return false, false, nil
+		}
+		return true, p.argon2NeedsRehash(params), nil
+
+	case PasswordSchemeBcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(plainPassword))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, fmt.Errorf("%w: %v", ErrInvalidPasswordHash, err)
+		}
+		return true, p.NeedsRehash(storedHash), nil
+
+	case PasswordSchemeLegacy:
+		decrypted, err := p.decryptLegacy(storedHash)
+		if err != nil {
+			return false, false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(plainPassword), []byte(decrypted)) != 1 {
+			return false, false, nil
+		}
+		return true, true, nil
 	}
+	return false, false, nil
+}
+
+func (p *passwordService) NeedsRehash(storedHash string) bool {
+	switch PasswordScheme(storedHash) {
+	case PasswordSchemeArgon2id:
+		params, err := decodeArgon2id(storedHash)
+		return err != nil || p.argon2NeedsRehash(params)
+	case PasswordSchemeBcrypt:
+		if p.config.Algorithm != PasswordAlgorithmBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(storedHash))
+		return err != nil || cost != p.config.BcryptCost
+	case PasswordSchemeLegacy:
+		return true
+	}
+	// sem senha utilizável não há o que refazer
+	return false
+}
 
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plainPassword), nil)
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+func (p *passwordService) argon2NeedsRehash(params *argon2Params) bool {
+	return p.config.Algorithm != PasswordAlgorithmArgon2id ||
+		params.memory != p.config.Argon2MemoryKiB ||
+		params.iterations != p.config.Argon2Iterations ||
+		params.parallelism != p.config.Argon2Parallelism ||
+		uint32(len(params.salt)) != p.config.Argon2SaltLength ||
+		uint32(len(params.key)) != p.config.Argon2KeyLength
 }
 
-func (p *passwordService) Decrypt(encryptedPassword string) (string, error) {
+// decryptLegacy abre uma senha cifrada com AES-GCM (nonce || ciphertext em
+// base64) pela versão anterior do serviço
+func (p *passwordService) decryptLegacy(encryptedPassword string) (string, error) {
+	if len(p.config.LegacyKey) == 0 {
+		return "", ErrLegacyPasswordKey
+	}
 	data, err := base64.StdEncoding.DecodeString(encryptedPassword)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%w: %v", ErrInvalidPasswordHash, err)
 	}
 
-	block, err := aes.NewCipher(p.key)
+	block, err := aes.NewCipher(p.config.LegacyKey)
 	if err != nil {
 		return "", err
 	}
-
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
 		return "", err
@@ -69,23 +261,57 @@ func (p *passwordService) Decrypt(encryptedPassword string) (string, error) {
 
 	nonceSize := gcm.NonceSize()
 	if len(data) < nonceSize {
-		return "", errors.New("dados criptografados inválidos")
+		return "", ErrInvalidPasswordHash
 	}
-
 	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
 	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%w: %v", ErrInvalidPasswordHash, err)
 	}
-
 	return string(plaintext), nil
 }
 
-func (p *passwordService) Compare(plainPassword, encryptedPassword string) (bool, error) {
-	decrypted, err := p.Decrypt(encryptedPassword)
-	if err != nil {
-		return false, err
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// encodeArgon2id gera a string PHC: $argon2id$v=19$m=65536,t=3,p=2$salt$hash,
+// com salt e hash em base64 sem preenchimento
+func encodeArgon2id(params *argon2Params) string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.memory, params.iterations, params.parallelism,
+		base64.RawStdEncoding.EncodeToString(params.salt),
+		base64.RawStdEncoding.EncodeToString(params.key))
+}
+
+func decodeArgon2id(encoded string) (*argon2Params, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrInvalidPasswordHash
 	}
 
-	return plainPassword == decrypted, nil
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("%w: versão do argon2 não suportada", ErrInvalidPasswordHash)
+	}
+	params := &argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return nil, fmt.Errorf("%w: parâmetros do argon2", ErrInvalidPasswordHash)
+	}
+	if params.iterations == 0 || params.parallelism == 0 {
+		return nil, fmt.Errorf("%w: parâmetros do argon2", ErrInvalidPasswordHash)
+	}
+
+	var err error
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("%w: salt", ErrInvalidPasswordHash)
+	}
+	if params.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(params.key) == 0 {
+		return nil, fmt.Errorf("%w: hash", ErrInvalidPasswordHash)
+	}
+	return params, nil
 }
@@ -0,0 +1,283 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// custos mínimos para que os testes sejam rápidos
+func testPasswordConfig(algorithm string) *PasswordConfig {
+	return &PasswordConfig{
+		Algorithm:         algorithm,
+		Argon2MemoryKiB:   64,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+		BcryptCost:        4,
+		LegacyKey:         []byte("0123456789abcdef0123456789abcdef"),
+	}
+}
+
+func testPasswordService(t *testing.T, cfg *PasswordConfig) PasswordService {
+	t.Helper()
+	p, err := NewPasswordService(cfg)
+	if err != nil {
+		t.Fatalf("NewPasswordService: %v", err)
+	}
+	return p
+}
+
+// legacyEncrypt reproduz a cifra AES-GCM da versão anterior do serviço
+func legacyEncrypt(t *testing.T, key []byte, password string) string {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(password), nil))
+}
+
+func TestPasswordHashRoundTrip(t *testing.T) {
+	for _, algorithm := range []string{PasswordAlgorithmArgon2id, PasswordAlgorithmBcrypt} {
+		t.Run(algorithm, func(t *testing.T) {
+			p := testPasswordService(t, testPasswordConfig(algorithm))
+			hash, err := p.Hash("S3nha forte!")
+			if err != nil {
+				t.Fatalf("Hash: %v", err)
+			}
+			if PasswordScheme(hash) != algorithm {
+				t.Fatalf("PasswordScheme(%q) = %s, want %s", hash, PasswordScheme(hash), algorithm)
+			}
+			again, _ := p.Hash("S3nha forte!")
+			if again == hash {
+				t.Fatal("Hash deve usar salt aleatório")
+			}
+
+			tests := []struct {
+				name     string
+				password string
+				wantOK   bool
+			}{
+				{"senha correta", "S3nha forte!", true},
+				{"senha errada", "S3nha forte?", false},
+				{"vazia", "", false},
+				{"prefixo", "S3nha", false},
+			}
+			for _, tt := range tests {
+				ok, needsRehash, err := p.Verify(tt.password, hash)
+				if err != nil {
+					t.Fatalf("%s: Verify: %v", tt.name, err)
+				}
+				if ok != tt.wantOK || needsRehash {
+					t.Errorf("%s: Verify = (%v, %v), want (%v, false)", tt.name, ok, needsRehash, tt.wantOK)
+				}
+			}
+			if p.NeedsRehash(hash) {
+				t.Error("NeedsRehash de um hash recém-criado deve ser false")
+			}
+		})
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	base := testPasswordConfig(PasswordAlgorithmArgon2id)
+	argonHash, err := testPasswordService(t, base).Hash("senha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHash, err := testPasswordService(t, testPasswordConfig(PasswordAlgorithmBcrypt)).Hash("senha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := legacyEncrypt(t, base.LegacyKey, "senha")
+
+	withConfig := func(change func(*PasswordConfig)) *PasswordConfig {
+		cfg := *testPasswordConfig(PasswordAlgorithmArgon2id)
+		change(&cfg)
+		return &cfg
+	}
+
+	tests := []struct {
+		name   string
+		config *PasswordConfig
+		hash   string
+		want   bool
+	}{
+		{"argon2id com a configuração atual", base, argonHash, false},
+		{"argon2id com outra memória", withConfig(func(c *PasswordConfig) { c.Argon2MemoryKiB = 128 }), argonHash, true},
+		{"argon2id com outras iterações", withConfig(func(c *PasswordConfig) { c.Argon2Iterations = 2 }), argonHash, true},
+		{"argon2id com outro paralelismo", withConfig(func(c *PasswordConfig) { c.Argon2Parallelism = 2 }), argonHash, true},
+		{"argon2id com outro tamanho de chave", withConfig(func(c *PasswordConfig) { c.Argon2KeyLength = 64 }), argonHash, true},
+		{"argon2id com algoritmo bcrypt", testPasswordConfig(PasswordAlgorithmBcrypt), argonHash, true},
+		{"argon2id malformado", base, "$argon2id$v=19$m=64,t=1$x$y", true},
+		{"bcrypt com algoritmo argon2id", base, bcryptHash, true},
+		{"bcrypt com o custo atual", testPasswordConfig(PasswordAlgorithmBcrypt), bcryptHash, false},
+		{"bcrypt com outro custo", withConfig(func(c *PasswordConfig) { c.Algorithm = PasswordAlgorithmBcrypt; c.BcryptCost = 5 }), bcryptHash, true},
+		{"legado", base, legacy, true},
+		{"inutilizável", base, "!", false},
+		{"vazio", base, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testPasswordService(t, tt.config).NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordVerifyRehashAfterConfigChange(t *testing.T) {
+	old := testPasswordService(t, testPasswordConfig(PasswordAlgorithmBcrypt))
+	hash, err := old.Hash("senha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := testPasswordService(t, testPasswordConfig(PasswordAlgorithmArgon2id))
+	ok, needsRehash, err := current.Verify("senha", hash)
+	if err != nil || !ok || !needsRehash {
+		t.Fatalf("Verify = (%v, %v, %v), want (true, true, nil)", ok, needsRehash, err)
+	}
+	ok, needsRehash, err = current.Verify("outra", hash)
+	if err != nil || ok || needsRehash {
+		t.Fatalf("Verify com senha errada = (%v, %v, %v), want (false, false, nil)", ok, needsRehash, err)
+	}
+}
+
+func TestPasswordVerifyLegacy(t *testing.T) {
+	cfg := testPasswordConfig(PasswordAlgorithmArgon2id)
+	legacy := legacyEncrypt(t, cfg.LegacyKey, "senha antiga")
+	otherKey := legacyEncrypt(t, []byte("fedcba9876543210fedcba9876543210"), "senha antiga")
+	withoutKey := testPasswordConfig(PasswordAlgorithmArgon2id)
+	withoutKey.LegacyKey = nil
+
+	tests := []struct {
+		name            string
+		config          *PasswordConfig
+		password        string
+		hash            string
+		wantOK          bool
+		wantNeedsRehash bool
+		wantErr         error
+	}{
+		{"senha correta", cfg, "senha antiga", legacy, true, true, nil},
+		{"senha errada", cfg, "senha nova", legacy, false, false, nil},
+		{"cifrada com outra chave", cfg, "senha antiga", otherKey, false, false, ErrInvalidPasswordHash},
+		{"base64 inválido", cfg, "senha antiga", "não é base64", false, false, ErrInvalidPasswordHash},
+		{"truncada", cfg, "senha antiga", base64.StdEncoding.EncodeToString([]byte("curta")), false, false, ErrInvalidPasswordHash},
+		{"sem a chave legada", withoutKey, "senha antiga", legacy, false, false, ErrLegacyPasswordKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, needsRehash, err := testPasswordService(t, tt.config).Verify(tt.password, tt.hash)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify err = %v, want %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || needsRehash != tt.wantNeedsRehash {
+				t.Fatalf("Verify = (%v, %v), want (%v, %v)", ok, needsRehash, tt.wantOK, tt.wantNeedsRehash)
+			}
+		})
+	}
+}
+
+func TestPasswordVerifyUnusable(t *testing.T) {
+	p := testPasswordService(t, testPasswordConfig(PasswordAlgorithmArgon2id))
+	for _, hash := range []string{"", "!", "!$argon2id$v=19$m=64,t=1,p=1$c2FsdA$a2V5", "$pbkdf2$abc"} {
+		ok, needsRehash, err := p.Verify("", hash)
+		if ok || needsRehash || err != nil {
+			t.Errorf("Verify(%q) = (%v, %v, %v), want (false, false, nil)", hash, ok, needsRehash, err)
+		}
+	}
+}
+
+func TestPasswordScheme(t *testing.T) {
+	tests := []struct {
+		hash string
+		want string
+	}{
+		{"$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$a2V5", PasswordSchemeArgon2id},
+		{"$2a$12$abcdefghijklmnopqrstuv", PasswordSchemeBcrypt},
+		{"$2b$12$abcdefghijklmnopqrstuv", PasswordSchemeBcrypt},
+		{"$2y$12$abcdefghijklmnopqrstuv", PasswordSchemeBcrypt},
+		{"$argon2i$v=19$m=65536,t=3,p=2$c2FsdA$a2V5", PasswordSchemeUnusable},
+		{"$pbkdf2-sha256$abc", PasswordSchemeUnusable},
+		{"!", PasswordSchemeUnusable},
+		{"", PasswordSchemeUnusable},
+		{"q83vEjRWeJA=", PasswordSchemeLegacy},
+	}
+	for _, tt := range tests {
+		if got := PasswordScheme(tt.hash); got != tt.want {
+			t.Errorf("PasswordScheme(%q) = %s, want %s", tt.hash, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeArgon2id(t *testing.T) {
+	valid := "$argon2id$v=19$m=65536,t=3,p=2$c2FsdHNhbHRzYWx0$a2V5a2V5a2V5a2V5"
+	params, err := decodeArgon2id(valid)
+	if err != nil {
+		t.Fatalf("decodeArgon2id: %v", err)
+	}
+	if params.memory != 65536 || params.iterations != 3 || params.parallelism != 2 ||
+		string(params.salt) != "saltsaltsalt" || string(params.key) != "keykeykeykey" {
+		t.Fatalf("decodeArgon2id = %+v", params)
+	}
+	if encoded := encodeArgon2id(params); encoded != valid {
+		t.Fatalf("encodeArgon2id = %q, want %q", encoded, valid)
+	}
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"partes faltando", "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA"},
+		{"partes a mais", valid + "$extra"},
+		{"outra variante", strings.Replace(valid, "argon2id", "argon2i", 1)},
+		{"outra versão", strings.Replace(valid, "v=19", "v=16", 1)},
+		{"parâmetros ilegíveis", strings.Replace(valid, "m=65536,t=3,p=2", "m=x,t=3,p=2", 1)},
+		{"zero iterações", strings.Replace(valid, "t=3", "t=0", 1)},
+		{"paralelismo zero", strings.Replace(valid, "p=2", "p=0", 1)},
+		{"salt inválido", strings.Replace(valid, "c2FsdHNhbHRzYWx0", "***", 1)},
+		{"hash vazio", "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeArgon2id(tt.encoded); !errors.Is(err, ErrInvalidPasswordHash) {
+				t.Fatalf("decodeArgon2id err = %v, want %v", err, ErrInvalidPasswordHash)
+			}
+		})
+	}
+}
+
+func TestNewPasswordServiceRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*PasswordConfig)
+	}{
+		{"algoritmo desconhecido", func(c *PasswordConfig) { c.Algorithm = "md5" }},
+		{"custo do bcrypt baixo", func(c *PasswordConfig) { c.BcryptCost = 3 }},
+		{"custo do bcrypt alto", func(c *PasswordConfig) { c.BcryptCost = 32 }},
+		{"memória insuficiente", func(c *PasswordConfig) { c.Argon2MemoryKiB = 8; c.Argon2Parallelism = 2 }},
+		{"chave legada com tamanho inválido", func(c *PasswordConfig) { c.LegacyKey = []byte("curta") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testPasswordConfig(PasswordAlgorithmArgon2id)
+			tt.change(cfg)
+			if _, err := NewPasswordService(cfg); err == nil {
+				t.Fatal("esperava erro")
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package security
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret é o segredo SHA-1 dos vetores da RFC 6238, "12345678901234567890" em base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// vetores SHA-1 do apêndice B da RFC 6238; com 6 dígitos vale o final do
+	// código de 8 dígitos publicado
+	tests := []struct {
+		unix int64
+		step int64
+		want string
+	}{
+		{59, 0x1, "287082"},
+		{1111111109, 0x23523EC, "081804"},
+		{1111111111, 0x23523ED, "050471"},
+		{1234567890, 0x273EF07, "005924"},
+		{2000000000, 0x3F940AA, "279037"},
+		{20000000000, 0x27BC86AA, "353130"},
+	}
+	for _, tt := range tests {
+		step := TOTPStep(time.Unix(tt.unix, 0))
+		if step != tt.step {
+			t.Errorf("TOTPStep(%d) = %#x, want %#x", tt.unix, step, tt.step)
+		}
+		got, err := TOTPCode(rfc6238Secret, step)
+		if err != nil {
+			t.Fatalf("TOTPCode: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestTOTPCodeRFC4226(t *testing.T) {
+	// vetores HOTP do apêndice D da RFC 4226, contadores 0 a 9
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		got, err := TOTPCode(rfc6238Secret, int64(counter))
+		if err != nil {
+			t.Fatalf("TOTPCode: %v", err)
+		}
+		if got != code {
+			t.Errorf("TOTPCode(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestTOTPCodeInvalidSecret(t *testing.T) {
+	if _, err := TOTPCode("não é base32!", 1); err == nil {
+		t.Fatal("esperava erro para segredo inválido")
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := TOTPStep(now)
+	code := func(step int64) string {
+		c, err := TOTPCode(rfc6238Secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		drift    int
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"passo atual", rfc6238Secret, "050471", 1, 0, current, true},
+		{"com espaços", rfc6238Secret, " 050471 ", 1, 0, current, true},
+		{"segredo em minúsculas", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "050471", 1, 0, current, true},
+		{"passo anterior dentro do drift", rfc6238Secret, code(current - 1), 1, 0, current - 1, true},
+		{"passo seguinte dentro do drift", rfc6238Secret, code(current + 1), 1, 0, current + 1, true},
+		{"passo anterior fora do drift", rfc6238Secret, code(current - 2), 1, 0, 0, false},
+		{"passo seguinte fora do drift", rfc6238Secret, code(current + 2), 1, 0, 0, false},
+		{"sem drift", rfc6238Secret, code(current - 1), 0, 0, 0, false},
+		{"código reutilizado", rfc6238Secret, "050471", 1, current, 0, false},
+		{"passo anterior ao último usado", rfc6238Secret, code(current - 1), 1, current, 0, false},
+		{"passo seguinte ao último usado", rfc6238Secret, code(current + 1), 1, current, current + 1, true},
+		{"código errado", rfc6238Secret, "050472", 1, 0, 0, false},
+		{"curto", rfc6238Secret, "05047", 1, 0, 0, false},
+		{"longo", rfc6238Secret, "0504711", 1, 0, 0, false},
+		{"vazio", rfc6238Secret, "", 1, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok, err := VerifyTOTP(tt.secret, tt.code, now, tt.drift, tt.lastStep)
+			if err != nil {
+				t.Fatalf("VerifyTOTP: %v", err)
+			}
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("VerifyTOTP = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestVerifyTOTPInvalidSecret(t *testing.T) {
+	if _, ok, err := VerifyTOTP("***", "123456", time.Now(), 1, 0); err == nil || ok {
+		t.Fatalf("VerifyTOTP = (%v, %v), want erro", ok, err)
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: %v", err)
+	}
+	// 160 bits em base32 sem preenchimento
+	if len(secret) != 32 {
+		t.Fatalf("segredo com %d caracteres, want 32", len(secret))
+	}
+	if _, err := TOTPCode(secret, 1); err != nil {
+		t.Fatalf("segredo gerado não é aceito por TOTPCode: %v", err)
+	}
+	other, _ := GenerateTOTPSecret()
+	if other == secret {
+		t.Fatal("GenerateTOTPSecret deve gerar segredos diferentes")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI(rfc6238Secret, "Oak Bank", "maria@example.com")
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("url.Parse: %v", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Fatalf("URI %q não é otpauth://totp", uri)
+	}
+	if u.Path != "/Oak Bank:maria@example.com" {
+		t.Errorf("rótulo = %q", u.Path)
+	}
+	query := u.Query()
+	want := map[string]string{
+		"secret":    rfc6238Secret,
+		"issuer":    "Oak Bank",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+	if strings.Contains(u.RawQuery, "+") {
+		t.Errorf("espaços devem ser codificados como %%20: %q", u.RawQuery)
+	}
+}
@@ -1 +1,152 @@
 package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAccountStatusUnchanged = fmt.Errorf("%w: conta já está no status informado", ErrConflict)
+	ErrAccountClosed          = fmt.Errorf("%w: conta encerrada não pode mudar de status", ErrConflict)
+)
+
+// AccountStatusHook é executado dentro da transação que grava a mudança de
+// status. Retornar erro desfaz a mudança.
+type AccountStatusHook func(ctx context.Context, tx *gorm.DB, account *models.Account, history *models.AccountStatusHistory) error
+
+type AccountService interface {
+	// ChangeStatus altera o status da conta e grava a entrada em AccountStatusHistory
+	ChangeStatus(ctx context.Context, actorUserID, accountID, ipAddress string, req *dtos.ChangeAccountStatusDTO) (*dtos.AccountStatusHistoryDTO, error)
+
+	// StatusHistory lista as mudanças de status da conta, da mais recente para a mais antiga
+	StatusHistory(ctx context.Context, accountID string) ([]dtos.AccountStatusHistoryDTO, error)
+
+	// AddStatusListener registra um hook executado após cada mudança de status
+	AddStatusListener(hook AccountStatusHook)
+}
+
+type accountService struct {
+	db        *gorm.DB
+	listeners []AccountStatusHook
+}
+
+func NewAccountService(db *gorm.DB) AccountService {
+	return &accountService{
+		db: db,
+	}
+}
+
+func (s *accountService) AddStatusListener(hook AccountStatusHook) {
+	s.listeners = append(s.listeners, hook)
+}
+
+func (s *accountService) ChangeStatus(ctx context.Context, actorUserID, accountID, ipAddress string, req *dtos.ChangeAccountStatusDTO) (*dtos.AccountStatusHistoryDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	var history models.AccountStatusHistory
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var account models.Account
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&account, "account_id = ?", accountID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAccountNotFound
+			}
+			return err
+		}
+		if account.AccountStatus == req.Status {
+			return ErrAccountStatusUnchanged
+		}
+		if account.AccountStatus == models.AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		updates := map[string]interface{}{
+			"account_status": req.Status,
+			"blocked_reason": nil,
+		}
+		switch req.Status {
+		case models.AccountStatusBlocked:
+			updates["blocked_reason"] = truncate(req.Reason, 200)
+		case models.AccountStatusClosed:
+			updates["date_closed"] = time.Now()
+		}
+		if err := tx.Model(&account).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		history = models.AccountStatusHistory{
+			AccountID:       account.AccountID,
+			PreviousStatus:  nullString(account.AccountStatus),
+			NewStatus:       req.Status,
+			ChangeReason:    nullString(req.Reason),
+			ChangedByUserID: nullString(actorUserID),
+			IPAddress:       nullString(ipAddress),
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+
+		account.AccountStatus = req.Status
+		for _, listener := range s.listeners {
+			if err := listener(ctx, tx, &account, &history); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := accountStatusHistoryDTO(&history)
+	return &response, nil
+}
+
+func (s *accountService) StatusHistory(ctx context.Context, accountID string) ([]dtos.AccountStatusHistoryDTO, error) {
+	var exists int64
+	if err := s.db.WithContext(ctx).Model(&models.Account{}).Where("account_id = ?", accountID).Count(&exists).Error; err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		return nil, ErrAccountNotFound
+	}
+
+	var entries []models.AccountStatusHistory
+	if err := s.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Order("changed_at DESC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.AccountStatusHistoryDTO, 0, len(entries))
+	for i := range entries {
+		result = append(result, accountStatusHistoryDTO(&entries[i]))
+	}
+	return result, nil
+}
+
+func accountStatusHistoryDTO(h *models.AccountStatusHistory) dtos.AccountStatusHistoryDTO {
+	return dtos.AccountStatusHistoryDTO{
+		HistoryID:       h.HistoryID,
+		AccountID:       h.AccountID,
+		PreviousStatus:  h.PreviousStatus.String,
+		NewStatus:       h.NewStatus,
+		ChangeReason:    h.ChangeReason.String,
+		ChangedByUserID: h.ChangedByUserID.String,
+		ChangedAt:       h.ChangedAt,
+	}
+}
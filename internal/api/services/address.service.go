@@ -1 +1,502 @@
 package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/security"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/internal/notifications"
+	"github.com/victor-lima-142/oak-bank/internal/postal"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"github.com/victor-lima-142/oak-bank/pkg/textutil"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPostalCodeInvalid  = fmt.Errorf("%w: CEP inválido", ErrInvalidInput)
+	ErrPostalCodeNotFound = fmt.Errorf("%w: CEP", ErrNotFound)
+	ErrAddressNotFound    = fmt.Errorf("%w: endereço vigente do cliente", ErrNotFound)
+	ErrLastAddress        = fmt.Errorf("%w: o cliente precisa manter ao menos um endereço vigente", ErrConflict)
+)
+
+type AddressService interface {
+	// LookupPostalCode retorna o endereço do CEP para preenchimento automático
+	LookupPostalCode(ctx context.Context, cep string) (*dtos.PostalCodeDTO, error)
+
+	// Normalize completa e padroniza um endereço brasileiro pelo CEP: formata
+	// o CEP, converte o estado para a sigla da UF, preenche os campos vazios
+	// e aponta divergências entre o CEP e a cidade ou UF informadas.
+	// Endereços de outros países são devolvidos sem alteração.
+	Normalize(ctx context.Context, req *dtos.CreateAddressDTO) (*dtos.AddressNormalizationDTO, error)
+
+	// List retorna os endereços vigentes do cliente do usuário
+	List(ctx context.Context, userID string) (*dtos.CustomerAddressListDTO, error)
+
+	// History lista o histórico de endereços do cliente do usuário, do mais
+	// recente para o mais antigo
+	History(ctx context.Context, userID string, req *dtos.AddressHistoryRequestDTO) (*dtos.CustomerAddressListDTO, error)
+
+	// CustomerHistory é a consulta do histórico de qualquer cliente pelo compliance
+	CustomerHistory(ctx context.Context, customerID string, req *dtos.AddressHistoryRequestDTO) (*dtos.CustomerAddressListDTO, error)
+
+	// Change inclui um endereço para o cliente do usuário, encerrando a
+	// vigência do endereço substituído
+	Change(ctx context.Context, userID string, req *dtos.ChangeAddressDTO) (*dtos.ChangeAddressResponseDTO, error)
+
+	// SetPrimary torna um endereço vigente o principal do seu tipo. O
+	// principal anterior continua vigente.
+	SetPrimary(ctx context.Context, userID, addressID string, req *dtos.ConfirmAddressChangeDTO) (*dtos.CustomerAddressDTO, error)
+
+	// End encerra a vigência de um endereço. Se era o principal, o endereço
+	// vigente mais recente do mesmo tipo assume.
+	End(ctx context.Context, userID, addressID string, req *dtos.ConfirmAddressChangeDTO) error
+}
+
+type addressService struct {
+	db            *gorm.DB
+	postal        postal.Provider
+	passwords     security.PasswordService
+	notifications NotificationService
+}
+
+func NewAddressService(db *gorm.DB, provider postal.Provider, passwords security.PasswordService, notifications NotificationService) AddressService {
+	return &addressService{
+		db:            db,
+		postal:        provider,
+		passwords:     passwords,
+		notifications: notifications,
+	}
+}
+
+func (s *addressService) LookupPostalCode(ctx context.Context, cep string) (*dtos.PostalCodeDTO, error) {
+	if !postal.ValidCEP(cep) {
+		return nil, ErrPostalCodeInvalid
+	}
+	address, err := s.postal.Lookup(ctx, postal.NormalizeCEP(cep))
+	if errors.Is(err, postal.ErrCEPNotFound) {
+		return nil, ErrPostalCodeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.PostalCodeDTO{
+		PostalCode:   postal.FormatCEP(address.CEP),
+		Street:       address.Street,
+		Neighborhood: address.Neighborhood,
+		City:         address.City,
+		State:        address.State,
+		StateName:    postal.StateName(address.State),
+	}, nil
+}
+
+func (s *addressService) Normalize(ctx context.Context, req *dtos.CreateAddressDTO) (*dtos.AddressNormalizationDTO, error) {
+	address := *req
+	result := &dtos.AddressNormalizationDTO{Address: &address}
+	if address.Country != "" && !postal.IsBrazil(address.Country) {
+		return result, nil
+	}
+	if !postal.ValidCEP(address.PostalCode) {
+		// sem CEP válido não há o que completar; a validação aponta o erro
+		return result, nil
+	}
+
+	address.Country = postal.CountryBrazil
+	address.PostalCode = postal.FormatCEP(address.PostalCode)
+	if uf, ok := postal.NormalizeUF(address.State); ok {
+		address.State = uf
+	}
+
+	found, err := s.postal.Lookup(ctx, postal.NormalizeCEP(address.PostalCode))
+	switch {
+	case errors.Is(err, postal.ErrCEPNotFound):
+		result.Warnings = map[string]string{"postal_code": "CEP não encontrado"}
+		return result, nil
+	case err != nil:
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// a consulta é uma conveniência: uma falha do provedor não impede o cadastro
+		log.Printf("postal: lookup of %s failed: %v", address.PostalCode, err)
+		result.Warnings = map[string]string{"postal_code": "não foi possível consultar o CEP"}
+		return result, nil
+	}
+	result.Found = true
+
+	warnings := map[string]string{}
+	if strings.TrimSpace(address.AddressLine1) == "" {
+		address.AddressLine1 = found.Street
+	}
+	if strings.TrimSpace(address.Neighborhood) == "" {
+		address.Neighborhood = found.Neighborhood
+	}
+	if strings.TrimSpace(address.City) == "" {
+		address.City = found.City
+	} else if textutil.Fold(address.City) != textutil.Fold(found.City) {
+		warnings["city"] = fmt.Sprintf("não corresponde ao CEP (%s)", found.City)
+	}
+	if strings.TrimSpace(address.State) == "" {
+		address.State = found.State
+	} else if address.State != found.State {
+		warnings["state"] = fmt.Sprintf("não corresponde ao CEP (%s)", found.State)
+	}
+	if len(warnings) > 0 {
+		result.Warnings = warnings
+	}
+	return result, nil
+}
+
+func (s *addressService) List(ctx context.Context, userID string) (*dtos.CustomerAddressListDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []models.CustomerAddress
+	if err := s.db.WithContext(ctx).Preload("Address").
+		Where("customer_id = ? AND effective_to IS NULL", customerID).
+		Order("address_type, is_primary DESC, effective_from DESC").
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return customerAddressListDTO(links), nil
+}
+
+func (s *addressService) History(ctx context.Context, userID string, req *dtos.AddressHistoryRequestDTO) (*dtos.CustomerAddressListDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.CustomerHistory(ctx, customerID, req)
+}
+
+func (s *addressService) CustomerHistory(ctx context.Context, customerID string, req *dtos.AddressHistoryRequestDTO) (*dtos.CustomerAddressListDTO, error) {
+	db := s.db.WithContext(ctx)
+	var customer models.Customer
+	if err := db.Select("customer_id", "timezone").First(&customer, "customer_id = ?", customerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, err
+	}
+
+	query := db.Preload("Address").Where("customer_id = ?", customerID)
+	if req.AddressType != "" {
+		query = query.Where("address_type = ?", req.AddressType)
+	}
+	if req.At != "" {
+		// a data é o dia inteiro no fuso do cliente
+		day, err := time.ParseInLocation("2006-01-02", req.At, customerLocation(&customer))
+		if err != nil {
+			return nil, &ValidationError{Fields: map[string]string{"at": "data inválida"}}
+		}
+		query = query.Where("effective_from < ? AND (effective_to IS NULL OR effective_to > ?)", day.AddDate(0, 0, 1), day)
+	}
+
+	var links []models.CustomerAddress
+	if err := query.Order("effective_from DESC, address_type").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return customerAddressListDTO(links), nil
+}
+
+func (s *addressService) Change(ctx context.Context, userID string, req *dtos.ChangeAddressDTO) (*dtos.ChangeAddressResponseDTO, error) {
+	// o endereço só é validado depois de completado pelo CEP
+	if err := validation.Validator().StructExcept(req, "Address"); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := reauthenticate(ctx, s.db, s.passwords, userID, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+
+	normalized, err := s.Normalize(ctx, &req.Address)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.Struct(normalized.Address); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: prefixFields("address", fields)}
+		}
+		return nil, err
+	}
+
+	var link models.CustomerAddress
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := lockCurrentAddresses(tx, customerID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		addressType := normalized.Address.AddressType
+
+		// o endereço substituído e, na troca do principal, o principal anterior deixam de valer
+		primary := req.Primary
+		var ended []string
+		if req.ReplacesAddressID != "" {
+			replaced := findAddressLink(current, req.ReplacesAddressID)
+			if replaced == nil {
+				return ErrAddressNotFound
+			}
+			ended = append(ended, replaced.AddressID)
+			if replaced.IsPrimary && replaced.AddressType == addressType {
+				primary = true
+			}
+		}
+		previous := primaryAddressLink(current, addressType)
+		if previous == nil || previous.AddressID == req.ReplacesAddressID {
+			primary = true
+		} else if primary {
+			ended = append(ended, previous.AddressID)
+		}
+		if len(ended) > 0 {
+			if err := tx.Model(&models.CustomerAddress{}).
+				Where("customer_id = ? AND address_id IN ?", customerID, ended).
+				Updates(map[string]interface{}{
+					"effective_to":       now,
+					"changed_by_user_id": userID,
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		address := &models.Address{
+			AddressLine1: normalized.Address.AddressLine1,
+			AddressLine2: nullString(normalized.Address.AddressLine2),
+			Neighborhood: nullString(normalized.Address.Neighborhood),
+			City:         normalized.Address.City,
+			State:        normalized.Address.State,
+			Country:      normalized.Address.Country,
+			PostalCode:   normalized.Address.PostalCode,
+		}
+		if err := tx.Create(address).Error; err != nil {
+			return err
+		}
+		link = models.CustomerAddress{
+			CustomerID:      customerID,
+			AddressID:       address.AddressID,
+			AddressType:     addressType,
+			IsPrimary:       primary,
+			EffectiveFrom:   now,
+			ChangedByUserID: nullString(userID),
+			Address:         address,
+		}
+		if err := tx.Create(&link).Error; err != nil {
+			return err
+		}
+
+		return s.afterChange(ctx, tx, customerID, address)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.ChangeAddressResponseDTO{
+		Address:  customerAddressDTO(&link),
+		Warnings: normalized.Warnings,
+	}
+	return response, nil
+}
+
+func (s *addressService) SetPrimary(ctx context.Context, userID, addressID string, req *dtos.ConfirmAddressChangeDTO) (*dtos.CustomerAddressDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(addressID); err != nil {
+		return nil, ErrAddressNotFound
+	}
+	if err := reauthenticate(ctx, s.db, s.passwords, userID, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+
+	var result dtos.CustomerAddressDTO
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := lockCurrentAddresses(tx, customerID)
+		if err != nil {
+			return err
+		}
+		target := findAddressLink(current, addressID)
+		if target == nil {
+			return ErrAddressNotFound
+		}
+		if target.IsPrimary {
+			result = customerAddressDTO(target)
+			return nil
+		}
+
+		if previous := primaryAddressLink(current, target.AddressType); previous != nil {
+			if err := tx.Model(previous).Updates(map[string]interface{}{
+				"is_primary":         false,
+				"changed_by_user_id": userID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(target).Updates(map[string]interface{}{
+			"is_primary":         true,
+			"changed_by_user_id": userID,
+		}).Error; err != nil {
+			return err
+		}
+
+		result = customerAddressDTO(target)
+		return s.afterChange(ctx, tx, customerID, target.Address)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *addressService) End(ctx context.Context, userID, addressID string, req *dtos.ConfirmAddressChangeDTO) error {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(addressID); err != nil {
+		return ErrAddressNotFound
+	}
+	if err := reauthenticate(ctx, s.db, s.passwords, userID, req.CurrentPassword); err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := lockCurrentAddresses(tx, customerID)
+		if err != nil {
+			return err
+		}
+		target := findAddressLink(current, addressID)
+		if target == nil {
+			return ErrAddressNotFound
+		}
+		if len(current) == 1 {
+			return ErrLastAddress
+		}
+
+		if err := tx.Model(target).Updates(map[string]interface{}{
+			"effective_to":       time.Now(),
+			"changed_by_user_id": userID,
+		}).Error; err != nil {
+			return err
+		}
+		if target.IsPrimary {
+			// current vem ordenado do mais recente para o mais antigo
+			for i := range current {
+				next := &current[i]
+				if next.AddressID == target.AddressID || next.AddressType != target.AddressType {
+					continue
+				}
+				if err := tx.Model(next).Update("is_primary", true).Error; err != nil {
+					return err
+				}
+				break
+			}
+		}
+
+		return s.afterChange(ctx, tx, customerID, target.Address)
+	})
+}
+
+// afterChange marca o cliente para reavaliação de risco, já que a geografia
+// compõe a classificação, e avisa o cliente da alteração
+func (s *addressService) afterChange(ctx context.Context, tx *gorm.DB, customerID string, address *models.Address) error {
+	if err := requestRiskReassessment(tx, customerID); err != nil {
+		return err
+	}
+
+	var customer models.Customer
+	if err := tx.Select("customer_id", "timezone").First(&customer, "customer_id = ?", customerID).Error; err != nil {
+		return err
+	}
+	err := s.notifications.Enqueue(ctx, tx, &NotificationRequest{
+		CustomerID: customerID,
+		Template:   notifications.TemplateAddressChanged,
+		Channels:   []string{notifications.ChannelEmail},
+		Data: map[string]string{
+			"date":  time.Now().In(customerLocation(&customer)).Format("02/01/2006 15:04"),
+			"city":  address.City,
+			"state": address.State,
+		},
+	})
+	// sem email cadastrado a alteração vale mesmo assim
+	if errors.Is(err, ErrNoRecipient) {
+		log.Printf("addresses: no recipient for change notice of customer %s", customerID)
+		return nil
+	}
+	return err
+}
+
+// lockCurrentAddresses bloqueia os vínculos vigentes do cliente, do mais
+// recente para o mais antigo, serializando as alterações de endereço
+func lockCurrentAddresses(tx *gorm.DB, customerID string) ([]models.CustomerAddress, error) {
+	var links []models.CustomerAddress
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Address").
+		Where("customer_id = ? AND effective_to IS NULL", customerID).
+		Order("effective_from DESC").
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func findAddressLink(links []models.CustomerAddress, addressID string) *models.CustomerAddress {
+	for i := range links {
+		if links[i].AddressID == addressID {
+			return &links[i]
+		}
+	}
+	return nil
+}
+
+func primaryAddressLink(links []models.CustomerAddress, addressType string) *models.CustomerAddress {
+	for i := range links {
+		if links[i].IsPrimary && links[i].AddressType == addressType {
+			return &links[i]
+		}
+	}
+	return nil
+}
+
+func customerAddressListDTO(links []models.CustomerAddress) *dtos.CustomerAddressListDTO {
+	result := &dtos.CustomerAddressListDTO{Addresses: make([]dtos.CustomerAddressDTO, 0, len(links))}
+	for i := range links {
+		result.Addresses = append(result.Addresses, customerAddressDTO(&links[i]))
+	}
+	return result
+}
+
+func customerAddressDTO(link *models.CustomerAddress) dtos.CustomerAddressDTO {
+	dto := dtos.CustomerAddressDTO{
+		AddressID:     link.AddressID,
+		AddressType:   link.AddressType,
+		IsPrimary:     link.IsPrimary,
+		EffectiveFrom: link.EffectiveFrom,
+		EffectiveTo:   nullTimePtr(link.EffectiveTo),
+	}
+	if address := link.Address; address != nil {
+		dto.AddressLine1 = address.AddressLine1
+		dto.AddressLine2 = address.AddressLine2.String
+		dto.Neighborhood = address.Neighborhood.String
+		dto.City = address.City
+		dto.State = address.State
+		dto.Country = address.Country
+		dto.PostalCode = address.PostalCode
+	}
+	return dto
+}
@@ -0,0 +1,952 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/internal/coaf"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAmlCaseNotFound    = fmt.Errorf("%w: caso de AML", ErrNotFound)
+	ErrAmlCaseClosed      = fmt.Errorf("%w: caso de AML encerrado", ErrConflict)
+	ErrAmlCaseNotReported = fmt.Errorf("%w: apenas casos encerrados como suspeita confirmada podem ser comunicados", ErrConflict)
+	ErrAmlAssigneeInvalid = fmt.Errorf("%w: responsável não encontrado", ErrInvalidInput)
+	ErrAmlNothingToReport = fmt.Errorf("%w: nenhum caso confirmado pendente de comunicação", ErrNotFound)
+)
+
+type AmlService interface {
+	// OnTransactionCompleted avalia as regras de monitoramento para os
+	// clientes de origem e destino. Deve ser registrado com
+	// MakeTransactionService.AddCompletedListener.
+	OnTransactionCompleted(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error
+
+	// OnProfileChanged alerta alterações cadastrais frequentes, indício de
+	// tomada de conta ou de uso da conta por terceiros. Deve ser registrado
+	// com ProfileService.AddChangeListener.
+	OnProfileChanged(ctx context.Context, tx *gorm.DB, customer *models.Customer, change *models.ProfileChangeRequest) error
+
+	ListCases(ctx context.Context, req *dtos.AmlCaseListRequestDTO) (*dtos.AmlCaseListDTO, error)
+	GetCase(ctx context.Context, caseID string) (*dtos.AmlCaseDTO, error)
+	Assign(ctx context.Context, actorUserID, caseID string, req *dtos.AssignAmlCaseDTO) (*dtos.AmlCaseDTO, error)
+	ChangeStatus(ctx context.Context, actorUserID, caseID string, req *dtos.ChangeAmlCaseStatusDTO) (*dtos.AmlCaseDTO, error)
+	AddNote(ctx context.Context, actorUserID, caseID string, req *dtos.AddAmlCaseNoteDTO) (*dtos.AmlCaseNoteDTO, error)
+	Close(ctx context.Context, actorUserID, caseID string, req *dtos.CloseAmlCaseDTO) (*dtos.AmlCaseDTO, error)
+
+	// ExportReport gera o lote de comunicação no leiaute do COAF com casos
+	// encerrados como suspeita confirmada e marca os casos como comunicados
+	ExportReport(ctx context.Context, actorUserID string, req *dtos.AmlReportRequestDTO) ([]byte, error)
+}
+
+// AmlThresholds são os limites de um perfil de cliente
+type AmlThresholds struct {
+	// Fracionamento: StructuringMinCount operações em StructuringWindow com
+	// valor entre ReportingThreshold*(1-StructuringMargin) e ReportingThreshold
+	StructuringMinCount int
+	StructuringMargin   float64
+
+	// Entrada e saída rápida: entradas de pelo menos RapidMinAmount em
+	// RapidWindow com saídas de pelo menos RapidOutRatio das entradas
+	RapidMinAmount float64
+	RapidOutRatio  float64
+
+	// Volume atípico: movimentação em VolumeWindow acima de VolumeFactor vezes
+	// a referência do cliente e de pelo menos VolumeMinAmount
+	VolumeFactor    float64
+	VolumeMinAmount float64
+
+	// Alterações cadastrais: ProfileChangeMinCount alterações de nome, email
+	// ou telefone aplicadas em ProfileChangeWindow
+	ProfileChangeMinCount int
+}
+
+// AmlConfig contém as regras de monitoramento. Strict vale para clientes PEP
+// ou com RiskRating HIGH.
+type AmlConfig struct {
+	ReportingThreshold   float64
+	StructuringWindow    time.Duration
+	RapidWindow          time.Duration
+	VolumeWindow         time.Duration
+	VolumeBaselineWindow time.Duration
+	ProfileChangeWindow  time.Duration
+
+	Standard AmlThresholds
+	Strict   AmlThresholds
+
+	// Dados da instituição comunicante no lote do COAF
+	ReporterTaxID string
+	ReporterName  string
+}
+
+type amlService struct {
+	db     *gorm.DB
+	config AmlConfig
+}
+
+func NewAmlService(db *gorm.DB, cfg *AmlConfig) AmlService {
+	if cfg == nil {
+		cfg = &AmlConfig{}
+	}
+	if cfg.ReportingThreshold == 0 {
+		cfg.ReportingThreshold = config.GetEnvFloat("AML_REPORTING_THRESHOLD", 10000)
+	}
+	if cfg.StructuringWindow == 0 {
+		cfg.StructuringWindow = config.GetEnvDuration("AML_STRUCTURING_WINDOW", 7*24*time.Hour)
+	}
+	if cfg.RapidWindow == 0 {
+		cfg.RapidWindow = config.GetEnvDuration("AML_RAPID_WINDOW", 48*time.Hour)
+	}
+	if cfg.VolumeWindow == 0 {
+		cfg.VolumeWindow = config.GetEnvDuration("AML_VOLUME_WINDOW", 30*24*time.Hour)
+	}
+	if cfg.VolumeBaselineWindow == 0 {
+		cfg.VolumeBaselineWindow = config.GetEnvDuration("AML_VOLUME_BASELINE_WINDOW", 180*24*time.Hour)
+	}
+	if cfg.ProfileChangeWindow == 0 {
+		cfg.ProfileChangeWindow = config.GetEnvDuration("AML_PROFILE_CHANGE_WINDOW", 30*24*time.Hour)
+	}
+	cfg.Standard = amlThresholdsFromEnv("AML_", cfg.Standard, AmlThresholds{
+		StructuringMinCount:   3,
+		StructuringMargin:     0.1,
+		RapidMinAmount:        5000,
+		RapidOutRatio:         0.8,
+		VolumeFactor:          3,
+		VolumeMinAmount:       50000,
+		ProfileChangeMinCount: 3,
+	})
+	cfg.Strict = amlThresholdsFromEnv("AML_STRICT_", cfg.Strict, AmlThresholds{
+		StructuringMinCount:   2,
+		StructuringMargin:     0.2,
+		RapidMinAmount:        2000,
+		RapidOutRatio:         0.6,
+		VolumeFactor:          2,
+		VolumeMinAmount:       20000,
+		ProfileChangeMinCount: 2,
+	})
+	if cfg.ReporterTaxID == "" {
+		cfg.ReporterTaxID = config.GetEnv("AML_REPORTER_TAX_ID", "")
+	}
+	if cfg.ReporterName == "" {
+		cfg.ReporterName = config.GetEnv("AML_REPORTER_NAME", "Oak Bank")
+	}
+
+	return &amlService{
+		db:     db,
+		config: *cfg,
+	}
+}
+
+func amlThresholdsFromEnv(prefix string, t, defaults AmlThresholds) AmlThresholds {
+	if t.StructuringMinCount == 0 {
+		t.StructuringMinCount = config.GetEnvInt(prefix+"STRUCTURING_MIN_COUNT", defaults.StructuringMinCount)
+	}
+	if t.StructuringMargin == 0 {
+		t.StructuringMargin = config.GetEnvFloat(prefix+"STRUCTURING_MARGIN", defaults.StructuringMargin)
+	}
+	if t.RapidMinAmount == 0 {
+		t.RapidMinAmount = config.GetEnvFloat(prefix+"RAPID_MIN_AMOUNT", defaults.RapidMinAmount)
+	}
+	if t.RapidOutRatio == 0 {
+		t.RapidOutRatio = config.GetEnvFloat(prefix+"RAPID_OUT_RATIO", defaults.RapidOutRatio)
+	}
+	if t.VolumeFactor == 0 {
+		t.VolumeFactor = config.GetEnvFloat(prefix+"VOLUME_FACTOR", defaults.VolumeFactor)
+	}
+	if t.VolumeMinAmount == 0 {
+		t.VolumeMinAmount = config.GetEnvFloat(prefix+"VOLUME_MIN_AMOUNT", defaults.VolumeMinAmount)
+	}
+	if t.ProfileChangeMinCount == 0 {
+		t.ProfileChangeMinCount = config.GetEnvInt(prefix+"PROFILE_CHANGE_MIN_COUNT", defaults.ProfileChangeMinCount)
+	}
+	return t
+}
+
+// amlDetection é o resultado de uma regra antes de virar alerta
+type amlDetection struct {
+	rule           string
+	bucket         string
+	description    string
+	total          float64
+	windowStart    time.Time
+	windowEnd      time.Time
+	transactionIDs []string
+	details        map[string]any
+}
+
+func (s *amlService) OnTransactionCompleted(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error {
+	accountIDs := []string{transaction.AccountIDOrigin}
+	if transaction.AccountIDDest.Valid {
+		accountIDs = append(accountIDs, transaction.AccountIDDest.String)
+	}
+
+	var customerIDs []string
+	if err := tx.Model(&models.Account{}).Where("account_id IN ?", accountIDs).Distinct().Pluck("customer_id", &customerIDs).Error; err != nil {
+		return err
+	}
+
+	for _, customerID := range customerIDs {
+		if err := s.monitorCustomer(tx, customerID, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *amlService) monitorCustomer(tx *gorm.DB, customerID string, now time.Time) error {
+	var customer models.Customer
+	if err := tx.First(&customer, "customer_id = ?", customerID).Error; err != nil {
+		return err
+	}
+
+	thresholds := s.config.Standard
+	strict := customer.IsPep || strings.EqualFold(customer.RiskRating.String, models.RiskRatingHigh)
+	if strict {
+		thresholds = s.config.Strict
+	}
+
+	var accountIDs []string
+	if err := tx.Model(&models.Account{}).Where("customer_id = ?", customerID).Pluck("account_id", &accountIDs).Error; err != nil {
+		return err
+	}
+	if len(accountIDs) == 0 {
+		return nil
+	}
+
+	var detections []amlDetection
+	for _, rule := range []func(*gorm.DB, *models.Customer, []string, AmlThresholds, time.Time) (*amlDetection, error){
+		s.detectStructuring,
+		s.detectRapidMovement,
+		s.detectUnusualVolume,
+	} {
+		detection, err := rule(tx, &customer, accountIDs, thresholds, now)
+		if err != nil {
+			return err
+		}
+		if detection != nil {
+			detections = append(detections, *detection)
+		}
+	}
+
+	for _, detection := range detections {
+		if err := s.raiseAlert(tx, customerID, strict, detection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *amlService) OnProfileChanged(ctx context.Context, tx *gorm.DB, customer *models.Customer, change *models.ProfileChangeRequest) error {
+	thresholds := s.config.Standard
+	strict := customer.IsPep || strings.EqualFold(customer.RiskRating.String, models.RiskRatingHigh)
+	if strict {
+		thresholds = s.config.Strict
+	}
+
+	now := time.Now()
+	start := now.Add(-s.config.ProfileChangeWindow)
+	var changes []models.ProfileChangeRequest
+	if err := tx.Select("request_id", "field_name").
+		Where("customer_id = ? AND request_status = ? AND applied_at >= ?", customer.CustomerID, models.ProfileChangeApplied, start).
+		Order("applied_at").
+		Find(&changes).Error; err != nil {
+		return err
+	}
+	if len(changes) < thresholds.ProfileChangeMinCount {
+		return nil
+	}
+
+	requestIDs := make([]string, 0, len(changes))
+	fields := make([]string, 0, len(changes))
+	for _, c := range changes {
+		requestIDs = append(requestIDs, c.RequestID)
+		fields = append(fields, c.FieldName)
+	}
+	return s.raiseAlert(tx, customer.CustomerID, strict, amlDetection{
+		rule:           models.AmlRuleProfileChanges,
+		bucket:         now.UTC().Format("2006-01-02"),
+		description:    fmt.Sprintf("%d alterações cadastrais em %s", len(changes), s.config.ProfileChangeWindow),
+		windowStart:    start,
+		windowEnd:      now,
+		transactionIDs: []string{},
+		details: map[string]any{
+			"count":              len(changes),
+			"fields":             uniqueStrings(fields),
+			"change_request_ids": requestIDs,
+		},
+	})
+}
+
+// externalTransactions considera as transações concluídas que entram ou saem
+// das contas do cliente, ignorando transferências entre contas próprias
+func externalTransactions(tx *gorm.DB, accountIDs []string) *gorm.DB {
+	return tx.Model(&models.Transaction{}).
+		Where("transaction_status = ?", models.TransactionStatusCompleted).
+		Where("account_id_origin IN ? OR account_id_dest IN ?", accountIDs, accountIDs).
+		Where("NOT (account_id_origin IN ? AND account_id_dest IS NOT NULL AND account_id_dest IN ?)", accountIDs, accountIDs)
+}
+
+type amlTransactionRow struct {
+	TransactionID     string
+	TransactionAmount float64
+}
+
+func (s *amlService) detectStructuring(tx *gorm.DB, _ *models.Customer, accountIDs []string, t AmlThresholds, now time.Time) (*amlDetection, error) {
+	start := now.Add(-s.config.StructuringWindow)
+	low := s.config.ReportingThreshold * (1 - t.StructuringMargin)
+
+	var rows []amlTransactionRow
+	if err := externalTransactions(tx, accountIDs).
+		Select("transaction_id", "transaction_amount").
+		Where("transaction_date >= ?", start).
+		Where("transaction_amount >= ? AND transaction_amount < ?", low, s.config.ReportingThreshold).
+		Order("transaction_date").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) < t.StructuringMinCount {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(rows))
+	total := 0.0
+	for _, row := range rows {
+		ids = append(ids, row.TransactionID)
+		total += row.TransactionAmount
+	}
+	return &amlDetection{
+		rule:           models.AmlRuleStructuring,
+		bucket:         now.UTC().Format("2006-01-02"),
+		description:    fmt.Sprintf("%d operações entre %.2f e %.2f em %s", len(rows), low, s.config.ReportingThreshold, s.config.StructuringWindow),
+		total:          total,
+		windowStart:    start,
+		windowEnd:      now,
+		transactionIDs: ids,
+		details: map[string]any{
+			"count":               len(rows),
+			"reporting_threshold": s.config.ReportingThreshold,
+			"lower_bound":         low,
+		},
+	}, nil
+}
+
+func (s *amlService) detectRapidMovement(tx *gorm.DB, _ *models.Customer, accountIDs []string, t AmlThresholds, now time.Time) (*amlDetection, error) {
+	start := now.Add(-s.config.RapidWindow)
+
+	var inflows, outflows []amlTransactionRow
+	if err := externalTransactions(tx, accountIDs).
+		Select("transaction_id", "transaction_amount").
+		Where("transaction_date >= ? AND account_id_dest IN ?", start, accountIDs).
+		Scan(&inflows).Error; err != nil {
+		return nil, err
+	}
+	if err := externalTransactions(tx, accountIDs).
+		Select("transaction_id", "transaction_amount").
+		Where("transaction_date >= ? AND account_id_origin IN ?", start, accountIDs).
+		Scan(&outflows).Error; err != nil {
+		return nil, err
+	}
+
+	in, out := 0.0, 0.0
+	ids := make([]string, 0, len(inflows)+len(outflows))
+	for _, row := range inflows {
+		in += row.TransactionAmount
+		ids = append(ids, row.TransactionID)
+	}
+	for _, row := range outflows {
+		out += row.TransactionAmount
+		ids = append(ids, row.TransactionID)
+	}
+	if in < t.RapidMinAmount || out < in*t.RapidOutRatio {
+		return nil, nil
+	}
+
+	return &amlDetection{
+		rule:           models.AmlRuleRapidMovement,
+		bucket:         now.UTC().Format("2006-01-02"),
+		description:    fmt.Sprintf("entradas de %.2f e saídas de %.2f em %s", in, out, s.config.RapidWindow),
+		total:          in + out,
+		windowStart:    start,
+		windowEnd:      now,
+		transactionIDs: ids,
+		details: map[string]any{
+			"inflow":  in,
+			"outflow": out,
+			"ratio":   out / in,
+		},
+	}, nil
+}
+
+func (s *amlService) detectUnusualVolume(tx *gorm.DB, customer *models.Customer, accountIDs []string, t AmlThresholds, now time.Time) (*amlDetection, error) {
+	start := now.Add(-s.config.VolumeWindow)
+	baselineStart := start.Add(-s.config.VolumeBaselineWindow)
+
+	var volume float64
+	if err := externalTransactions(tx, accountIDs).
+		Select("COALESCE(SUM(transaction_amount), 0)").
+		Where("transaction_date >= ?", start).
+		Scan(&volume).Error; err != nil {
+		return nil, err
+	}
+	if volume < t.VolumeMinAmount {
+		return nil, nil
+	}
+
+	var previous float64
+	if err := externalTransactions(tx, accountIDs).
+		Select("COALESCE(SUM(transaction_amount), 0)").
+		Where("transaction_date >= ? AND transaction_date < ?", baselineStart, start).
+		Scan(&previous).Error; err != nil {
+		return nil, err
+	}
+
+	// a referência é a maior entre a renda declarada e a média histórica,
+	// ambas proporcionais à janela
+	periods := float64(s.config.VolumeBaselineWindow) / float64(s.config.VolumeWindow)
+	historical := previous / periods
+	declared := declaredMonthlyIncome(customer) * float64(s.config.VolumeWindow) / float64(30*24*time.Hour)
+	baseline := historical
+	if declared > baseline {
+		baseline = declared
+	}
+	if baseline > 0 && volume <= baseline*t.VolumeFactor {
+		return nil, nil
+	}
+
+	var rows []amlTransactionRow
+	if err := externalTransactions(tx, accountIDs).
+		Select("transaction_id", "transaction_amount").
+		Where("transaction_date >= ?", start).
+		Order("transaction_amount DESC").
+		Limit(20).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.TransactionID)
+	}
+
+	return &amlDetection{
+		rule:           models.AmlRuleUnusualVolume,
+		bucket:         now.UTC().Format("2006-01"),
+		description:    fmt.Sprintf("movimentação de %.2f em %s frente à referência de %.2f", volume, s.config.VolumeWindow, baseline),
+		total:          volume,
+		windowStart:    start,
+		windowEnd:      now,
+		transactionIDs: ids,
+		details: map[string]any{
+			"volume":            volume,
+			"historical":        historical,
+			"declared_income":   declared,
+			"baseline":          baseline,
+			"factor_threshold":  t.VolumeFactor,
+			"minimum_threshold": t.VolumeMinAmount,
+		},
+	}, nil
+}
+
+// declaredMonthlyIncome lê a renda mensal informada em OtherDetails
+func declaredMonthlyIncome(customer *models.Customer) float64 {
+	if len(customer.OtherDetails) == 0 {
+		return 0
+	}
+	var details map[string]any
+	if err := json.Unmarshal(customer.OtherDetails, &details); err != nil {
+		return 0
+	}
+	switch v := details["monthly_income"].(type) {
+	case float64:
+		return v
+	case string:
+		income, _ := strconv.ParseFloat(strings.ReplaceAll(v, ",", "."), 64)
+		return income
+	}
+	return 0
+}
+
+// raiseAlert grava o alerta no caso aberto do cliente, abrindo um caso se não
+// houver. Alertas repetidos da mesma regra no mesmo período são ignorados.
+func (s *amlService) raiseAlert(tx *gorm.DB, customerID string, strict bool, d amlDetection) error {
+	dedupKey := fmt.Sprintf("%s:%s:%s", d.rule, customerID, d.bucket)
+
+	var exists int64
+	if err := tx.Model(&models.AmlAlert{}).Where("dedup_key = ?", dedupKey).Count(&exists).Error; err != nil {
+		return err
+	}
+	if exists > 0 {
+		return nil
+	}
+
+	amlCase, err := s.openCase(tx, customerID)
+	if err != nil {
+		return err
+	}
+
+	ids, err := json.Marshal(d.transactionIDs)
+	if err != nil {
+		return err
+	}
+	details, err := json.Marshal(d.details)
+	if err != nil {
+		return err
+	}
+
+	alert := &models.AmlAlert{
+		CaseID:         amlCase.CaseID,
+		CustomerID:     customerID,
+		RuleCode:       d.rule,
+		DedupKey:       dedupKey,
+		Description:    truncate(d.description, 500),
+		TotalAmount:    d.total,
+		WindowStart:    d.windowStart,
+		WindowEnd:      d.windowEnd,
+		TransactionIDs: datatypes.JSON(ids),
+		Details:        datatypes.JSON(details),
+		StrictProfile:  strict,
+	}
+	result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "dedup_key"}}, DoNothing: true}).Create(alert)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	return tx.Create(&models.AmlCaseNote{
+		CaseID:   amlCase.CaseID,
+		NoteType: models.AmlNoteAlert,
+		Note:     fmt.Sprintf("%s: %s", d.rule, d.description),
+	}).Error
+}
+
+// openCase retorna o caso não encerrado do cliente ou abre um novo. O índice
+// parcial idx_aml_cases_open_customer resolve a corrida entre transações
+// concorrentes do mesmo cliente.
+func (s *amlService) openCase(tx *gorm.DB, customerID string) (*models.AmlCase, error) {
+	amlCase := &models.AmlCase{CustomerID: customerID, CaseStatus: models.AmlCaseStatusOpen}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "customer_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Neq{Column: "case_status", Value: models.AmlCaseStatusClosed}}},
+		DoNothing:   true,
+	}).Create(amlCase).Error; err != nil {
+		return nil, err
+	}
+
+	var open models.AmlCase
+	if err := tx.Where("customer_id = ? AND case_status <> ?", customerID, models.AmlCaseStatusClosed).First(&open).Error; err != nil {
+		return nil, err
+	}
+	return &open, nil
+}
+
+func (s *amlService) ListCases(ctx context.Context, req *dtos.AmlCaseListRequestDTO) (*dtos.AmlCaseListDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.AmlCase{})
+	if req.Status != "" {
+		query = query.Where("case_status = ?", req.Status)
+	}
+	if req.Disposition != "" {
+		query = query.Where("disposition = ?", req.Disposition)
+	}
+	if req.AssignedToUserID != "" {
+		query = query.Where("assigned_to_user_id = ?", req.AssignedToUserID)
+	}
+	if req.CustomerID != "" {
+		query = query.Where("customer_id = ?", req.CustomerID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var cases []models.AmlCase
+	if err := query.Order("created_at DESC").Limit(limit).Offset(req.Offset).Find(&cases).Error; err != nil {
+		return nil, err
+	}
+
+	response := &dtos.AmlCaseListDTO{
+		Cases:      make([]dtos.AmlCaseDTO, 0, len(cases)),
+		TotalCount: int(total),
+		Limit:      limit,
+		Offset:     req.Offset,
+	}
+	for i := range cases {
+		response.Cases = append(response.Cases, amlCaseDTO(&cases[i]))
+	}
+	return response, nil
+}
+
+func (s *amlService) GetCase(ctx context.Context, caseID string) (*dtos.AmlCaseDTO, error) {
+	var amlCase models.AmlCase
+	err := s.db.WithContext(ctx).
+		Preload("Alerts", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("Notes", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		First(&amlCase, "case_id = ?", caseID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAmlCaseNotFound
+		}
+		return nil, err
+	}
+	response := amlCaseDTO(&amlCase)
+	return &response, nil
+}
+
+func (s *amlService) Assign(ctx context.Context, actorUserID, caseID string, req *dtos.AssignAmlCaseDTO) (*dtos.AmlCaseDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	var assignee int64
+	if err := s.db.WithContext(ctx).Model(&models.User{}).Where("user_id = ?", req.AssignedToUserID).Count(&assignee).Error; err != nil {
+		return nil, err
+	}
+	if assignee == 0 {
+		return nil, ErrAmlAssigneeInvalid
+	}
+
+	return s.updateCase(ctx, caseID, func(tx *gorm.DB, amlCase *models.AmlCase) error {
+		amlCase.AssignedToUserID = nullString(req.AssignedToUserID)
+		if err := tx.Model(amlCase).Update("assigned_to_user_id", amlCase.AssignedToUserID).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AmlCaseNote{
+			CaseID:       amlCase.CaseID,
+			AuthorUserID: nullString(actorUserID),
+			NoteType:     models.AmlNoteAssignment,
+			Note:         "caso atribuído a " + req.AssignedToUserID,
+		}).Error
+	})
+}
+
+func (s *amlService) ChangeStatus(ctx context.Context, actorUserID, caseID string, req *dtos.ChangeAmlCaseStatusDTO) (*dtos.AmlCaseDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	return s.updateCase(ctx, caseID, func(tx *gorm.DB, amlCase *models.AmlCase) error {
+		note := fmt.Sprintf("status alterado de %s para %s", amlCase.CaseStatus, req.Status)
+		if req.Note != "" {
+			note += ": " + req.Note
+		}
+
+		amlCase.CaseStatus = req.Status
+		if err := tx.Model(amlCase).Update("case_status", amlCase.CaseStatus).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AmlCaseNote{
+			CaseID:       amlCase.CaseID,
+			AuthorUserID: nullString(actorUserID),
+			NoteType:     models.AmlNoteStatusChange,
+			Note:         note,
+		}).Error
+	})
+}
+
+func (s *amlService) AddNote(ctx context.Context, actorUserID, caseID string, req *dtos.AddAmlCaseNoteDTO) (*dtos.AmlCaseNoteDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	var amlCase models.AmlCase
+	if err := s.db.WithContext(ctx).First(&amlCase, "case_id = ?", caseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAmlCaseNotFound
+		}
+		return nil, err
+	}
+
+	// notas continuam permitidas após o encerramento para registrar o
+	// acompanhamento da comunicação
+	note := &models.AmlCaseNote{
+		CaseID:       amlCase.CaseID,
+		AuthorUserID: nullString(actorUserID),
+		NoteType:     models.AmlNoteComment,
+		Note:         req.Note,
+	}
+	if err := s.db.WithContext(ctx).Create(note).Error; err != nil {
+		return nil, err
+	}
+	response := amlCaseNoteDTO(note)
+	return &response, nil
+}
+
+func (s *amlService) Close(ctx context.Context, actorUserID, caseID string, req *dtos.CloseAmlCaseDTO) (*dtos.AmlCaseDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	return s.updateCase(ctx, caseID, func(tx *gorm.DB, amlCase *models.AmlCase) error {
+		previous := amlCase.CaseStatus
+		amlCase.CaseStatus = models.AmlCaseStatusClosed
+		amlCase.Disposition = nullString(req.Disposition)
+		amlCase.DispositionReason = nullString(req.Reason)
+		amlCase.ClosedByUserID = nullString(actorUserID)
+		amlCase.ClosedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		if err := tx.Model(amlCase).Updates(map[string]interface{}{
+			"case_status":        amlCase.CaseStatus,
+			"disposition":        amlCase.Disposition,
+			"disposition_reason": amlCase.DispositionReason,
+			"closed_by_user_id":  amlCase.ClosedByUserID,
+			"closed_at":          amlCase.ClosedAt,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AmlCaseNote{
+			CaseID:       amlCase.CaseID,
+			AuthorUserID: nullString(actorUserID),
+			NoteType:     models.AmlNoteStatusChange,
+			Note:         fmt.Sprintf("status alterado de %s para %s com parecer %s: %s", previous, amlCase.CaseStatus, req.Disposition, req.Reason),
+		}).Error
+	})
+}
+
+// updateCase bloqueia o caso, recusa alterações em casos encerrados e aplica update
+func (s *amlService) updateCase(ctx context.Context, caseID string, update func(tx *gorm.DB, amlCase *models.AmlCase) error) (*dtos.AmlCaseDTO, error) {
+	var amlCase models.AmlCase
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&amlCase, "case_id = ?", caseID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAmlCaseNotFound
+			}
+			return err
+		}
+		if amlCase.CaseStatus == models.AmlCaseStatusClosed {
+			return ErrAmlCaseClosed
+		}
+		return update(tx, &amlCase)
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := amlCaseDTO(&amlCase)
+	return &response, nil
+}
+
+func (s *amlService) ExportReport(ctx context.Context, actorUserID string, req *dtos.AmlReportRequestDTO) ([]byte, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Alerts", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+			Preload("Customer")
+		if len(req.CaseIDs) > 0 {
+			query = query.Where("case_id IN ?", uniqueStrings(req.CaseIDs))
+		} else {
+			query = query.Where("case_status = ? AND disposition = ? AND reported_at IS NULL", models.AmlCaseStatusClosed, models.AmlDispositionConfirmed)
+		}
+
+		var cases []models.AmlCase
+		if err := query.Order("case_number").Find(&cases).Error; err != nil {
+			return err
+		}
+		if len(req.CaseIDs) > 0 && len(cases) != len(uniqueStrings(req.CaseIDs)) {
+			return ErrAmlCaseNotFound
+		}
+		if len(cases) == 0 {
+			return ErrAmlNothingToReport
+		}
+
+		lote := &coaf.Lote{
+			Comunicante: s.config.ReporterTaxID,
+			NomeEmpresa: s.config.ReporterName,
+			DataGeracao: coaf.Date(time.Now()),
+		}
+		caseIDs := make([]string, 0, len(cases))
+		for i := range cases {
+			amlCase := &cases[i]
+			if amlCase.CaseStatus != models.AmlCaseStatusClosed || amlCase.Disposition.String != models.AmlDispositionConfirmed {
+				return fmt.Errorf("%w: caso %d", ErrAmlCaseNotReported, amlCase.CaseNumber)
+			}
+			comunicacao, err := s.buildComunicacao(tx, amlCase)
+			if err != nil {
+				return err
+			}
+			lote.Comunicacoes = append(lote.Comunicacoes, *comunicacao)
+			caseIDs = append(caseIDs, amlCase.CaseID)
+		}
+
+		if err := coaf.Encode(&buf, lote); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&models.AmlCase{}).Where("case_id IN ?", caseIDs).Update("reported_at", now).Error; err != nil {
+			return err
+		}
+		notes := make([]models.AmlCaseNote, 0, len(caseIDs))
+		for _, caseID := range caseIDs {
+			notes = append(notes, models.AmlCaseNote{
+				CaseID:       caseID,
+				AuthorUserID: nullString(actorUserID),
+				NoteType:     models.AmlNoteComment,
+				Note:         "comunicação ao COAF exportada",
+			})
+		}
+		return tx.Create(&notes).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *amlService) buildComunicacao(tx *gorm.DB, amlCase *models.AmlCase) (*coaf.Comunicacao, error) {
+	comunicacao := &coaf.Comunicacao{
+		NumeroOcorrencia: strconv.FormatInt(amlCase.CaseNumber, 10),
+		Informacoes:      amlCase.DispositionReason.String,
+	}
+	if amlCase.Customer != nil {
+		comunicacao.Envolvidos = []coaf.Envolvido{{
+			Tipo:    "TITULAR",
+			CPFCNPJ: amlCase.Customer.TaxID,
+			Nome:    amlCase.Customer.CustomerName,
+			PEP:     coaf.PEP(amlCase.Customer.IsPep),
+		}}
+	}
+
+	var transactionIDs []string
+	var start, end time.Time
+	rules := map[string]bool{}
+	for _, alert := range amlCase.Alerts {
+		if start.IsZero() || alert.WindowStart.Before(start) {
+			start = alert.WindowStart
+		}
+		if alert.WindowEnd.After(end) {
+			end = alert.WindowEnd
+		}
+		if !rules[alert.RuleCode] {
+			rules[alert.RuleCode] = true
+			comunicacao.Enquadramentos = append(comunicacao.Enquadramentos, coaf.Enquadramento{
+				Codigo:    alert.RuleCode,
+				Descricao: alert.Description,
+			})
+		}
+		var ids []string
+		if err := json.Unmarshal(alert.TransactionIDs, &ids); err == nil {
+			transactionIDs = append(transactionIDs, ids...)
+		}
+	}
+	comunicacao.DataInicio = coaf.Date(start)
+	comunicacao.DataFim = coaf.Date(end)
+
+	var transactions []models.Transaction
+	if len(transactionIDs) > 0 {
+		if err := tx.Preload("AccountOrigin").Preload("AccountDest").
+			Where("transaction_id IN ?", uniqueStrings(transactionIDs)).
+			Order("transaction_date").
+			Find(&transactions).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	total := 0.0
+	for _, t := range transactions {
+		total += t.TransactionAmount
+		operacao := coaf.Operacao{
+			ID:    t.TransactionID,
+			Data:  coaf.Date(t.TransactionDate),
+			Tipo:  t.TransactionTypeCode,
+			Valor: coaf.Amount(t.TransactionAmount),
+		}
+		if t.AccountOrigin != nil {
+			operacao.ContaOrigem = t.AccountOrigin.AgencyNumber + "/" + t.AccountOrigin.AccountNumber
+		}
+		if t.AccountDest != nil {
+			operacao.ContaDestino = t.AccountDest.AgencyNumber + "/" + t.AccountDest.AccountNumber
+		}
+		comunicacao.Operacoes = append(comunicacao.Operacoes, operacao)
+	}
+	comunicacao.ValorTotal = coaf.Amount(total)
+	return comunicacao, nil
+}
+
+func amlCaseDTO(c *models.AmlCase) dtos.AmlCaseDTO {
+	response := dtos.AmlCaseDTO{
+		CaseID:            c.CaseID,
+		CaseNumber:        c.CaseNumber,
+		CustomerID:        c.CustomerID,
+		CaseStatus:        c.CaseStatus,
+		AssignedToUserID:  c.AssignedToUserID.String,
+		Disposition:       c.Disposition.String,
+		DispositionReason: c.DispositionReason.String,
+		ClosedByUserID:    c.ClosedByUserID.String,
+		ClosedAt:          nullTimePtr(c.ClosedAt),
+		ReportedAt:        nullTimePtr(c.ReportedAt),
+		CreatedAt:         c.CreatedAt,
+		UpdatedAt:         c.UpdatedAt,
+	}
+	for _, alert := range c.Alerts {
+		var ids []string
+		_ = json.Unmarshal(alert.TransactionIDs, &ids)
+		var details any
+		_ = json.Unmarshal(alert.Details, &details)
+		response.Alerts = append(response.Alerts, dtos.AmlAlertDTO{
+			AlertID:        alert.AlertID,
+			RuleCode:       alert.RuleCode,
+			Description:    alert.Description,
+			TotalAmount:    alert.TotalAmount,
+			WindowStart:    alert.WindowStart,
+			WindowEnd:      alert.WindowEnd,
+			TransactionIDs: ids,
+			Details:        details,
+			StrictProfile:  alert.StrictProfile,
+			CreatedAt:      alert.CreatedAt,
+		})
+	}
+	for i := range c.Notes {
+		response.Notes = append(response.Notes, amlCaseNoteDTO(&c.Notes[i]))
+	}
+	return response
+}
+
+func amlCaseNoteDTO(n *models.AmlCaseNote) dtos.AmlCaseNoteDTO {
+	return dtos.AmlCaseNoteDTO{
+		NoteID:       n.NoteID,
+		AuthorUserID: n.AuthorUserID.String,
+		NoteType:     n.NoteType,
+		Note:         n.Note,
+		CreatedAt:    n.CreatedAt,
+	}
+}
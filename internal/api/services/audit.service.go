@@ -1 +1,183 @@
 package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	AuditOperationInsert = "INSERT"
+	AuditOperationUpdate = "UPDATE"
+	AuditOperationDelete = "DELETE"
+
+	AuditResultSuccess = "SUCCESS"
+)
+
+type auditRequestKey struct{}
+
+type auditRequest struct {
+	ipAddress string
+	userAgent string
+}
+
+// WithAuditRequest marca no contexto a origem da requisição, gravada nos
+// registros de auditoria
+func WithAuditRequest(ctx context.Context, ipAddress, userAgent string) context.Context {
+	return context.WithValue(ctx, auditRequestKey{}, auditRequest{ipAddress: ipAddress, userAgent: userAgent})
+}
+
+// AuditEntry descreve uma alteração para o AuditLog. OldValues e NewValues
+// trazem apenas os campos alterados.
+type AuditEntry struct {
+	Table     string
+	RecordID  string
+	Operation string
+	UserID    string
+	OldValues map[string]any
+	NewValues map[string]any
+	Context   map[string]any
+}
+
+// recordAudit grava a entrada na transação do chamador, de modo que o
+// registro só existe se a alteração for confirmada
+func recordAudit(ctx context.Context, tx *gorm.DB, entry *AuditEntry) error {
+	oldValues, err := auditJSON(entry.OldValues)
+	if err != nil {
+		return err
+	}
+	newValues, err := auditJSON(entry.NewValues)
+	if err != nil {
+		return err
+	}
+	additional, err := auditJSON(entry.Context)
+	if err != nil {
+		return err
+	}
+
+	log := &models.AuditLog{
+		TableNameReference: entry.Table,
+		RecordID:           entry.RecordID,
+		OperationType:      entry.Operation,
+		UserID:             nullString(entry.UserID),
+		OldValues:          oldValues,
+		NewValues:          newValues,
+		OperationResult:    nullString(AuditResultSuccess),
+		AdditionalContext:  additional,
+	}
+	if request, ok := ctx.Value(auditRequestKey{}).(auditRequest); ok {
+		log.IPAddress = nullString(truncate(request.ipAddress, 45))
+		log.UserAgent = nullString(truncate(request.userAgent, 500))
+	}
+	return tx.Create(log).Error
+}
+
+func auditJSON(values map[string]any) (datatypes.JSON, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(raw), nil
+}
+
+type AuditService interface {
+	// List consulta o registro de auditoria, do mais recente para o mais antigo
+	List(ctx context.Context, req *dtos.AuditLogListRequestDTO) (*dtos.AuditLogListDTO, error)
+}
+
+type auditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) AuditService {
+	return &auditService{
+		db: db,
+	}
+}
+
+func (s *auditService) List(ctx context.Context, req *dtos.AuditLogListRequestDTO) (*dtos.AuditLogListDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.AuditLog{})
+	if req.Table != "" {
+		query = query.Where("table_name_reference = ?", req.Table)
+	}
+	if req.RecordID != "" {
+		query = query.Where("record_id = ?", req.RecordID)
+	}
+	if req.UserID != "" {
+		query = query.Where("user_id = ?", req.UserID)
+	}
+	if req.From != "" {
+		from, _ := time.Parse("2006-01-02", req.From)
+		query = query.Where("operation_timestamp >= ?", from)
+	}
+	if req.To != "" {
+		to, _ := time.Parse("2006-01-02", req.To)
+		query = query.Where("operation_timestamp < ?", to.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var entries []models.AuditLog
+	if err := query.Order("operation_timestamp DESC").Limit(limit).Offset(req.Offset).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	response := &dtos.AuditLogListDTO{
+		Entries:    make([]dtos.AuditLogDTO, 0, len(entries)),
+		TotalCount: int(total),
+		Limit:      limit,
+		Offset:     req.Offset,
+	}
+	for i := range entries {
+		response.Entries = append(response.Entries, auditLogDTO(&entries[i]))
+	}
+	return response, nil
+}
+
+func auditLogDTO(l *models.AuditLog) dtos.AuditLogDTO {
+	dto := dtos.AuditLogDTO{
+		AuditID:            l.AuditID,
+		Table:              l.TableNameReference,
+		RecordID:           l.RecordID,
+		OperationType:      l.OperationType,
+		UserID:             l.UserID.String,
+		OperationTimestamp: l.OperationTimestamp,
+		IPAddress:          l.IPAddress.String,
+		UserAgent:          l.UserAgent.String,
+		OperationResult:    l.OperationResult.String,
+	}
+	if len(l.OldValues) > 0 {
+		_ = json.Unmarshal(l.OldValues, &dto.OldValues)
+	}
+	if len(l.NewValues) > 0 {
+		_ = json.Unmarshal(l.NewValues, &dto.NewValues)
+	}
+	if len(l.AdditionalContext) > 0 {
+		_ = json.Unmarshal(l.AdditionalContext, &dto.AdditionalContext)
+	}
+	return dto
+}
@@ -1 +1,435 @@
 package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/security"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCredentials é a única resposta de um login recusado, seja qual
+// for o motivo, para não revelar se o usuário existe ou está bloqueado
+var ErrInvalidCredentials = errors.New("credenciais inválidas ou acesso temporariamente bloqueado")
+
+// Motivos de falha gravados em UserAuthLog.FailureReason
+const (
+	authFailureUnknownUser     = "UNKNOWN_USER"
+	authFailureInvalidPassword = "INVALID_PASSWORD"
+	authFailureInvalidMFACode  = "INVALID_MFA_CODE"
+	authFailureMFADisabled     = "MFA_DISABLED"
+	authFailureLocked          = "LOCKED"
+	authFailureInactive        = "INACTIVE"
+)
+
+// LoginHook é executado dentro da transação de um login bem-sucedido, depois
+// da gravação do UserAuthLog. Retornar erro recusa o login.
+type LoginHook func(ctx context.Context, tx *gorm.DB, authLog *models.UserAuthLog, customerID string) error
+
+type AuthService interface {
+	// Login autentica por email ou username e senha e retorna os tokens de
+	// acesso e de renovação. Toda tentativa fica em UserAuthLog; as falhas
+	// seguidas bloqueiam o usuário por LockDuration. Com a verificação em
+	// duas etapas ativa, retorna apenas o token para VerifyMFA.
+	Login(ctx context.Context, req *dtos.LoginRequestDTO) (*dtos.LoginResponseDTO, error)
+
+	// VerifyMFA conclui o login com o token da primeira etapa e o código do
+	// aplicativo autenticador, o código enviado por email ou SMS ou um código
+	// de recuperação. Os códigos errados contam para o bloqueio como as senhas
+	// erradas.
+	VerifyMFA(ctx context.Context, req *dtos.TwoFactorVerificationDTO) (*dtos.LoginResponseDTO, error)
+
+	// ResendMFACode envia um novo código da segunda etapa aos usuários com
+	// verificação por email ou SMS
+	ResendMFACode(ctx context.Context, req *dtos.MFAResendDTO) (*dtos.OneTimeCodeSentDTO, error)
+
+	// AddLoginListener registra um hook executado a cada login bem-sucedido
+	AddLoginListener(hook LoginHook)
+}
+
+// AuthConfig contém o limite de falhas e a validade dos tokens
+type AuthConfig struct {
+	// MaxFailedAttempts é o número de falhas seguidas que bloqueia o usuário
+	MaxFailedAttempts int
+	// LockDuration é o tempo de bloqueio; terminado o prazo o próximo login
+	// desbloqueia o usuário
+	LockDuration   time.Duration
+	AccessTokenTTL time.Duration
+	// MFATokenTTL é a validade do token entre as duas etapas do login
+	MFATokenTTL time.Duration
+}
+
+type authService struct {
+	db        *gorm.DB
+	jwt       security.JwtService
+	passwords security.PasswordService
+	twoFactor TwoFactorService
+	config    AuthConfig
+	listeners []LoginHook
+
+	dummyHashOnce sync.Once
+	dummyHash     string
+}
+
+func NewAuthService(db *gorm.DB, jwt security.JwtService, passwords security.PasswordService, twoFactor TwoFactorService, cfg *AuthConfig) AuthService {
+	if cfg == nil {
+		cfg = &AuthConfig{}
+	}
+	if cfg.MaxFailedAttempts == 0 {
+		cfg.MaxFailedAttempts = config.GetEnvInt("AUTH_MAX_FAILED_ATTEMPTS", 5)
+	}
+	if cfg.LockDuration == 0 {
+		cfg.LockDuration = config.GetEnvDuration("AUTH_LOCK_DURATION", 15*time.Minute)
+	}
+	if cfg.AccessTokenTTL == 0 {
+		cfg.AccessTokenTTL = config.GetEnvDuration("JWT_DEFAULT_EXPIRATION", 15*time.Minute)
+	}
+	if cfg.MFATokenTTL == 0 {
+		cfg.MFATokenTTL = config.GetEnvDuration("JWT_MFA_EXPIRATION", 5*time.Minute)
+	}
+
+	return &authService{
+		db:        db,
+		jwt:       jwt,
+		passwords: passwords,
+		twoFactor: twoFactor,
+		config:    *cfg,
+	}
+}
+
+func (s *authService) AddLoginListener(hook LoginHook) {
+	s.listeners = append(s.listeners, hook)
+}
+
+func (s *authService) Login(ctx context.Context, req *dtos.LoginRequestDTO) (*dtos.LoginResponseDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	var user models.User
+	var authLog *models.UserAuthLog
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+		identifier := strings.TrimSpace(req.EmailOrUsername)
+		if strings.Contains(identifier, "@") {
+			query = query.Where("email = ?", normalizeEmail(identifier))
+		} else {
+			query = query.Where("username = ?", identifier)
+		}
+		err := query.First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// o hash é calculado mesmo assim para que o tempo de resposta
+			// não denuncie os usuários inexistentes
+			_, _, _ = s.passwords.Verify(req.Password, s.unknownUserHash())
+			authLog = newAuthLog(ctx, "", authFailureUnknownUser)
+			return tx.Create(authLog).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if reason := checkUsable(&user, now); reason != "" {
+			_, _, _ = s.passwords.Verify(req.Password, user.PasswordHash)
+			authLog = newAuthLog(ctx, user.UserID, reason)
+			return tx.Create(authLog).Error
+		}
+
+		ok, err := verifyPassword(ctx, tx, s.passwords, &user, req.Password)
+		if err != nil && !errors.Is(err, security.ErrInvalidPasswordHash) {
+			return err
+		}
+		if !ok {
+			reason, err := recordFailure(tx, &user, authFailureInvalidPassword, now, s.config.MaxFailedAttempts, s.config.LockDuration)
+			if err != nil {
+				return err
+			}
+			authLog = newAuthLog(ctx, user.UserID, reason)
+			return tx.Create(authLog).Error
+		}
+
+		if user.TwoFactorEnabled {
+			// a senha confere, mas as falhas só são zeradas ao fim da segunda etapa
+			authLog = newAuthLog(ctx, user.UserID, "")
+			authLog.AuthEventType = models.AuthEventMFAChallenge
+			if err := tx.Create(authLog).Error; err != nil {
+				return err
+			}
+			// dentro do intervalo de reenvio o código anterior continua valendo
+			_, err := s.twoFactor.SendLoginCode(ctx, tx, &user)
+			if errors.Is(err, ErrOneTimeCodeCooldown) {
+				return nil
+			}
+			return err
+		}
+		authLog, err = s.completeLogin(ctx, tx, &user, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !authLog.Success {
+		return nil, ErrInvalidCredentials
+	}
+
+	if authLog.AuthEventType == models.AuthEventMFAChallenge {
+		return s.issueMFAToken(&user)
+	}
+	return s.issueTokens(&user, authLog.SessionID.String)
+}
+
+func (s *authService) VerifyMFA(ctx context.Context, req *dtos.TwoFactorVerificationDTO) (*dtos.LoginResponseDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+	claims, err := s.jwt.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	userID, _ := claims["user_id"].(string)
+	if userID == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	var user models.User
+	var authLog *models.UserAuthLog
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "user_id = ?", userID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			authLog = newAuthLog(ctx, "", authFailureUnknownUser)
+			return tx.Create(authLog).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		reason := checkUsable(&user, now)
+		if reason == "" && !user.TwoFactorEnabled {
+			reason = authFailureMFADisabled
+		}
+		if reason != "" {
+			authLog = newAuthLog(ctx, user.UserID, reason)
+			return tx.Create(authLog).Error
+		}
+
+		ok, err := s.twoFactor.VerifyCode(ctx, tx, &user, models.OneTimeCodePurposeLogin, req.Code, true)
+		if isOneTimeCodeError(err) {
+			// código expirado ou esgotado conta como código errado
+			ok, err = false, nil
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			reason, err := recordFailure(tx, &user, authFailureInvalidMFACode, now, s.config.MaxFailedAttempts, s.config.LockDuration)
+			if err != nil {
+				return err
+			}
+			authLog = newAuthLog(ctx, user.UserID, reason)
+			return tx.Create(authLog).Error
+		}
+		authLog, err = s.completeLogin(ctx, tx, &user, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !authLog.Success {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.issueTokens(&user, authLog.SessionID.String)
+}
+
+func (s *authService) ResendMFACode(ctx context.Context, req *dtos.MFAResendDTO) (*dtos.OneTimeCodeSentDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+	claims, err := s.jwt.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	userID, _ := claims["user_id"].(string)
+	if userID == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	var sent *dtos.OneTimeCodeSentDTO
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "user_id = ?", userID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidCredentials
+		}
+		if err != nil {
+			return err
+		}
+		if checkUsable(&user, time.Now()) != "" || !user.TwoFactorEnabled {
+			return ErrInvalidCredentials
+		}
+
+		sent, err = s.twoFactor.SendLoginCode(ctx, tx, &user)
+		if err == nil && sent == nil {
+			return ErrTwoFactorUseAuthenticator
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sent, nil
+}
+
+// checkUsable desfaz o bloqueio vencido e retorna o motivo da recusa quando
+// o usuário está inativo ou bloqueado
+func checkUsable(user *models.User, now time.Time) string {
+	if user.IsLocked && user.LockedUntil.Valid && !now.Before(user.LockedUntil.Time) {
+		user.IsLocked = false
+		user.LockedUntil = sql.NullTime{}
+		user.FailedLoginAttempts = 0
+	}
+	switch {
+	case !user.IsActive:
+		return authFailureInactive
+	case user.IsLocked:
+		return authFailureLocked
+	}
+	return ""
+}
+
+// completeLogin registra o login bem-sucedido, zera as falhas e executa os
+// listeners
+func (s *authService) completeLogin(ctx context.Context, tx *gorm.DB, user *models.User, now time.Time) (*models.UserAuthLog, error) {
+	authLog := newAuthLog(ctx, user.UserID, "")
+	authLog.SessionID = nullString(uuid.New().String())
+	if err := tx.Create(authLog).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(user).Updates(map[string]any{
+		"is_locked":             false,
+		"locked_until":          nil,
+		"failed_login_attempts": 0,
+		"last_login":            now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	for _, hook := range s.listeners {
+		if err := hook(ctx, tx, authLog, user.CustomerID.String); err != nil {
+			return nil, err
+		}
+	}
+	return authLog, nil
+}
+
+// recordFailure conta a falha e bloqueia o usuário por lockDuration ao
+// atingir maxAttempts. O contador é o mesmo do login e da verificação
+// adicional. Retorna o motivo gravado no UserAuthLog.
+func recordFailure(tx *gorm.DB, user *models.User, reason string, now time.Time, maxAttempts int, lockDuration time.Duration) (string, error) {
+	user.FailedLoginAttempts++
+	updates := map[string]any{
+		"failed_login_attempts": user.FailedLoginAttempts,
+		"is_locked":             false,
+		"locked_until":          nil,
+	}
+	if user.FailedLoginAttempts >= maxAttempts {
+		lockedUntil := now.Add(lockDuration)
+		updates["is_locked"] = true
+		updates["locked_until"] = lockedUntil
+		reason = fmt.Sprintf("%s; bloqueado até %s", reason, lockedUntil.Format(time.RFC3339))
+	}
+	return reason, tx.Model(user).Updates(updates).Error
+}
+
+func (s *authService) issueTokens(user *models.User, sessionID string) (*dtos.LoginResponseDTO, error) {
+	payload := map[string]interface{}{
+		"user_id":    user.UserID,
+		"username":   user.Username,
+		"email":      user.Email,
+		"role":       user.UserRole,
+		"session_id": sessionID,
+	}
+	expiresAt := time.Now().Add(s.config.AccessTokenTTL)
+	accessToken, err := s.jwt.CreateToken(payload, s.config.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := s.jwt.CreateRefreshToken(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.LoginResponseDTO{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+		UserID:       user.UserID,
+		UserRole:     user.UserRole,
+	}, nil
+}
+
+// issueMFAToken retorna o token da primeira etapa, aceito apenas por VerifyMFA
+func (s *authService) issueMFAToken(user *models.User) (*dtos.LoginResponseDTO, error) {
+	expiresAt := time.Now().Add(s.config.MFATokenTTL)
+	token, err := s.jwt.CreateMFAToken(map[string]interface{}{
+		"user_id": user.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.LoginResponseDTO{
+		MFARequired: true,
+		MFAToken:    token,
+		MFAMethod:   twoFactorMethod(user),
+		ExpiresAt:   expiresAt,
+		UserID:      user.UserID,
+	}, nil
+}
+
+// unknownUserHash é um hash com a configuração atual, usado na comparação
+// quando o usuário não existe
+func (s *authService) unknownUserHash() string {
+	s.dummyHashOnce.Do(func() {
+		s.dummyHash, _ = s.passwords.Hash(uuid.New().String())
+	})
+	return s.dummyHash
+}
+
+// newAuthLog monta o registro de uma tentativa com a origem da requisição.
+// Sem failureReason a tentativa é registrada como bem-sucedida.
+func newAuthLog(ctx context.Context, userID, failureReason string) *models.UserAuthLog {
+	authLog := &models.UserAuthLog{
+		UserID:            nullString(userID),
+		AuthEventType:     models.AuthEventLogin,
+		AuthTimestamp:     time.Now(),
+		DeviceFingerprint: nullString(truncate(deviceFingerprint(ctx), 255)),
+		Success:           failureReason == "",
+		FailureReason:     nullString(truncate(failureReason, 200)),
+	}
+	if request, ok := ctx.Value(auditRequestKey{}).(auditRequest); ok {
+		authLog.IPAddress = nullString(truncate(request.ipAddress, 45))
+		authLog.UserAgent = nullString(truncate(request.userAgent, 500))
+	}
+	return authLog
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/security"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+)
+
+// Categorias de erro retornadas pelos serviços. Os erros específicos de cada
+// serviço embrulham uma destas categorias para que os handlers possam mapear
+// o status HTTP com errors.Is
+var (
+	ErrNotFound     = errors.New("registro não encontrado")
+	ErrForbidden    = errors.New("acesso negado")
+	ErrConflict     = errors.New("conflito com o estado atual")
+	ErrInvalidInput = errors.New("dados inválidos")
+)
+
+// ValidationError carrega os erros de validação por campo
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %d campo(s) inválido(s)", ErrInvalidInput, len(e.Fields))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// ErrCustomerNotFound indica um cliente inexistente
+var ErrCustomerNotFound = fmt.Errorf("%w: cliente", ErrNotFound)
+
+// ErrUserWithoutCustomer indica que o usuário autenticado não está vinculado a um cliente
+var ErrUserWithoutCustomer = fmt.Errorf("%w: usuário não vinculado a um cliente", ErrForbidden)
+
+// customerIDForUser resolve o cliente vinculado ao usuário
+func customerIDForUser(ctx context.Context, db *gorm.DB, userID string) (string, error) {
+	var user models.User
+	err := db.WithContext(ctx).Select("user_id", "customer_id").First(&user, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("%w: usuário", ErrNotFound)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !user.CustomerID.Valid || user.CustomerID.String == "" {
+		return "", ErrUserWithoutCustomer
+	}
+	return user.CustomerID.String, nil
+}
+
+// Erros da confirmação de identidade
+var (
+	ErrReauthenticationFailed = fmt.Errorf("%w: senha de confirmação inválida", ErrForbidden)
+	ErrReauthenticationLocked = fmt.Errorf("%w: confirmação bloqueada temporariamente por excesso de tentativas", ErrForbidden)
+)
+
+// reauthenticate confirma a identidade do usuário antes de uma operação
+// sensível. Vale a verificação adicional já concluída na requisição
+// (WithStepUpVerified) ou a senha atual. As senhas erradas contam para o
+// mesmo bloqueio do login, e o usuário bloqueado ou inativo é recusado.
+func reauthenticate(ctx context.Context, db *gorm.DB, passwords security.PasswordService, userID, password string) error {
+	if stepUpVerified(ctx) {
+		return nil
+	}
+	if password == "" {
+		return ErrReauthenticationFailed
+	}
+
+	var refused error
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := lockUser(tx, userID, &user); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if reason := checkUsable(&user, now); reason != "" {
+			_, _, _ = passwords.Verify(password, user.PasswordHash)
+			refused = ErrReauthenticationLocked
+			authLog := newAuthLog(ctx, userID, reason)
+			authLog.AuthEventType = models.AuthEventReauth
+			return tx.Create(authLog).Error
+		}
+
+		ok, err := verifyPassword(ctx, tx, passwords, &user, password)
+		if err != nil && !errors.Is(err, security.ErrInvalidPasswordHash) {
+			return err
+		}
+		reason := ""
+		if ok {
+			if err := tx.Model(&user).Update("failed_login_attempts", 0).Error; err != nil {
+				return err
+			}
+		} else {
+			maxAttempts, lockDuration := lockoutPolicy()
+			reason, err = recordFailure(tx, &user, authFailureInvalidPassword, now, maxAttempts, lockDuration)
+			if err != nil {
+				return err
+			}
+			refused = ErrReauthenticationFailed
+		}
+		authLog := newAuthLog(ctx, userID, reason)
+		authLog.AuthEventType = models.AuthEventReauth
+		return tx.Create(authLog).Error
+	})
+	if err != nil {
+		return err
+	}
+	return refused
+}
+
+// lockoutPolicy retorna o limite de falhas seguidas e a duração do bloqueio,
+// os mesmos do login (AuthConfig)
+func lockoutPolicy() (int, time.Duration) {
+	return config.GetEnvInt("AUTH_MAX_FAILED_ATTEMPTS", 5),
+		config.GetEnvDuration("AUTH_LOCK_DURATION", 15*time.Minute)
+}
+
+// verifyPassword confere a senha com o hash do usuário e, quando ela confere
+// mas o hash é legado ou usa outra configuração, grava o hash novo. A troca
+// não altera password_changed_at, pois a senha é a mesma, e só acontece se o
+// hash não mudou desde a leitura. Uma falha ao regravar não impede o acesso:
+// dentro de uma transação a gravação usa um savepoint, e o erro desfaz só
+// ela, sem abortar a transação do chamador.
+func verifyPassword(ctx context.Context, db *gorm.DB, passwords security.PasswordService, user *models.User, password string) (bool, error) {
+	ok, needsRehash, err := passwords.Verify(password, user.PasswordHash)
+	if err != nil || !ok {
+		return false, err
+	}
+	if !needsRehash {
+		return true, nil
+	}
+
+	hash, err := passwords.Hash(password)
+	if err != nil {
+		log.Printf("password: failed to rehash password of user %s: %v", user.UserID, err)
+		return true, nil
+	}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&models.User{}).
+			Where("user_id = ? AND password_hash = ?", user.UserID, user.PasswordHash).
+			Update("password_hash", hash).Error
+	})
+	if err != nil {
+		log.Printf("password: failed to store rehashed password of user %s: %v", user.UserID, err)
+		return true, nil
+	}
+	user.PasswordHash = hash
+	return true, nil
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrBudgetNotFound       = fmt.Errorf("%w: orçamento", ErrNotFound)
+	ErrBudgetAlreadyExists  = fmt.Errorf("%w: já existe orçamento para esta categoria", ErrConflict)
+	ErrBudgetInflowCategory = fmt.Errorf("%w: orçamentos só se aplicam a categorias de saída", ErrInvalidInput)
+	ErrInvalidTimezone      = fmt.Errorf("%w: fuso horário inválido", ErrInvalidInput)
+)
+
+type BudgetService interface {
+	// Evaluate recalcula o uso dos orçamentos afetados pela transação e grava
+	// alertas para os limiares atingidos. Deve ser registrado como listener de
+	// conclusão de transação, depois da categorização.
+	Evaluate(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error
+
+	List(ctx context.Context, userID string) ([]dtos.BudgetResponseDTO, error)
+	Create(ctx context.Context, userID string, req *dtos.CreateBudgetDTO) (*dtos.BudgetResponseDTO, error)
+	Update(ctx context.Context, userID, budgetID string, req *dtos.UpdateBudgetDTO) (*dtos.BudgetResponseDTO, error)
+	Delete(ctx context.Context, userID, budgetID string) error
+
+	// ListAlerts retorna os alertas de orçamento do cliente, do mais recente ao mais antigo
+	ListAlerts(ctx context.Context, userID string, limit int) ([]dtos.BudgetAlertDTO, error)
+
+	// UpdateSettings altera o fuso horário usado para delimitar os meses
+	UpdateSettings(ctx context.Context, userID string, req *dtos.BudgetSettingsDTO) (*dtos.BudgetSettingsDTO, error)
+}
+
+type budgetService struct {
+	db *gorm.DB
+}
+
+func NewBudgetService(db *gorm.DB) BudgetService {
+	return &budgetService{
+		db: db,
+	}
+}
+
+func (s *budgetService) Evaluate(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error {
+	var origin models.Account
+	if err := tx.Preload("Customer").First(&origin, "account_id = ?", transaction.AccountIDOrigin).Error; err != nil {
+		return err
+	}
+	if origin.Customer == nil {
+		return nil
+	}
+
+	var categoryCode string
+	if err := tx.Model(&models.TransactionCategory{}).
+		Where("transaction_id = ? AND customer_id = ?", transaction.TransactionID, origin.CustomerID).
+		Pluck("category_code", &categoryCode).Error; err != nil {
+		return err
+	}
+
+	query := tx.Where("customer_id = ? AND is_active = ?", origin.CustomerID, true)
+	if categoryCode != "" {
+		query = query.Where("category_code IS NULL OR category_code = ?", categoryCode)
+	} else {
+		query = query.Where("category_code IS NULL")
+	}
+	var budgets []models.Budget
+	if err := query.Find(&budgets).Error; err != nil {
+		return err
+	}
+	if len(budgets) == 0 {
+		return nil
+	}
+
+	start, end := monthBounds(transaction.TransactionDate, customerLocation(origin.Customer))
+	period := start.Format("2006-01")
+
+	for _, budget := range budgets {
+		spent, err := budgetSpent(tx, &budget, start, end)
+		if err != nil {
+			return err
+		}
+
+		usage := spent / budget.MonthlyLimit * 100
+		for _, threshold := range models.BudgetThresholds {
+			if usage < float64(threshold) {
+				break
+			}
+			alert := &models.BudgetAlert{
+				BudgetID:      budget.BudgetID,
+				CustomerID:    budget.CustomerID,
+				PeriodMonth:   period,
+				Threshold:     threshold,
+				CategoryCode:  budget.CategoryCode,
+				SpentAmount:   spent,
+				LimitAmount:   budget.MonthlyLimit,
+				TransactionID: sql.NullString{String: transaction.TransactionID, Valid: true},
+				AlertStatus:   models.BudgetAlertStatusPending,
+			}
+			// o índice único garante um alerta por limiar no mês
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(alert).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *budgetService) List(ctx context.Context, userID string) ([]dtos.BudgetResponseDTO, error) {
+	customer, err := s.customerForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var budgets []models.Budget
+	if err := s.db.WithContext(ctx).
+		Where("customer_id = ?", customer.CustomerID).
+		Order("category_code NULLS FIRST").
+		Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.BudgetResponseDTO, 0, len(budgets))
+	for i := range budgets {
+		response, err := s.budgetResponse(s.db.WithContext(ctx), &budgets[i], customer)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *response)
+	}
+	return result, nil
+}
+
+func (s *budgetService) Create(ctx context.Context, userID string, req *dtos.CreateBudgetDTO) (*dtos.BudgetResponseDTO, error) {
+	customer, err := s.customerForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := &models.Budget{
+		CustomerID:   customer.CustomerID,
+		MonthlyLimit: req.MonthlyLimit,
+		IsActive:     true,
+	}
+
+	if req.CategoryCode != "" {
+		var category models.RefTransactionCategory
+		err := s.db.WithContext(ctx).First(&category, "category_code = ?", strings.ToUpper(req.CategoryCode)).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if category.Direction != models.CategoryDirectionOutflow {
+			return nil, ErrBudgetInflowCategory
+		}
+		budget.CategoryCode = sql.NullString{String: category.CategoryCode, Valid: true}
+	}
+
+	exists, err := s.scopeExists(ctx, customer.CustomerID, budget.CategoryCode)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrBudgetAlreadyExists
+	}
+
+	if err := s.db.WithContext(ctx).Create(budget).Error; err != nil {
+		return nil, err
+	}
+	return s.budgetResponse(s.db.WithContext(ctx), budget, customer)
+}
+
+func (s *budgetService) Update(ctx context.Context, userID, budgetID string, req *dtos.UpdateBudgetDTO) (*dtos.BudgetResponseDTO, error) {
+	customer, err := s.customerForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := s.findBudget(ctx, customer.CustomerID, budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	budget.MonthlyLimit = req.MonthlyLimit
+	if req.IsActive != nil {
+		budget.IsActive = *req.IsActive
+	}
+	if err := s.db.WithContext(ctx).Model(budget).Updates(map[string]interface{}{
+		"monthly_limit": budget.MonthlyLimit,
+		"is_active":     budget.IsActive,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return s.budgetResponse(s.db.WithContext(ctx), budget, customer)
+}
+
+func (s *budgetService) Delete(ctx context.Context, userID, budgetID string) error {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.WithContext(ctx).Delete(&models.Budget{}, "budget_id = ? AND customer_id = ?", budgetID, customerID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrBudgetNotFound
+	}
+	return nil
+}
+
+func (s *budgetService) ListAlerts(ctx context.Context, userID string, limit int) ([]dtos.BudgetAlertDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	var alerts []models.BudgetAlert
+	if err := s.db.WithContext(ctx).
+		Where("customer_id = ?", customerID).
+		Order("triggered_at DESC").
+		Limit(limit).
+		Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.BudgetAlertDTO, 0, len(alerts))
+	for _, a := range alerts {
+		result = append(result, dtos.BudgetAlertDTO{
+			AlertID:      a.AlertID,
+			BudgetID:     a.BudgetID,
+			CategoryCode: a.CategoryCode.String,
+			PeriodMonth:  a.PeriodMonth,
+			Threshold:    a.Threshold,
+			SpentAmount:  a.SpentAmount,
+			LimitAmount:  a.LimitAmount,
+			TriggeredAt:  a.TriggeredAt,
+		})
+	}
+	return result, nil
+}
+
+func (s *budgetService) UpdateSettings(ctx context.Context, userID string, req *dtos.BudgetSettingsDTO) (*dtos.BudgetSettingsDTO, error) {
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		return nil, ErrInvalidTimezone
+	}
+
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.Customer{}).
+		Where("customer_id = ?", customerID).
+		Update("timezone", req.Timezone).Error; err != nil {
+		return nil, err
+	}
+	return &dtos.BudgetSettingsDTO{Timezone: req.Timezone}, nil
+}
+
+func (s *budgetService) budgetResponse(db *gorm.DB, budget *models.Budget, customer *models.Customer) (*dtos.BudgetResponseDTO, error) {
+	start, end := monthBounds(time.Now(), customerLocation(customer))
+	spent, err := budgetSpent(db, budget, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.BudgetResponseDTO{
+		BudgetID:     budget.BudgetID,
+		CategoryCode: budget.CategoryCode.String,
+		MonthlyLimit: budget.MonthlyLimit,
+		IsActive:     budget.IsActive,
+		PeriodMonth:  start.Format("2006-01"),
+		Spent:        spent,
+		Remaining:    budget.MonthlyLimit - spent,
+		UsagePercent: spent / budget.MonthlyLimit * 100,
+	}, nil
+}
+
+func (s *budgetService) customerForUser(ctx context.Context, userID string) (*models.Customer, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var customer models.Customer
+	if err := s.db.WithContext(ctx).First(&customer, "customer_id = ?", customerID).Error; err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (s *budgetService) findBudget(ctx context.Context, customerID, budgetID string) (*models.Budget, error) {
+	var budget models.Budget
+	err := s.db.WithContext(ctx).First(&budget, "budget_id = ? AND customer_id = ?", budgetID, customerID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBudgetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (s *budgetService) scopeExists(ctx context.Context, customerID string, categoryCode sql.NullString) (bool, error) {
+	query := s.db.WithContext(ctx).Model(&models.Budget{}).Where("customer_id = ?", customerID)
+	if categoryCode.Valid {
+		query = query.Where("category_code = ?", categoryCode.String)
+	} else {
+		query = query.Where("category_code IS NULL")
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// budgetSpent soma as saídas categorizadas do cliente no intervalo [start, end)
+func budgetSpent(db *gorm.DB, budget *models.Budget, start, end time.Time) (float64, error) {
+	query := db.Model(&models.TransactionCategory{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("customer_id = ? AND direction = ? AND transaction_date >= ? AND transaction_date < ?",
+			budget.CustomerID, models.CategoryDirectionOutflow, start, end)
+	if budget.CategoryCode.Valid {
+		query = query.Where("category_code = ?", budget.CategoryCode.String)
+	}
+
+	var spent float64
+	err := query.Scan(&spent).Error
+	return spent, err
+}
+
+// monthBounds retorna o início do mês de t e o início do mês seguinte no fuso informado
+func monthBounds(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	local := t.In(loc)
+	start := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 1, 0)
+}
+
+func customerLocation(customer *models.Customer) *time.Location {
+	if customer != nil && customer.Timezone != "" {
+		if loc, err := time.LoadLocation(customer.Timezone); err == nil {
+			return loc
+		}
+	}
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
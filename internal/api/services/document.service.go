@@ -0,0 +1,325 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/internal/storage"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrDocumentNotFound       = fmt.Errorf("%w: documento", ErrNotFound)
+	ErrDocumentPurged         = fmt.Errorf("%w: documento removido após o prazo de retenção", ErrNotFound)
+	ErrDocumentEmpty          = fmt.Errorf("%w: arquivo vazio", ErrInvalidInput)
+	ErrDocumentTooLarge       = fmt.Errorf("%w: arquivo maior que o permitido", ErrInvalidInput)
+	ErrDocumentTypeNotAllowed = fmt.Errorf("%w: tipo de arquivo não permitido", ErrInvalidInput)
+	// ErrDocumentIntegrity indica que o conteúdo lido não confere com o hash
+	// gravado no envio
+	ErrDocumentIntegrity = errors.New("documento não confere com o hash registrado")
+)
+
+type DocumentService interface {
+	// Upload guarda um documento do cliente do usuário
+	Upload(ctx context.Context, userID string, req *dtos.UploadDocumentDTO, fileName string, file io.Reader) (*dtos.CustomerDocumentDTO, error)
+	List(ctx context.Context, userID string) ([]dtos.CustomerDocumentDTO, error)
+	// Download retorna o conteúdo original de um documento do cliente do usuário
+	Download(ctx context.Context, userID, documentID string) (*dtos.CustomerDocumentDTO, []byte, error)
+
+	// ListForCustomer e DownloadAny são usados pela análise de KYC
+	ListForCustomer(ctx context.Context, customerID string) ([]dtos.CustomerDocumentDTO, error)
+	DownloadAny(ctx context.Context, documentID string) (*dtos.CustomerDocumentDTO, []byte, error)
+
+	// Run remove periodicamente do object store os documentos com prazo de
+	// retenção vencido
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// DocumentConfig contém os limites de envio e o prazo de retenção
+type DocumentConfig struct {
+	MaxSize      int64
+	AllowedTypes []string
+	// Retention é contado a partir do envio. O padrão de cinco anos segue o
+	// prazo de guarda de registros da Lei 9.613/98.
+	Retention  time.Duration
+	PurgeBatch int
+}
+
+type documentService struct {
+	db     *gorm.DB
+	store  storage.ObjectStore
+	cipher *storage.Cipher
+	config DocumentConfig
+}
+
+func NewDocumentService(db *gorm.DB, store storage.ObjectStore, cipher *storage.Cipher, cfg *DocumentConfig) DocumentService {
+	if cfg == nil {
+		cfg = &DocumentConfig{}
+	}
+	if cfg.MaxSize == 0 {
+		cfg.MaxSize = int64(config.GetEnvInt("DOCUMENT_MAX_SIZE_BYTES", 10<<20))
+	}
+	if len(cfg.AllowedTypes) == 0 {
+		for _, t := range strings.Split(config.GetEnv("DOCUMENT_ALLOWED_TYPES", "image/jpeg,image/png,image/heic,application/pdf"), ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				cfg.AllowedTypes = append(cfg.AllowedTypes, t)
+			}
+		}
+	}
+	if cfg.Retention == 0 {
+		cfg.Retention = config.GetEnvDuration("DOCUMENT_RETENTION", 5*365*24*time.Hour)
+	}
+	if cfg.PurgeBatch == 0 {
+		cfg.PurgeBatch = config.GetEnvInt("DOCUMENT_PURGE_BATCH_SIZE", 100)
+	}
+
+	return &documentService{
+		db:     db,
+		store:  store,
+		cipher: cipher,
+		config: *cfg,
+	}
+}
+
+func (s *documentService) Upload(ctx context.Context, userID string, req *dtos.UploadDocumentDTO, fileName string, file io.Reader) (*dtos.CustomerDocumentDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, s.config.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrDocumentEmpty
+	}
+	if int64(len(data)) > s.config.MaxSize {
+		return nil, ErrDocumentTooLarge
+	}
+
+	// o tipo vem do conteúdo; a extensão e o Content-Type do envio são ignorados
+	detected := mimetype.Detect(data)
+	contentType := ""
+	for _, allowed := range s.config.AllowedTypes {
+		if detected.Is(allowed) {
+			contentType = allowed
+			break
+		}
+	}
+	if contentType == "" {
+		return nil, fmt.Errorf("%w: %s", ErrDocumentTypeNotAllowed, detected.String())
+	}
+
+	sum := sha256.Sum256(data)
+	document := &models.CustomerDocument{
+		CustomerID:       customerID,
+		DocumentType:     req.DocumentType,
+		FileName:         truncate(sanitizeFileName(fileName), 255),
+		ContentType:      contentType,
+		SizeBytes:        int64(len(data)),
+		SHA256:           hex.EncodeToString(sum[:]),
+		StorageBackend:   s.store.Backend(),
+		UploadedByUserID: nullString(userID),
+		RetainUntil:      time.Now().Add(s.config.Retention),
+	}
+	// o id é gerado antes para compor a chave do objeto e o dado autenticado
+	// da cifra, que impede a troca de conteúdo entre documentos
+	if err := document.BeforeCreate(nil); err != nil {
+		return nil, err
+	}
+	document.StorageKey = fmt.Sprintf("customers/%s/documents/%s", customerID, document.DocumentID)
+
+	keyID, ciphertext, err := s.cipher.Seal(data, []byte(document.DocumentID))
+	if err != nil {
+		return nil, err
+	}
+	document.EncryptionKeyID = keyID
+
+	if err := s.store.Put(ctx, document.StorageKey, ciphertext, "application/octet-stream"); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(document).Error; err != nil {
+		if delErr := s.store.Delete(context.WithoutCancel(ctx), document.StorageKey); delErr != nil {
+			log.Printf("documents: failed to remove orphan object %s: %v", document.StorageKey, delErr)
+		}
+		return nil, err
+	}
+
+	response := customerDocumentDTO(document)
+	return &response, nil
+}
+
+func (s *documentService) List(ctx context.Context, userID string) ([]dtos.CustomerDocumentDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListForCustomer(ctx, customerID)
+}
+
+func (s *documentService) ListForCustomer(ctx context.Context, customerID string) ([]dtos.CustomerDocumentDTO, error) {
+	var documents []models.CustomerDocument
+	if err := s.db.WithContext(ctx).
+		Where("customer_id = ?", customerID).
+		Order("created_at DESC").
+		Find(&documents).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.CustomerDocumentDTO, 0, len(documents))
+	for i := range documents {
+		response = append(response, customerDocumentDTO(&documents[i]))
+	}
+	return response, nil
+}
+
+func (s *documentService) Download(ctx context.Context, userID, documentID string) (*dtos.CustomerDocumentDTO, []byte, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.download(ctx, s.db.WithContext(ctx).Where("customer_id = ?", customerID), documentID)
+}
+
+func (s *documentService) DownloadAny(ctx context.Context, documentID string) (*dtos.CustomerDocumentDTO, []byte, error) {
+	return s.download(ctx, s.db.WithContext(ctx), documentID)
+}
+
+func (s *documentService) download(ctx context.Context, query *gorm.DB, documentID string) (*dtos.CustomerDocumentDTO, []byte, error) {
+	var document models.CustomerDocument
+	if err := query.First(&document, "document_id = ?", documentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrDocumentNotFound
+		}
+		return nil, nil, err
+	}
+	if document.PurgedAt.Valid {
+		return nil, nil, ErrDocumentPurged
+	}
+
+	object, err := s.store.Get(ctx, document.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer object.Close()
+
+	var ciphertext bytes.Buffer
+	if _, err := io.Copy(&ciphertext, object); err != nil {
+		return nil, nil, err
+	}
+	data, err := s.cipher.Open(document.EncryptionKeyID, ciphertext.Bytes(), []byte(document.DocumentID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != document.SHA256 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrDocumentIntegrity, document.DocumentID)
+	}
+
+	response := customerDocumentDTO(&document)
+	return &response, data, nil
+}
+
+func (s *documentService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.purgeExpired(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("documents: purge failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeExpired remove os objetos com prazo vencido. Cada documento é
+// bloqueado com SKIP LOCKED, permitindo várias instâncias.
+func (s *documentService) purgeExpired(ctx context.Context) error {
+	for {
+		purged := 0
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var documents []models.CustomerDocument
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("retain_until < ? AND purged_at IS NULL", time.Now()).
+				Order("retain_until").
+				Limit(s.config.PurgeBatch).
+				Find(&documents).Error; err != nil {
+				return err
+			}
+
+			for i := range documents {
+				if err := s.store.Delete(ctx, documents[i].StorageKey); err != nil {
+					return err
+				}
+				if err := tx.Model(&documents[i]).Update("purged_at", sql.NullTime{Time: time.Now(), Valid: true}).Error; err != nil {
+					return err
+				}
+				purged++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if purged > 0 {
+			log.Printf("documents: purged %d expired documents", purged)
+		}
+		if purged < s.config.PurgeBatch {
+			return nil
+		}
+	}
+}
+
+// sanitizeFileName mantém apenas o nome base enviado pelo cliente
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		return "document"
+	}
+	return name
+}
+
+func customerDocumentDTO(document *models.CustomerDocument) dtos.CustomerDocumentDTO {
+	return dtos.CustomerDocumentDTO{
+		DocumentID:   document.DocumentID,
+		CustomerID:   document.CustomerID,
+		DocumentType: document.DocumentType,
+		FileName:     document.FileName,
+		ContentType:  document.ContentType,
+		SizeBytes:    document.SizeBytes,
+		SHA256:       document.SHA256,
+		RetainUntil:  document.RetainUntil,
+		PurgedAt:     nullTimePtr(document.PurgedAt),
+		CreatedAt:    document.CreatedAt,
+	}
+}
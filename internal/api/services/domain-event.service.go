@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+
+	"github.com/victor-lima-142/oak-bank/internal/outbox"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+)
+
+const (
+	EventTransactionCompleted     = "TransactionCompleted"
+	EventTransactionHeld          = "TransactionHeld"
+	EventTransactionCancelled     = "TransactionCancelled"
+	EventTransactionStatusChanged = "TransactionStatusChanged"
+	EventAccountActivated         = "AccountActivated"
+	EventAccountBlocked           = "AccountBlocked"
+	EventAccountClosed            = "AccountClosed"
+	EventCustomerKycApproved      = "CustomerKycApproved"
+	EventCustomerKycRejected      = "CustomerKycRejected"
+	EventCustomerKycStatusChanged = "CustomerKycStatusChanged"
+	EventCustomerProfileChanged   = "CustomerProfileChanged"
+	EventUserLoggedIn             = "UserLoggedIn"
+)
+
+// DomainEventService grava eventos de domínio no outbox. Os hooks rodam dentro
+// da transação de banco da mudança, então o evento é confirmado junto com ela.
+type DomainEventService interface {
+	OnTransactionStatusChanged(ctx context.Context, tx *gorm.DB, transaction *models.Transaction, previousStatus string) error
+	OnAccountStatusChanged(ctx context.Context, tx *gorm.DB, account *models.Account, history *models.AccountStatusHistory) error
+	OnKYCStatusChanged(ctx context.Context, tx *gorm.DB, customer *models.Customer, previousStatus string) error
+	OnProfileChanged(ctx context.Context, tx *gorm.DB, customer *models.Customer, change *models.ProfileChangeRequest) error
+	OnUserLoggedIn(ctx context.Context, tx *gorm.DB, authLog *models.UserAuthLog, customerID string) error
+}
+
+type domainEventService struct{}
+
+func NewDomainEventService() DomainEventService {
+	return &domainEventService{}
+}
+
+func (s *domainEventService) OnTransactionStatusChanged(_ context.Context, tx *gorm.DB, transaction *models.Transaction, previousStatus string) error {
+	eventType := EventTransactionStatusChanged
+	switch transaction.TransactionStatus {
+	case models.TransactionStatusCompleted:
+		eventType = EventTransactionCompleted
+	case models.TransactionStatusOnHold:
+		eventType = EventTransactionHeld
+	case models.TransactionStatusCancelled:
+		eventType = EventTransactionCancelled
+	}
+
+	return outbox.Append(tx, outbox.Event{
+		AggregateType: models.OutboxAggregateTransaction,
+		AggregateID:   transaction.TransactionID,
+		EventType:     eventType,
+		Payload: map[string]any{
+			"transaction_id":        transaction.TransactionID,
+			"previous_status":       previousStatus,
+			"status":                transaction.TransactionStatus,
+			"transaction_type_code": transaction.TransactionTypeCode,
+			"amount":                transaction.TransactionAmount,
+			"account_id_origin":     transaction.AccountIDOrigin,
+			"account_id_dest":       transaction.AccountIDDest.String,
+			"balance_after":         transaction.BalanceAfter.Float64,
+			"created_by_source":     transaction.CreatedBySource.String,
+			"transaction_date":      transaction.TransactionDate,
+			"completed_at":          nullTimePtr(transaction.CompletedAt),
+		},
+	})
+}
+
+func (s *domainEventService) OnAccountStatusChanged(_ context.Context, tx *gorm.DB, account *models.Account, history *models.AccountStatusHistory) error {
+	eventType := EventAccountActivated
+	switch history.NewStatus {
+	case models.AccountStatusBlocked:
+		eventType = EventAccountBlocked
+	case models.AccountStatusClosed:
+		eventType = EventAccountClosed
+	}
+
+	return outbox.Append(tx, outbox.Event{
+		AggregateType: models.OutboxAggregateAccount,
+		AggregateID:   account.AccountID,
+		EventType:     eventType,
+		Payload: map[string]any{
+			"account_id":         account.AccountID,
+			"customer_id":        account.CustomerID,
+			"previous_status":    history.PreviousStatus.String,
+			"status":             history.NewStatus,
+			"reason":             history.ChangeReason.String,
+			"changed_by_user_id": history.ChangedByUserID.String,
+			"changed_at":         history.ChangedAt,
+		},
+	})
+}
+
+func (s *domainEventService) OnKYCStatusChanged(_ context.Context, tx *gorm.DB, customer *models.Customer, previousStatus string) error {
+	eventType := EventCustomerKycStatusChanged
+	switch customer.KYCStatus {
+	case models.KYCStatusApproved:
+		eventType = EventCustomerKycApproved
+	case models.KYCStatusRejected:
+		eventType = EventCustomerKycRejected
+	}
+
+	return outbox.Append(tx, outbox.Event{
+		AggregateType: models.OutboxAggregateCustomer,
+		AggregateID:   customer.CustomerID,
+		EventType:     eventType,
+		Payload: map[string]any{
+			"customer_id":     customer.CustomerID,
+			"previous_status": previousStatus,
+			"status":          customer.KYCStatus,
+			"verified_at":     nullTimePtr(customer.KYCVerifiedAt),
+		},
+	})
+}
+
+// OnProfileChanged publica apenas o campo alterado; os valores são dados
+// pessoais e ficam no cadastro
+func (s *domainEventService) OnProfileChanged(_ context.Context, tx *gorm.DB, customer *models.Customer, change *models.ProfileChangeRequest) error {
+	return outbox.Append(tx, outbox.Event{
+		AggregateType: models.OutboxAggregateCustomer,
+		AggregateID:   customer.CustomerID,
+		EventType:     EventCustomerProfileChanged,
+		Payload: map[string]any{
+			"customer_id":       customer.CustomerID,
+			"field":             change.FieldName,
+			"change_request_id": change.RequestID,
+			"changed_at":        change.AppliedAt.Time,
+		},
+	})
+}
+
+func (s *domainEventService) OnUserLoggedIn(_ context.Context, tx *gorm.DB, authLog *models.UserAuthLog, customerID string) error {
+	return outbox.Append(tx, outbox.Event{
+		AggregateType: models.OutboxAggregateUser,
+		AggregateID:   authLog.UserID.String,
+		EventType:     EventUserLoggedIn,
+		Payload: map[string]any{
+			"user_id":            authLog.UserID.String,
+			"customer_id":        customerID,
+			"auth_event_type":    authLog.AuthEventType,
+			"ip_address":         authLog.IPAddress.String,
+			"user_agent":         authLog.UserAgent.String,
+			"device_fingerprint": authLog.DeviceFingerprint.String,
+			"logged_in_at":       authLog.AuthTimestamp,
+		},
+	})
+}
@@ -0,0 +1,604 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrStepUpRequired        = fmt.Errorf("%w: transação exige verificação adicional de identidade", ErrForbidden)
+	ErrTransactionBlocked    = fmt.Errorf("%w: transação bloqueada pela análise de fraude", ErrForbidden)
+	ErrFraudDecisionNotFound = fmt.Errorf("%w: decisão de fraude", ErrNotFound)
+	ErrFraudDecisionReviewed = fmt.Errorf("%w: decisão não está pendente de análise", ErrConflict)
+)
+
+// Regras avaliadas pelo motor de fraude
+const (
+	FraudRuleVelocity        = "VELOCITY"
+	FraudRuleAmountHistory   = "AMOUNT_VS_HISTORY"
+	FraudRuleNewDestination  = "NEW_DESTINATION"
+	FraudRuleNewDevice       = "NEW_DEVICE"
+	FraudRuleNightHighValue  = "NIGHT_HIGH_VALUE"
+	FraudRulePasswordChanged = "RECENT_PASSWORD_CHANGE"
+)
+
+type deviceFingerprintKey struct{}
+type stepUpVerifiedKey struct{}
+
+// WithDeviceFingerprint marca no contexto o fingerprint do dispositivo que
+// originou a requisição
+func WithDeviceFingerprint(ctx context.Context, fingerprint string) context.Context {
+	return context.WithValue(ctx, deviceFingerprintKey{}, fingerprint)
+}
+
+func deviceFingerprint(ctx context.Context) string {
+	fingerprint, _ := ctx.Value(deviceFingerprintKey{}).(string)
+	return fingerprint
+}
+
+// WithStepUpVerified marca no contexto que o usuário concluiu a verificação
+// adicional de identidade para esta requisição
+func WithStepUpVerified(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stepUpVerifiedKey{}, true)
+}
+
+func stepUpVerified(ctx context.Context) bool {
+	verified, _ := ctx.Value(stepUpVerifiedKey{}).(bool)
+	return verified
+}
+
+type FraudService interface {
+	// Evaluate pontua a transação antes da conclusão. Deve ser registrado com
+	// MakeTransactionService.AddGuard.
+	Evaluate(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error
+
+	ListDecisions(ctx context.Context, req *dtos.FraudDecisionListRequestDTO) (*dtos.FraudDecisionListDTO, error)
+	GetDecision(ctx context.Context, decisionID string) (*dtos.FraudDecisionDTO, error)
+
+	// Approve libera a transação retida pela decisão
+	Approve(ctx context.Context, reviewerUserID, decisionID string, req *dtos.FraudReviewDTO) (*dtos.FraudDecisionDTO, error)
+
+	// Reject cancela a transação retida pela decisão
+	Reject(ctx context.Context, reviewerUserID, decisionID string, req *dtos.FraudReviewDTO) (*dtos.FraudDecisionDTO, error)
+}
+
+// FraudConfig contém os pesos das regras e os limites de decisão
+type FraudConfig struct {
+	// Mais de VelocityMaxCount transações do cliente em VelocityWindow
+	VelocityWindow   time.Duration
+	VelocityMaxCount int
+	VelocityScore    int
+
+	// Valor comparado à média das transações concluídas em HistoryWindow.
+	// Com menos de HistoryMinCount transações, vale NoHistoryAmount.
+	HistoryWindow     time.Duration
+	HistoryMinCount   int
+	HighRatio         float64
+	HighRatioScore    int
+	ExtremeRatio      float64
+	ExtremeRatioScore int
+	NoHistoryAmount   float64
+	NoHistoryScore    int
+
+	NewDestinationScore int
+	NewDeviceScore      int
+
+	// Horário noturno no fuso do cliente: [NightStartHour, NightEndHour)
+	NightStartHour int
+	NightEndHour   int
+	NightAmount    float64
+	NightScore     int
+
+	PasswordChangeWindow time.Duration
+	PasswordChangeScore  int
+
+	// Pontuação mínima de cada ação
+	StepUpThreshold int
+	HoldThreshold   int
+	BlockThreshold  int
+
+	// StepUpEnabled indica que a aplicação oferece como concluir a verificação
+	// adicional (POST /2fa/step-up). Sem isso STEP_UP vira HOLD, para que a
+	// transação não fique bloqueada sem saída para o cliente.
+	StepUpEnabled bool
+}
+
+type fraudService struct {
+	db           *gorm.DB
+	transactions MakeTransactionService
+	config       FraudConfig
+}
+
+func NewFraudService(db *gorm.DB, transactions MakeTransactionService, cfg *FraudConfig) FraudService {
+	if cfg == nil {
+		cfg = &FraudConfig{}
+	}
+	if cfg.VelocityWindow == 0 {
+		cfg.VelocityWindow = config.GetEnvDuration("FRAUD_VELOCITY_WINDOW", 10*time.Minute)
+	}
+	if cfg.VelocityMaxCount == 0 {
+		cfg.VelocityMaxCount = config.GetEnvInt("FRAUD_VELOCITY_MAX_COUNT", 5)
+	}
+	if cfg.VelocityScore == 0 {
+		cfg.VelocityScore = config.GetEnvInt("FRAUD_VELOCITY_SCORE", 30)
+	}
+	if cfg.HistoryWindow == 0 {
+		cfg.HistoryWindow = config.GetEnvDuration("FRAUD_HISTORY_WINDOW", 90*24*time.Hour)
+	}
+	if cfg.HistoryMinCount == 0 {
+		cfg.HistoryMinCount = config.GetEnvInt("FRAUD_HISTORY_MIN_COUNT", 5)
+	}
+	if cfg.HighRatio == 0 {
+		cfg.HighRatio = config.GetEnvFloat("FRAUD_HIGH_RATIO", 5)
+	}
+	if cfg.HighRatioScore == 0 {
+		cfg.HighRatioScore = config.GetEnvInt("FRAUD_HIGH_RATIO_SCORE", 25)
+	}
+	if cfg.ExtremeRatio == 0 {
+		cfg.ExtremeRatio = config.GetEnvFloat("FRAUD_EXTREME_RATIO", 10)
+	}
+	if cfg.ExtremeRatioScore == 0 {
+		cfg.ExtremeRatioScore = config.GetEnvInt("FRAUD_EXTREME_RATIO_SCORE", 40)
+	}
+	if cfg.NoHistoryAmount == 0 {
+		cfg.NoHistoryAmount = config.GetEnvFloat("FRAUD_NO_HISTORY_AMOUNT", 5000)
+	}
+	if cfg.NoHistoryScore == 0 {
+		cfg.NoHistoryScore = config.GetEnvInt("FRAUD_NO_HISTORY_SCORE", 20)
+	}
+	if cfg.NewDestinationScore == 0 {
+		cfg.NewDestinationScore = config.GetEnvInt("FRAUD_NEW_DESTINATION_SCORE", 15)
+	}
+	if cfg.NewDeviceScore == 0 {
+		cfg.NewDeviceScore = config.GetEnvInt("FRAUD_NEW_DEVICE_SCORE", 20)
+	}
+	if cfg.NightStartHour == 0 && cfg.NightEndHour == 0 {
+		cfg.NightStartHour = config.GetEnvInt("FRAUD_NIGHT_START_HOUR", 0)
+		cfg.NightEndHour = config.GetEnvInt("FRAUD_NIGHT_END_HOUR", 6)
+	}
+	if cfg.NightAmount == 0 {
+		cfg.NightAmount = config.GetEnvFloat("FRAUD_NIGHT_AMOUNT", 1000)
+	}
+	if cfg.NightScore == 0 {
+		cfg.NightScore = config.GetEnvInt("FRAUD_NIGHT_SCORE", 20)
+	}
+	if cfg.PasswordChangeWindow == 0 {
+		cfg.PasswordChangeWindow = config.GetEnvDuration("FRAUD_PASSWORD_CHANGE_WINDOW", 24*time.Hour)
+	}
+	if cfg.PasswordChangeScore == 0 {
+		cfg.PasswordChangeScore = config.GetEnvInt("FRAUD_PASSWORD_CHANGE_SCORE", 25)
+	}
+	if cfg.StepUpThreshold == 0 {
+		cfg.StepUpThreshold = config.GetEnvInt("FRAUD_STEP_UP_THRESHOLD", 40)
+	}
+	if cfg.HoldThreshold == 0 {
+		cfg.HoldThreshold = config.GetEnvInt("FRAUD_HOLD_THRESHOLD", 60)
+	}
+	if cfg.BlockThreshold == 0 {
+		cfg.BlockThreshold = config.GetEnvInt("FRAUD_BLOCK_THRESHOLD", 80)
+	}
+
+	return &fraudService{
+		db:           db,
+		transactions: transactions,
+		config:       *cfg,
+	}
+}
+
+// fraudRuleHit é o formato gravado em FraudDecision.RulesFired
+type fraudRuleHit struct {
+	Rule   string `json:"rule"`
+	Score  int    `json:"score"`
+	Detail string `json:"detail"`
+}
+
+func (s *fraudService) Evaluate(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error {
+	// a liberação de uma transação retida já é o resultado da análise
+	if releasingHeld(ctx) {
+		return nil
+	}
+
+	var origin models.Account
+	if err := tx.Preload("Customer").First(&origin, "account_id = ?", transaction.AccountIDOrigin).Error; err != nil {
+		return err
+	}
+
+	hits, err := s.runRules(tx, transaction, &origin, deviceFingerprint(ctx))
+	if err != nil {
+		return err
+	}
+
+	score := 0
+	for _, hit := range hits {
+		score += hit.Score
+	}
+	action := s.action(score)
+
+	// lotes não têm como concluir a verificação adicional, nem a API quando ela
+	// não está habilitada; a transação fica retida para análise
+	if action == models.FraudActionStepUp && (!s.config.StepUpEnabled || transactionSource(ctx) != "API") {
+		action = models.FraudActionHold
+	}
+
+	rules, err := json.Marshal(hits)
+	if err != nil {
+		return err
+	}
+	decision := &models.FraudDecision{
+		TransactionID:     nullString(transaction.TransactionID),
+		IdempotencyKey:    transaction.IdempotencyKey,
+		CustomerID:        origin.CustomerID,
+		UserID:            transaction.CreatedByUserID,
+		AccountIDOrigin:   transaction.AccountIDOrigin,
+		AccountIDDest:     transaction.AccountIDDest,
+		Amount:            transaction.TransactionAmount,
+		Score:             score,
+		Action:            action,
+		RulesFired:        datatypes.JSON(rules),
+		DeviceFingerprint: nullString(truncate(deviceFingerprint(ctx), 255)),
+	}
+
+	switch action {
+	case models.FraudActionAllow:
+		return tx.Create(decision).Error
+
+	case models.FraudActionStepUp:
+		if stepUpVerified(ctx) {
+			decision.StepUpVerified = true
+			return tx.Create(decision).Error
+		}
+		decision.TransactionID = sql.NullString{}
+		if err := outsideGuardTx(ctx, s.db).Create(decision).Error; err != nil {
+			return err
+		}
+		return ErrStepUpRequired
+
+	case models.FraudActionHold:
+		decision.ReviewStatus = nullString(models.FraudReviewPending)
+		if err := tx.Create(decision).Error; err != nil {
+			return err
+		}
+		return ErrHoldForReview
+
+	default:
+		decision.TransactionID = sql.NullString{}
+		if err := outsideGuardTx(ctx, s.db).Create(decision).Error; err != nil {
+			return err
+		}
+		return ErrTransactionBlocked
+	}
+}
+
+func (s *fraudService) action(score int) string {
+	switch {
+	case score >= s.config.BlockThreshold:
+		return models.FraudActionBlock
+	case score >= s.config.HoldThreshold:
+		return models.FraudActionHold
+	case score >= s.config.StepUpThreshold:
+		return models.FraudActionStepUp
+	default:
+		return models.FraudActionAllow
+	}
+}
+
+// runRules executa as regras usando tx, que enxerga a transação recém-criada.
+// As consultas de histórico a excluem pelo id.
+func (s *fraudService) runRules(tx *gorm.DB, transaction *models.Transaction, origin *models.Account, fingerprint string) ([]fraudRuleHit, error) {
+	hits := []fraudRuleHit{}
+	now := time.Now()
+	amount := transaction.TransactionAmount
+
+	customerAccounts := tx.Model(&models.Account{}).Select("account_id").Where("customer_id = ?", origin.CustomerID)
+	customerTransactions := func() *gorm.DB {
+		return tx.Model(&models.Transaction{}).
+			Where("account_id_origin IN (?)", customerAccounts).
+			Where("transaction_id <> ?", transaction.TransactionID)
+	}
+
+	var recent int64
+	if err := customerTransactions().
+		Where("transaction_date >= ?", now.Add(-s.config.VelocityWindow)).
+		Where("transaction_status NOT IN ?", []string{models.TransactionStatusFailed, models.TransactionStatusCancelled}).
+		Count(&recent).Error; err != nil {
+		return nil, err
+	}
+	if int(recent) >= s.config.VelocityMaxCount {
+		hits = append(hits, fraudRuleHit{
+			Rule:   FraudRuleVelocity,
+			Score:  s.config.VelocityScore,
+			Detail: fmt.Sprintf("%d transações nos últimos %s", recent+1, s.config.VelocityWindow),
+		})
+	}
+
+	var history struct {
+		Count   int64
+		Average float64
+	}
+	if err := customerTransactions().
+		Select("COUNT(*) AS count, COALESCE(AVG(transaction_amount), 0) AS average").
+		Where("transaction_status = ? AND transaction_date >= ?", models.TransactionStatusCompleted, now.Add(-s.config.HistoryWindow)).
+		Scan(&history).Error; err != nil {
+		return nil, err
+	}
+	switch {
+	case int(history.Count) < s.config.HistoryMinCount:
+		if amount >= s.config.NoHistoryAmount {
+			hits = append(hits, fraudRuleHit{
+				Rule:   FraudRuleAmountHistory,
+				Score:  s.config.NoHistoryScore,
+				Detail: fmt.Sprintf("valor %.2f com apenas %d transações no histórico", amount, history.Count),
+			})
+		}
+	case history.Average > 0:
+		ratio := amount / history.Average
+		if ratio >= s.config.ExtremeRatio {
+			hits = append(hits, fraudRuleHit{
+				Rule:   FraudRuleAmountHistory,
+				Score:  s.config.ExtremeRatioScore,
+				Detail: fmt.Sprintf("valor %.1fx a média de %.2f", ratio, history.Average),
+			})
+		} else if ratio >= s.config.HighRatio {
+			hits = append(hits, fraudRuleHit{
+				Rule:   FraudRuleAmountHistory,
+				Score:  s.config.HighRatioScore,
+				Detail: fmt.Sprintf("valor %.1fx a média de %.2f", ratio, history.Average),
+			})
+		}
+	}
+
+	if transaction.AccountIDDest.Valid {
+		var ownDest int64
+		if err := tx.Model(&models.Account{}).
+			Where("account_id = ? AND customer_id = ?", transaction.AccountIDDest.String, origin.CustomerID).
+			Count(&ownDest).Error; err != nil {
+			return nil, err
+		}
+		if ownDest == 0 {
+			var previous int64
+			if err := customerTransactions().
+				Where("account_id_dest = ? AND transaction_status = ?", transaction.AccountIDDest.String, models.TransactionStatusCompleted).
+				Limit(1).Count(&previous).Error; err != nil {
+				return nil, err
+			}
+			if previous == 0 {
+				hits = append(hits, fraudRuleHit{
+					Rule:   FraudRuleNewDestination,
+					Score:  s.config.NewDestinationScore,
+					Detail: "primeira transferência para a conta de destino",
+				})
+			}
+		}
+	}
+
+	if fingerprint != "" {
+		known, err := s.knownDevice(tx, transaction.CreatedByUserID, origin.CustomerID, fingerprint)
+		if err != nil {
+			return nil, err
+		}
+		if !known {
+			hits = append(hits, fraudRuleHit{
+				Rule:   FraudRuleNewDevice,
+				Score:  s.config.NewDeviceScore,
+				Detail: "dispositivo sem histórico para o usuário",
+			})
+		}
+	}
+
+	local := now.In(customerLocation(origin.Customer))
+	if inHourRange(local.Hour(), s.config.NightStartHour, s.config.NightEndHour) && amount >= s.config.NightAmount {
+		hits = append(hits, fraudRuleHit{
+			Rule:   FraudRuleNightHighValue,
+			Score:  s.config.NightScore,
+			Detail: fmt.Sprintf("valor %.2f às %s no horário do cliente", amount, local.Format("15:04")),
+		})
+	}
+
+	if transaction.CreatedByUserID.Valid {
+		var user models.User
+		err := tx.Select("user_id", "password_changed_at").First(&user, "user_id = ?", transaction.CreatedByUserID.String).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if user.PasswordChangedAt.Valid && now.Sub(user.PasswordChangedAt.Time) < s.config.PasswordChangeWindow {
+			hits = append(hits, fraudRuleHit{
+				Rule:   FraudRulePasswordChanged,
+				Score:  s.config.PasswordChangeScore,
+				Detail: fmt.Sprintf("senha alterada em %s", user.PasswordChangedAt.Time.Format(time.RFC3339)),
+			})
+		}
+	}
+
+	return hits, nil
+}
+
+// knownDevice considera conhecido o dispositivo já usado em um login bem
+// sucedido do usuário ou em uma transação liberada do cliente
+func (s *fraudService) knownDevice(tx *gorm.DB, userID sql.NullString, customerID, fingerprint string) (bool, error) {
+	var count int64
+	if userID.Valid {
+		if err := tx.Model(&models.UserAuthLog{}).
+			Where("user_id = ? AND device_fingerprint = ? AND success = ?", userID.String, fingerprint, true).
+			Limit(1).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	err := tx.Model(&models.FraudDecision{}).
+		Where("customer_id = ? AND device_fingerprint = ?", customerID, fingerprint).
+		Where("action IN ? OR review_status = ?", []string{models.FraudActionAllow, models.FraudActionStepUp}, models.FraudReviewApproved).
+		Where("action <> ? OR step_up_verified = ?", models.FraudActionStepUp, true).
+		Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+// inHourRange indica se hour está em [start, end), aceitando faixas que
+// atravessam a meia-noite (ex.: 22 a 6)
+func inHourRange(hour, start, end int) bool {
+	if start <= end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+func (s *fraudService) ListDecisions(ctx context.Context, req *dtos.FraudDecisionListRequestDTO) (*dtos.FraudDecisionListDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.FraudDecision{})
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+	if req.ReviewStatus != "" {
+		query = query.Where("review_status = ?", req.ReviewStatus)
+	}
+	if req.CustomerID != "" {
+		query = query.Where("customer_id = ?", req.CustomerID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var decisions []models.FraudDecision
+	if err := query.Order("created_at DESC").Limit(limit).Offset(req.Offset).Find(&decisions).Error; err != nil {
+		return nil, err
+	}
+
+	response := &dtos.FraudDecisionListDTO{
+		Decisions:  make([]dtos.FraudDecisionDTO, 0, len(decisions)),
+		TotalCount: int(total),
+		Limit:      limit,
+		Offset:     req.Offset,
+	}
+	for i := range decisions {
+		response.Decisions = append(response.Decisions, fraudDecisionDTO(&decisions[i]))
+	}
+	return response, nil
+}
+
+func (s *fraudService) GetDecision(ctx context.Context, decisionID string) (*dtos.FraudDecisionDTO, error) {
+	var decision models.FraudDecision
+	if err := s.db.WithContext(ctx).First(&decision, "decision_id = ?", decisionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFraudDecisionNotFound
+		}
+		return nil, err
+	}
+	response := fraudDecisionDTO(&decision)
+	return &response, nil
+}
+
+func (s *fraudService) Approve(ctx context.Context, reviewerUserID, decisionID string, req *dtos.FraudReviewDTO) (*dtos.FraudDecisionDTO, error) {
+	return s.review(ctx, reviewerUserID, decisionID, req, models.FraudReviewApproved)
+}
+
+func (s *fraudService) Reject(ctx context.Context, reviewerUserID, decisionID string, req *dtos.FraudReviewDTO) (*dtos.FraudDecisionDTO, error) {
+	return s.review(ctx, reviewerUserID, decisionID, req, models.FraudReviewRejected)
+}
+
+func (s *fraudService) review(ctx context.Context, reviewerUserID, decisionID string, req *dtos.FraudReviewDTO, status string) (*dtos.FraudDecisionDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	var decision models.FraudDecision
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&decision, "decision_id = ?", decisionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrFraudDecisionNotFound
+			}
+			return err
+		}
+		if decision.ReviewStatus.String != models.FraudReviewPending || !decision.TransactionID.Valid {
+			return ErrFraudDecisionReviewed
+		}
+
+		// a decisão fica bloqueada enquanto a transação é liberada ou
+		// cancelada, então duas análises simultâneas não se sobrepõem; ambas
+		// são gravadas na mesma transação de banco
+		if status == models.FraudReviewApproved {
+			if _, err := s.transactions.ReleaseHeldTx(ctx, tx, decision.TransactionID.String); err != nil {
+				return err
+			}
+		} else if err := s.transactions.RejectHeldTx(ctx, tx, decision.TransactionID.String, "recusada na análise de fraude"); err != nil {
+			return err
+		}
+
+		decision.ReviewStatus = nullString(status)
+		decision.ReviewedByUserID = nullString(reviewerUserID)
+		decision.ReviewedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		decision.ReviewNote = nullString(truncate(req.Note, 500))
+		return tx.Model(&decision).Updates(map[string]interface{}{
+			"review_status":       decision.ReviewStatus,
+			"reviewed_by_user_id": decision.ReviewedByUserID,
+			"reviewed_at":         decision.ReviewedAt,
+			"review_note":         decision.ReviewNote,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := fraudDecisionDTO(&decision)
+	return &response, nil
+}
+
+func fraudDecisionDTO(d *models.FraudDecision) dtos.FraudDecisionDTO {
+	var hits []fraudRuleHit
+	_ = json.Unmarshal(d.RulesFired, &hits)
+
+	rules := make([]dtos.FraudRuleHitDTO, 0, len(hits))
+	for _, hit := range hits {
+		rules = append(rules, dtos.FraudRuleHitDTO{Rule: hit.Rule, Score: hit.Score, Detail: hit.Detail})
+	}
+
+	return dtos.FraudDecisionDTO{
+		DecisionID:        d.DecisionID,
+		TransactionID:     d.TransactionID.String,
+		IdempotencyKey:    d.IdempotencyKey,
+		CustomerID:        d.CustomerID,
+		UserID:            d.UserID.String,
+		AccountIDOrigin:   d.AccountIDOrigin,
+		AccountIDDest:     d.AccountIDDest.String,
+		Amount:            d.Amount,
+		Score:             d.Score,
+		Action:            d.Action,
+		RulesFired:        rules,
+		DeviceFingerprint: d.DeviceFingerprint.String,
+		StepUpVerified:    d.StepUpVerified,
+		ReviewStatus:      d.ReviewStatus.String,
+		ReviewedByUserID:  d.ReviewedByUserID.String,
+		ReviewedAt:        nullTimePtr(d.ReviewedAt),
+		ReviewNote:        d.ReviewNote.String,
+		CreatedAt:         d.CreatedAt,
+	}
+}
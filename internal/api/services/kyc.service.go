@@ -0,0 +1,771 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"github.com/victor-lima-142/oak-bank/pkg/taxid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrKYCSubmissionNotFound = fmt.Errorf("%w: envio de KYC", ErrNotFound)
+	ErrKYCSubmissionPending  = fmt.Errorf("%w: já existe um envio de KYC em análise", ErrConflict)
+	ErrKYCSubmissionDecided  = fmt.Errorf("%w: envio de KYC já decidido", ErrConflict)
+	ErrKYCAlreadyApproved    = fmt.Errorf("%w: cliente já aprovado no KYC", ErrConflict)
+	ErrKYCRejected           = fmt.Errorf("%w: cadastro reprovado no KYC", ErrForbidden)
+	ErrKYCChecksFailed       = fmt.Errorf("%w: verificações automáticas do KYC não aprovadas", ErrConflict)
+	ErrKYCAssigneeInvalid    = fmt.Errorf("%w: responsável não encontrado", ErrInvalidInput)
+	ErrKYCDocumentInvalid    = fmt.Errorf("%w: documento não encontrado entre os arquivos do cliente", ErrInvalidInput)
+	ErrKYCLimitExceeded      = fmt.Errorf("%w: limite de movimentação para cadastro não verificado excedido", ErrForbidden)
+)
+
+// KYCStatusHook é executado dentro da transação que grava a mudança de
+// Customer.KYCStatus. Retornar erro desfaz a mudança.
+type KYCStatusHook func(ctx context.Context, tx *gorm.DB, customer *models.Customer, previousStatus string) error
+
+type KYCService interface {
+	// Submit registra os dados e documentos do cliente do usuário, executa as
+	// verificações automáticas e coloca o envio na fila de análise
+	Submit(ctx context.Context, userID string, req *dtos.SubmitKYCDTO) (*dtos.KYCSubmissionDTO, error)
+
+	// Status retorna a situação de KYC do cliente do usuário
+	Status(ctx context.Context, userID string) (*dtos.KYCStatusDTO, error)
+
+	ListSubmissions(ctx context.Context, req *dtos.KYCSubmissionListRequestDTO) (*dtos.KYCSubmissionListDTO, error)
+	GetSubmission(ctx context.Context, submissionID string) (*dtos.KYCSubmissionDTO, error)
+	Assign(ctx context.Context, actorUserID, submissionID string, req *dtos.AssignKYCSubmissionDTO) (*dtos.KYCSubmissionDTO, error)
+
+	// RunChecks executa novamente as verificações automáticas de um envio pendente
+	RunChecks(ctx context.Context, submissionID string) (*dtos.KYCSubmissionDTO, error)
+
+	// Decide grava a decisão do analista e altera Customer.KYCStatus
+	Decide(ctx context.Context, actorUserID, submissionID string, req *dtos.KYCDecisionDTO) (*dtos.KYCSubmissionDTO, error)
+
+	// StatusHistory lista as mudanças de status de KYC do cliente, da mais
+	// recente para a mais antiga
+	StatusHistory(ctx context.Context, customerID string) ([]dtos.KYCStatusHistoryDTO, error)
+
+	// GuardLimits restringe a movimentação de clientes não aprovados. Deve ser
+	// registrado com MakeTransactionService.AddGuard.
+	GuardLimits(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error
+
+	// AddStatusListener registra um hook executado após cada mudança de status
+	AddStatusListener(hook KYCStatusHook)
+}
+
+// KYCLimits são os limites de movimentação aplicados enquanto o cliente não
+// tem o KYC aprovado
+type KYCLimits struct {
+	MaxTransactionAmount float64
+	DailyLimit           float64
+	MonthlyLimit         float64
+}
+
+// KYCConfig contém as regras de verificação. Individual vale para PF e
+// Business para PJ.
+type KYCConfig struct {
+	MinAge int
+	// MinCompanyAge é o tempo mínimo desde a constituição da PJ; zero não
+	// restringe
+	MinCompanyAge time.Duration
+
+	Individual KYCLimits
+	Business   KYCLimits
+}
+
+type kycService struct {
+	db        *gorm.DB
+	screening ScreeningService
+	config    KYCConfig
+	listeners []KYCStatusHook
+}
+
+func NewKYCService(db *gorm.DB, screening ScreeningService, cfg *KYCConfig) KYCService {
+	if cfg == nil {
+		cfg = &KYCConfig{}
+	}
+	if cfg.MinAge == 0 {
+		cfg.MinAge = config.GetEnvInt("KYC_MIN_AGE", 18)
+	}
+	if cfg.MinCompanyAge == 0 {
+		cfg.MinCompanyAge = config.GetEnvDuration("KYC_MIN_COMPANY_AGE", 0)
+	}
+	cfg.Individual = kycLimitsFromEnv("KYC_UNVERIFIED_", cfg.Individual, KYCLimits{
+		MaxTransactionAmount: 1000,
+		DailyLimit:           2000,
+		MonthlyLimit:         5000,
+	})
+	cfg.Business = kycLimitsFromEnv("KYC_UNVERIFIED_PJ_", cfg.Business, KYCLimits{
+		MaxTransactionAmount: 5000,
+		DailyLimit:           10000,
+		MonthlyLimit:         30000,
+	})
+
+	return &kycService{
+		db:        db,
+		screening: screening,
+		config:    *cfg,
+	}
+}
+
+func kycLimitsFromEnv(prefix string, l, defaults KYCLimits) KYCLimits {
+	if l.MaxTransactionAmount == 0 {
+		l.MaxTransactionAmount = config.GetEnvFloat(prefix+"MAX_TRANSACTION", defaults.MaxTransactionAmount)
+	}
+	if l.DailyLimit == 0 {
+		l.DailyLimit = config.GetEnvFloat(prefix+"DAILY_LIMIT", defaults.DailyLimit)
+	}
+	if l.MonthlyLimit == 0 {
+		l.MonthlyLimit = config.GetEnvFloat(prefix+"MONTHLY_LIMIT", defaults.MonthlyLimit)
+	}
+	return l
+}
+
+func (s *kycService) limits(customer *models.Customer) KYCLimits {
+	if customer.CustomerType == models.CustomerTypeBusiness {
+		return s.config.Business
+	}
+	return s.config.Individual
+}
+
+func (s *kycService) AddStatusListener(hook KYCStatusHook) {
+	s.listeners = append(s.listeners, hook)
+}
+
+func (s *kycService) Submit(ctx context.Context, userID string, req *dtos.SubmitKYCDTO) (*dtos.KYCSubmissionDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"occupation":     req.Occupation,
+		"monthly_income": req.MonthlyIncome,
+		"nationality":    req.Nationality,
+	})
+	if err != nil {
+		return nil, err
+	}
+	documents, err := json.Marshal(req.Documents)
+	if err != nil {
+		return nil, err
+	}
+
+	var submission models.KYCSubmission
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var customer models.Customer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, "customer_id = ?", customerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCustomerNotFound
+			}
+			return err
+		}
+		switch customer.KYCStatus {
+		case models.KYCStatusApproved:
+			return ErrKYCAlreadyApproved
+		case models.KYCStatusRejected:
+			return ErrKYCRejected
+		}
+
+		var pending int64
+		if err := tx.Model(&models.KYCSubmission{}).
+			Where("customer_id = ? AND submission_status = ?", customerID, models.KYCStatusPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrKYCSubmissionPending
+		}
+
+		var documentIDs []string
+		for _, document := range req.Documents {
+			if document.DocumentID != "" {
+				documentIDs = append(documentIDs, document.DocumentID)
+			}
+		}
+		documentIDs = uniqueStrings(documentIDs)
+		if len(documentIDs) > 0 {
+			var found int64
+			if err := tx.Model(&models.CustomerDocument{}).
+				Where("customer_id = ? AND document_id IN ? AND purged_at IS NULL", customerID, documentIDs).
+				Count(&found).Error; err != nil {
+				return err
+			}
+			if int(found) != len(documentIDs) {
+				return ErrKYCDocumentInvalid
+			}
+		}
+
+		submission = models.KYCSubmission{
+			CustomerID:        customerID,
+			SubmittedByUserID: nullString(userID),
+			SubmissionStatus:  models.KYCStatusPending,
+			SubmittedData:     datatypes.JSON(data),
+			Documents:         datatypes.JSON(documents),
+		}
+		if err := tx.Create(&submission).Error; err != nil {
+			return err
+		}
+		if err := s.runChecks(ctx, tx, &customer, &submission); err != nil {
+			return err
+		}
+
+		// o reenvio após pedido de complemento volta o cliente para análise
+		if customer.KYCStatus != models.KYCStatusPending {
+			return s.changeStatus(ctx, tx, &customer, models.KYCStatusPending, submission.SubmissionID, "novo envio de KYC", userID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := kycSubmissionDTO(&submission)
+	return &response, nil
+}
+
+func (s *kycService) Status(ctx context.Context, userID string) (*dtos.KYCStatusDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var customer models.Customer
+	if err := s.db.WithContext(ctx).First(&customer, "customer_id = ?", customerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, err
+	}
+
+	history, err := s.StatusHistory(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.KYCStatusDTO{
+		CustomerID:    customer.CustomerID,
+		KYCStatus:     customer.KYCStatus,
+		KYCVerifiedAt: nullTimePtr(customer.KYCVerifiedAt),
+		History:       history,
+	}
+
+	var latest models.KYCSubmission
+	err = s.db.WithContext(ctx).Where("customer_id = ?", customerID).Order("submitted_at DESC").First(&latest).Error
+	if err == nil {
+		dto := kycSubmissionDTO(&latest)
+		response.LatestSubmission = &dto
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if customer.KYCStatus != models.KYCStatusApproved {
+		limits := s.limits(&customer)
+		response.Limits = &dtos.KYCLimitsDTO{
+			MaxTransactionAmount: limits.MaxTransactionAmount,
+			DailyLimit:           limits.DailyLimit,
+			MonthlyLimit:         limits.MonthlyLimit,
+		}
+	}
+	return response, nil
+}
+
+func (s *kycService) ListSubmissions(ctx context.Context, req *dtos.KYCSubmissionListRequestDTO) (*dtos.KYCSubmissionListDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.KYCSubmission{})
+	if req.Status != "" {
+		query = query.Where("submission_status = ?", req.Status)
+	}
+	if req.AssignedToUserID != "" {
+		query = query.Where("assigned_to_user_id = ?", req.AssignedToUserID)
+	} else if req.Unassigned {
+		query = query.Where("assigned_to_user_id IS NULL")
+	}
+	if req.CustomerID != "" {
+		query = query.Where("customer_id = ?", req.CustomerID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	// a fila é atendida por ordem de chegada
+	var submissions []models.KYCSubmission
+	if err := query.Order("submitted_at").Limit(limit).Offset(req.Offset).Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+
+	response := &dtos.KYCSubmissionListDTO{
+		Submissions: make([]dtos.KYCSubmissionDTO, 0, len(submissions)),
+		TotalCount:  int(total),
+		Limit:       limit,
+		Offset:      req.Offset,
+	}
+	for i := range submissions {
+		response.Submissions = append(response.Submissions, kycSubmissionDTO(&submissions[i]))
+	}
+	return response, nil
+}
+
+func (s *kycService) GetSubmission(ctx context.Context, submissionID string) (*dtos.KYCSubmissionDTO, error) {
+	var submission models.KYCSubmission
+	if err := s.db.WithContext(ctx).First(&submission, "submission_id = ?", submissionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKYCSubmissionNotFound
+		}
+		return nil, err
+	}
+	response := kycSubmissionDTO(&submission)
+	return &response, nil
+}
+
+func (s *kycService) Assign(ctx context.Context, actorUserID, submissionID string, req *dtos.AssignKYCSubmissionDTO) (*dtos.KYCSubmissionDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	assigneeID := req.AssignedToUserID
+	if assigneeID == "" {
+		assigneeID = actorUserID
+	}
+	var assignee int64
+	if err := s.db.WithContext(ctx).Model(&models.User{}).Where("user_id = ?", assigneeID).Count(&assignee).Error; err != nil {
+		return nil, err
+	}
+	if assignee == 0 {
+		return nil, ErrKYCAssigneeInvalid
+	}
+
+	return s.updatePending(ctx, submissionID, func(tx *gorm.DB, _ *models.Customer, submission *models.KYCSubmission) error {
+		submission.AssignedToUserID = nullString(assigneeID)
+		return tx.Model(submission).Update("assigned_to_user_id", submission.AssignedToUserID).Error
+	})
+}
+
+func (s *kycService) RunChecks(ctx context.Context, submissionID string) (*dtos.KYCSubmissionDTO, error) {
+	return s.updatePending(ctx, submissionID, func(tx *gorm.DB, customer *models.Customer, submission *models.KYCSubmission) error {
+		return s.runChecks(ctx, tx, customer, submission)
+	})
+}
+
+func (s *kycService) Decide(ctx context.Context, actorUserID, submissionID string, req *dtos.KYCDecisionDTO) (*dtos.KYCSubmissionDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	return s.updatePending(ctx, submissionID, func(tx *gorm.DB, customer *models.Customer, submission *models.KYCSubmission) error {
+		now := time.Now()
+
+		if req.Status == models.KYCStatusApproved {
+			// a aprovação exige as verificações atualizadas, inclusive a
+			// triagem, que pode ter mudado desde o envio
+			if err := s.runChecks(ctx, tx, customer, submission); err != nil {
+				return err
+			}
+			if !submission.ChecksPassed {
+				return ErrKYCChecksFailed
+			}
+
+			details, err := mergeDetails(customer.OtherDetails, submission.SubmittedData)
+			if err != nil {
+				return err
+			}
+			customer.OtherDetails = details
+			customer.KYCVerifiedAt = sql.NullTime{Time: now, Valid: true}
+			if err := tx.Model(customer).Updates(map[string]interface{}{
+				"other_details":   customer.OtherDetails,
+				"kyc_verified_at": customer.KYCVerifiedAt,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		submission.SubmissionStatus = req.Status
+		submission.DecidedByUserID = nullString(actorUserID)
+		submission.DecidedAt = sql.NullTime{Time: now, Valid: true}
+		submission.DecisionReason = nullString(req.Reason)
+		if err := tx.Model(submission).Updates(map[string]interface{}{
+			"submission_status":  submission.SubmissionStatus,
+			"decided_by_user_id": submission.DecidedByUserID,
+			"decided_at":         submission.DecidedAt,
+			"decision_reason":    submission.DecisionReason,
+		}).Error; err != nil {
+			return err
+		}
+
+		if customer.KYCStatus == req.Status {
+			return nil
+		}
+		return s.changeStatus(ctx, tx, customer, req.Status, submission.SubmissionID, req.Reason, actorUserID)
+	})
+}
+
+// updatePending bloqueia o cliente e o envio, recusa envios já decididos e aplica update
+func (s *kycService) updatePending(ctx context.Context, submissionID string, update func(tx *gorm.DB, customer *models.Customer, submission *models.KYCSubmission) error) (*dtos.KYCSubmissionDTO, error) {
+	var submission models.KYCSubmission
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&submission, "submission_id = ?", submissionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrKYCSubmissionNotFound
+			}
+			return err
+		}
+
+		// cliente antes do envio, na mesma ordem de Submit
+		var customer models.Customer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, "customer_id = ?", submission.CustomerID).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&submission, "submission_id = ?", submissionID).Error; err != nil {
+			return err
+		}
+		if submission.SubmissionStatus != models.KYCStatusPending {
+			return ErrKYCSubmissionDecided
+		}
+		return update(tx, &customer, &submission)
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := kycSubmissionDTO(&submission)
+	return &response, nil
+}
+
+// runChecks executa as verificações automáticas e grava o resultado no envio.
+// PF confere CPF e idade; PJ confere CNPJ, dados de constituição e
+// representantes legais. A triagem em listas restritivas vale para ambos.
+func (s *kycService) runChecks(ctx context.Context, tx *gorm.DB, customer *models.Customer, submission *models.KYCSubmission) error {
+	now := time.Now()
+
+	var results []dtos.KYCCheckResultDTO
+	if customer.CustomerType == models.CustomerTypeBusiness {
+		taxID := dtos.KYCCheckResultDTO{Check: models.KYCCheckTaxID, Passed: taxid.ValidCNPJ(customer.TaxID)}
+		if !taxID.Passed {
+			taxID.Detail = "CNPJ inválido"
+		}
+		company, representatives, err := s.checkCompany(tx, customer, now)
+		if err != nil {
+			return err
+		}
+		results = append(results, taxID, company, representatives)
+	} else {
+		taxID := dtos.KYCCheckResultDTO{Check: models.KYCCheckTaxID, Passed: taxid.ValidCPF(customer.TaxID)}
+		if !taxID.Passed {
+			taxID.Detail = "CPF inválido"
+		}
+		results = append(results, taxID, s.checkAge(customer, now))
+	}
+
+	sanctions := dtos.KYCCheckResultDTO{Check: models.KYCCheckSanctions}
+	if s.screening == nil {
+		sanctions.Passed = true
+		sanctions.Detail = "triagem não configurada"
+	} else {
+		result, err := s.screening.ScreenCustomer(ctx, tx, customer.CustomerID, models.ScreeningContextOnboarding)
+		if err != nil {
+			return err
+		}
+		sanctions.Passed = result.Status == ScreeningStatusClear
+		switch result.Status {
+		case ScreeningStatusPending:
+			sanctions.Detail = "possível correspondência em lista restritiva aguardando análise"
+		case ScreeningStatusConfirmed:
+			sanctions.Detail = "correspondência confirmada em lista restritiva"
+		}
+	}
+	results = append(results, sanctions)
+
+	passed := true
+	for _, result := range results {
+		passed = passed && result.Passed
+	}
+	encoded, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+
+	submission.CheckResults = datatypes.JSON(encoded)
+	submission.ChecksPassed = passed
+	submission.ChecksRunAt = sql.NullTime{Time: now, Valid: true}
+	return tx.Model(submission).Updates(map[string]interface{}{
+		"check_results": submission.CheckResults,
+		"checks_passed": submission.ChecksPassed,
+		"checks_run_at": submission.ChecksRunAt,
+	}).Error
+}
+
+// checkAge calcula a idade na data local do cliente
+func (s *kycService) checkAge(customer *models.Customer, now time.Time) dtos.KYCCheckResultDTO {
+	if !customer.DateOfBirth.Valid {
+		return dtos.KYCCheckResultDTO{Check: models.KYCCheckAge, Detail: "data de nascimento não informada"}
+	}
+
+	age := ageOn(customer.DateOfBirth.Time, now, customerLocation(customer))
+	result := dtos.KYCCheckResultDTO{Check: models.KYCCheckAge, Passed: age >= s.config.MinAge}
+	if !result.Passed {
+		result.Detail = fmt.Sprintf("idade de %d anos abaixo do mínimo de %d", age, s.config.MinAge)
+	}
+	return result
+}
+
+// checkCompany confere a constituição da PJ e os representantes legais: ao
+// menos um administrador ou procurador, todos com CPF válido e maiores de idade
+func (s *kycService) checkCompany(tx *gorm.DB, customer *models.Customer, now time.Time) (dtos.KYCCheckResultDTO, dtos.KYCCheckResultDTO, error) {
+	company := dtos.KYCCheckResultDTO{Check: models.KYCCheckCompany}
+	representatives := dtos.KYCCheckResultDTO{Check: models.KYCCheckRepresentatives}
+
+	var record models.CustomerCompany
+	err := tx.First(&record, "customer_id = ?", customer.CustomerID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		company.Detail = "dados da empresa não informados"
+	case err != nil:
+		return company, representatives, err
+	case record.IncorporationDate.After(now):
+		company.Detail = "data de constituição no futuro"
+	case now.Sub(record.IncorporationDate) < s.config.MinCompanyAge:
+		company.Detail = fmt.Sprintf("empresa constituída há menos de %s", s.config.MinCompanyAge)
+	default:
+		company.Passed = true
+	}
+
+	var people []models.CustomerLegalRepresentative
+	if err := tx.Where("customer_id = ?", customer.CustomerID).Order("created_at").Find(&people).Error; err != nil {
+		return company, representatives, err
+	}
+
+	loc := customerLocation(customer)
+	signatory := false
+	var problems []string
+	for _, person := range people {
+		if person.Role == models.LegalRepresentativeAdministrator || person.Role == models.LegalRepresentativeAttorney {
+			signatory = true
+		}
+		if !taxid.ValidCPF(person.TaxID) {
+			problems = append(problems, person.RepresentativeName+": CPF inválido")
+		}
+		if age := ageOn(person.DateOfBirth, now, loc); age < s.config.MinAge {
+			problems = append(problems, fmt.Sprintf("%s: idade de %d anos abaixo do mínimo de %d", person.RepresentativeName, age, s.config.MinAge))
+		}
+	}
+	switch {
+	case len(people) == 0:
+		representatives.Detail = "nenhum representante legal informado"
+	case !signatory:
+		representatives.Detail = "nenhum administrador ou procurador entre os representantes"
+	case len(problems) > 0:
+		representatives.Detail = truncate(strings.Join(problems, "; "), 500)
+	default:
+		representatives.Passed = true
+	}
+	return company, representatives, nil
+}
+
+// ageOn calcula a idade em anos completos na data local. A data de nascimento
+// é uma data de calendário e não é convertida de fuso.
+func ageOn(birth, now time.Time, loc *time.Location) int {
+	today := now.In(loc)
+	year, month, day := birth.Date()
+	age := today.Year() - year
+	if today.Month() < month || (today.Month() == month && today.Day() < day) {
+		age--
+	}
+	return age
+}
+
+// changeStatus altera Customer.KYCStatus, grava o histórico e executa os listeners
+func (s *kycService) changeStatus(ctx context.Context, tx *gorm.DB, customer *models.Customer, status, submissionID, reason, actorUserID string) error {
+	previous := customer.KYCStatus
+	customer.KYCStatus = status
+	if err := tx.Model(customer).Update("kyc_status", customer.KYCStatus).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Create(&models.KYCStatusHistory{
+		CustomerID:      customer.CustomerID,
+		SubmissionID:    nullString(submissionID),
+		PreviousStatus:  previous,
+		NewStatus:       status,
+		Reason:          nullString(truncate(reason, 1000)),
+		ChangedByUserID: nullString(actorUserID),
+	}).Error; err != nil {
+		return err
+	}
+
+	for _, listener := range s.listeners {
+		if err := listener(ctx, tx, customer, previous); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *kycService) StatusHistory(ctx context.Context, customerID string) ([]dtos.KYCStatusHistoryDTO, error) {
+	var entries []models.KYCStatusHistory
+	if err := s.db.WithContext(ctx).
+		Where("customer_id = ?", customerID).
+		Order("changed_at DESC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.KYCStatusHistoryDTO, 0, len(entries))
+	for i := range entries {
+		response = append(response, kycStatusHistoryDTO(&entries[i]))
+	}
+	return response, nil
+}
+
+func (s *kycService) GuardLimits(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error {
+	var origin models.Account
+	if err := tx.Preload("Customer").First(&origin, "account_id = ?", transaction.AccountIDOrigin).Error; err != nil {
+		return err
+	}
+	if origin.Customer == nil || origin.Customer.KYCStatus == models.KYCStatusApproved {
+		return nil
+	}
+
+	limits := s.limits(origin.Customer)
+	if transaction.TransactionAmount > limits.MaxTransactionAmount {
+		return fmt.Errorf("%w: valor máximo por operação de %.2f", ErrKYCLimitExceeded, limits.MaxTransactionAmount)
+	}
+
+	var accountIDs []string
+	if err := tx.Model(&models.Account{}).Where("customer_id = ?", origin.CustomerID).Pluck("account_id", &accountIDs).Error; err != nil {
+		return err
+	}
+
+	loc := customerLocation(origin.Customer)
+	now := time.Now().In(loc)
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	monthStart, _ := monthBounds(now, loc)
+
+	for _, limit := range []struct {
+		since time.Time
+		max   float64
+		label string
+	}{
+		{dayStart, limits.DailyLimit, "diário"},
+		{monthStart, limits.MonthlyLimit, "mensal"},
+	} {
+		var used float64
+		if err := tx.Model(&models.Transaction{}).
+			Select("COALESCE(SUM(transaction_amount), 0)").
+			Where("account_id_origin IN ? AND transaction_id <> ?", accountIDs, transaction.TransactionID).
+			Where("transaction_status IN ?", []string{models.TransactionStatusPending, models.TransactionStatusOnHold, models.TransactionStatusCompleted}).
+			Where("transaction_date >= ?", limit.since).
+			Scan(&used).Error; err != nil {
+			return err
+		}
+		if used+transaction.TransactionAmount > limit.max {
+			return fmt.Errorf("%w: limite %s de %.2f", ErrKYCLimitExceeded, limit.label, limit.max)
+		}
+	}
+	return nil
+}
+
+// mergeDetails sobrepõe os dados verificados aos já existentes em OtherDetails
+func mergeDetails(current, verified datatypes.JSON) (datatypes.JSON, error) {
+	merged := map[string]any{}
+	if len(current) > 0 {
+		if err := json.Unmarshal(current, &merged); err != nil {
+			return nil, err
+		}
+	}
+	var data map[string]any
+	if len(verified) > 0 {
+		if err := json.Unmarshal(verified, &data); err != nil {
+			return nil, err
+		}
+	}
+	for k, v := range data {
+		if v != "" && v != nil {
+			merged[k] = v
+		}
+	}
+	encoded, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(encoded), nil
+}
+
+func kycSubmissionDTO(submission *models.KYCSubmission) dtos.KYCSubmissionDTO {
+	dto := dtos.KYCSubmissionDTO{
+		SubmissionID:      submission.SubmissionID,
+		CustomerID:        submission.CustomerID,
+		SubmissionStatus:  submission.SubmissionStatus,
+		Documents:         []dtos.KYCDocumentDTO{},
+		CheckResults:      []dtos.KYCCheckResultDTO{},
+		ChecksPassed:      submission.ChecksPassed,
+		ChecksRunAt:       nullTimePtr(submission.ChecksRunAt),
+		AssignedToUserID:  submission.AssignedToUserID.String,
+		DecidedByUserID:   submission.DecidedByUserID.String,
+		DecidedAt:         nullTimePtr(submission.DecidedAt),
+		DecisionReason:    submission.DecisionReason.String,
+		SubmittedAt:       submission.SubmittedAt,
+		SubmittedByUserID: submission.SubmittedByUserID.String,
+	}
+	if len(submission.SubmittedData) > 0 {
+		var data map[string]any
+		if json.Unmarshal(submission.SubmittedData, &data) == nil {
+			dto.SubmittedData = data
+		}
+	}
+	if len(submission.Documents) > 0 {
+		_ = json.Unmarshal(submission.Documents, &dto.Documents)
+	}
+	if len(submission.CheckResults) > 0 {
+		_ = json.Unmarshal(submission.CheckResults, &dto.CheckResults)
+	}
+	return dto
+}
+
+func kycStatusHistoryDTO(entry *models.KYCStatusHistory) dtos.KYCStatusHistoryDTO {
+	return dtos.KYCStatusHistoryDTO{
+		HistoryID:       entry.HistoryID,
+		SubmissionID:    entry.SubmissionID.String,
+		PreviousStatus:  entry.PreviousStatus,
+		NewStatus:       entry.NewStatus,
+		Reason:          entry.Reason.String,
+		ChangedByUserID: entry.ChangedByUserID.String,
+		ChangedAt:       entry.ChangedAt,
+	}
+}
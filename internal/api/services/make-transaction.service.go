@@ -1 +1,530 @@
 package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAccountNotFound         = fmt.Errorf("%w: conta", ErrNotFound)
+	ErrTransactionTypeNotFound = fmt.Errorf("%w: tipo de transação", ErrNotFound)
+	ErrAccountNotOwned         = fmt.Errorf("%w: conta de origem não pertence ao cliente", ErrForbidden)
+	ErrAccountNotActive        = fmt.Errorf("%w: conta não está ativa", ErrConflict)
+	ErrInsufficientFunds       = fmt.Errorf("%w: saldo insuficiente", ErrConflict)
+	ErrDailyLimitExceeded      = fmt.Errorf("%w: limite diário do tipo de transação excedido", ErrConflict)
+	ErrIdempotencyKeyReused    = fmt.Errorf("%w: chave de idempotência já usada em outra transação", ErrConflict)
+	ErrSameAccount             = fmt.Errorf("%w: conta de origem e destino são iguais", ErrInvalidInput)
+	ErrDestinationRequired     = fmt.Errorf("%w: tipo de transação exige conta de destino", ErrInvalidInput)
+	ErrTransactionNotFound     = fmt.Errorf("%w: transação", ErrNotFound)
+	ErrTransactionNotHeld      = fmt.Errorf("%w: transação não está retida para análise", ErrConflict)
+
+	// ErrHoldForReview é retornado por um guard para reter a transação: ela é
+	// gravada como ON_HOLD, sem movimentar saldo, até ReleaseHeld ou RejectHeld
+	ErrHoldForReview = errors.New("transação retida para análise")
+)
+
+type transactionSourceKey struct{}
+
+// WithTransactionSource marca no contexto a origem (API, BATCH...) gravada em
+// Transaction.CreatedBySource
+func WithTransactionSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, transactionSourceKey{}, source)
+}
+
+type heldReleaseKey struct{}
+
+// withHeldRelease marca no contexto que os guards rodam na liberação de uma
+// transação retida, e não na criação
+func withHeldRelease(ctx context.Context) context.Context {
+	return context.WithValue(ctx, heldReleaseKey{}, true)
+}
+
+// releasingHeld indica que a transação já foi analisada e está sendo
+// liberada; guards que retêm para análise não devem retê-la de novo
+func releasingHeld(ctx context.Context) bool {
+	releasing, _ := ctx.Value(heldReleaseKey{}).(bool)
+	return releasing
+}
+
+func transactionSource(ctx context.Context) string {
+	if source, ok := ctx.Value(transactionSourceKey{}).(string); ok && source != "" {
+		return source
+	}
+	return "API"
+}
+
+// TransactionHook é executado dentro da transação de banco que efetiva a
+// movimentação. Retornar erro desfaz toda a operação.
+type TransactionHook func(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error
+
+// TransactionStatusHook é executado dentro da transação de banco que grava a
+// mudança de Transaction.TransactionStatus. Retornar erro desfaz a mudança.
+type TransactionStatusHook func(ctx context.Context, tx *gorm.DB, transaction *models.Transaction, previousStatus string) error
+
+// outsideGuardTx é a conexão para o que um guard grava fora da transação de
+// banco: quando o guard recusa a transação, tx é desfeita, e as decisões e
+// revisões que explicam a recusa precisam ser mantidas
+func outsideGuardTx(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return db.WithContext(ctx)
+}
+
+type MakeTransactionService interface {
+	// Execute valida e efetiva uma transação em nome do usuário. Requisições
+	// repetidas com a mesma chave de idempotência retornam a transação original.
+	Execute(ctx context.Context, userID string, req *dtos.TransactionRequestDTO) (*dtos.TransactionResponseDTO, error)
+
+	// ReleaseHeld conclui uma transação retida, movimentando os saldos. O
+	// limite diário e os guards são conferidos de novo; os que retêm para
+	// análise identificam a liberação por releasingHeld.
+	ReleaseHeld(ctx context.Context, transactionID string) (*dtos.TransactionResponseDTO, error)
+
+	// ReleaseHeldTx é ReleaseHeld dentro da transação de banco tx do
+	// chamador, para que a liberação e o registro da análise sejam gravados
+	// juntos
+	ReleaseHeldTx(ctx context.Context, tx *gorm.DB, transactionID string) (*dtos.TransactionResponseDTO, error)
+
+	// RejectHeld cancela uma transação retida
+	RejectHeld(ctx context.Context, transactionID, reason string) error
+
+	// RejectHeldTx é RejectHeld dentro da transação de banco tx do chamador
+	RejectHeldTx(ctx context.Context, tx *gorm.DB, transactionID, reason string) error
+
+	// AddGuard registra uma verificação executada antes da conclusão da
+	// transação. O guard pode recusar a transação ou retê-la com ErrHoldForReview.
+	AddGuard(hook TransactionHook)
+
+	// AddCompletedListener registra um hook executado após a conclusão da transação
+	AddCompletedListener(hook TransactionHook)
+
+	// AddStatusListener registra um hook executado a cada mudança de status
+	// gravada: retenção, conclusão e cancelamento
+	AddStatusListener(hook TransactionStatusHook)
+}
+
+type makeTransactionService struct {
+	db              *gorm.DB
+	guards          []TransactionHook
+	listeners       []TransactionHook
+	statusListeners []TransactionStatusHook
+}
+
+func NewMakeTransactionService(db *gorm.DB) MakeTransactionService {
+	return &makeTransactionService{
+		db: db,
+	}
+}
+
+func (s *makeTransactionService) AddGuard(hook TransactionHook) {
+	s.guards = append(s.guards, hook)
+}
+
+func (s *makeTransactionService) AddCompletedListener(hook TransactionHook) {
+	s.listeners = append(s.listeners, hook)
+}
+
+func (s *makeTransactionService) AddStatusListener(hook TransactionStatusHook) {
+	s.statusListeners = append(s.statusListeners, hook)
+}
+
+func (s *makeTransactionService) Execute(ctx context.Context, userID string, req *dtos.TransactionRequestDTO) (*dtos.TransactionResponseDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+	if req.AccountIDDest != "" && req.AccountIDDest == req.AccountIDOrigin {
+		return nil, ErrSameAccount
+	}
+
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *dtos.TransactionResponseDTO
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var refType models.RefTransactionType
+		if err := tx.First(&refType, "transaction_type_code = ?", req.TransactionTypeCode).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTransactionTypeNotFound
+			}
+			return err
+		}
+		if refType.RequiresDestination && req.AccountIDDest == "" {
+			return ErrDestinationRequired
+		}
+
+		origin, dest, err := lockAccounts(tx, req.AccountIDOrigin, req.AccountIDDest)
+		if err != nil {
+			return err
+		}
+		if origin.CustomerID != customerID {
+			return ErrAccountNotOwned
+		}
+
+		// A conta de origem está bloqueada, então a checagem de idempotência
+		// não sofre corrida com outra requisição usando a mesma chave
+		var existing models.Transaction
+		err = tx.Where("idempotency_key = ?", req.IdempotencyKey).First(&existing).Error
+		if err == nil {
+			if existing.AccountIDOrigin != req.AccountIDOrigin || existing.TransactionAmount != req.Amount {
+				return ErrIdempotencyKeyReused
+			}
+			response = buildTransactionResponse(&existing, origin, dest)
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if origin.AccountStatus != models.AccountStatusActive {
+			return fmt.Errorf("%w: origem", ErrAccountNotActive)
+		}
+		if dest != nil && dest.AccountStatus != models.AccountStatusActive {
+			return fmt.Errorf("%w: destino", ErrAccountNotActive)
+		}
+		if origin.AvailableBalance+origin.OverdraftLimit < req.Amount {
+			return ErrInsufficientFunds
+		}
+		if refType.MaxDailyAmount.Valid {
+			if err := checkDailyLimit(tx, origin, refType, req.Amount, ""); err != nil {
+				return err
+			}
+		}
+
+		transaction, err := newTransactionFromRequest(transactionSource(ctx), userID, req)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
+
+		for _, guard := range s.guards {
+			err := guard(ctx, tx, transaction)
+			if errors.Is(err, ErrHoldForReview) {
+				if err := s.changeStatus(ctx, tx, transaction, map[string]interface{}{
+					"transaction_status": models.TransactionStatusOnHold,
+				}); err != nil {
+					return err
+				}
+				response = buildTransactionResponse(transaction, origin, dest)
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := s.complete(ctx, tx, transaction, origin, dest); err != nil {
+			return err
+		}
+
+		response = buildTransactionResponse(transaction, origin, dest)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *makeTransactionService) ReleaseHeld(ctx context.Context, transactionID string) (*dtos.TransactionResponseDTO, error) {
+	var response *dtos.TransactionResponseDTO
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		response, err = s.ReleaseHeldTx(ctx, tx, transactionID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *makeTransactionService) ReleaseHeldTx(ctx context.Context, tx *gorm.DB, transactionID string) (*dtos.TransactionResponseDTO, error) {
+	transaction, err := findHeldTransaction(tx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// a linha da transação fica bloqueada antes das contas; Execute nunca
+	// bloqueia transações retidas, então a ordem não gera deadlock
+	origin, dest, err := lockAccounts(tx, transaction.AccountIDOrigin, transaction.AccountIDDest.String)
+	if err != nil {
+		return nil, err
+	}
+
+	if origin.AccountStatus != models.AccountStatusActive {
+		return nil, fmt.Errorf("%w: origem", ErrAccountNotActive)
+	}
+	if dest != nil && dest.AccountStatus != models.AccountStatusActive {
+		return nil, fmt.Errorf("%w: destino", ErrAccountNotActive)
+	}
+	if origin.AvailableBalance+origin.OverdraftLimit < transaction.TransactionAmount {
+		return nil, ErrInsufficientFunds
+	}
+
+	// os limites e guards são conferidos de novo: outras transações podem ter
+	// sido concluídas ou retidas enquanto esta aguardava a análise
+	var refType models.RefTransactionType
+	if err := tx.First(&refType, "transaction_type_code = ?", transaction.TransactionTypeCode).Error; err != nil {
+		return nil, err
+	}
+	if refType.MaxDailyAmount.Valid {
+		if err := checkDailyLimit(tx, origin, refType, transaction.TransactionAmount, transaction.TransactionID); err != nil {
+			return nil, err
+		}
+	}
+	releaseCtx := withHeldRelease(ctx)
+	for _, guard := range s.guards {
+		if err := guard(releaseCtx, tx, transaction); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.complete(ctx, tx, transaction, origin, dest); err != nil {
+		return nil, err
+	}
+	return buildTransactionResponse(transaction, origin, dest), nil
+}
+
+func (s *makeTransactionService) RejectHeld(ctx context.Context, transactionID, reason string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return s.RejectHeldTx(ctx, tx, transactionID, reason)
+	})
+}
+
+func (s *makeTransactionService) RejectHeldTx(ctx context.Context, tx *gorm.DB, transactionID, reason string) error {
+	transaction, err := findHeldTransaction(tx, transactionID)
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{"transaction_status": models.TransactionStatusCancelled}
+	if reason != "" {
+		description := reason
+		if transaction.Description.Valid {
+			description = transaction.Description.String + " | " + reason
+		}
+		updates["description"] = truncate(description, 500)
+	}
+	return s.changeStatus(ctx, tx, transaction, updates)
+}
+
+func findHeldTransaction(tx *gorm.DB, transactionID string) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, "transaction_id = ?", transactionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, err
+	}
+	if transaction.TransactionStatus != models.TransactionStatusOnHold {
+		return nil, ErrTransactionNotHeld
+	}
+	return &transaction, nil
+}
+
+// complete movimenta os saldos, conclui a transação e executa os listeners.
+// As contas já devem estar bloqueadas por lockAccounts.
+func (s *makeTransactionService) complete(ctx context.Context, tx *gorm.DB, transaction *models.Transaction, origin, dest *models.Account) error {
+	amount := transaction.TransactionAmount
+
+	origin.CurrentBalance -= amount
+	origin.AvailableBalance -= amount
+	if err := tx.Model(origin).Updates(map[string]interface{}{
+		"current_balance":   origin.CurrentBalance,
+		"available_balance": origin.AvailableBalance,
+	}).Error; err != nil {
+		return err
+	}
+	if dest != nil {
+		dest.CurrentBalance += amount
+		dest.AvailableBalance += amount
+		if err := tx.Model(dest).Updates(map[string]interface{}{
+			"current_balance":   dest.CurrentBalance,
+			"available_balance": dest.AvailableBalance,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := s.changeStatus(ctx, tx, transaction, map[string]interface{}{
+		"transaction_status": models.TransactionStatusCompleted,
+		"completed_at":       sql.NullTime{Time: time.Now(), Valid: true},
+		"balance_after":      sql.NullFloat64{Float64: origin.CurrentBalance, Valid: true},
+	}); err != nil {
+		return err
+	}
+
+	for _, listener := range s.listeners {
+		if err := listener(ctx, tx, transaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeStatus grava as alterações, entre elas transaction_status, e executa
+// os listeners de status
+func (s *makeTransactionService) changeStatus(ctx context.Context, tx *gorm.DB, transaction *models.Transaction, updates map[string]interface{}) error {
+	previous := transaction.TransactionStatus
+	if err := tx.Model(transaction).Updates(updates).Error; err != nil {
+		return err
+	}
+	for _, listener := range s.statusListeners {
+		if err := listener(ctx, tx, transaction, previous); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockAccounts bloqueia as contas envolvidas sempre na mesma ordem para evitar deadlocks
+func lockAccounts(tx *gorm.DB, originID, destID string) (*models.Account, *models.Account, error) {
+	ids := []string{originID}
+	if destID != "" {
+		ids = append(ids, destID)
+	}
+
+	var accounts []models.Account
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("account_id IN ?", ids).
+		Order("account_id").
+		Find(&accounts).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var origin, dest *models.Account
+	for i := range accounts {
+		switch accounts[i].AccountID {
+		case originID:
+			origin = &accounts[i]
+		case destID:
+			dest = &accounts[i]
+		}
+	}
+	if origin == nil {
+		return nil, nil, fmt.Errorf("%w: origem", ErrAccountNotFound)
+	}
+	if destID != "" && dest == nil {
+		return nil, nil, fmt.Errorf("%w: destino", ErrAccountNotFound)
+	}
+	return origin, dest, nil
+}
+
+// checkDailyLimit soma o que já saiu da conta hoje, contando o dia a partir
+// da meia-noite no fuso do cliente e não em UTC. As transações pendentes e
+// retidas entram na soma, para que liberá-las não ultrapasse o limite;
+// excludeID é a própria transação quando ela já está gravada.
+func checkDailyLimit(tx *gorm.DB, origin *models.Account, refType models.RefTransactionType, amount float64, excludeID string) error {
+	var timezone string
+	if err := tx.Model(&models.Customer{}).
+		Select("timezone").
+		Where("customer_id = ?", origin.CustomerID).
+		Scan(&timezone).Error; err != nil {
+		return err
+	}
+	startOfDay := dayStart(time.Now(), customerLocation(&models.Customer{Timezone: timezone}))
+
+	query := tx.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(transaction_amount), 0)").
+		Where("account_id_origin = ? AND transaction_type_code = ? AND transaction_date >= ?",
+			origin.AccountID, refType.TransactionTypeCode, startOfDay).
+		Where("transaction_status IN ?", []string{models.TransactionStatusPending, models.TransactionStatusOnHold, models.TransactionStatusCompleted})
+	if excludeID != "" {
+		query = query.Where("transaction_id <> ?", excludeID)
+	}
+	var total float64
+	if err := query.Scan(&total).Error; err != nil {
+		return err
+	}
+
+	if total+amount > refType.MaxDailyAmount.Float64 {
+		return ErrDailyLimitExceeded
+	}
+	return nil
+}
+
+// dayStart retorna a meia-noite do dia de t no fuso informado
+func dayStart(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+func newTransactionFromRequest(source, userID string, req *dtos.TransactionRequestDTO) (*models.Transaction, error) {
+	transaction := &models.Transaction{
+		AccountIDOrigin:     req.AccountIDOrigin,
+		TransactionTypeCode: req.TransactionTypeCode,
+		TransactionAmount:   req.Amount,
+		TransactionStatus:   models.TransactionStatusPending,
+		CreatedByUserID:     sql.NullString{String: userID, Valid: userID != ""},
+		CreatedBySource:     sql.NullString{String: source, Valid: true},
+		IdempotencyKey:      req.IdempotencyKey,
+	}
+	if req.AccountIDDest != "" {
+		transaction.AccountIDDest = sql.NullString{String: req.AccountIDDest, Valid: true}
+	}
+	if req.Description != "" {
+		transaction.Description = sql.NullString{String: req.Description, Valid: true}
+	}
+	if req.ExternalReference != "" {
+		transaction.ExternalReference = sql.NullString{String: req.ExternalReference, Valid: true}
+	}
+	if req.Metadata != nil {
+		metadata, err := json.Marshal(req.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("%w: metadata", ErrInvalidInput)
+		}
+		transaction.Metadata = datatypes.JSON(metadata)
+	}
+	return transaction, nil
+}
+
+func buildTransactionResponse(t *models.Transaction, origin, dest *models.Account) *dtos.TransactionResponseDTO {
+	response := &dtos.TransactionResponseDTO{
+		TransactionID:     t.TransactionID,
+		TransactionType:   t.TransactionTypeCode,
+		TransactionStatus: t.TransactionStatus,
+		TransactionDate:   t.TransactionDate,
+		Amount:            t.TransactionAmount,
+		Description:       t.Description.String,
+		AccountOrigin:     accountMini(origin),
+	}
+	if t.CompletedAt.Valid {
+		completedAt := t.CompletedAt.Time
+		response.CompletedAt = &completedAt
+	}
+	if t.BalanceAfter.Valid {
+		balance := t.BalanceAfter.Float64
+		response.BalanceAfter = &balance
+	}
+	if dest != nil {
+		mini := accountMini(dest)
+		response.AccountDest = &mini
+	}
+	return response
+}
+
+func accountMini(acc *models.Account) dtos.AccountMiniDTO {
+	return dtos.AccountMiniDTO{
+		AccountID:     acc.AccountID,
+		AccountNumber: acc.AccountNumber,
+		AgencyNumber:  acc.AgencyNumber,
+		AccountType:   acc.AccountTypeCode,
+	}
+}
@@ -0,0 +1,660 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/notifications"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotificationNotFound    = fmt.Errorf("%w: notificação", ErrNotFound)
+	ErrUnknownNotificationType = fmt.Errorf("%w: tipo de evento de notificação desconhecido", ErrInvalidInput)
+	ErrNoRecipient             = fmt.Errorf("%w: nenhum destinatário disponível para a notificação", ErrInvalidInput)
+)
+
+// NotificationRequest descreve uma notificação a ser enfileirada
+type NotificationRequest struct {
+	CustomerID string
+	UserID     string
+	Template   string
+	Data       map[string]string
+	// Channels força os canais, ignorando as preferências do cliente.
+	// Usado em mensagens de segurança (códigos, redefinição de senha).
+	Channels []string
+	// Recipient substitui o destinatário cadastrado (ex.: email informado no pedido)
+	Recipient string
+}
+
+type NotificationService interface {
+	// Enqueue renderiza e grava a notificação na fila. Com tx não nulo a
+	// gravação participa da transação do chamador.
+	Enqueue(ctx context.Context, tx *gorm.DB, req *NotificationRequest) error
+
+	// OnTransactionCompleted enfileira os avisos de transação para origem e destino
+	OnTransactionCompleted(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error
+
+	// ProcessPending envia as notificações vencidas. Retorna quantas foram processadas.
+	ProcessPending(ctx context.Context, limit int) (int, error)
+
+	// Run processa a fila e os alertas de orçamento até o contexto ser cancelado
+	Run(ctx context.Context, interval time.Duration)
+
+	GetPreferences(ctx context.Context, userID string) (*dtos.NotificationPreferencesDTO, error)
+	UpdatePreferences(ctx context.Context, userID string, req *dtos.NotificationPreferencesDTO) (*dtos.NotificationPreferencesDTO, error)
+	RegisterDevice(ctx context.Context, userID string, req *dtos.RegisterPushDeviceDTO) error
+	UnregisterDevice(ctx context.Context, userID, token string) error
+
+	// ListDeadLetters lista notificações que esgotaram as tentativas
+	ListDeadLetters(ctx context.Context, limit int) ([]dtos.NotificationDTO, error)
+
+	// Requeue devolve uma notificação da dead-letter para a fila
+	Requeue(ctx context.Context, notificationID string) error
+}
+
+// NotificationConfig contém as configurações da fila de notificações
+type NotificationConfig struct {
+	MaxAttempts  int
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	SendTimeout  time.Duration
+}
+
+type notificationService struct {
+	db           *gorm.DB
+	senders      *notifications.Senders
+	maxAttempts  int
+	retryBackoff time.Duration
+	maxBackoff   time.Duration
+	sendTimeout  time.Duration
+}
+
+func NewNotificationService(db *gorm.DB, senders *notifications.Senders, cfg *NotificationConfig) NotificationService {
+	if cfg == nil {
+		cfg = &NotificationConfig{}
+	}
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = config.GetEnvInt("NOTIFICATION_MAX_ATTEMPTS", 5)
+	}
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = config.GetEnvDuration("NOTIFICATION_RETRY_BACKOFF", 30*time.Second)
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = config.GetEnvDuration("NOTIFICATION_MAX_BACKOFF", time.Hour)
+	}
+	if cfg.SendTimeout == 0 {
+		cfg.SendTimeout = config.GetEnvDuration("NOTIFICATION_SEND_TIMEOUT", 15*time.Second)
+	}
+
+	return &notificationService{
+		db:           db,
+		senders:      senders,
+		maxAttempts:  cfg.MaxAttempts,
+		retryBackoff: cfg.RetryBackoff,
+		maxBackoff:   cfg.MaxBackoff,
+		sendTimeout:  cfg.SendTimeout,
+	}
+}
+
+func (s *notificationService) Enqueue(ctx context.Context, tx *gorm.DB, req *NotificationRequest) error {
+	if !notifications.HasTemplate(req.Template) {
+		return ErrUnknownNotificationType
+	}
+	if tx == nil {
+		tx = s.db.WithContext(ctx)
+	}
+
+	var customer *models.Customer
+	if req.CustomerID != "" {
+		customer = &models.Customer{}
+		if err := tx.First(customer, "customer_id = ?", req.CustomerID).Error; err != nil {
+			return err
+		}
+	}
+
+	var user *models.User
+	if req.UserID != "" {
+		user = &models.User{}
+		if err := tx.First(user, "user_id = ?", req.UserID).Error; err != nil {
+			return err
+		}
+	} else if customer != nil {
+		user = &models.User{}
+		err := tx.Where("customer_id = ? AND is_active = ?", customer.CustomerID, true).Order("created_at").First(user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			user = nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	channels := req.Channels
+	if len(channels) == 0 {
+		if customer == nil {
+			channels = []string{notifications.ChannelEmail}
+		} else {
+			var err error
+			channels, err = s.enabledChannels(tx, customer.CustomerID, req.Template)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	locale := notifications.DefaultLocale
+	data := make(map[string]string, len(req.Data)+1)
+	if customer != nil {
+		locale = customer.Locale
+		data["name"] = firstName(customer.CustomerName)
+	} else if user != nil {
+		data["name"] = user.Username
+	}
+	for k, v := range req.Data {
+		data[k] = v
+	}
+
+	var queued []models.Notification
+	for _, channel := range channels {
+		recipients, err := s.recipients(tx, channel, req.Recipient, customer, user)
+		if err != nil {
+			return err
+		}
+
+		subject, body, err := notifications.Render(req.Template, locale, channel, data)
+		if err != nil {
+			return err
+		}
+
+		for _, recipient := range recipients {
+			queued = append(queued, models.Notification{
+				CustomerID:         nullString(req.CustomerID),
+				UserID:             userIDOf(user),
+				Channel:            channel,
+				Recipient:          recipient,
+				TemplateKey:        req.Template,
+				Locale:             notifications.NormalizeLocale(locale),
+				Subject:            truncate(subject, 255),
+				Body:               body,
+				NotificationStatus: models.NotificationStatusPending,
+				MaxAttempts:        s.maxAttempts,
+				NextAttemptAt:      time.Now(),
+			})
+		}
+	}
+
+	if len(queued) == 0 {
+		// preferências desligadas para o evento não são erro; destino forçado sem contato é
+		if len(req.Channels) > 0 {
+			return ErrNoRecipient
+		}
+		return nil
+	}
+	return tx.Create(&queued).Error
+}
+
+func (s *notificationService) OnTransactionCompleted(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error {
+	ids := []string{transaction.AccountIDOrigin}
+	if transaction.AccountIDDest.Valid {
+		ids = append(ids, transaction.AccountIDDest.String)
+	}
+
+	var accounts []models.Account
+	if err := tx.Preload("Customer").Where("account_id IN ?", ids).Find(&accounts).Error; err != nil {
+		return err
+	}
+
+	for _, acc := range accounts {
+		if acc.Customer == nil {
+			continue
+		}
+		loc := customerLocation(acc.Customer)
+		data := map[string]string{
+			"type":   transaction.TransactionTypeCode,
+			"amount": formatMoney(transaction.TransactionAmount, acc.Customer.Locale),
+			"date":   transaction.CompletedAt.Time.In(loc).Format("02/01/2006 15:04"),
+		}
+
+		template := notifications.TemplateTransactionReceived
+		if acc.AccountID == transaction.AccountIDOrigin {
+			template = notifications.TemplateTransactionCompleted
+			data["balance"] = formatMoney(transaction.BalanceAfter.Float64, acc.Customer.Locale)
+		}
+
+		if err := s.Enqueue(ctx, tx, &NotificationRequest{
+			CustomerID: acc.CustomerID,
+			Template:   template,
+			Data:       data,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *notificationService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.dispatchBudgetAlerts(ctx, 100); err != nil {
+			log.Printf("notifications: budget alerts: %v", err)
+		}
+		for {
+			processed, err := s.ProcessPending(ctx, 50)
+			if err != nil {
+				log.Printf("notifications: %v", err)
+				break
+			}
+			if processed == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *notificationService) ProcessPending(ctx context.Context, limit int) (int, error) {
+	batch, err := s.claim(ctx, limit)
+	if err != nil || len(batch) == 0 {
+		return 0, err
+	}
+
+	for i := range batch {
+		s.deliver(ctx, &batch[i])
+	}
+	return len(batch), nil
+}
+
+// claim reserva notificações vencidas. O status SENDING com next_attempt_at
+// no futuro funciona como lease: se a instância cair, outra retoma depois.
+func (s *notificationService) claim(ctx context.Context, limit int) ([]models.Notification, error) {
+	var batch []models.Notification
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("notification_status IN ? AND next_attempt_at <= ?",
+				[]string{models.NotificationStatusPending, models.NotificationStatusSending}, time.Now()).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		ids := make([]string, 0, len(batch))
+		for _, n := range batch {
+			ids = append(ids, n.NotificationID)
+		}
+		return tx.Model(&models.Notification{}).
+			Where("notification_id IN ?", ids).
+			Updates(map[string]interface{}{
+				"notification_status": models.NotificationStatusSending,
+				"next_attempt_at":     time.Now().Add(2 * s.sendTimeout),
+			}).Error
+	})
+	return batch, err
+}
+
+func (s *notificationService) deliver(ctx context.Context, n *models.Notification) {
+	attempts := n.Attempts + 1
+	updates := map[string]interface{}{"attempts": attempts}
+
+	sender, ok := s.senders.For(n.Channel)
+	var (
+		providerID string
+		err        error
+	)
+	if !ok {
+		err = fmt.Errorf("%w: canal %s sem provedor configurado", notifications.ErrPermanent, n.Channel)
+	} else {
+		sendCtx, cancel := context.WithTimeout(ctx, s.sendTimeout)
+		providerID, err = sender.Send(sendCtx, &notifications.Message{
+			ID:        n.NotificationID,
+			Recipient: n.Recipient,
+			Subject:   n.Subject,
+			Body:      n.Body,
+			Data:      map[string]string{"template": n.TemplateKey},
+		})
+		cancel()
+	}
+
+	switch {
+	case err == nil:
+		updates["notification_status"] = models.NotificationStatusSent
+		updates["sent_at"] = time.Now()
+		updates["provider_message_id"] = providerID
+		updates["last_error"] = nil
+	case errors.Is(err, notifications.ErrPermanent) || attempts >= n.MaxAttempts:
+		updates["notification_status"] = models.NotificationStatusDead
+		updates["last_error"] = truncate(err.Error(), 500)
+	default:
+		updates["notification_status"] = models.NotificationStatusPending
+		updates["next_attempt_at"] = time.Now().Add(s.backoff(attempts))
+		updates["last_error"] = truncate(err.Error(), 500)
+	}
+
+	if err := s.db.WithContext(ctx).Model(n).Updates(updates).Error; err != nil {
+		log.Printf("notifications: failed to update %s: %v", n.NotificationID, err)
+	}
+}
+
+// backoff cresce exponencialmente a partir de retryBackoff até maxBackoff
+func (s *notificationService) backoff(attempts int) time.Duration {
+	delay := time.Duration(float64(s.retryBackoff) * math.Pow(2, float64(attempts-1)))
+	if delay <= 0 || delay > s.maxBackoff {
+		return s.maxBackoff
+	}
+	return delay
+}
+
+// dispatchBudgetAlerts transforma alertas de orçamento pendentes em notificações
+func (s *notificationService) dispatchBudgetAlerts(ctx context.Context, limit int) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var alerts []models.BudgetAlert
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("alert_status = ?", models.BudgetAlertStatusPending).
+			Order("triggered_at").
+			Limit(limit).
+			Find(&alerts).Error; err != nil {
+			return err
+		}
+
+		for _, alert := range alerts {
+			var customer models.Customer
+			if err := tx.Select("customer_id", "locale").First(&customer, "customer_id = ?", alert.CustomerID).Error; err != nil {
+				return err
+			}
+			locale := customer.Locale
+			budgetName := alert.CategoryCode.String
+			if !alert.CategoryCode.Valid {
+				budgetName = "total"
+			}
+
+			if err := s.Enqueue(ctx, tx, &NotificationRequest{
+				CustomerID: alert.CustomerID,
+				Template:   notifications.TemplateBudgetAlert,
+				Data: map[string]string{
+					"threshold": fmt.Sprintf("%d", alert.Threshold),
+					"budget":    budgetName,
+					"period":    alert.PeriodMonth,
+					"spent":     formatMoney(alert.SpentAmount, locale),
+					"limit":     formatMoney(alert.LimitAmount, locale),
+				},
+			}); err != nil {
+				return err
+			}
+
+			if err := tx.Model(&alert).Updates(map[string]interface{}{
+				"alert_status": models.BudgetAlertStatusNotified,
+				"notified_at":  time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *notificationService) GetPreferences(ctx context.Context, userID string) (*dtos.NotificationPreferencesDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var customer models.Customer
+	if err := s.db.WithContext(ctx).Select("customer_id", "locale").First(&customer, "customer_id = ?", customerID).Error; err != nil {
+		return nil, err
+	}
+
+	var preferences []models.NotificationPreference
+	if err := s.db.WithContext(ctx).Where("customer_id = ?", customerID).Order("event_type").Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+
+	response := &dtos.NotificationPreferencesDTO{
+		Locale:      customer.Locale,
+		Preferences: make([]dtos.NotificationPreferenceDTO, 0, len(preferences)+1),
+	}
+	hasDefault := false
+	for _, p := range preferences {
+		hasDefault = hasDefault || p.EventType == models.NotificationEventDefault
+		response.Preferences = append(response.Preferences, dtos.NotificationPreferenceDTO{
+			EventType:    p.EventType,
+			EmailEnabled: p.EmailEnabled,
+			SMSEnabled:   p.SMSEnabled,
+			PushEnabled:  p.PushEnabled,
+		})
+	}
+	if !hasDefault {
+		def := defaultNotificationPreference(customerID)
+		response.Preferences = append([]dtos.NotificationPreferenceDTO{{
+			EventType:    def.EventType,
+			EmailEnabled: def.EmailEnabled,
+			SMSEnabled:   def.SMSEnabled,
+			PushEnabled:  def.PushEnabled,
+		}}, response.Preferences...)
+	}
+	return response, nil
+}
+
+func (s *notificationService) UpdatePreferences(ctx context.Context, userID string, req *dtos.NotificationPreferencesDTO) (*dtos.NotificationPreferencesDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range req.Preferences {
+		if p.EventType != models.NotificationEventDefault && !notifications.HasTemplate(p.EventType) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNotificationType, p.EventType)
+		}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if req.Locale != "" {
+			if err := tx.Model(&models.Customer{}).
+				Where("customer_id = ?", customerID).
+				Update("locale", notifications.NormalizeLocale(req.Locale)).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, p := range req.Preferences {
+			preference := models.NotificationPreference{
+				CustomerID:   customerID,
+				EventType:    p.EventType,
+				EmailEnabled: p.EmailEnabled,
+				SMSEnabled:   p.SMSEnabled,
+				PushEnabled:  p.PushEnabled,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "customer_id"}, {Name: "event_type"}},
+				DoUpdates: clause.AssignmentColumns([]string{"email_enabled", "sms_enabled", "push_enabled", "updated_at"}),
+			}).Create(&preference).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPreferences(ctx, userID)
+}
+
+func (s *notificationService) RegisterDevice(ctx context.Context, userID string, req *dtos.RegisterPushDeviceDTO) error {
+	device := models.PushDevice{
+		UserID:     userID,
+		Token:      req.Token,
+		Platform:   req.Platform,
+		LastSeenAt: time.Now(),
+	}
+	// um token pertence a um único usuário; reinstalações transferem o token
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "last_seen_at"}),
+	}).Create(&device).Error
+}
+
+func (s *notificationService) UnregisterDevice(ctx context.Context, userID, token string) error {
+	return s.db.WithContext(ctx).Delete(&models.PushDevice{}, "user_id = ? AND token = ?", userID, token).Error
+}
+
+func (s *notificationService) ListDeadLetters(ctx context.Context, limit int) ([]dtos.NotificationDTO, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	var dead []models.Notification
+	if err := s.db.WithContext(ctx).
+		Where("notification_status = ?", models.NotificationStatusDead).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&dead).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.NotificationDTO, 0, len(dead))
+	for _, n := range dead {
+		result = append(result, dtos.NotificationDTO{
+			NotificationID: n.NotificationID,
+			Channel:        n.Channel,
+			Recipient:      n.Recipient,
+			TemplateKey:    n.TemplateKey,
+			Status:         n.NotificationStatus,
+			Attempts:       n.Attempts,
+			LastError:      n.LastError.String,
+			CreatedAt:      n.CreatedAt,
+			SentAt:         nullTimePtr(n.SentAt),
+		})
+	}
+	return result, nil
+}
+
+func (s *notificationService) Requeue(ctx context.Context, notificationID string) error {
+	result := s.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("notification_id = ? AND notification_status = ?", notificationID, models.NotificationStatusDead).
+		Updates(map[string]interface{}{
+			"notification_status": models.NotificationStatusPending,
+			"attempts":            0,
+			"next_attempt_at":     time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// enabledChannels resolve os canais do evento: preferência específica,
+// depois a preferência padrão "*", depois o padrão do sistema
+func (s *notificationService) enabledChannels(tx *gorm.DB, customerID, eventType string) ([]string, error) {
+	var preferences []models.NotificationPreference
+	if err := tx.Where("customer_id = ? AND event_type IN ?", customerID, []string{eventType, models.NotificationEventDefault}).
+		Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+
+	preference := defaultNotificationPreference(customerID)
+	for _, p := range preferences {
+		if p.EventType == eventType {
+			preference = p
+			break
+		}
+		preference = p
+	}
+
+	var channels []string
+	if preference.EmailEnabled {
+		channels = append(channels, notifications.ChannelEmail)
+	}
+	if preference.SMSEnabled {
+		channels = append(channels, notifications.ChannelSMS)
+	}
+	if preference.PushEnabled {
+		channels = append(channels, notifications.ChannelPush)
+	}
+	return channels, nil
+}
+
+func (s *notificationService) recipients(tx *gorm.DB, channel, override string, customer *models.Customer, user *models.User) ([]string, error) {
+	if override != "" {
+		return []string{override}, nil
+	}
+
+	switch channel {
+	case notifications.ChannelEmail:
+		if customer != nil && customer.CustomerEmail.Valid && customer.CustomerEmail.String != "" {
+			return []string{customer.CustomerEmail.String}, nil
+		}
+		if user != nil && user.Email != "" {
+			return []string{user.Email}, nil
+		}
+	case notifications.ChannelSMS:
+		if customer != nil && customer.CustomerPhone.Valid && customer.CustomerPhone.String != "" {
+			return []string{customer.CustomerPhone.String}, nil
+		}
+	case notifications.ChannelPush:
+		if user == nil {
+			return nil, nil
+		}
+		var tokens []string
+		if err := tx.Model(&models.PushDevice{}).Where("user_id = ?", user.UserID).Pluck("token", &tokens).Error; err != nil {
+			return nil, err
+		}
+		return tokens, nil
+	}
+	return nil, nil
+}
+
+func defaultNotificationPreference(customerID string) models.NotificationPreference {
+	return models.NotificationPreference{
+		CustomerID:   customerID,
+		EventType:    models.NotificationEventDefault,
+		EmailEnabled: true,
+		SMSEnabled:   false,
+		PushEnabled:  true,
+	}
+}
+
+func userIDOf(user *models.User) sql.NullString {
+	if user == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: user.UserID, Valid: true}
+}
+
+func firstName(name string) string {
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return fields[0]
+	}
+	return name
+}
+
+// formatMoney formata o valor com duas casas, usando vírgula decimal em pt-BR
+func formatMoney(amount float64, locale string) string {
+	formatted := fmt.Sprintf("%.2f", amount)
+	if notifications.NormalizeLocale(locale) == notifications.LocalePtBR {
+		formatted = strings.Replace(formatted, ".", ",", 1)
+	}
+	return formatted
+}
@@ -0,0 +1,866 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/security"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/internal/notifications"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"github.com/victor-lima-142/oak-bank/pkg/taxid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOnboardingSessionNotFound  = fmt.Errorf("%w: sessão de onboarding", ErrNotFound)
+	ErrOnboardingSessionCompleted = fmt.Errorf("%w: sessão de onboarding já concluída", ErrConflict)
+)
+
+// Seções do AccountOnboardingDTO, na ordem do cadastro
+const (
+	onboardingSectionUser     = "user"
+	onboardingSectionCustomer = "customer"
+	onboardingSectionAddress  = "address"
+	onboardingSectionAccount  = "account"
+	onboardingSectionKYC      = "kyc"
+)
+
+// accountNumberAttempts é o número de tentativas para gerar um número de
+// conta livre
+const accountNumberAttempts = 5
+
+type OnboardingService interface {
+	// Register faz o cadastro completo em um único envio: usuário, cliente,
+	// endereço e conta são criados na mesma transação
+	Register(ctx context.Context, req *dtos.AccountOnboardingDTO) (*dtos.AccountOnboardingResponseDTO, error)
+
+	// CreateSession inicia um cadastro em etapas com as seções enviadas. O
+	// token de retomada só é devolvido aqui e em RequestResume.
+	CreateSession(ctx context.Context, req *dtos.AccountOnboardingDTO) (*dtos.OnboardingSessionDTO, error)
+
+	// GetSession retorna as seções salvas e a situação de cada uma
+	GetSession(ctx context.Context, sessionID, token string) (*dtos.OnboardingSessionDTO, error)
+
+	// SaveSession grava as seções presentes em req, mantendo as demais
+	SaveSession(ctx context.Context, sessionID, token string, req *dtos.AccountOnboardingDTO) (*dtos.OnboardingSessionDTO, error)
+
+	// CompleteSession conclui o cadastro com as seções salvas
+	CompleteSession(ctx context.Context, sessionID, token string) (*dtos.AccountOnboardingResponseDTO, error)
+
+	// RequestResume gera um novo token para a sessão em andamento do email e
+	// o envia por email. Não informa se existe sessão para o email.
+	RequestResume(ctx context.Context, req *dtos.ResumeOnboardingDTO) error
+
+	// Run remove as sessões expiradas até o contexto ser cancelado
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// OnboardingConfig contém as configurações do cadastro
+type OnboardingConfig struct {
+	// SessionTTL é o prazo para concluir uma sessão, renovado a cada gravação
+	SessionTTL    time.Duration
+	DefaultAgency string
+}
+
+type onboardingService struct {
+	db            *gorm.DB
+	passwords     security.PasswordService
+	addresses     AddressService
+	screening     ScreeningService
+	notifications NotificationService
+	config        OnboardingConfig
+}
+
+func NewOnboardingService(db *gorm.DB, passwords security.PasswordService, addresses AddressService, screening ScreeningService, notifications NotificationService, cfg *OnboardingConfig) OnboardingService {
+	if cfg == nil {
+		cfg = &OnboardingConfig{}
+	}
+	if cfg.SessionTTL == 0 {
+		cfg.SessionTTL = config.GetEnvDuration("ONBOARDING_SESSION_TTL", 30*24*time.Hour)
+	}
+	if cfg.DefaultAgency == "" {
+		cfg.DefaultAgency = config.GetEnv("ONBOARDING_AGENCY_NUMBER", "0001")
+	}
+
+	return &onboardingService{
+		db:            db,
+		passwords:     passwords,
+		addresses:     addresses,
+		screening:     screening,
+		notifications: notifications,
+		config:        *cfg,
+	}
+}
+
+// onboardingDraft é o conteúdo de OnboardingSession.Draft. A senha é
+// guardada apenas como hash em PasswordHash e removida da seção de usuário;
+// AddressWarnings guarda as divergências encontradas na consulta do CEP.
+type onboardingDraft struct {
+	dtos.AccountOnboardingDTO
+	PasswordHash    string            `json:"password_hash,omitempty"`
+	AddressWarnings map[string]string `json:"address_warnings,omitempty"`
+}
+
+func (s *onboardingService) Register(ctx context.Context, req *dtos.AccountOnboardingDTO) (*dtos.AccountOnboardingResponseDTO, error) {
+	draft := &onboardingDraft{}
+	if err := s.merge(ctx, draft, req); err != nil {
+		return nil, err
+	}
+	return s.complete(ctx, nil, draft)
+}
+
+func (s *onboardingService) CreateSession(ctx context.Context, req *dtos.AccountOnboardingDTO) (*dtos.OnboardingSessionDTO, error) {
+	draft := &onboardingDraft{}
+	if err := s.merge(ctx, draft, req); err != nil {
+		return nil, err
+	}
+	sections, err := validateOnboardingSections(draft)
+	if err != nil {
+		return nil, err
+	}
+
+	token, tokenHash, err := newOnboardingToken()
+	if err != nil {
+		return nil, err
+	}
+	session := &models.OnboardingSession{
+		ResumeTokenHash: tokenHash,
+		SessionStatus:   models.OnboardingStatusInProgress,
+		ExpiresAt:       time.Now().Add(s.config.SessionTTL),
+	}
+	if err := setOnboardingDraft(session, draft); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(session).Error; err != nil {
+		return nil, err
+	}
+
+	result := onboardingSessionDTO(session, draft, sections)
+	result.ResumeToken = token
+	return result, nil
+}
+
+func (s *onboardingService) GetSession(ctx context.Context, sessionID, token string) (*dtos.OnboardingSessionDTO, error) {
+	db := s.db.WithContext(ctx)
+	session, err := findOnboardingSession(db, sessionID, token, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.SessionStatus == models.OnboardingStatusCompleted {
+		result := onboardingSessionDTO(session, nil, nil)
+		result.Result, err = s.result(ctx, db, session.UserID.String, session.CustomerID.String, session.AccountID.String)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	draft, err := onboardingDraftOf(session)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := validateOnboardingSections(draft)
+	if err != nil {
+		return nil, err
+	}
+	return onboardingSessionDTO(session, draft, sections), nil
+}
+
+func (s *onboardingService) SaveSession(ctx context.Context, sessionID, token string, req *dtos.AccountOnboardingDTO) (*dtos.OnboardingSessionDTO, error) {
+	var result *dtos.OnboardingSessionDTO
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		session, err := findOnboardingSession(tx, sessionID, token, true)
+		if err != nil {
+			return err
+		}
+		if session.SessionStatus != models.OnboardingStatusInProgress {
+			return ErrOnboardingSessionCompleted
+		}
+
+		draft, err := onboardingDraftOf(session)
+		if err != nil {
+			return err
+		}
+		if err := s.merge(ctx, draft, req); err != nil {
+			return err
+		}
+		sections, err := validateOnboardingSections(draft)
+		if err != nil {
+			return err
+		}
+
+		session.ExpiresAt = time.Now().Add(s.config.SessionTTL)
+		if err := setOnboardingDraft(session, draft); err != nil {
+			return err
+		}
+		if err := tx.Model(session).
+			Select("draft", "email_hash", "expires_at").
+			Updates(session).Error; err != nil {
+			return err
+		}
+
+		result = onboardingSessionDTO(session, draft, sections)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *onboardingService) CompleteSession(ctx context.Context, sessionID, token string) (*dtos.AccountOnboardingResponseDTO, error) {
+	session, err := findOnboardingSession(s.db.WithContext(ctx), sessionID, token, false)
+	if err != nil {
+		return nil, err
+	}
+	if session.SessionStatus != models.OnboardingStatusInProgress {
+		return nil, ErrOnboardingSessionCompleted
+	}
+	draft, err := onboardingDraftOf(session)
+	if err != nil {
+		return nil, err
+	}
+	return s.complete(ctx, session, draft)
+}
+
+func (s *onboardingService) RequestResume(ctx context.Context, req *dtos.ResumeOnboardingDTO) error {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return &ValidationError{Fields: fields}
+		}
+		return err
+	}
+	emailHash, err := models.OnboardingEmailHash(req.Email)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var session models.OnboardingSession
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("email_hash = ? AND session_status = ? AND expires_at > ?", emailHash, models.OnboardingStatusInProgress, time.Now()).
+			Order("updated_at DESC").
+			First(&session).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		token, tokenHash, err := newOnboardingToken()
+		if err != nil {
+			return err
+		}
+		session.ResumeTokenHash = tokenHash
+		session.ExpiresAt = time.Now().Add(s.config.SessionTTL)
+		if err := tx.Model(&session).
+			Select("resume_token_hash", "expires_at").
+			Updates(&session).Error; err != nil {
+			return err
+		}
+
+		return s.notifications.Enqueue(ctx, tx, &NotificationRequest{
+			Template:  notifications.TemplateOnboardingResume,
+			Channels:  []string{notifications.ChannelEmail},
+			Recipient: strings.TrimSpace(req.Email),
+			Data: map[string]string{
+				"token":      token,
+				"expires_at": session.ExpiresAt.In(customerLocation(nil)).Format("02/01/2006 15:04"),
+			},
+		})
+	})
+}
+
+func (s *onboardingService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result := s.db.WithContext(ctx).
+			Where("session_status = ? AND expires_at < ?", models.OnboardingStatusInProgress, time.Now()).
+			Delete(&models.OnboardingSession{})
+		if result.Error != nil && ctx.Err() == nil {
+			log.Printf("onboarding: purge failed: %v", result.Error)
+		} else if result.RowsAffected > 0 {
+			log.Printf("onboarding: purged %d expired sessions", result.RowsAffected)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// merge copia para o rascunho as seções presentes em req. Uma senha nova é
+// cifrada e removida da seção de usuário; o endereço é completado pelo CEP.
+func (s *onboardingService) merge(ctx context.Context, draft *onboardingDraft, req *dtos.AccountOnboardingDTO) error {
+	if req == nil {
+		return nil
+	}
+	if req.User != nil {
+		user := *req.User
+		if user.Password != "" {
+			// a regra de tamanho é conferida aqui porque a senha não fica no rascunho
+			if err := validation.Validator().StructPartial(&user, "Password"); err != nil {
+				if fields := validation.FieldErrors(err); fields != nil {
+					return &ValidationError{Fields: prefixFields(onboardingSectionUser, fields)}
+				}
+				return err
+			}
+			hash, err := s.passwords.Hash(user.Password)
+			if err != nil {
+				return err
+			}
+			draft.PasswordHash = hash
+			user.Password = ""
+		}
+		draft.User = &user
+	}
+	if req.Customer != nil {
+		draft.Customer = req.Customer
+	}
+	if req.Address != nil {
+		normalized, err := s.addresses.Normalize(ctx, req.Address)
+		if err != nil {
+			return err
+		}
+		draft.Address = normalized.Address
+		draft.AddressWarnings = prefixFields(onboardingSectionAddress, normalized.Warnings)
+	}
+	if req.Account != nil {
+		draft.Account = req.Account
+	}
+	if req.KYC != nil {
+		draft.KYC = req.KYC
+	}
+	return nil
+}
+
+// validateOnboardingSections valida cada seção separadamente. Seções
+// obrigatórias ausentes têm o erro no nome da própria seção.
+func validateOnboardingSections(draft *onboardingDraft) (map[string]dtos.OnboardingSectionDTO, error) {
+	entries := []struct {
+		name     string
+		value    any
+		present  bool
+		required bool
+	}{
+		{onboardingSectionUser, draft.User, draft.User != nil, true},
+		{onboardingSectionCustomer, draft.Customer, draft.Customer != nil, true},
+		{onboardingSectionAddress, draft.Address, draft.Address != nil, true},
+		{onboardingSectionAccount, draft.Account, draft.Account != nil, true},
+		{onboardingSectionKYC, draft.KYC, draft.KYC != nil, false},
+	}
+
+	sections := make(map[string]dtos.OnboardingSectionDTO, len(entries))
+	for _, entry := range entries {
+		section := dtos.OnboardingSectionDTO{Saved: entry.present}
+		switch {
+		case entry.present:
+			var err error
+			if entry.name == onboardingSectionUser && draft.PasswordHash != "" {
+				err = validation.Validator().StructExcept(entry.value, "Password")
+			} else {
+				err = validation.Struct(entry.value)
+			}
+			if err != nil {
+				fields := validation.FieldErrors(err)
+				if fields == nil {
+					return nil, err
+				}
+				section.Errors = prefixFields(entry.name, fields)
+			}
+		case entry.required:
+			section.Errors = map[string]string{entry.name: "seção obrigatória"}
+		}
+		section.Complete = len(section.Errors) == 0
+		if entry.name == onboardingSectionAddress && len(draft.AddressWarnings) > 0 {
+			section.Warnings = draft.AddressWarnings
+		}
+		sections[entry.name] = section
+	}
+	return sections, nil
+}
+
+// onboardingErrors junta os erros de todas as seções
+func onboardingErrors(sections map[string]dtos.OnboardingSectionDTO) map[string]string {
+	fields := map[string]string{}
+	for _, section := range sections {
+		maps.Copy(fields, section.Errors)
+	}
+	return fields
+}
+
+func prefixFields(prefix string, fields map[string]string) map[string]string {
+	prefixed := make(map[string]string, len(fields))
+	for field, msg := range fields {
+		prefixed[prefix+"."+field] = msg
+	}
+	return prefixed
+}
+
+// complete cria o cadastro a partir do rascunho. Com session não nulo a
+// sessão é marcada como concluída na mesma transação.
+func (s *onboardingService) complete(ctx context.Context, session *models.OnboardingSession, draft *onboardingDraft) (*dtos.AccountOnboardingResponseDTO, error) {
+	sections, err := validateOnboardingSections(draft)
+	if err != nil {
+		return nil, err
+	}
+	if fields := onboardingErrors(sections); len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+
+	var result *dtos.AccountOnboardingResponseDTO
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if session != nil {
+			var locked models.OnboardingSession
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Select("session_id", "session_status").
+				First(&locked, "session_id = ?", session.SessionID).Error; err != nil {
+				return err
+			}
+			if locked.SessionStatus != models.OnboardingStatusInProgress {
+				return ErrOnboardingSessionCompleted
+			}
+		}
+
+		fields, err := checkOnboardingAvailability(tx, draft)
+		if err != nil {
+			return err
+		}
+		if len(fields) > 0 {
+			return &ValidationError{Fields: fields}
+		}
+
+		result, err = s.create(ctx, tx, draft)
+		if err != nil {
+			return err
+		}
+
+		if session == nil {
+			return nil
+		}
+		session.SessionStatus = models.OnboardingStatusCompleted
+		session.UserID = nullString(result.UserID)
+		session.CustomerID = nullString(result.CustomerID)
+		session.AccountID = nullString(result.AccountID)
+		session.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		// os dados já estão no cadastro; o rascunho não precisa ser mantido
+		session.Draft = "{}"
+		return tx.Model(session).
+			Select("session_status", "user_id", "customer_id", "account_id", "completed_at", "draft").
+			Updates(session).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkOnboardingAvailability confere os dados que dependem do banco: login,
+// email e documento livres e tipo de conta existente
+func checkOnboardingAvailability(tx *gorm.DB, draft *onboardingDraft) (map[string]string, error) {
+	fields := map[string]string{}
+
+	var count int64
+	if err := tx.Model(&models.User{}).Where("username = ?", strings.TrimSpace(draft.User.Username)).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		fields["user.username"] = "já está em uso"
+	}
+	if err := tx.Model(&models.User{}).Where("LOWER(email) = ?", normalizeEmail(draft.User.Email)).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		fields["user.email"] = "já está em uso"
+	}
+
+	taxIDHash, err := models.CustomerTaxIDHash(draft.Customer.TaxID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.Customer{}).Where("tax_id_hash = ?", taxIDHash).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		fields["customer.tax_id"] = "já cadastrado"
+	}
+
+	if err := tx.Model(&models.RefAccountType{}).Where("account_type_code = ?", draft.Account.AccountTypeCode).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		fields["account.account_type_code"] = "tipo de conta inexistente"
+	}
+	return fields, nil
+}
+
+// create grava cliente, usuário, endereço e conta e faz a triagem do cliente.
+// Conflitos de unicidade entre a verificação e a gravação viram erros de campo.
+func (s *onboardingService) create(ctx context.Context, tx *gorm.DB, draft *onboardingDraft) (*dtos.AccountOnboardingResponseDTO, error) {
+	customer, err := onboardingCustomer(draft)
+	if err != nil {
+		return nil, err
+	}
+	created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(customer)
+	if created.Error != nil {
+		return nil, created.Error
+	}
+	if created.RowsAffected == 0 {
+		return nil, &ValidationError{Fields: map[string]string{"customer.tax_id": "já cadastrado"}}
+	}
+
+	if company := draft.Customer.Company; company != nil {
+		if err := tx.Create(&models.CustomerCompany{
+			CustomerID:        customer.CustomerID,
+			LegalName:         company.LegalName,
+			TradeName:         nullString(company.TradeName),
+			IncorporationDate: company.IncorporationDate,
+		}).Error; err != nil {
+			return nil, err
+		}
+		for i, rep := range company.LegalRepresentatives {
+			created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.CustomerLegalRepresentative{
+				CustomerID:         customer.CustomerID,
+				RepresentativeName: rep.RepresentativeName,
+				TaxID:              taxid.OnlyDigits(rep.TaxID),
+				DateOfBirth:        rep.DateOfBirth,
+				Role:               rep.Role,
+				IsPep:              rep.IsPep,
+				Email:              nullString(rep.Email),
+			})
+			if created.Error != nil {
+				return nil, created.Error
+			}
+			if created.RowsAffected == 0 {
+				return nil, &ValidationError{Fields: map[string]string{
+					fmt.Sprintf("customer.company.legal_representatives[%d].tax_id", i): "representante repetido",
+				}}
+			}
+		}
+	}
+
+	user := &models.User{
+		CustomerID:        sql.NullString{String: customer.CustomerID, Valid: true},
+		Username:          strings.TrimSpace(draft.User.Username),
+		Email:             normalizeEmail(draft.User.Email),
+		PasswordHash:      draft.PasswordHash,
+		PasswordChangedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		UserRole:          "customer",
+		IsActive:          true,
+	}
+	created = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(user)
+	if created.Error != nil {
+		return nil, created.Error
+	}
+	if created.RowsAffected == 0 {
+		return nil, &ValidationError{Fields: map[string]string{
+			"user.username": "já está em uso",
+			"user.email":    "já está em uso",
+		}}
+	}
+
+	address := &models.Address{
+		AddressLine1: draft.Address.AddressLine1,
+		AddressLine2: nullString(draft.Address.AddressLine2),
+		Neighborhood: nullString(draft.Address.Neighborhood),
+		City:         draft.Address.City,
+		State:        draft.Address.State,
+		Country:      draft.Address.Country,
+		PostalCode:   draft.Address.PostalCode,
+	}
+	if err := tx.Create(address).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&models.CustomerAddress{
+		CustomerID:    customer.CustomerID,
+		AddressID:     address.AddressID,
+		AddressType:   draft.Address.AddressType,
+		IsPrimary:     true,
+		EffectiveFrom: time.Now(),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	account, err := s.openAccount(tx, customer.CustomerID, draft.Account)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&models.AccountStatusHistory{
+		AccountID:       account.AccountID,
+		NewStatus:       models.AccountStatusActive,
+		ChangeReason:    nullString("abertura de conta no onboarding"),
+		ChangedByUserID: sql.NullString{String: user.UserID, Valid: true},
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	screening, err := s.screening.ScreenCustomer(ctx, tx, customer.CustomerID, models.ScreeningContextOnboarding)
+	if err != nil {
+		return nil, err
+	}
+	if err := requestRiskReassessment(tx, customer.CustomerID); err != nil {
+		return nil, err
+	}
+
+	return &dtos.AccountOnboardingResponseDTO{
+		UserID:          user.UserID,
+		CustomerID:      customer.CustomerID,
+		AddressID:       address.AddressID,
+		AccountID:       account.AccountID,
+		KYCStatus:       customer.KYCStatus,
+		AccountType:     account.AccountTypeCode,
+		AgencyNum:       account.AgencyNumber,
+		AccountNum:      account.AccountNumber,
+		ScreeningStatus: screening.Status,
+		Warnings:        draft.AddressWarnings,
+	}, nil
+}
+
+// onboardingCustomer monta o cliente com os dados declarados no KYC em
+// OtherDetails. O email do cliente é o do usuário quando não informado.
+func onboardingCustomer(draft *onboardingDraft) (*models.Customer, error) {
+	req := draft.Customer
+	customer := &models.Customer{
+		CustomerType:  req.CustomerType,
+		TaxID:         taxid.NormalizeCNPJ(req.TaxID),
+		CustomerName:  req.CustomerName,
+		CustomerPhone: nullString(req.CustomerPhone),
+		CustomerEmail: nullString(normalizeEmail(req.CustomerEmail)),
+		IsPep:         req.IsPep,
+		KYCStatus:     models.KYCStatusPending,
+	}
+	if customer.CustomerType == "" {
+		customer.CustomerType = models.CustomerTypeIndividual
+	}
+	if !customer.CustomerEmail.Valid {
+		customer.CustomerEmail = nullString(normalizeEmail(draft.User.Email))
+	}
+	if customer.CustomerType == models.CustomerTypeIndividual {
+		customer.DateOfBirth = sql.NullTime{Time: req.DateOfBirth, Valid: true}
+	}
+
+	if kyc := draft.KYC; kyc != nil {
+		details := map[string]any{}
+		maps.Copy(details, kyc.OtherDetails)
+		if kyc.Occupation != "" {
+			details["occupation"] = kyc.Occupation
+		}
+		if kyc.MonthlyIncome > 0 {
+			details["monthly_income"] = kyc.MonthlyIncome
+		}
+		if kyc.Nationality != "" {
+			details["nationality"] = kyc.Nationality
+		}
+		if len(details) > 0 {
+			raw, err := json.Marshal(details)
+			if err != nil {
+				return nil, err
+			}
+			customer.OtherDetails = datatypes.JSON(raw)
+		}
+	}
+	return customer, nil
+}
+
+// openAccount abre a conta com saldo zero e um número livre, sorteado com
+// dígito verificador
+func (s *onboardingService) openAccount(tx *gorm.DB, customerID string, req *dtos.CreateAccountDTO) (*models.Account, error) {
+	agency := req.AgencyNumber
+	if agency == "" {
+		agency = s.config.DefaultAgency
+	}
+
+	for range accountNumberAttempts {
+		number, err := newAccountNumber()
+		if err != nil {
+			return nil, err
+		}
+		account := &models.Account{
+			CustomerID:      customerID,
+			AccountTypeCode: req.AccountTypeCode,
+			AccountNumber:   number,
+			AgencyNumber:    agency,
+			DateOpened:      time.Now().In(customerLocation(nil)),
+			AccountStatus:   models.AccountStatusActive,
+		}
+		created := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "account_number"}}, DoNothing: true}).Create(account)
+		if created.Error != nil {
+			return nil, created.Error
+		}
+		if created.RowsAffected == 1 {
+			return account, nil
+		}
+	}
+	return nil, errors.New("não foi possível gerar um número de conta livre")
+}
+
+// newAccountNumber sorteia oito dígitos e acrescenta o dígito verificador do
+// módulo 11 (00000000-0)
+func newAccountNumber() (string, error) {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	digits := fmt.Sprintf("%08d", binary.BigEndian.Uint32(buf)%100000000)
+
+	sum, weight := 0, 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		sum += int(digits[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	check := 11 - sum%11
+	if check >= 10 {
+		check = 0
+	}
+	return fmt.Sprintf("%s-%d", digits, check), nil
+}
+
+// result monta a resposta de um cadastro concluído
+func (s *onboardingService) result(ctx context.Context, db *gorm.DB, userID, customerID, accountID string) (*dtos.AccountOnboardingResponseDTO, error) {
+	var customer models.Customer
+	if err := db.Select("customer_id", "kyc_status").First(&customer, "customer_id = ?", customerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, err
+	}
+	var account models.Account
+	if err := db.First(&account, "account_id = ?", accountID).Error; err != nil {
+		return nil, err
+	}
+	var address models.CustomerAddress
+	if err := db.Where("customer_id = ? AND effective_to IS NULL", customerID).Order("is_primary DESC, created_at").First(&address).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	screeningStatus, err := s.screening.CustomerStatus(ctx, db, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.AccountOnboardingResponseDTO{
+		UserID:          userID,
+		CustomerID:      customerID,
+		AddressID:       address.AddressID,
+		AccountID:       account.AccountID,
+		KYCStatus:       customer.KYCStatus,
+		AccountType:     account.AccountTypeCode,
+		AgencyNum:       account.AgencyNumber,
+		AccountNum:      account.AccountNumber,
+		ScreeningStatus: screeningStatus,
+	}, nil
+}
+
+// findOnboardingSession busca a sessão pelo id e confere o token. Sessão
+// inexistente, expirada ou com token errado retornam o mesmo erro.
+func findOnboardingSession(db *gorm.DB, sessionID, token string, lock bool) (*models.OnboardingSession, error) {
+	if _, err := uuid.Parse(sessionID); err != nil || token == "" {
+		return nil, ErrOnboardingSessionNotFound
+	}
+	if lock {
+		db = db.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	var session models.OnboardingSession
+	if err := db.First(&session, "session_id = ?", sessionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOnboardingSessionNotFound
+		}
+		return nil, err
+	}
+	if !hmac.Equal([]byte(hashOnboardingToken(token)), []byte(session.ResumeTokenHash)) {
+		return nil, ErrOnboardingSessionNotFound
+	}
+	if session.SessionStatus == models.OnboardingStatusInProgress && time.Now().After(session.ExpiresAt) {
+		return nil, ErrOnboardingSessionNotFound
+	}
+	return &session, nil
+}
+
+func onboardingDraftOf(session *models.OnboardingSession) (*onboardingDraft, error) {
+	draft := &onboardingDraft{}
+	if session.Draft == "" {
+		return draft, nil
+	}
+	if err := json.Unmarshal([]byte(session.Draft), draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// setOnboardingDraft grava o rascunho na sessão e atualiza o índice do email
+func setOnboardingDraft(session *models.OnboardingSession, draft *onboardingDraft) error {
+	raw, err := json.Marshal(draft)
+	if err != nil {
+		return err
+	}
+	session.Draft = string(raw)
+
+	if draft.User != nil && draft.User.Email != "" {
+		hash, err := models.OnboardingEmailHash(draft.User.Email)
+		if err != nil {
+			return err
+		}
+		session.EmailHash = sql.NullString{String: hash, Valid: true}
+	}
+	return nil
+}
+
+func onboardingSessionDTO(session *models.OnboardingSession, draft *onboardingDraft, sections map[string]dtos.OnboardingSectionDTO) *dtos.OnboardingSessionDTO {
+	result := &dtos.OnboardingSessionDTO{
+		SessionID: session.SessionID,
+		Status:    session.SessionStatus,
+		Sections:  sections,
+		ExpiresAt: session.ExpiresAt,
+		UpdatedAt: session.UpdatedAt,
+	}
+	if draft != nil {
+		result.Draft = &draft.AccountOnboardingDTO
+	}
+	return result
+}
+
+// newOnboardingToken gera o token de retomada e o hash guardado na sessão
+func newOnboardingToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(buf)
+	return token, hashOnboardingToken(token), nil
+}
+
+func hashOnboardingToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOneTimeCodeInvalid  = fmt.Errorf("%w: código de verificação inválido", ErrInvalidInput)
+	ErrOneTimeCodeExpired  = fmt.Errorf("%w: código de verificação expirado, solicite um novo", ErrInvalidInput)
+	ErrOneTimeCodeAttempts = fmt.Errorf("%w: tentativas esgotadas, solicite um novo código", ErrForbidden)
+	ErrOneTimeCodeCooldown = fmt.Errorf("%w: aguarde para solicitar um novo código", ErrConflict)
+)
+
+// issueOneTimeCode grava um código novo para code.Purpose e code.Reference,
+// invalidando os anteriores ainda não usados, e retorna o código em claro
+// para envio
+func issueOneTimeCode(tx *gorm.DB, code *models.OneTimeCode, ttl time.Duration) (string, error) {
+	now := time.Now()
+	if err := tx.Model(&models.OneTimeCode{}).
+		Where("purpose = ? AND reference = ? AND consumed_at IS NULL AND expires_at > ?", code.Purpose, code.Reference, now).
+		Update("expires_at", now).Error; err != nil {
+		return "", err
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	plain := fmt.Sprintf("%06d", n.Int64())
+
+	// o hash depende do id, que precisa existir antes da gravação
+	if err := code.BeforeCreate(tx); err != nil {
+		return "", err
+	}
+	hash, err := models.OneTimeCodeHash(code.CodeID, plain)
+	if err != nil {
+		return "", err
+	}
+	code.CodeHash = hash
+	code.ExpiresAt = now.Add(ttl)
+	if err := tx.Create(code).Error; err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// verifyOneTimeCode confere o código mais recente de purpose e reference e o
+// marca como usado. Uma tentativa errada é contada na transação tx, então o
+// chamador deve confirmá-la mesmo quando o erro for ErrOneTimeCodeInvalid.
+func verifyOneTimeCode(tx *gorm.DB, purpose, reference, plain string) error {
+	var code models.OneTimeCode
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("purpose = ? AND reference = ? AND consumed_at IS NULL", purpose, reference).
+		Order("created_at DESC").
+		First(&code).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrOneTimeCodeExpired
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if !now.Before(code.ExpiresAt) {
+		return ErrOneTimeCodeExpired
+	}
+	if code.Attempts >= code.MaxAttempts {
+		return ErrOneTimeCodeAttempts
+	}
+
+	hash, err := models.OneTimeCodeHash(code.CodeID, plain)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(hash), []byte(code.CodeHash)) {
+		if err := tx.Model(&code).UpdateColumn("attempts", gorm.Expr("attempts + 1")).Error; err != nil {
+			return err
+		}
+		if code.Attempts+1 >= code.MaxAttempts {
+			return ErrOneTimeCodeAttempts
+		}
+		return ErrOneTimeCodeInvalid
+	}
+
+	return tx.Model(&code).UpdateColumn("consumed_at", sql.NullTime{Time: now, Valid: true}).Error
+}
+
+// oneTimeCodeResendAt retorna a partir de quando um novo código pode ser
+// emitido para purpose e reference, contando cooldown desde o último emitido
+func oneTimeCodeResendAt(tx *gorm.DB, purpose, reference string, cooldown time.Duration) (time.Time, error) {
+	var last models.OneTimeCode
+	err := tx.Select("created_at").
+		Where("purpose = ? AND reference = ?", purpose, reference).
+		Order("created_at DESC").
+		First(&last).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return last.CreatedAt.Add(cooldown), nil
+}
+
+// isOneTimeCodeError indica os erros de verifyOneTimeCode causados pelo
+// código informado, cuja tentativa deve ser confirmada pelo chamador
+func isOneTimeCodeError(err error) bool {
+	return errors.Is(err, ErrOneTimeCodeInvalid) || errors.Is(err, ErrOneTimeCodeExpired) || errors.Is(err, ErrOneTimeCodeAttempts)
+}
@@ -0,0 +1,791 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrBatchNotFound        = fmt.Errorf("%w: lote de pagamentos", ErrNotFound)
+	ErrBatchEmptyFile       = fmt.Errorf("%w: arquivo sem linhas de pagamento", ErrInvalidInput)
+	ErrBatchTooManyRows     = fmt.Errorf("%w: arquivo excede o número máximo de linhas", ErrInvalidInput)
+	ErrBatchMissingColumns  = fmt.Errorf("%w: cabeçalho sem colunas obrigatórias", ErrInvalidInput)
+	ErrBatchDuplicateFile   = fmt.Errorf("%w: arquivo já enviado em outro lote ativo", ErrConflict)
+	ErrBatchInvalidStatus   = fmt.Errorf("%w: operação não permitida no status atual do lote", ErrConflict)
+	ErrBatchNoValidRows     = fmt.Errorf("%w: lote não possui linhas válidas", ErrConflict)
+	ErrBatchSelfApproval    = fmt.Errorf("%w: o lote deve ser aprovado por outro usuário", ErrForbidden)
+	batchRequiredColumns    = []string{"transaction_type_code", "account_id_origin", "amount"}
+	batchResultsCSVHeader   = []string{"line_number", "status", "transaction_type_code", "account_id_origin", "account_id_dest", "amount", "idempotency_key", "transaction_id", "balance_after", "error"}
+	batchStaleProcessingAge = 5 * time.Minute
+)
+
+type PaymentBatchService interface {
+	// Upload lê o CSV, valida cada linha com as regras de TransactionRequestDTO
+	// e grava o lote aguardando aprovação
+	Upload(ctx context.Context, userID, fileName string, file io.Reader) (*dtos.PaymentBatchPreviewDTO, error)
+
+	// Get retorna o resumo e o progresso do lote
+	Get(ctx context.Context, userID, batchID string) (*dtos.PaymentBatchSummaryDTO, error)
+
+	// ListRows retorna as linhas do lote com os erros de validação e execução
+	ListRows(ctx context.Context, userID, batchID string, req *dtos.PaymentBatchRowsRequestDTO) (*dtos.PaymentBatchPreviewDTO, error)
+
+	// Approve libera o lote para execução assíncrona
+	Approve(ctx context.Context, userID, batchID string) (*dtos.PaymentBatchSummaryDTO, error)
+
+	// Cancel cancela o lote. Linhas ainda não executadas são ignoradas.
+	Cancel(ctx context.Context, userID, batchID string) (*dtos.PaymentBatchSummaryDTO, error)
+
+	// WriteResultsCSV escreve o resultado linha a linha do lote em CSV
+	WriteResultsCSV(ctx context.Context, userID, batchID string, w io.Writer) error
+
+	// ProcessNext executa o próximo lote aprovado. Retorna false se não havia lote.
+	ProcessNext(ctx context.Context) (bool, error)
+
+	// Run processa lotes aprovados até o contexto ser cancelado
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// PaymentBatchConfig contém as configurações do processamento de lotes
+type PaymentBatchConfig struct {
+	MaxRows             int
+	RequireDualApproval bool
+}
+
+type paymentBatchService struct {
+	db                  *gorm.DB
+	transactions        MakeTransactionService
+	maxRows             int
+	requireDualApproval bool
+}
+
+func NewPaymentBatchService(db *gorm.DB, transactions MakeTransactionService, cfg *PaymentBatchConfig) PaymentBatchService {
+	if cfg == nil {
+		cfg = &PaymentBatchConfig{}
+	}
+	if cfg.MaxRows == 0 {
+		cfg.MaxRows = config.GetEnvInt("PAYMENT_BATCH_MAX_ROWS", 5000)
+	}
+	if !cfg.RequireDualApproval {
+		cfg.RequireDualApproval = config.GetEnvBool("PAYMENT_BATCH_DUAL_APPROVAL", false)
+	}
+
+	return &paymentBatchService{
+		db:                  db,
+		transactions:        transactions,
+		maxRows:             cfg.MaxRows,
+		requireDualApproval: cfg.RequireDualApproval,
+	}
+}
+
+func (s *paymentBatchService) Upload(ctx context.Context, userID, fileName string, file io.Reader) (*dtos.PaymentBatchPreviewDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	fileHash := hex.EncodeToString(sum[:])
+
+	duplicated, err := s.activeBatchWithHash(ctx, customerID, fileHash)
+	if err != nil {
+		return nil, err
+	}
+	if duplicated {
+		return nil, ErrBatchDuplicateFile
+	}
+
+	records, err := readBatchCSV(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, ErrBatchEmptyFile
+	}
+	if len(records)-1 > s.maxRows {
+		return nil, fmt.Errorf("%w (máximo %d)", ErrBatchTooManyRows, s.maxRows)
+	}
+
+	columns, err := batchColumnIndex(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var ownedAccounts []string
+	if err := s.db.WithContext(ctx).Model(&models.Account{}).
+		Where("customer_id = ?", customerID).
+		Pluck("account_id", &ownedAccounts).Error; err != nil {
+		return nil, err
+	}
+
+	batch := &models.PaymentBatch{
+		CustomerID:      customerID,
+		CreatedByUserID: userID,
+		FileName:        fileName,
+		FileSHA256:      fileHash,
+		BatchStatus:     models.PaymentBatchStatusValidated,
+	}
+	// o ID é gerado antes para compor as chaves de idempotência das linhas
+	batch.BatchID = uuid.New().String()
+
+	rows := make([]models.PaymentBatchRow, 0, len(records)-1)
+	seenKeys := make(map[string]int, len(records)-1)
+	for i, record := range records[1:] {
+		// linha 1 é o cabeçalho
+		line := i + 2
+		row, fieldErrors := parseBatchRow(batch.BatchID, line, columns, record)
+
+		if row.AccountIDOrigin != "" && !slices.Contains(ownedAccounts, row.AccountIDOrigin) {
+			fieldErrors["account_id_origin"] = "conta de origem não pertence ao cliente"
+		}
+		if row.AccountIDDest.Valid && row.AccountIDDest.String == row.AccountIDOrigin {
+			fieldErrors["account_id_dest"] = "conta de destino igual à conta de origem"
+		}
+		if previous, ok := seenKeys[row.IdempotencyKey]; ok {
+			fieldErrors["idempotency_key"] = fmt.Sprintf("chave repetida na linha %d", previous)
+		}
+		seenKeys[row.IdempotencyKey] = line
+
+		applyRowErrors(&row, fieldErrors)
+		rows = append(rows, row)
+	}
+
+	if err := s.flagUsedIdempotencyKeys(ctx, rows); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		batch.TotalRows++
+		if row.RowStatus == models.PaymentBatchRowStatusValid {
+			batch.ValidRows++
+			batch.TotalAmount += row.Amount
+		} else {
+			batch.InvalidRows++
+		}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	previewRows := rows
+	if len(previewRows) > 100 {
+		previewRows = previewRows[:100]
+	}
+	preview := &dtos.PaymentBatchPreviewDTO{
+		Batch:      batchSummary(batch),
+		Rows:       make([]dtos.PaymentBatchRowDTO, 0, len(previewRows)),
+		TotalCount: len(rows),
+		Limit:      len(previewRows),
+	}
+	for i := range previewRows {
+		preview.Rows = append(preview.Rows, batchRowDTO(&previewRows[i]))
+	}
+	return preview, nil
+}
+
+func (s *paymentBatchService) Get(ctx context.Context, userID, batchID string) (*dtos.PaymentBatchSummaryDTO, error) {
+	batch, err := s.findOwnedBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	summary := batchSummary(batch)
+	return &summary, nil
+}
+
+func (s *paymentBatchService) ListRows(ctx context.Context, userID, batchID string, req *dtos.PaymentBatchRowsRequestDTO) (*dtos.PaymentBatchPreviewDTO, error) {
+	batch, err := s.findOwnedBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.PaymentBatchRow{}).Where("batch_id = ?", batch.BatchID)
+	if req.OnlyInvalid {
+		query = query.Where("row_status IN ?", []string{models.PaymentBatchRowStatusInvalid, models.PaymentBatchRowStatusFailed})
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []models.PaymentBatchRow
+	if err := query.Order("line_number").Limit(limit).Offset(req.Offset).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	preview := &dtos.PaymentBatchPreviewDTO{
+		Batch:      batchSummary(batch),
+		Rows:       make([]dtos.PaymentBatchRowDTO, 0, len(rows)),
+		TotalCount: int(total),
+		Limit:      limit,
+		Offset:     req.Offset,
+	}
+	for i := range rows {
+		preview.Rows = append(preview.Rows, batchRowDTO(&rows[i]))
+	}
+	return preview, nil
+}
+
+func (s *paymentBatchService) Approve(ctx context.Context, userID, batchID string) (*dtos.PaymentBatchSummaryDTO, error) {
+	batch, err := s.findOwnedBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if s.requireDualApproval && batch.CreatedByUserID == userID {
+		return nil, ErrBatchSelfApproval
+	}
+	if batch.ValidRows == 0 {
+		return nil, ErrBatchNoValidRows
+	}
+
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&models.PaymentBatch{}).
+		Where("batch_id = ? AND batch_status = ?", batch.BatchID, models.PaymentBatchStatusValidated).
+		Updates(map[string]interface{}{
+			"batch_status":        models.PaymentBatchStatusApproved,
+			"approved_by_user_id": userID,
+			"approved_at":         now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrBatchInvalidStatus
+	}
+
+	return s.Get(ctx, userID, batchID)
+}
+
+func (s *paymentBatchService) Cancel(ctx context.Context, userID, batchID string) (*dtos.PaymentBatchSummaryDTO, error) {
+	batch, err := s.findOwnedBatch(ctx, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.db.WithContext(ctx).Model(&models.PaymentBatch{}).
+		Where("batch_id = ? AND batch_status IN ?", batch.BatchID, []string{
+			models.PaymentBatchStatusValidated,
+			models.PaymentBatchStatusApproved,
+			models.PaymentBatchStatusProcessing,
+		}).
+		Updates(map[string]interface{}{
+			"batch_status": models.PaymentBatchStatusCancelled,
+			"finished_at":  time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrBatchInvalidStatus
+	}
+
+	return s.Get(ctx, userID, batchID)
+}
+
+func (s *paymentBatchService) WriteResultsCSV(ctx context.Context, userID, batchID string, w io.Writer) error {
+	batch, err := s.findOwnedBatch(ctx, userID, batchID)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(batchResultsCSVHeader); err != nil {
+		return err
+	}
+
+	var rows []models.PaymentBatchRow
+	err = s.db.WithContext(ctx).
+		Where("batch_id = ?", batch.BatchID).
+		Order("line_number").
+		FindInBatches(&rows, 500, func(_ *gorm.DB, _ int) error {
+			for i := range rows {
+				if err := writer.Write(batchRowCSVRecord(&rows[i])); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *paymentBatchService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			processed, err := s.ProcessNext(ctx)
+			if err != nil {
+				log.Printf("payment batch: %v", err)
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *paymentBatchService) ProcessNext(ctx context.Context) (bool, error) {
+	batch, err := s.claimBatch(ctx)
+	if err != nil || batch == nil {
+		return false, err
+	}
+
+	var rows []models.PaymentBatchRow
+	if err := s.db.WithContext(ctx).
+		Where("batch_id = ? AND row_status = ?", batch.BatchID, models.PaymentBatchRowStatusValid).
+		Order("line_number").
+		Find(&rows).Error; err != nil {
+		return true, err
+	}
+
+	executor := batch.CreatedByUserID
+	if batch.ApprovedByUserID.Valid {
+		executor = batch.ApprovedByUserID.String
+	}
+	txCtx := WithTransactionSource(ctx, "BATCH")
+
+	for i := range rows {
+		if ctx.Err() != nil {
+			return true, ctx.Err()
+		}
+
+		cancelled, err := s.isCancelled(ctx, batch.BatchID)
+		if err != nil {
+			return true, err
+		}
+		if cancelled {
+			return true, s.skipRemainingRows(ctx, batch.BatchID)
+		}
+
+		response, execErr := s.transactions.Execute(txCtx, executor, batchRowRequest(&rows[i]))
+		if execErr != nil && ctx.Err() != nil {
+			return true, ctx.Err()
+		}
+		if err := s.recordRowResult(ctx, &rows[i], response, execErr); err != nil {
+			return true, err
+		}
+	}
+
+	err = s.db.WithContext(ctx).Model(&models.PaymentBatch{}).
+		Where("batch_id = ? AND batch_status = ?", batch.BatchID, models.PaymentBatchStatusProcessing).
+		Updates(map[string]interface{}{
+			"batch_status": models.PaymentBatchStatusCompleted,
+			"finished_at":  time.Now(),
+		}).Error
+	return true, err
+}
+
+// claimBatch reserva um lote aprovado (ou um lote em processamento abandonado
+// por outra instância). SKIP LOCKED permite várias instâncias do worker.
+func (s *paymentBatchService) claimBatch(ctx context.Context) (*models.PaymentBatch, error) {
+	var batch models.PaymentBatch
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("batch_status = ? OR (batch_status = ? AND updated_at < ?)",
+				models.PaymentBatchStatusApproved,
+				models.PaymentBatchStatusProcessing,
+				time.Now().Add(-batchStaleProcessingAge)).
+			Order("approved_at").
+			First(&batch).Error
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"batch_status": models.PaymentBatchStatusProcessing,
+			"updated_at":   time.Now(),
+		}
+		if !batch.StartedAt.Valid {
+			updates["started_at"] = time.Now()
+		}
+		return tx.Model(&batch).Updates(updates).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (s *paymentBatchService) recordRowResult(ctx context.Context, row *models.PaymentBatchRow, response *dtos.TransactionResponseDTO, execErr error) error {
+	now := time.Now()
+	rowUpdates := map[string]interface{}{"processed_at": now}
+	batchUpdates := map[string]interface{}{
+		"processed_rows": gorm.Expr("processed_rows + 1"),
+		"updated_at":     now,
+	}
+
+	if execErr != nil {
+		rowUpdates["row_status"] = models.PaymentBatchRowStatusFailed
+		rowUpdates["error_message"] = truncate(execErr.Error(), 500)
+		batchUpdates["failed_rows"] = gorm.Expr("failed_rows + 1")
+	} else if response.TransactionStatus == models.TransactionStatusOnHold {
+		// retida pela análise de risco: não conta como sucesso nem falha
+		rowUpdates["row_status"] = models.PaymentBatchRowStatusOnHold
+		rowUpdates["transaction_id"] = response.TransactionID
+	} else {
+		rowUpdates["row_status"] = models.PaymentBatchRowStatusSucceeded
+		rowUpdates["transaction_id"] = response.TransactionID
+		if response.BalanceAfter != nil {
+			rowUpdates["balance_after"] = *response.BalanceAfter
+		}
+		batchUpdates["succeeded_rows"] = gorm.Expr("succeeded_rows + 1")
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(row).Updates(rowUpdates).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.PaymentBatch{}).Where("batch_id = ?", row.BatchID).Updates(batchUpdates).Error
+	})
+}
+
+func (s *paymentBatchService) isCancelled(ctx context.Context, batchID string) (bool, error) {
+	var status string
+	err := s.db.WithContext(ctx).Model(&models.PaymentBatch{}).
+		Where("batch_id = ?", batchID).
+		Pluck("batch_status", &status).Error
+	return status == models.PaymentBatchStatusCancelled, err
+}
+
+func (s *paymentBatchService) skipRemainingRows(ctx context.Context, batchID string) error {
+	return s.db.WithContext(ctx).Model(&models.PaymentBatchRow{}).
+		Where("batch_id = ? AND row_status = ?", batchID, models.PaymentBatchRowStatusValid).
+		Update("row_status", models.PaymentBatchRowStatusSkipped).Error
+}
+
+func (s *paymentBatchService) findOwnedBatch(ctx context.Context, userID, batchID string) (*models.PaymentBatch, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch models.PaymentBatch
+	err = s.db.WithContext(ctx).First(&batch, "batch_id = ? AND customer_id = ?", batchID, customerID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBatchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (s *paymentBatchService) activeBatchWithHash(ctx context.Context, customerID, fileHash string) (bool, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.PaymentBatch{}).
+		Where("customer_id = ? AND file_sha256 = ? AND batch_status <> ?", customerID, fileHash, models.PaymentBatchStatusCancelled).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// flagUsedIdempotencyKeys invalida linhas cuja chave já foi usada em uma
+// transação ou em outro lote
+func (s *paymentBatchService) flagUsedIdempotencyKeys(ctx context.Context, rows []models.PaymentBatchRow) error {
+	keys := make([]string, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, row.IdempotencyKey)
+	}
+
+	used := make(map[string]bool)
+	for start := 0; start < len(keys); start += 1000 {
+		end := min(start+1000, len(keys))
+
+		var found []string
+		if err := s.db.WithContext(ctx).Model(&models.Transaction{}).
+			Where("idempotency_key IN ?", keys[start:end]).
+			Pluck("idempotency_key", &found).Error; err != nil {
+			return err
+		}
+		var inBatches []string
+		if err := s.db.WithContext(ctx).Model(&models.PaymentBatchRow{}).
+			Where("idempotency_key IN ?", keys[start:end]).
+			Pluck("idempotency_key", &inBatches).Error; err != nil {
+			return err
+		}
+		for _, key := range append(found, inBatches...) {
+			used[key] = true
+		}
+	}
+
+	for i := range rows {
+		if !used[rows[i].IdempotencyKey] {
+			continue
+		}
+		fieldErrors := map[string]string{}
+		if len(rows[i].ValidationErrors) > 0 {
+			_ = json.Unmarshal(rows[i].ValidationErrors, &fieldErrors)
+		}
+		fieldErrors["idempotency_key"] = "chave de idempotência já utilizada"
+		applyRowErrors(&rows[i], fieldErrors)
+	}
+	return nil
+}
+
+func applyRowErrors(row *models.PaymentBatchRow, fieldErrors map[string]string) {
+	if len(fieldErrors) == 0 {
+		row.RowStatus = models.PaymentBatchRowStatusValid
+		row.ValidationErrors = nil
+		return
+	}
+	encoded, _ := json.Marshal(fieldErrors)
+	row.RowStatus = models.PaymentBatchRowStatusInvalid
+	row.ValidationErrors = datatypes.JSON(encoded)
+}
+
+// readBatchCSV detecta o separador (vírgula ou ponto e vírgula) pelo cabeçalho
+func readBatchCSV(content []byte) ([][]string, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	firstLine, _ := bufio.NewReader(bytes.NewReader(content)).ReadString('\n')
+	reader := csv.NewReader(bytes.NewReader(content))
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: CSV malformado: %v", ErrInvalidInput, err)
+	}
+	return records, nil
+}
+
+func batchColumnIndex(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var missing []string
+	for _, required := range batchRequiredColumns {
+		if _, ok := columns[required]; !ok {
+			missing = append(missing, required)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrBatchMissingColumns, strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+// parseBatchRow converte uma linha do CSV e aplica as mesmas regras de
+// validação de TransactionRequestDTO
+func parseBatchRow(batchID string, line int, columns map[string]int, record []string) (models.PaymentBatchRow, map[string]string) {
+	field := func(name string) string {
+		if idx, ok := columns[name]; ok && idx < len(record) {
+			return strings.TrimSpace(record[idx])
+		}
+		return ""
+	}
+
+	fieldErrors := map[string]string{}
+	req := dtos.TransactionRequestDTO{
+		TransactionTypeCode: strings.ToUpper(field("transaction_type_code")),
+		AccountIDOrigin:     field("account_id_origin"),
+		AccountIDDest:       field("account_id_dest"),
+		Description:         field("description"),
+		IdempotencyKey:      field("idempotency_key"),
+		ExternalReference:   field("external_reference"),
+	}
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = fmt.Sprintf("batch-%s-%d", batchID, line)
+	}
+
+	amount, err := parseBatchAmount(field("amount"))
+	if err != nil {
+		fieldErrors["amount"] = "valor numérico inválido"
+	}
+	req.Amount = amount
+
+	if err := validation.Struct(&req); err != nil {
+		for name, msg := range validation.FieldErrors(err) {
+			if _, exists := fieldErrors[name]; !exists {
+				fieldErrors[name] = msg
+			}
+		}
+	}
+
+	row := models.PaymentBatchRow{
+		BatchID:             batchID,
+		LineNumber:          line,
+		TransactionTypeCode: truncate(req.TransactionTypeCode, 20),
+		AccountIDOrigin:     truncate(req.AccountIDOrigin, 36),
+		Amount:              req.Amount,
+		IdempotencyKey:      truncate(req.IdempotencyKey, 100),
+	}
+	if req.AccountIDDest != "" {
+		row.AccountIDDest = sql.NullString{String: truncate(req.AccountIDDest, 36), Valid: true}
+	}
+	if req.Description != "" {
+		row.Description = sql.NullString{String: truncate(req.Description, 500), Valid: true}
+	}
+	if req.ExternalReference != "" {
+		row.ExternalReference = sql.NullString{String: truncate(req.ExternalReference, 100), Valid: true}
+	}
+	return row, fieldErrors
+}
+
+// parseBatchAmount aceita "1234.56" e o formato brasileiro "1.234,56"
+func parseBatchAmount(value string) (float64, error) {
+	if strings.Contains(value, ",") {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
+
+func batchRowRequest(row *models.PaymentBatchRow) *dtos.TransactionRequestDTO {
+	return &dtos.TransactionRequestDTO{
+		TransactionTypeCode: row.TransactionTypeCode,
+		AccountIDOrigin:     row.AccountIDOrigin,
+		AccountIDDest:       row.AccountIDDest.String,
+		Amount:              row.Amount,
+		Description:         row.Description.String,
+		IdempotencyKey:      row.IdempotencyKey,
+		ExternalReference:   row.ExternalReference.String,
+	}
+}
+
+func batchSummary(batch *models.PaymentBatch) dtos.PaymentBatchSummaryDTO {
+	summary := dtos.PaymentBatchSummaryDTO{
+		BatchID:       batch.BatchID,
+		FileName:      batch.FileName,
+		Status:        batch.BatchStatus,
+		TotalRows:     batch.TotalRows,
+		ValidRows:     batch.ValidRows,
+		InvalidRows:   batch.InvalidRows,
+		ProcessedRows: batch.ProcessedRows,
+		SucceededRows: batch.SucceededRows,
+		FailedRows:    batch.FailedRows,
+		TotalAmount:   batch.TotalAmount,
+		CreatedAt:     batch.CreatedAt,
+		ApprovedAt:    nullTimePtr(batch.ApprovedAt),
+		StartedAt:     nullTimePtr(batch.StartedAt),
+		FinishedAt:    nullTimePtr(batch.FinishedAt),
+	}
+	if batch.ValidRows > 0 {
+		summary.ProgressPercent = float64(batch.ProcessedRows) / float64(batch.ValidRows) * 100
+	}
+	return summary
+}
+
+func batchRowDTO(row *models.PaymentBatchRow) dtos.PaymentBatchRowDTO {
+	dto := dtos.PaymentBatchRowDTO{
+		LineNumber:          row.LineNumber,
+		Status:              row.RowStatus,
+		TransactionTypeCode: row.TransactionTypeCode,
+		AccountIDOrigin:     row.AccountIDOrigin,
+		AccountIDDest:       row.AccountIDDest.String,
+		Amount:              row.Amount,
+		Description:         row.Description.String,
+		IdempotencyKey:      row.IdempotencyKey,
+		TransactionID:       row.TransactionID.String,
+		ErrorMessage:        row.ErrorMessage.String,
+	}
+	if len(row.ValidationErrors) > 0 {
+		_ = json.Unmarshal(row.ValidationErrors, &dto.Errors)
+	}
+	return dto
+}
+
+func batchRowCSVRecord(row *models.PaymentBatchRow) []string {
+	message := row.ErrorMessage.String
+	if row.RowStatus == models.PaymentBatchRowStatusInvalid {
+		message = string(row.ValidationErrors)
+	}
+
+	balance := ""
+	if row.BalanceAfter.Valid {
+		balance = strconv.FormatFloat(row.BalanceAfter.Float64, 'f', 2, 64)
+	}
+
+	return []string{
+		strconv.Itoa(row.LineNumber),
+		row.RowStatus,
+		row.TransactionTypeCode,
+		row.AccountIDOrigin,
+		row.AccountIDDest.String,
+		strconv.FormatFloat(row.Amount, 'f', 2, 64),
+		row.IdempotencyKey,
+		row.TransactionID.String,
+		balance,
+		message,
+	}
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	value := t.Time
+	return &value
+}
+
+// truncate corta o texto em max caracteres sem quebrar runas multibyte
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+)
+
+// privacyExportFormat é a versão do formato do arquivo de exportação,
+// gravada no manifesto
+const privacyExportFormat = 1
+
+// privacyExportBatch é o tamanho dos lotes lidos nas tabelas de histórico
+const privacyExportBatch = 500
+
+type privacyExportManifest struct {
+	FormatVersion int            `json:"format_version"`
+	RequestID     string         `json:"request_id"`
+	CustomerID    string         `json:"customer_id"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+	Files         map[string]int `json:"files"`
+}
+
+// privacyExportCustomer e privacyExportUser deixam de fora os índices cegos,
+// o hash da senha e o segredo do 2FA, que não são dados do titular
+type privacyExportCustomer struct {
+	CustomerID           string                               `json:"customer_id"`
+	CustomerType         string                               `json:"customer_type"`
+	TaxID                string                               `json:"tax_id"`
+	CustomerName         string                               `json:"customer_name"`
+	CustomerPhone        string                               `json:"customer_phone,omitempty"`
+	CustomerEmail        string                               `json:"customer_email,omitempty"`
+	DateOfBirth          *time.Time                           `json:"date_of_birth,omitempty"`
+	DateRegistered       time.Time                            `json:"date_registered"`
+	IsPep                bool                                 `json:"is_pep"`
+	KYCStatus            string                               `json:"kyc_status"`
+	KYCVerifiedAt        *time.Time                           `json:"kyc_verified_at,omitempty"`
+	RiskRating           string                               `json:"risk_rating,omitempty"`
+	RiskAssessedAt       *time.Time                           `json:"risk_assessed_at,omitempty"`
+	OtherDetails         json.RawMessage                      `json:"other_details,omitempty"`
+	Timezone             string                               `json:"timezone"`
+	Locale               string                               `json:"locale"`
+	AnonymizedAt         *time.Time                           `json:"anonymized_at,omitempty"`
+	Company              *models.CustomerCompany              `json:"company,omitempty"`
+	LegalRepresentatives []models.CustomerLegalRepresentative `json:"legal_representatives,omitempty"`
+}
+
+type privacyExportUser struct {
+	UserID            string     `json:"user_id"`
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	UserRole          string     `json:"user_role"`
+	IsActive          bool       `json:"is_active"`
+	IsLocked          bool       `json:"is_locked"`
+	TwoFactorEnabled  bool       `json:"two_factor_enabled"`
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+	LastLogin         *time.Time `json:"last_login,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// writeExport grava em w o arquivo zip com um JSON por entidade: cadastro,
+// usuários, endereços (com o histórico), contas, transações, acessos e
+// auditoria, além do manifesto com a contagem de registros de cada arquivo
+func (s *privacyService) writeExport(tx *gorm.DB, requestID string, customer *models.Customer, now time.Time, w io.Writer) error {
+	manifest := privacyExportManifest{
+		FormatVersion: privacyExportFormat,
+		RequestID:     requestID,
+		CustomerID:    customer.CustomerID,
+		GeneratedAt:   now.UTC(),
+		Files:         map[string]int{},
+	}
+	zw := zip.NewWriter(w)
+
+	exported := privacyExportCustomer{
+		CustomerID:     customer.CustomerID,
+		CustomerType:   customer.CustomerType,
+		TaxID:          customer.TaxID,
+		CustomerName:   customer.CustomerName,
+		CustomerPhone:  customer.CustomerPhone.String,
+		CustomerEmail:  customer.CustomerEmail.String,
+		DateOfBirth:    nullTimePtr(customer.DateOfBirth),
+		DateRegistered: customer.DateRegistered,
+		IsPep:          customer.IsPep,
+		KYCStatus:      customer.KYCStatus,
+		KYCVerifiedAt:  nullTimePtr(customer.KYCVerifiedAt),
+		RiskRating:     customer.RiskRating.String,
+		RiskAssessedAt: nullTimePtr(customer.RiskAssessedAt),
+		OtherDetails:   json.RawMessage(customer.OtherDetails),
+		Timezone:       customer.Timezone,
+		Locale:         customer.Locale,
+		AnonymizedAt:   nullTimePtr(customer.AnonymizedAt),
+	}
+	if customer.CustomerType == models.CustomerTypeBusiness {
+		var company models.CustomerCompany
+		if err := tx.Where("customer_id = ?", customer.CustomerID).Limit(1).Find(&company).Error; err != nil {
+			return err
+		}
+		if company.CustomerID != "" {
+			exported.Company = &company
+		}
+		if err := tx.Where("customer_id = ?", customer.CustomerID).Order("created_at").
+			Find(&exported.LegalRepresentatives).Error; err != nil {
+			return err
+		}
+	}
+	if err := writeExportJSON(zw, "customer.json", exported); err != nil {
+		return err
+	}
+	manifest.Files["customer.json"] = 1
+
+	var users []models.User
+	if err := tx.Where("customer_id = ?", customer.CustomerID).Order("created_at").Find(&users).Error; err != nil {
+		return err
+	}
+	userIDs := make([]string, 0, len(users))
+	exportedUsers := make([]privacyExportUser, 0, len(users))
+	for i := range users {
+		userIDs = append(userIDs, users[i].UserID)
+		exportedUsers = append(exportedUsers, privacyExportUser{
+			UserID:            users[i].UserID,
+			Username:          users[i].Username,
+			Email:             users[i].Email,
+			UserRole:          users[i].UserRole,
+			IsActive:          users[i].IsActive,
+			IsLocked:          users[i].IsLocked,
+			TwoFactorEnabled:  users[i].TwoFactorEnabled,
+			PasswordChangedAt: nullTimePtr(users[i].PasswordChangedAt),
+			LastLogin:         nullTimePtr(users[i].LastLogin),
+			CreatedAt:         users[i].CreatedAt,
+		})
+	}
+	if err := writeExportJSON(zw, "users.json", exportedUsers); err != nil {
+		return err
+	}
+	manifest.Files["users.json"] = len(exportedUsers)
+
+	var addresses []models.CustomerAddress
+	if err := tx.Preload("Address").Where("customer_id = ?", customer.CustomerID).
+		Order("effective_from").Find(&addresses).Error; err != nil {
+		return err
+	}
+	if err := writeExportJSON(zw, "addresses.json", addresses); err != nil {
+		return err
+	}
+	manifest.Files["addresses.json"] = len(addresses)
+
+	var accounts []models.Account
+	if err := tx.Preload("StatusHistory").Where("customer_id = ?", customer.CustomerID).Find(&accounts).Error; err != nil {
+		return err
+	}
+	accountIDs := make([]string, 0, len(accounts))
+	for i := range accounts {
+		accountIDs = append(accountIDs, accounts[i].AccountID)
+	}
+	if err := writeExportJSON(zw, "accounts.json", accounts); err != nil {
+		return err
+	}
+	manifest.Files["accounts.json"] = len(accounts)
+
+	n, err := writeExportRows[models.Transaction](zw, "transactions.json",
+		tx.Where("account_id_origin IN ? OR account_id_dest IN ?", accountIDs, accountIDs))
+	if err != nil {
+		return err
+	}
+	manifest.Files["transactions.json"] = n
+
+	n, err = writeExportRows[models.UserAuthLog](zw, "auth_logs.json", tx.Where("user_id IN ?", userIDs))
+	if err != nil {
+		return err
+	}
+	manifest.Files["auth_logs.json"] = n
+
+	n, err = writeExportRows[models.AuditLog](zw, "audit_logs.json",
+		tx.Where("user_id IN ? OR (table_name_reference = ? AND record_id = ?)", userIDs, models.Customer{}.TableName(), customer.CustomerID))
+	if err != nil {
+		return err
+	}
+	manifest.Files["audit_logs.json"] = n
+
+	if err := writeExportJSON(zw, "manifest.json", manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeExportJSON(zw *zip.Writer, name string, value any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// writeExportRows grava um array JSON com as linhas da consulta, lidas em
+// lotes para não carregar o histórico inteiro de uma vez. Retorna o número de
+// linhas gravadas.
+func writeExportRows[T any](zw *zip.Writer, name string, query *gorm.DB) (int, error) {
+	w, err := zw.Create(name)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.WriteString(w, "["); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	var rows []T
+	if err := query.FindInBatches(&rows, privacyExportBatch, func(_ *gorm.DB, _ int) error {
+		for i := range rows {
+			raw, err := json.Marshal(&rows[i])
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			if _, err := w.Write(raw); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	}).Error; err != nil {
+		return count, err
+	}
+
+	_, err = io.WriteString(w, "]\n")
+	return count, err
+}
@@ -0,0 +1,876 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/security"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/internal/notifications"
+	"github.com/victor-lima-142/oak-bank/internal/storage"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPrivacyRequestNotFound   = fmt.Errorf("%w: pedido do titular", ErrNotFound)
+	ErrPrivacyRequestOpen       = fmt.Errorf("%w: já existe um pedido deste tipo em andamento", ErrConflict)
+	ErrPrivacyRequestClosed     = fmt.Errorf("%w: pedido do titular já encerrado", ErrConflict)
+	ErrPrivacyRequestNotFailed  = fmt.Errorf("%w: apenas pedidos com falha podem ser reprocessados", ErrConflict)
+	ErrPrivacyAccountOpen       = fmt.Errorf("%w: a anonimização exige o encerramento da conta", ErrConflict)
+	ErrCustomerAnonymized       = fmt.Errorf("%w: cliente já anonimizado", ErrConflict)
+	ErrPrivacyExportUnavailable = fmt.Errorf("%w: arquivo da exportação indisponível", ErrNotFound)
+	// ErrPrivacyExportIntegrity indica que o arquivo lido não confere com o
+	// hash gravado na geração
+	ErrPrivacyExportIntegrity = errors.New("arquivo da exportação não confere com o hash registrado")
+)
+
+// anonymizedValue substitui os dados pessoais em colunas obrigatórias
+const anonymizedValue = "ANONIMIZADO"
+
+type PrivacyService interface {
+	// CreateRequest abre um pedido de exportação ou anonimização para o
+	// cliente do usuário. O pedido é atendido em segundo plano.
+	CreateRequest(ctx context.Context, userID string, req *dtos.CreatePrivacyRequestDTO) (*dtos.PrivacyRequestDTO, error)
+
+	// ListOwn lista os pedidos do cliente do usuário, do mais recente para o mais antigo
+	ListOwn(ctx context.Context, userID string) ([]dtos.PrivacyRequestDTO, error)
+	GetOwn(ctx context.Context, userID, requestID string) (*dtos.PrivacyRequestDTO, error)
+
+	// DownloadExport retorna o arquivo zip de uma exportação concluída do
+	// cliente do usuário
+	DownloadExport(ctx context.Context, userID, requestID string) (*dtos.PrivacyRequestDTO, []byte, error)
+
+	// ListRequests, GetRequest, CreateForCustomer, Reject, Retry e DownloadAny
+	// atendem o encarregado de dados e o compliance
+	ListRequests(ctx context.Context, req *dtos.PrivacyRequestListRequestDTO) (*dtos.PrivacyRequestListDTO, error)
+	GetRequest(ctx context.Context, requestID string) (*dtos.PrivacyRequestDTO, error)
+	CreateForCustomer(ctx context.Context, actorUserID, customerID string, req *dtos.CreateCustomerPrivacyRequestDTO) (*dtos.PrivacyRequestDTO, error)
+	Reject(ctx context.Context, actorUserID, requestID string, req *dtos.RejectPrivacyRequestDTO) (*dtos.PrivacyRequestDTO, error)
+	Retry(ctx context.Context, actorUserID, requestID string) (*dtos.PrivacyRequestDTO, error)
+	DownloadAny(ctx context.Context, requestID string) (*dtos.PrivacyRequestDTO, []byte, error)
+
+	// Process atende o pedido imediatamente, sem esperar o worker. Usado por
+	// cmd/privacy-request.
+	Process(ctx context.Context, requestID string) (*dtos.PrivacyRequestDTO, error)
+
+	// Run atende os pedidos pendentes e os agendados vencidos e remove os
+	// arquivos de exportação expirados
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// PrivacyConfig contém os prazos dos pedidos do titular
+type PrivacyConfig struct {
+	// Deadline é o prazo de resposta ao titular. O padrão de 15 dias segue o
+	// art. 19, II da LGPD.
+	Deadline time.Duration
+	// Retention é o prazo de guarda dos registros depois do fim do
+	// relacionamento (encerramento da conta ou última transação). O padrão de
+	// cinco anos segue a Lei 9.613/98.
+	Retention   time.Duration
+	ExportTTL   time.Duration
+	MaxAttempts int
+	BatchSize   int
+}
+
+type privacyService struct {
+	db            *gorm.DB
+	store         storage.ObjectStore
+	cipher        *storage.Cipher
+	passwords     security.PasswordService
+	notifications NotificationService
+	config        PrivacyConfig
+}
+
+func NewPrivacyService(db *gorm.DB, store storage.ObjectStore, cipher *storage.Cipher, passwords security.PasswordService, notifications NotificationService, cfg *PrivacyConfig) PrivacyService {
+	if cfg == nil {
+		cfg = &PrivacyConfig{}
+	}
+	if cfg.Deadline == 0 {
+		cfg.Deadline = config.GetEnvDuration("PRIVACY_REQUEST_DEADLINE", 15*24*time.Hour)
+	}
+	if cfg.Retention == 0 {
+		cfg.Retention = config.GetEnvDuration("PRIVACY_RETENTION", 5*365*24*time.Hour)
+	}
+	if cfg.ExportTTL == 0 {
+		cfg.ExportTTL = config.GetEnvDuration("PRIVACY_EXPORT_TTL", 7*24*time.Hour)
+	}
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = config.GetEnvInt("PRIVACY_REQUEST_MAX_ATTEMPTS", 5)
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = config.GetEnvInt("PRIVACY_REQUEST_BATCH_SIZE", 10)
+	}
+
+	return &privacyService{
+		db:            db,
+		store:         store,
+		cipher:        cipher,
+		passwords:     passwords,
+		notifications: notifications,
+		config:        *cfg,
+	}
+}
+
+func (s *privacyService) CreateRequest(ctx context.Context, userID string, req *dtos.CreatePrivacyRequestDTO) (*dtos.PrivacyRequestDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := reauthenticate(ctx, s.db, s.passwords, userID, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+	return s.create(ctx, customerID, userID, req.Type, req.Reason)
+}
+
+func (s *privacyService) CreateForCustomer(ctx context.Context, actorUserID, customerID string, req *dtos.CreateCustomerPrivacyRequestDTO) (*dtos.PrivacyRequestDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+	if _, err := uuid.Parse(customerID); err != nil {
+		return nil, ErrCustomerNotFound
+	}
+	return s.create(ctx, customerID, actorUserID, req.Type, req.Reason)
+}
+
+// create registra o pedido com o prazo legal de resposta. A anonimização só
+// é aceita com a conta encerrada; o prazo de guarda é conferido no
+// atendimento.
+func (s *privacyService) create(ctx context.Context, customerID, actorUserID, requestType, reason string) (*dtos.PrivacyRequestDTO, error) {
+	var request models.DataSubjectRequest
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		customer, err := lockCustomer(tx, customerID)
+		if err != nil {
+			return err
+		}
+
+		if requestType == models.DataSubjectRequestAnonymization {
+			if customer.AnonymizedAt.Valid {
+				return ErrCustomerAnonymized
+			}
+			var open int64
+			if err := tx.Model(&models.Account{}).
+				Where("customer_id = ? AND account_status <> ?", customerID, models.AccountStatusClosed).
+				Count(&open).Error; err != nil {
+				return err
+			}
+			if open > 0 {
+				return ErrPrivacyAccountOpen
+			}
+		}
+
+		var pending int64
+		if err := tx.Model(&models.DataSubjectRequest{}).
+			Where("customer_id = ? AND request_type = ? AND request_status IN ?", customerID, requestType, openPrivacyStatuses).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrPrivacyRequestOpen
+		}
+
+		request = models.DataSubjectRequest{
+			CustomerID:        customerID,
+			RequestType:       requestType,
+			RequestStatus:     models.DataSubjectRequestPending,
+			RequestedByUserID: nullString(actorUserID),
+			Reason:            nullString(reason),
+			DueAt:             time.Now().Add(s.config.Deadline),
+		}
+		return tx.Create(&request).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dto := privacyRequestDTO(&request)
+	return &dto, nil
+}
+
+func (s *privacyService) ListOwn(ctx context.Context, userID string) ([]dtos.PrivacyRequestDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []models.DataSubjectRequest
+	if err := s.db.WithContext(ctx).
+		Where("customer_id = ?", customerID).
+		Order("created_at DESC").
+		Limit(100).
+		Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.PrivacyRequestDTO, 0, len(requests))
+	for i := range requests {
+		result = append(result, privacyRequestDTO(&requests[i]))
+	}
+	return result, nil
+}
+
+func (s *privacyService) GetOwn(ctx context.Context, userID, requestID string) (*dtos.PrivacyRequestDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	request, err := s.find(s.db.WithContext(ctx).Where("customer_id = ?", customerID), requestID)
+	if err != nil {
+		return nil, err
+	}
+	dto := privacyRequestDTO(request)
+	return &dto, nil
+}
+
+func (s *privacyService) GetRequest(ctx context.Context, requestID string) (*dtos.PrivacyRequestDTO, error) {
+	request, err := s.find(s.db.WithContext(ctx), requestID)
+	if err != nil {
+		return nil, err
+	}
+	dto := privacyRequestDTO(request)
+	return &dto, nil
+}
+
+func (s *privacyService) find(query *gorm.DB, requestID string) (*models.DataSubjectRequest, error) {
+	if _, err := uuid.Parse(requestID); err != nil {
+		return nil, ErrPrivacyRequestNotFound
+	}
+	var request models.DataSubjectRequest
+	if err := query.First(&request, "request_id = ?", requestID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPrivacyRequestNotFound
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (s *privacyService) ListRequests(ctx context.Context, req *dtos.PrivacyRequestListRequestDTO) (*dtos.PrivacyRequestListDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.DataSubjectRequest{})
+	if req.Status != "" {
+		query = query.Where("request_status = ?", req.Status)
+	}
+	if req.Type != "" {
+		query = query.Where("request_type = ?", req.Type)
+	}
+	if req.CustomerID != "" {
+		query = query.Where("customer_id = ?", req.CustomerID)
+	}
+	if req.Overdue {
+		query = query.Where("request_status IN ? AND due_at < ?", unansweredPrivacyStatuses, time.Now())
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	// o prazo legal mais próximo primeiro
+	var requests []models.DataSubjectRequest
+	if err := query.Order("due_at").Limit(limit).Offset(req.Offset).Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	response := &dtos.PrivacyRequestListDTO{
+		Requests:   make([]dtos.PrivacyRequestDTO, 0, len(requests)),
+		TotalCount: int(total),
+		Limit:      limit,
+		Offset:     req.Offset,
+	}
+	for i := range requests {
+		response.Requests = append(response.Requests, privacyRequestDTO(&requests[i]))
+	}
+	return response, nil
+}
+
+func (s *privacyService) Reject(ctx context.Context, actorUserID, requestID string, req *dtos.RejectPrivacyRequestDTO) (*dtos.PrivacyRequestDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	return s.update(ctx, requestID, func(tx *gorm.DB, request *models.DataSubjectRequest) error {
+		if !slices.Contains(openPrivacyStatuses, request.RequestStatus) {
+			return ErrPrivacyRequestClosed
+		}
+		return s.close(tx, request, models.DataSubjectRequestRejected, actorUserID, req.Note)
+	})
+}
+
+func (s *privacyService) Retry(ctx context.Context, actorUserID, requestID string) (*dtos.PrivacyRequestDTO, error) {
+	return s.update(ctx, requestID, func(tx *gorm.DB, request *models.DataSubjectRequest) error {
+		if request.RequestStatus != models.DataSubjectRequestFailed {
+			return ErrPrivacyRequestNotFailed
+		}
+		request.RequestStatus = models.DataSubjectRequestPending
+		request.Attempts = 0
+		request.HandledByUserID = nullString(actorUserID)
+		return tx.Model(request).Updates(map[string]interface{}{
+			"request_status":     request.RequestStatus,
+			"attempts":           request.Attempts,
+			"handled_by_user_id": request.HandledByUserID,
+		}).Error
+	})
+}
+
+// update bloqueia o pedido e aplica fn na mesma transação
+func (s *privacyService) update(ctx context.Context, requestID string, fn func(tx *gorm.DB, request *models.DataSubjectRequest) error) (*dtos.PrivacyRequestDTO, error) {
+	var request *models.DataSubjectRequest
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		request, err = s.find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), requestID)
+		if err != nil {
+			return err
+		}
+		return fn(tx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dto := privacyRequestDTO(request)
+	return &dto, nil
+}
+
+// close encerra o pedido com o status final e a nota de resolução
+func (s *privacyService) close(tx *gorm.DB, request *models.DataSubjectRequest, status, actorUserID, note string) error {
+	request.RequestStatus = status
+	request.HandledByUserID = nullString(actorUserID)
+	request.ResolutionNote = nullString(truncate(note, 1000))
+	request.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return tx.Model(request).Updates(map[string]interface{}{
+		"request_status":     request.RequestStatus,
+		"handled_by_user_id": request.HandledByUserID,
+		"resolution_note":    request.ResolutionNote,
+		"completed_at":       request.CompletedAt,
+	}).Error
+}
+
+func (s *privacyService) DownloadExport(ctx context.Context, userID, requestID string) (*dtos.PrivacyRequestDTO, []byte, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.download(ctx, s.db.WithContext(ctx).Where("customer_id = ?", customerID), requestID)
+}
+
+func (s *privacyService) DownloadAny(ctx context.Context, requestID string) (*dtos.PrivacyRequestDTO, []byte, error) {
+	return s.download(ctx, s.db.WithContext(ctx), requestID)
+}
+
+func (s *privacyService) download(ctx context.Context, query *gorm.DB, requestID string) (*dtos.PrivacyRequestDTO, []byte, error) {
+	request, err := s.find(query, requestID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if request.RequestType != models.DataSubjectRequestExport ||
+		request.RequestStatus != models.DataSubjectRequestCompleted ||
+		!request.ArtifactKey.Valid ||
+		(request.ArtifactExpiresAt.Valid && !time.Now().Before(request.ArtifactExpiresAt.Time)) {
+		return nil, nil, ErrPrivacyExportUnavailable
+	}
+
+	object, err := s.store.Get(ctx, request.ArtifactKey.String)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer object.Close()
+
+	var ciphertext bytes.Buffer
+	if _, err := io.Copy(&ciphertext, object); err != nil {
+		return nil, nil, err
+	}
+	data, err := s.cipher.Open(request.ArtifactKeyID.String, ciphertext.Bytes(), []byte(request.RequestID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != request.ArtifactSHA256.String {
+		return nil, nil, fmt.Errorf("%w: %s", ErrPrivacyExportIntegrity, request.RequestID)
+	}
+
+	dto := privacyRequestDTO(request)
+	return &dto, data, nil
+}
+
+func (s *privacyService) Process(ctx context.Context, requestID string) (*dtos.PrivacyRequestDTO, error) {
+	if _, err := s.GetRequest(ctx, requestID); err != nil {
+		return nil, err
+	}
+	if err := s.process(ctx, requestID); err != nil {
+		return nil, err
+	}
+	return s.GetRequest(ctx, requestID)
+}
+
+func (s *privacyService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.processDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("privacy: processing failed: %v", err)
+		}
+		if err := s.purgeExpiredExports(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("privacy: export purge failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processDue atende os pedidos pendentes e os agendados vencidos, do prazo
+// legal mais próximo para o mais distante
+func (s *privacyService) processDue(ctx context.Context) error {
+	var requestIDs []string
+	if err := s.db.WithContext(ctx).Model(&models.DataSubjectRequest{}).
+		Where("request_status = ? OR (request_status = ? AND scheduled_for <= ?)",
+			models.DataSubjectRequestPending, models.DataSubjectRequestScheduled, time.Now()).
+		Order("due_at").
+		Limit(s.config.BatchSize).
+		Pluck("request_id", &requestIDs).Error; err != nil {
+		return err
+	}
+
+	for _, requestID := range requestIDs {
+		if err := s.process(ctx, requestID); err != nil {
+			log.Printf("privacy: request %s failed: %v", requestID, err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// process atende um pedido em uma transação. O pedido bloqueado por outra
+// instância é ignorado. A falha é registrada fora da transação desfeita; após
+// MaxAttempts o pedido fica FAILED para análise do compliance.
+func (s *privacyService) process(ctx context.Context, requestID string) error {
+	var artifactKey string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var request models.DataSubjectRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			First(&request, "request_id = ?", requestID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		now := time.Now()
+		due := request.RequestStatus == models.DataSubjectRequestPending ||
+			(request.RequestStatus == models.DataSubjectRequestScheduled && request.ScheduledFor.Valid && !now.Before(request.ScheduledFor.Time))
+		if !due {
+			return nil
+		}
+
+		switch request.RequestType {
+		case models.DataSubjectRequestExport:
+			var err error
+			artifactKey, err = s.export(ctx, tx, &request, now)
+			return err
+		case models.DataSubjectRequestAnonymization:
+			return s.anonymize(ctx, tx, &request, now)
+		}
+		return nil
+	})
+	if err == nil {
+		return nil
+	}
+
+	if artifactKey != "" {
+		if delErr := s.store.Delete(context.WithoutCancel(ctx), artifactKey); delErr != nil {
+			log.Printf("privacy: failed to remove orphan object %s: %v", artifactKey, delErr)
+		}
+	}
+	if ctx.Err() != nil {
+		return err
+	}
+
+	// a contagem é feita no banco para não perder tentativas de outra instância
+	if updateErr := s.db.WithContext(ctx).Model(&models.DataSubjectRequest{}).
+		Where("request_id = ?", requestID).
+		Updates(map[string]interface{}{
+			"attempts":       gorm.Expr("attempts + 1"),
+			"last_error":     truncate(err.Error(), 500),
+			"request_status": gorm.Expr("CASE WHEN attempts + 1 >= ? THEN ? ELSE request_status END", s.config.MaxAttempts, models.DataSubjectRequestFailed),
+		}).Error; updateErr != nil {
+		log.Printf("privacy: failed to record failure of request %s: %v", requestID, updateErr)
+	}
+	return err
+}
+
+// export gera o arquivo, grava-o cifrado no object store e conclui o pedido.
+// Retorna a chave do objeto gravado para que o chamador o remova se a
+// transação falhar.
+func (s *privacyService) export(ctx context.Context, tx *gorm.DB, request *models.DataSubjectRequest, now time.Time) (string, error) {
+	var customer models.Customer
+	if err := tx.First(&customer, "customer_id = ?", request.CustomerID).Error; err != nil {
+		return "", err
+	}
+
+	var archive bytes.Buffer
+	if err := s.writeExport(tx, request.RequestID, &customer, now, &archive); err != nil {
+		return "", err
+	}
+	data := archive.Bytes()
+	sum := sha256.Sum256(data)
+
+	// o id do pedido é o dado autenticado da cifra, como nos documentos
+	keyID, ciphertext, err := s.cipher.Seal(data, []byte(request.RequestID))
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprintf("customers/%s/privacy/%s.zip", request.CustomerID, request.RequestID)
+	if err := s.store.Put(ctx, key, ciphertext, "application/octet-stream"); err != nil {
+		return "", err
+	}
+
+	request.RequestStatus = models.DataSubjectRequestCompleted
+	request.ArtifactKey = nullString(key)
+	request.ArtifactKeyID = nullString(keyID)
+	request.ArtifactSHA256 = nullString(hex.EncodeToString(sum[:]))
+	request.ArtifactSizeBytes = sql.NullInt64{Int64: int64(len(data)), Valid: true}
+	request.ArtifactExpiresAt = sql.NullTime{Time: now.Add(s.config.ExportTTL), Valid: true}
+	request.CompletedAt = sql.NullTime{Time: now, Valid: true}
+	if err := tx.Model(request).Updates(map[string]interface{}{
+		"request_status":      request.RequestStatus,
+		"artifact_key":        request.ArtifactKey,
+		"artifact_key_id":     request.ArtifactKeyID,
+		"artifact_sha256":     request.ArtifactSHA256,
+		"artifact_size_bytes": request.ArtifactSizeBytes,
+		"artifact_expires_at": request.ArtifactExpiresAt,
+		"completed_at":        request.CompletedAt,
+		"last_error":          nil,
+	}).Error; err != nil {
+		return key, err
+	}
+
+	err = s.notifications.Enqueue(ctx, tx, &NotificationRequest{
+		CustomerID: customer.CustomerID,
+		Template:   notifications.TemplatePrivacyExportReady,
+		Channels:   []string{notifications.ChannelEmail},
+		Data: map[string]string{
+			"expires_at": request.ArtifactExpiresAt.Time.In(customerLocation(&customer)).Format("02/01/2006 15:04"),
+		},
+	})
+	if errors.Is(err, ErrNoRecipient) {
+		log.Printf("privacy: no recipient for export notice of customer %s", customer.CustomerID)
+		err = nil
+	}
+	return key, err
+}
+
+// anonymize remove os dados pessoais do cliente. Com a conta reaberta o
+// pedido é rejeitado; dentro do prazo de guarda ele fica agendado para o
+// fim do prazo.
+func (s *privacyService) anonymize(ctx context.Context, tx *gorm.DB, request *models.DataSubjectRequest, now time.Time) error {
+	customer, err := lockCustomer(tx, request.CustomerID)
+	if err != nil {
+		return err
+	}
+	if customer.AnonymizedAt.Valid {
+		return s.close(tx, request, models.DataSubjectRequestCompleted, "", "cliente já anonimizado")
+	}
+
+	var accounts []models.Account
+	if err := tx.Where("customer_id = ?", customer.CustomerID).Find(&accounts).Error; err != nil {
+		return err
+	}
+	relationshipEnd := customer.DateRegistered
+	accountIDs := make([]string, 0, len(accounts))
+	for i := range accounts {
+		if accounts[i].AccountStatus != models.AccountStatusClosed {
+			return s.close(tx, request, models.DataSubjectRequestRejected, "", "a conta do cliente não está encerrada")
+		}
+		if accounts[i].DateClosed.Valid && accounts[i].DateClosed.Time.After(relationshipEnd) {
+			relationshipEnd = accounts[i].DateClosed.Time
+		}
+		accountIDs = append(accountIDs, accounts[i].AccountID)
+	}
+	if len(accountIDs) > 0 {
+		var lastTransaction sql.NullTime
+		if err := tx.Model(&models.Transaction{}).
+			Where("account_id_origin IN ? OR account_id_dest IN ?", accountIDs, accountIDs).
+			Select("MAX(transaction_date)").
+			Scan(&lastTransaction).Error; err != nil {
+			return err
+		}
+		if lastTransaction.Valid && lastTransaction.Time.After(relationshipEnd) {
+			relationshipEnd = lastTransaction.Time
+		}
+	}
+
+	if retainUntil := relationshipEnd.Add(s.config.Retention); now.Before(retainUntil) {
+		request.RequestStatus = models.DataSubjectRequestScheduled
+		request.ScheduledFor = sql.NullTime{Time: retainUntil, Valid: true}
+		request.ResolutionNote = nullString("dados mantidos até o fim do prazo legal de guarda dos registros")
+		return tx.Model(request).Updates(map[string]interface{}{
+			"request_status":  request.RequestStatus,
+			"scheduled_for":   request.ScheduledFor,
+			"resolution_note": request.ResolutionNote,
+		}).Error
+	}
+
+	if err := s.scrub(ctx, tx, customer, now); err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, tx, &AuditEntry{
+		Table:     models.Customer{}.TableName(),
+		RecordID:  customer.CustomerID,
+		Operation: AuditOperationUpdate,
+		UserID:    request.RequestedByUserID.String,
+		NewValues: map[string]any{"anonymized_at": now},
+		Context: map[string]any{
+			"data_subject_request_id": request.RequestID,
+			"relationship_end":        relationshipEnd,
+		},
+	}); err != nil {
+		return err
+	}
+	return s.close(tx, request, models.DataSubjectRequestCompleted, "", "dados pessoais anonimizados; registros financeiros preservados")
+}
+
+// scrub substitui ou remove os dados pessoais do cliente e dos seus usuários.
+// Contas, transações e os registros de AML, triagem, KYC e risco são
+// mantidos, ligados ao cliente anonimizado, por exigência regulatória.
+func (s *privacyService) scrub(ctx context.Context, tx *gorm.DB, customer *models.Customer, now time.Time) error {
+	customerID := customer.CustomerID
+
+	// o CPF/CNPJ é obrigatório e único; o marcador mantém o índice cego único
+	customer.TaxID = anonymizedValue + "-" + customerID
+	customer.CustomerName = anonymizedValue
+	customer.CustomerPhone = sql.NullString{}
+	customer.PhoneHash = sql.NullString{}
+	customer.CustomerEmail = sql.NullString{}
+	customer.EmailHash = sql.NullString{}
+	customer.DateOfBirth = sql.NullTime{}
+	customer.OtherDetails = nil
+	customer.AnonymizedAt = sql.NullTime{Time: now, Valid: true}
+	if err := tx.Model(customer).
+		Select("tax_id", "tax_id_hash", "customer_name", "customer_phone", "phone_hash", "customer_email", "email_hash", "date_of_birth", "other_details", "anonymized_at").
+		Updates(customer).Error; err != nil {
+		return err
+	}
+
+	var userIDs []string
+	if err := tx.Model(&models.User{}).Where("customer_id = ?", customerID).Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+	if len(userIDs) > 0 {
+		// "!" não é um hash válido, então nenhuma senha confere
+		if err := tx.Model(&models.User{}).Where("user_id IN ?", userIDs).Updates(map[string]interface{}{
+			"username":              gorm.Expr("'anon-' || user_id::text"),
+			"email":                 gorm.Expr("user_id::text || '@anonimizado.invalid'"),
+			"password_hash":         "!",
+			"is_active":             false,
+			"is_locked":             true,
+			"failed_login_attempts": 0,
+			"two_factor_enabled":    false,
+			"two_factor_secret":     nil,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id IN ?", userIDs).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.UserAuthLog{}).Where("user_id IN ?", userIDs).Updates(map[string]interface{}{
+			"ip_address":         nil,
+			"user_agent":         nil,
+			"device_fingerprint": nil,
+			"session_id":         nil,
+			"geolocation":        nil,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.AuditLog{}).Where("user_id IN ?", userIDs).Updates(map[string]interface{}{
+			"ip_address": nil,
+			"user_agent": nil,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id IN ?", userIDs).Delete(&models.OneTimeCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id IN ?", userIDs).Delete(&models.PushDevice{}).Error; err != nil {
+			return err
+		}
+	}
+
+	// valores anteriores do cadastro gravados na auditoria
+	if err := tx.Model(&models.AuditLog{}).
+		Where("table_name_reference = ? AND record_id = ?", models.Customer{}.TableName(), customerID).
+		Updates(map[string]interface{}{"old_values": nil, "new_values": nil}).Error; err != nil {
+		return err
+	}
+
+	// cidade, UF e país continuam disponíveis para os relatórios regulatórios
+	if err := tx.Model(&models.Address{}).
+		Where("address_id IN (?)", tx.Model(&models.CustomerAddress{}).Select("address_id").Where("customer_id = ?", customerID)).
+		Updates(map[string]interface{}{
+			"address_line1": anonymizedValue,
+			"address_line2": nil,
+			"neighborhood":  nil,
+			"postal_code":   "",
+		}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.KYCSubmission{}).Where("customer_id = ?", customerID).
+		Update("submitted_data", nil).Error; err != nil {
+		return err
+	}
+
+	for _, model := range []any{
+		&models.CustomerLegalRepresentative{},
+		&models.OnboardingSession{},
+		&models.ProfileChangeRequest{},
+		&models.CustomerProfileVersion{},
+	} {
+		if err := tx.Where("customer_id = ?", customerID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Where("customer_id = ? OR user_id IN ?", customerID, userIDs).Delete(&models.Notification{}).Error; err != nil {
+		return err
+	}
+
+	return s.purgeObjects(ctx, tx, customerID, now)
+}
+
+// purgeObjects remove do object store os documentos e os arquivos de
+// exportação do cliente, mantendo os registros
+func (s *privacyService) purgeObjects(ctx context.Context, tx *gorm.DB, customerID string, now time.Time) error {
+	var documents []models.CustomerDocument
+	if err := tx.Where("customer_id = ? AND purged_at IS NULL", customerID).Find(&documents).Error; err != nil {
+		return err
+	}
+	for i := range documents {
+		if err := s.store.Delete(ctx, documents[i].StorageKey); err != nil {
+			return err
+		}
+		if err := tx.Model(&documents[i]).Updates(map[string]interface{}{
+			"file_name": "document",
+			"purged_at": sql.NullTime{Time: now, Valid: true},
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	var exports []models.DataSubjectRequest
+	if err := tx.Where("customer_id = ? AND artifact_key IS NOT NULL", customerID).Find(&exports).Error; err != nil {
+		return err
+	}
+	for i := range exports {
+		if err := s.removeArtifact(ctx, tx, &exports[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeExpiredExports remove os arquivos de exportação vencidos. Cada pedido
+// é bloqueado com SKIP LOCKED, permitindo várias instâncias.
+func (s *privacyService) purgeExpiredExports(ctx context.Context) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var requests []models.DataSubjectRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("artifact_key IS NOT NULL AND artifact_expires_at < ?", time.Now()).
+			Limit(s.config.BatchSize).
+			Find(&requests).Error; err != nil {
+			return err
+		}
+		for i := range requests {
+			if err := s.removeArtifact(ctx, tx, &requests[i]); err != nil {
+				return err
+			}
+		}
+		if len(requests) > 0 {
+			log.Printf("privacy: removed %d expired exports", len(requests))
+		}
+		return nil
+	})
+}
+
+func (s *privacyService) removeArtifact(ctx context.Context, tx *gorm.DB, request *models.DataSubjectRequest) error {
+	if err := s.store.Delete(ctx, request.ArtifactKey.String); err != nil {
+		return err
+	}
+	request.ArtifactKey = sql.NullString{}
+	return tx.Model(request).Update("artifact_key", nil).Error
+}
+
+var (
+	// openPrivacyStatuses impedem um novo pedido do mesmo tipo
+	// (idx_data_subject_requests_open_type)
+	openPrivacyStatuses = []string{models.DataSubjectRequestPending, models.DataSubjectRequestScheduled, models.DataSubjectRequestFailed}
+	// unansweredPrivacyStatuses contam para o prazo legal; o agendamento da
+	// anonimização já é a resposta ao titular
+	unansweredPrivacyStatuses = []string{models.DataSubjectRequestPending, models.DataSubjectRequestFailed}
+)
+
+func privacyRequestDTO(r *models.DataSubjectRequest) dtos.PrivacyRequestDTO {
+	return dtos.PrivacyRequestDTO{
+		RequestID:         r.RequestID,
+		CustomerID:        r.CustomerID,
+		Type:              r.RequestType,
+		Status:            r.RequestStatus,
+		Reason:            r.Reason.String,
+		DueAt:             r.DueAt,
+		Overdue:           slices.Contains(unansweredPrivacyStatuses, r.RequestStatus) && time.Now().After(r.DueAt),
+		ScheduledFor:      nullTimePtr(r.ScheduledFor),
+		Attempts:          r.Attempts,
+		LastError:         r.LastError.String,
+		ArtifactSHA256:    r.ArtifactSHA256.String,
+		ArtifactSizeBytes: r.ArtifactSizeBytes.Int64,
+		ArtifactExpiresAt: nullTimePtr(r.ArtifactExpiresAt),
+		RequestedByUserID: r.RequestedByUserID.String,
+		HandledByUserID:   r.HandledByUserID.String,
+		ResolutionNote:    r.ResolutionNote.String,
+		CompletedAt:       nullTimePtr(r.CompletedAt),
+		CreatedAt:         r.CreatedAt,
+	}
+}
@@ -0,0 +1,760 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/security"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/internal/notifications"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"github.com/victor-lima-142/oak-bank/pkg/taxid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrProfileChangeNotFound   = fmt.Errorf("%w: pedido de alteração do cadastro", ErrNotFound)
+	ErrProfileChangeNotPending = fmt.Errorf("%w: pedido de alteração já encerrado", ErrConflict)
+	ErrProfileChangeExpired    = fmt.Errorf("%w: pedido de alteração expirado, faça um novo pedido", ErrConflict)
+	ErrProfileChangeUnchanged  = fmt.Errorf("%w: o novo valor é igual ao atual", ErrInvalidInput)
+	ErrProfileDocumentInvalid  = fmt.Errorf("%w: documento não encontrado entre os arquivos do cliente", ErrInvalidInput)
+)
+
+// ProfileChangeHook é executado dentro da transação que aplica uma alteração
+// do cadastro, depois da gravação do novo valor. Retornar erro desfaz a
+// alteração.
+type ProfileChangeHook func(ctx context.Context, tx *gorm.DB, customer *models.Customer, change *models.ProfileChangeRequest) error
+
+type ProfileService interface {
+	// RequestChange abre um pedido de alteração de nome, email ou telefone do
+	// cliente do usuário, substituindo o pedido em aberto do mesmo campo.
+	// Email e telefone recebem um código de confirmação no novo contato.
+	RequestChange(ctx context.Context, userID string, req *dtos.ProfileChangeRequestDTO) (*dtos.ProfileChangeDTO, error)
+
+	// ListChanges lista os pedidos do cliente do usuário, do mais recente para o mais antigo
+	ListChanges(ctx context.Context, userID string) ([]dtos.ProfileChangeDTO, error)
+
+	// ResendCode envia um novo código de confirmação, invalidando o anterior
+	ResendCode(ctx context.Context, userID, requestID string) (*dtos.ProfileChangeDTO, error)
+
+	// Verify confere o código enviado ao novo contato e aplica a alteração
+	Verify(ctx context.Context, userID, requestID string, req *dtos.VerifyProfileChangeDTO) (*dtos.ProfileChangeDTO, error)
+
+	Cancel(ctx context.Context, userID, requestID string) (*dtos.ProfileChangeDTO, error)
+
+	// History lista as versões dos campos do cadastro do cliente do usuário
+	History(ctx context.Context, userID string, req *dtos.ProfileHistoryRequestDTO) (*dtos.ProfileHistoryDTO, error)
+
+	// ListRequests, GetRequest e Review atendem a análise dos pedidos de
+	// alteração de nome pelo compliance
+	ListRequests(ctx context.Context, req *dtos.ProfileChangeListRequestDTO) (*dtos.ProfileChangeListDTO, error)
+	GetRequest(ctx context.Context, requestID string) (*dtos.ProfileChangeDTO, error)
+	Review(ctx context.Context, actorUserID, requestID string, req *dtos.ReviewProfileChangeDTO) (*dtos.ProfileChangeDTO, error)
+
+	// CustomerHistory é a consulta das versões do cadastro de qualquer cliente
+	CustomerHistory(ctx context.Context, customerID string, req *dtos.ProfileHistoryRequestDTO) (*dtos.ProfileHistoryDTO, error)
+
+	// AddChangeListener registra um hook executado a cada alteração aplicada
+	AddChangeListener(hook ProfileChangeHook)
+}
+
+// ProfileConfig contém os prazos da confirmação de email e telefone
+type ProfileConfig struct {
+	// RequestTTL é o prazo para confirmar o novo contato, contado a partir do pedido
+	RequestTTL      time.Duration
+	CodeTTL         time.Duration
+	CodeMaxAttempts int
+}
+
+type profileService struct {
+	db            *gorm.DB
+	passwords     security.PasswordService
+	screening     ScreeningService
+	notifications NotificationService
+	config        ProfileConfig
+	listeners     []ProfileChangeHook
+}
+
+func NewProfileService(db *gorm.DB, passwords security.PasswordService, screening ScreeningService, notifications NotificationService, cfg *ProfileConfig) ProfileService {
+	if cfg == nil {
+		cfg = &ProfileConfig{}
+	}
+	if cfg.RequestTTL == 0 {
+		cfg.RequestTTL = config.GetEnvDuration("PROFILE_CHANGE_REQUEST_TTL", 24*time.Hour)
+	}
+	if cfg.CodeTTL == 0 {
+		cfg.CodeTTL = config.GetEnvDuration("PROFILE_CHANGE_CODE_TTL", 15*time.Minute)
+	}
+	if cfg.CodeMaxAttempts == 0 {
+		cfg.CodeMaxAttempts = config.GetEnvInt("PROFILE_CHANGE_CODE_MAX_ATTEMPTS", 5)
+	}
+
+	return &profileService{
+		db:            db,
+		passwords:     passwords,
+		screening:     screening,
+		notifications: notifications,
+		config:        *cfg,
+	}
+}
+
+func (s *profileService) AddChangeListener(hook ProfileChangeHook) {
+	s.listeners = append(s.listeners, hook)
+}
+
+func (s *profileService) RequestChange(ctx context.Context, userID string, req *dtos.ProfileChangeRequestDTO) (*dtos.ProfileChangeDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+	value, fields := normalizeProfileValue(req.Field, req.Value)
+	if req.Field == models.ProfileFieldName {
+		if len(req.DocumentIDs) == 0 {
+			fields["document_ids"] = "obrigatório para alteração do nome"
+		}
+	} else if len(req.DocumentIDs) > 0 {
+		fields["document_ids"] = "permitido apenas na alteração do nome"
+	}
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := reauthenticate(ctx, s.db, s.passwords, userID, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+
+	var request models.ProfileChangeRequest
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		customer, err := lockCustomer(tx, customerID)
+		if err != nil {
+			return err
+		}
+		current := profileFieldValue(customer, req.Field)
+		if current.Valid && sameProfileValue(req.Field, current.String, value) {
+			return ErrProfileChangeUnchanged
+		}
+
+		var documents datatypes.JSON
+		if documentIDs := uniqueStrings(req.DocumentIDs); len(documentIDs) > 0 {
+			var found int64
+			if err := tx.Model(&models.CustomerDocument{}).
+				Where("customer_id = ? AND document_id IN ? AND purged_at IS NULL", customerID, documentIDs).
+				Count(&found).Error; err != nil {
+				return err
+			}
+			if int(found) != len(documentIDs) {
+				return ErrProfileDocumentInvalid
+			}
+			raw, err := json.Marshal(documentIDs)
+			if err != nil {
+				return err
+			}
+			documents = datatypes.JSON(raw)
+		}
+
+		// o pedido novo substitui o que estiver em aberto para o mesmo campo
+		if err := tx.Model(&models.ProfileChangeRequest{}).
+			Where("customer_id = ? AND field_name = ? AND request_status IN ?", customerID, req.Field, openProfileChangeStatuses).
+			Update("request_status", models.ProfileChangeCancelled).Error; err != nil {
+			return err
+		}
+
+		request = models.ProfileChangeRequest{
+			CustomerID:        customerID,
+			RequestedByUserID: nullString(userID),
+			FieldName:         req.Field,
+			OldValue:          current,
+			NewValue:          value,
+			Reason:            nullString(req.Reason),
+			Documents:         documents,
+			RequestStatus:     models.ProfileChangePendingReview,
+		}
+		if req.Field != models.ProfileFieldName {
+			request.RequestStatus = models.ProfileChangePendingVerification
+			request.ExpiresAt = sql.NullTime{Time: time.Now().Add(s.config.RequestTTL), Valid: true}
+		}
+		if err := tx.Create(&request).Error; err != nil {
+			return err
+		}
+
+		if request.RequestStatus == models.ProfileChangePendingVerification {
+			return s.sendCode(ctx, tx, userID, &request)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dto := profileChangeDTO(&request)
+	return &dto, nil
+}
+
+func (s *profileService) ListChanges(ctx context.Context, userID string) ([]dtos.ProfileChangeDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []models.ProfileChangeRequest
+	if err := s.db.WithContext(ctx).
+		Where("customer_id = ?", customerID).
+		Order("created_at DESC").
+		Limit(100).
+		Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.ProfileChangeDTO, 0, len(requests))
+	for i := range requests {
+		result = append(result, profileChangeDTO(&requests[i]))
+	}
+	return result, nil
+}
+
+func (s *profileService) ResendCode(ctx context.Context, userID, requestID string) (*dtos.ProfileChangeDTO, error) {
+	return s.updateOwn(ctx, userID, requestID, func(tx *gorm.DB, request *models.ProfileChangeRequest) error {
+		if request.RequestStatus != models.ProfileChangePendingVerification {
+			return ErrProfileChangeNotPending
+		}
+		return s.sendCode(ctx, tx, userID, request)
+	})
+}
+
+func (s *profileService) Verify(ctx context.Context, userID, requestID string, req *dtos.VerifyProfileChangeDTO) (*dtos.ProfileChangeDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	// a tentativa errada é gravada junto com a transação; o erro do código
+	// só é devolvido depois da confirmação
+	var codeErr error
+	result, err := s.updateOwn(ctx, userID, requestID, func(tx *gorm.DB, request *models.ProfileChangeRequest) error {
+		if request.RequestStatus != models.ProfileChangePendingVerification {
+			return ErrProfileChangeNotPending
+		}
+		if codeErr = verifyOneTimeCode(tx, models.OneTimeCodePurposeProfileChange, request.RequestID, req.Code); codeErr != nil {
+			return nil
+		}
+		return s.apply(ctx, tx, request, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if codeErr != nil {
+		return nil, codeErr
+	}
+	return result, nil
+}
+
+func (s *profileService) Cancel(ctx context.Context, userID, requestID string) (*dtos.ProfileChangeDTO, error) {
+	return s.updateOwn(ctx, userID, requestID, func(tx *gorm.DB, request *models.ProfileChangeRequest) error {
+		request.RequestStatus = models.ProfileChangeCancelled
+		return tx.Model(request).Update("request_status", request.RequestStatus).Error
+	})
+}
+
+// updateOwn bloqueia um pedido em aberto do cliente do usuário e aplica
+// update. Pedidos com o prazo de confirmação vencido são marcados como
+// expirados.
+func (s *profileService) updateOwn(ctx context.Context, userID, requestID string, update func(tx *gorm.DB, request *models.ProfileChangeRequest) error) (*dtos.ProfileChangeDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(requestID); err != nil {
+		return nil, ErrProfileChangeNotFound
+	}
+
+	var request models.ProfileChangeRequest
+	expired := false
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&request, "request_id = ? AND customer_id = ?", requestID, customerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProfileChangeNotFound
+			}
+			return err
+		}
+		if !slices.Contains(openProfileChangeStatuses, request.RequestStatus) {
+			return ErrProfileChangeNotPending
+		}
+		if request.ExpiresAt.Valid && !time.Now().Before(request.ExpiresAt.Time) {
+			expired = true
+			request.RequestStatus = models.ProfileChangeExpired
+			return tx.Model(&request).Update("request_status", request.RequestStatus).Error
+		}
+		return update(tx, &request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, ErrProfileChangeExpired
+	}
+
+	dto := profileChangeDTO(&request)
+	return &dto, nil
+}
+
+// sendCode emite um código para o pedido e o envia ao novo contato
+func (s *profileService) sendCode(ctx context.Context, tx *gorm.DB, userID string, request *models.ProfileChangeRequest) error {
+	channel := notifications.ChannelEmail
+	if request.FieldName == models.ProfileFieldPhone {
+		channel = notifications.ChannelSMS
+	}
+
+	code, err := issueOneTimeCode(tx, &models.OneTimeCode{
+		UserID:      nullString(userID),
+		Purpose:     models.OneTimeCodePurposeProfileChange,
+		Reference:   nullString(request.RequestID),
+		Channel:     channel,
+		Destination: nullString(request.NewValue),
+		MaxAttempts: s.config.CodeMaxAttempts,
+	}, s.config.CodeTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.notifications.Enqueue(ctx, tx, &NotificationRequest{
+		CustomerID: request.CustomerID,
+		UserID:     userID,
+		Template:   notifications.TemplateTwoFactorCode,
+		Channels:   []string{channel},
+		Recipient:  request.NewValue,
+		Data: map[string]string{
+			"code":       code,
+			"expires_in": fmt.Sprintf("%d min", int(s.config.CodeTTL.Minutes())),
+		},
+	})
+}
+
+func (s *profileService) History(ctx context.Context, userID string, req *dtos.ProfileHistoryRequestDTO) (*dtos.ProfileHistoryDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.CustomerHistory(ctx, customerID, req)
+}
+
+func (s *profileService) CustomerHistory(ctx context.Context, customerID string, req *dtos.ProfileHistoryRequestDTO) (*dtos.ProfileHistoryDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+	if _, err := uuid.Parse(customerID); err != nil {
+		return nil, ErrCustomerNotFound
+	}
+
+	db := s.db.WithContext(ctx)
+	var count int64
+	if err := db.Model(&models.Customer{}).Where("customer_id = ?", customerID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrCustomerNotFound
+	}
+
+	query := db.Where("customer_id = ?", customerID)
+	if req.Field != "" {
+		query = query.Where("field_name = ?", req.Field)
+	}
+	var versions []models.CustomerProfileVersion
+	if err := query.Order("field_name, version DESC").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	result := &dtos.ProfileHistoryDTO{Versions: make([]dtos.ProfileVersionDTO, 0, len(versions))}
+	for _, v := range versions {
+		result.Versions = append(result.Versions, dtos.ProfileVersionDTO{
+			Field:           v.FieldName,
+			Version:         v.Version,
+			Value:           v.Value.String,
+			ValidFrom:       v.ValidFrom,
+			ValidTo:         nullTimePtr(v.ValidTo),
+			ChangeRequestID: v.ChangeRequestID.String,
+			ChangedByUserID: v.ChangedByUserID.String,
+		})
+	}
+	return result, nil
+}
+
+func (s *profileService) ListRequests(ctx context.Context, req *dtos.ProfileChangeListRequestDTO) (*dtos.ProfileChangeListDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.ProfileChangeRequest{})
+	if req.Status != "" {
+		query = query.Where("request_status = ?", req.Status)
+	}
+	if req.Field != "" {
+		query = query.Where("field_name = ?", req.Field)
+	}
+	if req.CustomerID != "" {
+		query = query.Where("customer_id = ?", req.CustomerID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	// a fila é atendida por ordem de chegada
+	var requests []models.ProfileChangeRequest
+	if err := query.Order("created_at").Limit(limit).Offset(req.Offset).Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	response := &dtos.ProfileChangeListDTO{
+		Requests:   make([]dtos.ProfileChangeDTO, 0, len(requests)),
+		TotalCount: int(total),
+		Limit:      limit,
+		Offset:     req.Offset,
+	}
+	for i := range requests {
+		response.Requests = append(response.Requests, profileChangeDTO(&requests[i]))
+	}
+	return response, nil
+}
+
+func (s *profileService) GetRequest(ctx context.Context, requestID string) (*dtos.ProfileChangeDTO, error) {
+	if _, err := uuid.Parse(requestID); err != nil {
+		return nil, ErrProfileChangeNotFound
+	}
+
+	var request models.ProfileChangeRequest
+	if err := s.db.WithContext(ctx).First(&request, "request_id = ?", requestID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProfileChangeNotFound
+		}
+		return nil, err
+	}
+	dto := profileChangeDTO(&request)
+	return &dto, nil
+}
+
+func (s *profileService) Review(ctx context.Context, actorUserID, requestID string, req *dtos.ReviewProfileChangeDTO) (*dtos.ProfileChangeDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+	if _, err := uuid.Parse(requestID); err != nil {
+		return nil, ErrProfileChangeNotFound
+	}
+
+	var request models.ProfileChangeRequest
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, "request_id = ?", requestID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProfileChangeNotFound
+			}
+			return err
+		}
+		if request.RequestStatus != models.ProfileChangePendingReview {
+			return ErrProfileChangeNotPending
+		}
+
+		request.ReviewedByUserID = nullString(actorUserID)
+		request.ReviewedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		request.ReviewNote = nullString(req.Note)
+		if err := tx.Model(&request).Updates(map[string]interface{}{
+			"reviewed_by_user_id": request.ReviewedByUserID,
+			"reviewed_at":         request.ReviewedAt,
+			"review_note":         request.ReviewNote,
+		}).Error; err != nil {
+			return err
+		}
+
+		if req.Decision == models.ProfileChangeRejected {
+			request.RequestStatus = models.ProfileChangeRejected
+			return tx.Model(&request).Update("request_status", request.RequestStatus).Error
+		}
+		return s.apply(ctx, tx, &request, actorUserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dto := profileChangeDTO(&request)
+	return &dto, nil
+}
+
+// apply grava o novo valor no cliente, fecha a versão vigente do campo e abre
+// a nova, registra a auditoria e executa os listeners. Um novo nome passa pela
+// triagem de sanções.
+func (s *profileService) apply(ctx context.Context, tx *gorm.DB, request *models.ProfileChangeRequest, actorUserID string) error {
+	customer, err := lockCustomer(tx, request.CustomerID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	previous := profileFieldValue(customer, request.FieldName)
+
+	switch request.FieldName {
+	case models.ProfileFieldName:
+		customer.CustomerName = request.NewValue
+		err = tx.Model(customer).Update("customer_name", customer.CustomerName).Error
+	case models.ProfileFieldEmail:
+		customer.CustomerEmail = nullString(request.NewValue)
+		err = tx.Model(customer).Select("customer_email", "email_hash").Updates(customer).Error
+	case models.ProfileFieldPhone:
+		customer.CustomerPhone = nullString(request.NewValue)
+		err = tx.Model(customer).Select("customer_phone", "phone_hash").Updates(customer).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	version, err := s.recordVersion(tx, customer, request, previous, actorUserID, now)
+	if err != nil {
+		return err
+	}
+
+	request.RequestStatus = models.ProfileChangeApplied
+	request.AppliedAt = sql.NullTime{Time: now, Valid: true}
+	if err := tx.Model(request).Updates(map[string]interface{}{
+		"request_status": request.RequestStatus,
+		"applied_at":     request.AppliedAt,
+	}).Error; err != nil {
+		return err
+	}
+
+	// email e telefone são cifrados no cadastro; a auditoria guarda apenas a
+	// forma mascarada e aponta para as versões
+	if err := recordAudit(ctx, tx, &AuditEntry{
+		Table:     models.Customer{}.TableName(),
+		RecordID:  customer.CustomerID,
+		Operation: AuditOperationUpdate,
+		UserID:    actorUserID,
+		OldValues: map[string]any{request.FieldName: auditProfileValue(request.FieldName, previous)},
+		NewValues: map[string]any{request.FieldName: auditProfileValue(request.FieldName, nullString(request.NewValue))},
+		Context: map[string]any{
+			"change_request_id": request.RequestID,
+			"version":           version,
+			"requested_by":      request.RequestedByUserID.String,
+			"reviewed_by":       request.ReviewedByUserID.String,
+		},
+	}); err != nil {
+		return err
+	}
+
+	if request.FieldName == models.ProfileFieldName {
+		if _, err := s.screening.ScreenCustomer(ctx, tx, customer.CustomerID, models.ScreeningContextProfileChange); err != nil {
+			return err
+		}
+	}
+	for _, hook := range s.listeners {
+		if err := hook(ctx, tx, customer, request); err != nil {
+			return err
+		}
+	}
+	return s.notifyChange(ctx, tx, customer, request, previous, now)
+}
+
+// recordVersion encerra a versão vigente do campo e grava a nova. Na primeira
+// alteração o valor original do cadastro vira a versão 1. Retorna o número
+// da nova versão.
+func (s *profileService) recordVersion(tx *gorm.DB, customer *models.Customer, request *models.ProfileChangeRequest, previous sql.NullString, actorUserID string, now time.Time) (int, error) {
+	var current models.CustomerProfileVersion
+	err := tx.Where("customer_id = ? AND field_name = ?", customer.CustomerID, request.FieldName).
+		Order("version DESC").
+		First(&current).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		current = models.CustomerProfileVersion{
+			CustomerID: customer.CustomerID,
+			FieldName:  request.FieldName,
+			Version:    1,
+			Value:      previous,
+			ValidFrom:  customer.DateRegistered,
+			ValidTo:    sql.NullTime{Time: now, Valid: true},
+		}
+		if err := tx.Create(&current).Error; err != nil {
+			return 0, err
+		}
+	case err != nil:
+		return 0, err
+	default:
+		if err := tx.Model(&current).Update("valid_to", now).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	next := &models.CustomerProfileVersion{
+		CustomerID:      customer.CustomerID,
+		FieldName:       request.FieldName,
+		Version:         current.Version + 1,
+		Value:           nullString(request.NewValue),
+		ValidFrom:       now,
+		ChangeRequestID: nullString(request.RequestID),
+		ChangedByUserID: nullString(actorUserID),
+	}
+	if err := tx.Create(next).Error; err != nil {
+		return 0, err
+	}
+	return next.Version, nil
+}
+
+// notifyChange avisa o cliente da alteração. Na troca de email o aviso vai
+// para o endereço anterior, que é quem precisa saber caso não a reconheça.
+func (s *profileService) notifyChange(ctx context.Context, tx *gorm.DB, customer *models.Customer, request *models.ProfileChangeRequest, previous sql.NullString, now time.Time) error {
+	notification := &NotificationRequest{
+		CustomerID: customer.CustomerID,
+		Template:   notifications.TemplateProfileChanged,
+		Channels:   []string{notifications.ChannelEmail},
+		Data: map[string]string{
+			"field": request.FieldName,
+			"date":  now.In(customerLocation(customer)).Format("02/01/2006 15:04"),
+		},
+	}
+	if request.FieldName == models.ProfileFieldEmail {
+		if !previous.Valid || previous.String == "" {
+			return nil
+		}
+		notification.Recipient = previous.String
+	}
+
+	err := s.notifications.Enqueue(ctx, tx, notification)
+	if errors.Is(err, ErrNoRecipient) {
+		log.Printf("profile: no recipient for change notice of customer %s", customer.CustomerID)
+		return nil
+	}
+	return err
+}
+
+var openProfileChangeStatuses = []string{models.ProfileChangePendingVerification, models.ProfileChangePendingReview}
+
+func lockCustomer(tx *gorm.DB, customerID string) (*models.Customer, error) {
+	var customer models.Customer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, "customer_id = ?", customerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// normalizeProfileValue padroniza o valor informado e retorna os erros de campo
+func normalizeProfileValue(field, value string) (string, map[string]string) {
+	fields := map[string]string{}
+	switch field {
+	case models.ProfileFieldName:
+		value = strings.Join(strings.Fields(value), " ")
+	case models.ProfileFieldEmail:
+		value = normalizeEmail(value)
+		if validation.Validator().Var(value, "email,max=100") != nil {
+			fields["value"] = "email inválido"
+		}
+	case models.ProfileFieldPhone:
+		value = strings.TrimSpace(value)
+		if digits := len(taxid.OnlyDigits(value)); digits < 10 || digits > 13 || len(value) > 20 {
+			fields["value"] = "telefone inválido"
+		}
+	}
+	if value == "" {
+		fields["value"] = "obrigatório"
+	}
+	return value, fields
+}
+
+func profileFieldValue(customer *models.Customer, field string) sql.NullString {
+	switch field {
+	case models.ProfileFieldName:
+		return nullString(customer.CustomerName)
+	case models.ProfileFieldEmail:
+		return customer.CustomerEmail
+	case models.ProfileFieldPhone:
+		return customer.CustomerPhone
+	}
+	return sql.NullString{}
+}
+
+func sameProfileValue(field, current, value string) bool {
+	switch field {
+	case models.ProfileFieldEmail:
+		return normalizeEmail(current) == value
+	case models.ProfileFieldPhone:
+		return taxid.OnlyDigits(current) == taxid.OnlyDigits(value)
+	}
+	return current == value
+}
+
+// auditProfileValue mascara email e telefone para o registro de auditoria
+func auditProfileValue(field string, value sql.NullString) any {
+	if !value.Valid || value.String == "" {
+		return nil
+	}
+	switch field {
+	case models.ProfileFieldEmail:
+		local, domain, ok := strings.Cut(value.String, "@")
+		if !ok || local == "" {
+			return "***"
+		}
+		return local[:1] + "***@" + domain
+	case models.ProfileFieldPhone:
+		digits := taxid.OnlyDigits(value.String)
+		if len(digits) <= 4 {
+			return "***"
+		}
+		return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+	}
+	return value.String
+}
+
+func profileChangeDTO(r *models.ProfileChangeRequest) dtos.ProfileChangeDTO {
+	dto := dtos.ProfileChangeDTO{
+		RequestID:         r.RequestID,
+		CustomerID:        r.CustomerID,
+		Field:             r.FieldName,
+		OldValue:          r.OldValue.String,
+		NewValue:          r.NewValue,
+		Reason:            r.Reason.String,
+		Status:            r.RequestStatus,
+		ExpiresAt:         nullTimePtr(r.ExpiresAt),
+		RequestedByUserID: r.RequestedByUserID.String,
+		ReviewedByUserID:  r.ReviewedByUserID.String,
+		ReviewedAt:        nullTimePtr(r.ReviewedAt),
+		ReviewNote:        r.ReviewNote.String,
+		AppliedAt:         nullTimePtr(r.AppliedAt),
+		CreatedAt:         r.CreatedAt,
+	}
+	if len(r.Documents) > 0 {
+		_ = json.Unmarshal(r.Documents, &dto.DocumentIDs)
+	}
+	return dto
+}
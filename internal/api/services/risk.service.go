@@ -0,0 +1,823 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"github.com/victor-lima-142/oak-bank/pkg/textutil"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRiskOverrideNotFound = fmt.Errorf("%w: revisão manual de risco ativa", ErrNotFound)
+	ErrRiskOverrideExpired  = fmt.Errorf("%w: a validade da revisão deve ser futura", ErrInvalidInput)
+)
+
+type RiskService interface {
+	// OnTransactionCompleted, OnKYCStatusChanged, OnAccountStatusChanged e
+	// OnProfileChanged marcam os clientes envolvidos para recálculo pelo
+	// worker. Devem ser registrados nos respectivos serviços.
+	OnTransactionCompleted(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error
+	OnKYCStatusChanged(ctx context.Context, tx *gorm.DB, customer *models.Customer, previousStatus string) error
+	OnAccountStatusChanged(ctx context.Context, tx *gorm.DB, account *models.Account, history *models.AccountStatusHistory) error
+	OnProfileChanged(ctx context.Context, tx *gorm.DB, customer *models.Customer, change *models.ProfileChangeRequest) error
+
+	Get(ctx context.Context, customerID string) (*dtos.CustomerRiskDTO, error)
+	// Assess recalcula a classificação na hora, a pedido do compliance
+	Assess(ctx context.Context, actorUserID, customerID string) (*dtos.CustomerRiskDTO, error)
+	History(ctx context.Context, customerID string, req *dtos.RiskAssessmentListRequestDTO) (*dtos.RiskAssessmentListDTO, error)
+	Overrides(ctx context.Context, customerID string) (*dtos.RiskOverrideListDTO, error)
+	// Override define a classificação manualmente, substituindo a revisão anterior
+	Override(ctx context.Context, actorUserID, customerID string, req *dtos.OverrideRiskRatingDTO) (*dtos.CustomerRiskDTO, error)
+	RevokeOverride(ctx context.Context, actorUserID, customerID string, req *dtos.RevokeRiskOverrideDTO) (*dtos.CustomerRiskDTO, error)
+
+	// Run processa os clientes marcados para recálculo e, uma vez por dia a
+	// partir de NightlyHour, recalcula todos os clientes
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// RiskConfig contém os limites de pontuação e as listas usadas nos fatores
+type RiskConfig struct {
+	// Pontuação mínima para MEDIUM e HIGH
+	MediumScore int
+	HighScore   int
+
+	// Ocupações comparadas por trecho, sem acentos e sem diferenciar maiúsculas
+	HighRiskOccupations []string
+	// Países aceitos por código ou nome. HighRisk eleva o cliente a HIGH;
+	// Monitored apenas soma pontos.
+	HighRiskCountries  []string
+	MonitoredCountries []string
+	DomesticCountries  []string
+
+	// Movimentação em VolumeWindow acima de VolumeIncomeFactor vezes a renda
+	// declarada no mesmo período, ou acima de VolumeNoIncomeAmount sem renda
+	VolumeWindow         time.Duration
+	VolumeIncomeFactor   float64
+	VolumeNoIncomeAmount float64
+
+	// NewRelationship é o tempo de relacionamento abaixo do qual o cliente é
+	// considerado novo
+	NewRelationship time.Duration
+
+	// NightlyHour é a hora local (Location) a partir da qual o recálculo
+	// diário começa
+	NightlyHour int
+	Location    *time.Location
+	BatchSize   int
+}
+
+type riskService struct {
+	db     *gorm.DB
+	config RiskConfig
+}
+
+func NewRiskService(db *gorm.DB, cfg *RiskConfig) RiskService {
+	if cfg == nil {
+		cfg = &RiskConfig{}
+	}
+	if cfg.MediumScore == 0 {
+		cfg.MediumScore = config.GetEnvInt("RISK_MEDIUM_SCORE", 30)
+	}
+	if cfg.HighScore == 0 {
+		cfg.HighScore = config.GetEnvInt("RISK_HIGH_SCORE", 60)
+	}
+	if len(cfg.HighRiskOccupations) == 0 {
+		cfg.HighRiskOccupations = foldedList(config.GetEnv("RISK_HIGH_RISK_OCCUPATIONS",
+			"cambista,doleiro,joalheiro,comerciante de joias,comerciante de obras de arte,antiquario,leiloeiro,comerciante de veiculos,corretor de imoveis,empresario de futebol,agente de atletas"))
+	}
+	if len(cfg.HighRiskCountries) == 0 {
+		cfg.HighRiskCountries = foldedList(config.GetEnv("RISK_HIGH_RISK_COUNTRIES", "IR,IRN,Irã,Iran,KP,PRK,Coreia do Norte,North Korea,MM,MMR,Mianmar,Myanmar"))
+	}
+	if len(cfg.MonitoredCountries) == 0 {
+		cfg.MonitoredCountries = foldedList(config.GetEnv("RISK_MONITORED_COUNTRIES", ""))
+	}
+	if len(cfg.DomesticCountries) == 0 {
+		cfg.DomesticCountries = foldedList(config.GetEnv("RISK_DOMESTIC_COUNTRIES", "BR,BRA,Brasil,Brazil,Brasileiro,Brasileira"))
+	}
+	if cfg.VolumeWindow == 0 {
+		cfg.VolumeWindow = config.GetEnvDuration("RISK_VOLUME_WINDOW", 90*24*time.Hour)
+	}
+	if cfg.VolumeIncomeFactor == 0 {
+		cfg.VolumeIncomeFactor = config.GetEnvFloat("RISK_VOLUME_INCOME_FACTOR", 3)
+	}
+	if cfg.VolumeNoIncomeAmount == 0 {
+		cfg.VolumeNoIncomeAmount = config.GetEnvFloat("RISK_VOLUME_NO_INCOME_AMOUNT", 50000)
+	}
+	if cfg.NewRelationship == 0 {
+		cfg.NewRelationship = config.GetEnvDuration("RISK_NEW_RELATIONSHIP", 180*24*time.Hour)
+	}
+	if cfg.NightlyHour == 0 {
+		cfg.NightlyHour = config.GetEnvInt("RISK_NIGHTLY_HOUR", 2)
+	}
+	if cfg.Location == nil {
+		cfg.Location = customerLocation(nil)
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = config.GetEnvInt("RISK_BATCH_SIZE", 100)
+	}
+
+	return &riskService{
+		db:     db,
+		config: *cfg,
+	}
+}
+
+// foldedList separa uma lista por vírgulas já normalizada para comparação
+func foldedList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = textutil.Fold(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (s *riskService) OnTransactionCompleted(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error {
+	accountIDs := []string{transaction.AccountIDOrigin}
+	if transaction.AccountIDDest.Valid {
+		accountIDs = append(accountIDs, transaction.AccountIDDest.String)
+	}
+
+	var customerIDs []string
+	if err := tx.Model(&models.Account{}).Where("account_id IN ?", accountIDs).Distinct().Pluck("customer_id", &customerIDs).Error; err != nil {
+		return err
+	}
+	return requestRiskReassessment(tx, customerIDs...)
+}
+
+func (s *riskService) OnKYCStatusChanged(ctx context.Context, tx *gorm.DB, customer *models.Customer, previousStatus string) error {
+	return requestRiskReassessment(tx, customer.CustomerID)
+}
+
+func (s *riskService) OnAccountStatusChanged(ctx context.Context, tx *gorm.DB, account *models.Account, history *models.AccountStatusHistory) error {
+	return requestRiskReassessment(tx, account.CustomerID)
+}
+
+func (s *riskService) OnProfileChanged(ctx context.Context, tx *gorm.DB, customer *models.Customer, change *models.ProfileChangeRequest) error {
+	return requestRiskReassessment(tx, customer.CustomerID)
+}
+
+// requestRiskReassessment marca os clientes para o worker. Clientes já
+// marcados mantêm a data original, preservando a ordem da fila.
+func requestRiskReassessment(tx *gorm.DB, customerIDs ...string) error {
+	if len(customerIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.Customer{}).
+		Where("customer_id IN ? AND risk_reassess_at IS NULL", customerIDs).
+		UpdateColumn("risk_reassess_at", time.Now()).Error
+}
+
+func (s *riskService) Get(ctx context.Context, customerID string) (*dtos.CustomerRiskDTO, error) {
+	var customer models.Customer
+	if err := s.db.WithContext(ctx).First(&customer, "customer_id = ?", customerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, err
+	}
+	return s.customerRisk(s.db.WithContext(ctx), &customer)
+}
+
+func (s *riskService) Assess(ctx context.Context, actorUserID, customerID string) (*dtos.CustomerRiskDTO, error) {
+	return s.withCustomer(ctx, customerID, func(tx *gorm.DB, customer *models.Customer) error {
+		return s.assess(tx, customer, models.RiskTriggerManual, actorUserID, time.Now())
+	})
+}
+
+func (s *riskService) History(ctx context.Context, customerID string, req *dtos.RiskAssessmentListRequestDTO) (*dtos.RiskAssessmentListDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.CustomerRiskAssessment{}).Where("customer_id = ?", customerID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var assessments []models.CustomerRiskAssessment
+	if err := query.Order("created_at DESC").Limit(limit).Offset(req.Offset).Find(&assessments).Error; err != nil {
+		return nil, err
+	}
+
+	response := &dtos.RiskAssessmentListDTO{
+		Assessments: make([]dtos.RiskAssessmentDTO, 0, len(assessments)),
+		TotalCount:  int(total),
+		Limit:       limit,
+		Offset:      req.Offset,
+	}
+	for i := range assessments {
+		response.Assessments = append(response.Assessments, riskAssessmentDTO(&assessments[i]))
+	}
+	return response, nil
+}
+
+func (s *riskService) Overrides(ctx context.Context, customerID string) (*dtos.RiskOverrideListDTO, error) {
+	var overrides []models.CustomerRiskOverride
+	if err := s.db.WithContext(ctx).
+		Where("customer_id = ?", customerID).
+		Order("created_at DESC").
+		Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+
+	response := &dtos.RiskOverrideListDTO{Overrides: make([]dtos.RiskOverrideDTO, 0, len(overrides))}
+	for i := range overrides {
+		response.Overrides = append(response.Overrides, riskOverrideDTO(&overrides[i]))
+	}
+	return response, nil
+}
+
+func (s *riskService) Override(ctx context.Context, actorUserID, customerID string, req *dtos.OverrideRiskRatingDTO) (*dtos.CustomerRiskDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, ErrRiskOverrideExpired
+	}
+
+	return s.withCustomer(ctx, customerID, func(tx *gorm.DB, customer *models.Customer) error {
+		if err := s.revokeActive(tx, customerID, actorUserID, "substituída por nova revisão", now); err != nil && !errors.Is(err, ErrRiskOverrideNotFound) {
+			return err
+		}
+
+		override := &models.CustomerRiskOverride{
+			CustomerID:      customerID,
+			RiskRating:      req.RiskRating,
+			Justification:   strings.TrimSpace(req.Justification),
+			CreatedByUserID: actorUserID,
+		}
+		if req.ExpiresAt != nil {
+			override.ExpiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+		}
+		if err := tx.Create(override).Error; err != nil {
+			return err
+		}
+		return s.assess(tx, customer, models.RiskTriggerOverride, actorUserID, now)
+	})
+}
+
+func (s *riskService) RevokeOverride(ctx context.Context, actorUserID, customerID string, req *dtos.RevokeRiskOverrideDTO) (*dtos.CustomerRiskDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	return s.withCustomer(ctx, customerID, func(tx *gorm.DB, customer *models.Customer) error {
+		now := time.Now()
+		if err := s.revokeActive(tx, customerID, actorUserID, req.Reason, now); err != nil {
+			return err
+		}
+		return s.assess(tx, customer, models.RiskTriggerOverride, actorUserID, now)
+	})
+}
+
+// revokeActive revoga a revisão não revogada do cliente, inclusive se já expirou
+func (s *riskService) revokeActive(tx *gorm.DB, customerID, actorUserID, reason string, now time.Time) error {
+	result := tx.Model(&models.CustomerRiskOverride{}).
+		Where("customer_id = ? AND revoked_at IS NULL", customerID).
+		Updates(map[string]interface{}{
+			"revoked_at":         now,
+			"revoked_by_user_id": nullString(actorUserID),
+			"revocation_reason":  nullString(truncate(strings.TrimSpace(reason), 1000)),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRiskOverrideNotFound
+	}
+	return nil
+}
+
+// withCustomer executa update com o cliente bloqueado e devolve a classificação atualizada
+func (s *riskService) withCustomer(ctx context.Context, customerID string, update func(tx *gorm.DB, customer *models.Customer) error) (*dtos.CustomerRiskDTO, error) {
+	var customer models.Customer
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, "customer_id = ?", customerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCustomerNotFound
+			}
+			return err
+		}
+		return update(tx, &customer)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.customerRisk(s.db.WithContext(ctx), &customer)
+}
+
+// assess calcula os fatores e grava a classificação em vigor no cliente. O
+// histórico só recebe um registro quando a pontuação, os fatores ou a
+// classificação mudam, ou quando o cálculo é manual; recálculos sem mudança
+// apenas atualizam RiskAssessedAt.
+func (s *riskService) assess(tx *gorm.DB, customer *models.Customer, trigger, actorUserID string, now time.Time) error {
+	factors, err := s.factors(tx, customer, now)
+	if err != nil {
+		return err
+	}
+	score, computed := s.rate(factors)
+
+	var override models.CustomerRiskOverride
+	hasOverride := true
+	if err := tx.Where("customer_id = ? AND revoked_at IS NULL", customer.CustomerID).First(&override).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		hasOverride = false
+	}
+	rating := computed
+	overrideID := sql.NullString{}
+	if hasOverride && override.Active(now) {
+		rating = override.RiskRating
+		overrideID = nullString(override.OverrideID)
+	}
+
+	encoded, err := json.Marshal(factors)
+	if err != nil {
+		return err
+	}
+
+	record := trigger == models.RiskTriggerManual || trigger == models.RiskTriggerOverride
+	if !record {
+		var last models.CustomerRiskAssessment
+		err := tx.Where("customer_id = ?", customer.CustomerID).Order("created_at DESC").First(&last).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			record = true
+		case err != nil:
+			return err
+		default:
+			record = last.RiskScore != score || last.RiskRating != rating || last.OverrideID != overrideID || !sameFactors(last.Factors, factors)
+		}
+	}
+	if record {
+		if err := tx.Create(&models.CustomerRiskAssessment{
+			CustomerID:       customer.CustomerID,
+			RiskScore:        score,
+			ComputedRating:   computed,
+			RiskRating:       rating,
+			Factors:          datatypes.JSON(encoded),
+			TriggerType:      trigger,
+			OverrideID:       overrideID,
+			AssessedByUserID: nullString(actorUserID),
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	customer.RiskRating = nullString(rating)
+	customer.RiskScore = sql.NullInt32{Int32: int32(score), Valid: true}
+	customer.RiskAssessedAt = sql.NullTime{Time: now, Valid: true}
+	customer.RiskReassessAt = sql.NullTime{}
+	return tx.Model(customer).UpdateColumns(map[string]interface{}{
+		"risk_rating":      customer.RiskRating,
+		"risk_score":       customer.RiskScore,
+		"risk_assessed_at": customer.RiskAssessedAt,
+		"risk_reassess_at": nil,
+	}).Error
+}
+
+// sameFactors compara os fatores gravados com os recém-calculados
+func sameFactors(stored datatypes.JSON, factors []dtos.RiskFactorDTO) bool {
+	var previous []dtos.RiskFactorDTO
+	if err := json.Unmarshal(stored, &previous); err != nil {
+		return false
+	}
+	return slices.Equal(previous, factors)
+}
+
+// rate soma os pontos e aplica o nível mínimo exigido pelos fatores
+func (s *riskService) rate(factors []dtos.RiskFactorDTO) (int, string) {
+	score := 0
+	floor := models.RiskRatingLow
+	for _, factor := range factors {
+		score += factor.Points
+		if riskLevel(factor.MinRating) > riskLevel(floor) {
+			floor = factor.MinRating
+		}
+	}
+
+	rating := models.RiskRatingLow
+	switch {
+	case score >= s.config.HighScore:
+		rating = models.RiskRatingHigh
+	case score >= s.config.MediumScore:
+		rating = models.RiskRatingMedium
+	}
+	if riskLevel(floor) > riskLevel(rating) {
+		rating = floor
+	}
+	return score, rating
+}
+
+func riskLevel(rating string) int {
+	switch rating {
+	case models.RiskRatingHigh:
+		return 3
+	case models.RiskRatingMedium:
+		return 2
+	case models.RiskRatingLow:
+		return 1
+	}
+	return 0
+}
+
+// factors avalia cada fator de risco do cliente. Pontos e níveis mínimos
+// seguem a abordagem baseada em risco da Circular BCB 3.978/2020: PEP,
+// acertos confirmados em listas restritivas, países de alto risco e
+// comunicações ao COAF elevam o cliente a HIGH.
+func (s *riskService) factors(tx *gorm.DB, customer *models.Customer, now time.Time) ([]dtos.RiskFactorDTO, error) {
+	var factors []dtos.RiskFactorDTO
+	add := func(code, description string, points int, minRating string) {
+		factors = append(factors, dtos.RiskFactorDTO{Code: code, Description: description, Points: points, MinRating: minRating})
+	}
+
+	details := map[string]any{}
+	if len(customer.OtherDetails) > 0 {
+		_ = json.Unmarshal(customer.OtherDetails, &details)
+	}
+
+	// PEP
+	if customer.IsPep {
+		add(models.RiskFactorPep, "cliente é pessoa politicamente exposta", 40, models.RiskRatingHigh)
+	}
+	if customer.CustomerType == models.CustomerTypeBusiness {
+		add(models.RiskFactorCustomerType, "pessoa jurídica", 10, "")
+
+		var pepRepresentatives int64
+		if err := tx.Model(&models.CustomerLegalRepresentative{}).
+			Where("customer_id = ? AND is_pep", customer.CustomerID).
+			Count(&pepRepresentatives).Error; err != nil {
+			return nil, err
+		}
+		if pepRepresentatives > 0 {
+			add(models.RiskFactorPep, fmt.Sprintf("%d representante(s) legal(is) politicamente exposto(s)", pepRepresentatives), 30, models.RiskRatingHigh)
+		}
+	}
+
+	// triagem em listas restritivas
+	var reviews []struct {
+		ReviewStatus string
+		Total        int64
+	}
+	if err := tx.Model(&models.ScreeningReview{}).
+		Select("review_status, COUNT(*) AS total").
+		Where("customer_id = ? AND review_status IN ?", customer.CustomerID, []string{models.ScreeningReviewPending, models.ScreeningReviewConfirmed}).
+		Group("review_status").
+		Scan(&reviews).Error; err != nil {
+		return nil, err
+	}
+	for _, review := range reviews {
+		switch review.ReviewStatus {
+		case models.ScreeningReviewConfirmed:
+			add(models.RiskFactorScreening, "acerto confirmado em lista restritiva", 50, models.RiskRatingHigh)
+		case models.ScreeningReviewPending:
+			add(models.RiskFactorScreening, "possível acerto em lista restritiva aguardando análise", 25, models.RiskRatingMedium)
+		}
+	}
+
+	// ocupação e renda declaradas
+	occupation, _ := details["occupation"].(string)
+	if customer.CustomerType != models.CustomerTypeBusiness {
+		if folded := textutil.Fold(occupation); folded == "" {
+			add(models.RiskFactorOccupation, "ocupação não informada", 5, "")
+		} else if slices.ContainsFunc(s.config.HighRiskOccupations, func(o string) bool { return strings.Contains(folded, o) }) {
+			add(models.RiskFactorOccupation, "ocupação de maior risco: "+occupation, 20, "")
+		}
+	}
+	income := declaredMonthlyIncome(customer)
+	if income <= 0 {
+		add(models.RiskFactorIncome, "renda ou faturamento não informado", 10, "")
+	}
+
+	// geografia: nacionalidade e endereços vigentes
+	nationality, _ := details["nationality"].(string)
+	places := []string{nationality}
+	var countries []string
+	if err := tx.Model(&models.Address{}).
+		Joins("JOIN customer_addresses ON customer_addresses.address_id = addresses.address_id").
+		Where("customer_addresses.customer_id = ? AND customer_addresses.effective_to IS NULL", customer.CustomerID).
+		Distinct().
+		Pluck("addresses.country", &countries).Error; err != nil {
+		return nil, err
+	}
+	places = append(places, countries...)
+	if factor, ok := s.geographyFactor(places); ok {
+		factors = append(factors, factor)
+	}
+
+	// tempo de relacionamento
+	if now.Sub(customer.DateRegistered) < s.config.NewRelationship {
+		add(models.RiskFactorRelationshipTime, "relacionamento recente", 10, "")
+	}
+
+	// produtos
+	var accounts []models.Account
+	if err := tx.Where("customer_id = ?", customer.CustomerID).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	accountIDs := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		accountIDs = append(accountIDs, account.AccountID)
+		if account.OverdraftLimit > 0 {
+			add(models.RiskFactorProduct, "conta com limite de cheque especial", 5, "")
+		}
+	}
+	var batches int64
+	if err := tx.Model(&models.PaymentBatch{}).
+		Where("customer_id = ? AND created_at >= ?", customer.CustomerID, now.Add(-s.config.VolumeWindow)).
+		Count(&batches).Error; err != nil {
+		return nil, err
+	}
+	if batches > 0 {
+		add(models.RiskFactorProduct, "uso de pagamentos em lote", 10, "")
+	}
+
+	// padrão de transações
+	if len(accountIDs) > 0 {
+		var volume float64
+		if err := externalTransactions(tx, accountIDs).
+			Select("COALESCE(SUM(transaction_amount), 0)").
+			Where("transaction_date >= ?", now.Add(-s.config.VolumeWindow)).
+			Scan(&volume).Error; err != nil {
+			return nil, err
+		}
+		months := s.config.VolumeWindow.Hours() / (24 * 30)
+		switch {
+		case income > 0 && volume > income*months*s.config.VolumeIncomeFactor:
+			add(models.RiskFactorTransactions, "movimentação incompatível com a renda declarada", 20, "")
+		case income <= 0 && volume > s.config.VolumeNoIncomeAmount:
+			add(models.RiskFactorTransactions, "movimentação relevante sem renda declarada", 15, "")
+		}
+	}
+
+	// monitoramento de AML
+	var cases []models.AmlCase
+	if err := tx.Where("customer_id = ?", customer.CustomerID).Find(&cases).Error; err != nil {
+		return nil, err
+	}
+	open, confirmed := false, false
+	for _, amlCase := range cases {
+		if amlCase.CaseStatus != models.AmlCaseStatusClosed {
+			open = true
+		}
+		if amlCase.Disposition.String == models.AmlDispositionConfirmed {
+			confirmed = true
+		}
+	}
+	if confirmed {
+		add(models.RiskFactorAml, "caso de AML encerrado como suspeita confirmada", 40, models.RiskRatingHigh)
+	}
+	if open {
+		add(models.RiskFactorAml, "caso de AML em análise", 25, models.RiskRatingMedium)
+	}
+
+	return factors, nil
+}
+
+// geographyFactor retorna o fator do local de maior risco entre nacionalidade
+// e países dos endereços
+func (s *riskService) geographyFactor(places []string) (dtos.RiskFactorDTO, bool) {
+	var foreign string
+	for _, level := range []struct {
+		list      []string
+		points    int
+		minRating string
+		label     string
+	}{
+		{s.config.HighRiskCountries, 40, models.RiskRatingHigh, "país de alto risco"},
+		{s.config.MonitoredCountries, 20, "", "país sob monitoramento"},
+	} {
+		for _, place := range places {
+			folded := textutil.Fold(place)
+			if folded == "" {
+				continue
+			}
+			if slices.Contains(level.list, folded) {
+				return dtos.RiskFactorDTO{
+					Code:        models.RiskFactorGeography,
+					Description: level.label + ": " + place,
+					Points:      level.points,
+					MinRating:   level.minRating,
+				}, true
+			}
+			if foreign == "" && !slices.Contains(s.config.DomesticCountries, folded) {
+				foreign = place
+			}
+		}
+	}
+	if foreign != "" {
+		return dtos.RiskFactorDTO{
+			Code:        models.RiskFactorGeography,
+			Description: "vínculo com o exterior: " + foreign,
+			Points:      10,
+		}, true
+	}
+	return dtos.RiskFactorDTO{}, false
+}
+
+func (s *riskService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.requestExpiredOverrides(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("risk: failed to queue expired overrides: %v", err)
+		}
+		if err := s.reassess(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("risk: reassessment failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// requestExpiredOverrides marca os clientes cuja revisão manual expirou
+// depois do último cálculo
+func (s *riskService) requestExpiredOverrides(ctx context.Context) error {
+	now := time.Now()
+	expired := s.db.Model(&models.CustomerRiskOverride{}).
+		Select("customer_id").
+		Where("revoked_at IS NULL AND expires_at <= ?", now).
+		Where("expires_at > customers.risk_assessed_at")
+	return s.db.WithContext(ctx).Model(&models.Customer{}).
+		Where("risk_reassess_at IS NULL AND customer_id IN (?)", expired).
+		UpdateColumn("risk_reassess_at", now).Error
+}
+
+// reassess alterna lotes da fila de eventos com lotes do recálculo diário,
+// para que os eventos não esperem o fim do recálculo de toda a base
+func (s *riskService) reassess(ctx context.Context) error {
+	queued, nightly := 0, 0
+	defer func() {
+		if queued > 0 || nightly > 0 {
+			log.Printf("risk: reassessed %d queued and %d scheduled customers", queued, nightly)
+		}
+	}()
+
+	for {
+		n, err := s.reassessQueued(ctx)
+		queued += n
+		if err != nil {
+			return err
+		}
+		n, err = s.reassessNightly(ctx)
+		nightly += n
+		if err != nil {
+			return err
+		}
+		if n < s.config.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *riskService) reassessQueued(ctx context.Context) (int, error) {
+	return s.reassessWhere(ctx, models.RiskTriggerEvent, func(query *gorm.DB) *gorm.DB {
+		return query.Where("risk_reassess_at IS NOT NULL").Order("risk_reassess_at")
+	})
+}
+
+// reassessNightly recalcula, a partir de NightlyHour, os clientes sem cálculo
+// desde o início da janela do dia. O progresso fica em RiskAssessedAt, então
+// o recálculo é retomado após uma queda e dividido entre instâncias.
+func (s *riskService) reassessNightly(ctx context.Context) (int, error) {
+	now := time.Now().In(s.config.Location)
+	start := time.Date(now.Year(), now.Month(), now.Day(), s.config.NightlyHour, 0, 0, 0, s.config.Location)
+	if now.Before(start) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return s.reassessWhere(ctx, models.RiskTriggerScheduled, func(query *gorm.DB) *gorm.DB {
+		return query.Where("risk_assessed_at IS NULL OR risk_assessed_at < ?", start).Order("customer_id")
+	})
+}
+
+// reassessWhere recalcula até BatchSize clientes, um por transação, reservado com SKIP LOCKED
+// para não segurar por muito tempo o bloqueio usado pelos listeners
+func (s *riskService) reassessWhere(ctx context.Context, trigger string, filter func(*gorm.DB) *gorm.DB) (int, error) {
+	done := 0
+	for done < s.config.BatchSize {
+		found := false
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var customer models.Customer
+			result := filter(tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})).Limit(1).Find(&customer)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			found = true
+			return s.assess(tx, &customer, trigger, "", time.Now())
+		})
+		if err != nil {
+			return done, err
+		}
+		if !found {
+			return done, nil
+		}
+		done++
+	}
+	return done, nil
+}
+
+// customerRisk monta a classificação atual com o último cálculo e a revisão em vigor
+func (s *riskService) customerRisk(db *gorm.DB, customer *models.Customer) (*dtos.CustomerRiskDTO, error) {
+	response := &dtos.CustomerRiskDTO{
+		CustomerID:     customer.CustomerID,
+		RiskRating:     customer.RiskRating.String,
+		RiskAssessedAt: nullTimePtr(customer.RiskAssessedAt),
+	}
+	if customer.RiskScore.Valid {
+		score := int(customer.RiskScore.Int32)
+		response.RiskScore = &score
+	}
+
+	var last models.CustomerRiskAssessment
+	if err := db.Where("customer_id = ?", customer.CustomerID).Order("created_at DESC").First(&last).Error; err == nil {
+		assessment := riskAssessmentDTO(&last)
+		response.LastAssessment = &assessment
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var override models.CustomerRiskOverride
+	if err := db.Where("customer_id = ? AND revoked_at IS NULL", customer.CustomerID).First(&override).Error; err == nil {
+		if override.Active(time.Now()) {
+			active := riskOverrideDTO(&override)
+			response.ActiveOverride = &active
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return response, nil
+}
+
+func riskAssessmentDTO(a *models.CustomerRiskAssessment) dtos.RiskAssessmentDTO {
+	var factors []dtos.RiskFactorDTO
+	_ = json.Unmarshal(a.Factors, &factors)
+	if factors == nil {
+		factors = []dtos.RiskFactorDTO{}
+	}
+	return dtos.RiskAssessmentDTO{
+		AssessmentID:     a.AssessmentID,
+		CustomerID:       a.CustomerID,
+		RiskScore:        a.RiskScore,
+		ComputedRating:   a.ComputedRating,
+		RiskRating:       a.RiskRating,
+		Factors:          factors,
+		TriggerType:      a.TriggerType,
+		OverrideID:       a.OverrideID.String,
+		AssessedByUserID: a.AssessedByUserID.String,
+		CreatedAt:        a.CreatedAt,
+	}
+}
+
+func riskOverrideDTO(o *models.CustomerRiskOverride) dtos.RiskOverrideDTO {
+	return dtos.RiskOverrideDTO{
+		OverrideID:       o.OverrideID,
+		CustomerID:       o.CustomerID,
+		RiskRating:       o.RiskRating,
+		Justification:    o.Justification,
+		CreatedByUserID:  o.CreatedByUserID,
+		ExpiresAt:        nullTimePtr(o.ExpiresAt),
+		RevokedAt:        nullTimePtr(o.RevokedAt),
+		RevokedByUserID:  o.RevokedByUserID.String,
+		RevocationReason: o.RevocationReason.String,
+		CreatedAt:        o.CreatedAt,
+	}
+}
@@ -0,0 +1,730 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/internal/screening"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrScreeningPending         = fmt.Errorf("%w: operação aguardando análise de triagem de sanções", ErrForbidden)
+	ErrSanctionsMatch           = fmt.Errorf("%w: cliente consta em lista restritiva", ErrForbidden)
+	ErrScreeningReviewNotFound  = fmt.Errorf("%w: revisão de triagem", ErrNotFound)
+	ErrScreeningReviewResolved  = fmt.Errorf("%w: revisão de triagem já analisada", ErrConflict)
+	ErrSanctionsListInvalid     = fmt.Errorf("%w: arquivo de lista inválido", ErrInvalidInput)
+	ErrSanctionsListTypeChanged = fmt.Errorf("%w: lista já importada com outro tipo", ErrConflict)
+)
+
+// Situação de triagem de um cliente
+const (
+	ScreeningStatusClear     = "CLEAR"
+	ScreeningStatusPending   = "PENDING"
+	ScreeningStatusConfirmed = "CONFIRMED"
+)
+
+type ScreeningService interface {
+	// Import grava uma nova versão da lista. Conteúdo idêntico ao da versão
+	// atual não altera nada; conteúdo novo agenda a retriagem dos clientes.
+	Import(ctx context.Context, actorUserID string, req *dtos.ImportSanctionsListDTO, file io.Reader) (*dtos.SanctionsListDTO, error)
+	ListLists(ctx context.Context) ([]dtos.SanctionsListDTO, error)
+
+	// ScreenCustomer faz a triagem do nome do cliente, abrindo uma revisão
+	// para acertos novos. Com tx nil a gravação usa uma conexão própria.
+	ScreenCustomer(ctx context.Context, tx *gorm.DB, customerID, screeningContext string) (*dtos.ScreeningResultDTO, error)
+
+	// CustomerStatus retorna CLEAR, PENDING ou CONFIRMED
+	CustomerStatus(ctx context.Context, tx *gorm.DB, customerID string) (string, error)
+
+	// GuardTransfer bloqueia transações de clientes com revisão em aberto e faz
+	// a triagem do destinatário na primeira transferência para ele. Deve ser
+	// registrado com MakeTransactionService.AddGuard.
+	GuardTransfer(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error
+
+	ListReviews(ctx context.Context, req *dtos.ScreeningReviewListRequestDTO) (*dtos.ScreeningReviewListDTO, error)
+	GetReview(ctx context.Context, reviewID string) (*dtos.ScreeningReviewDTO, error)
+
+	// Clear descarta a revisão como falso positivo
+	Clear(ctx context.Context, reviewerUserID, reviewID string, req *dtos.ResolveScreeningReviewDTO) (*dtos.ScreeningReviewDTO, error)
+
+	// Confirm confirma o acerto, bloqueando o cliente em definitivo
+	Confirm(ctx context.Context, reviewerUserID, reviewID string, req *dtos.ResolveScreeningReviewDTO) (*dtos.ScreeningReviewDTO, error)
+
+	// Rescreen refaz a triagem de todos os clientes e retorna quantas revisões foram abertas
+	Rescreen(ctx context.Context) (int, error)
+
+	// Run retriagem os clientes sempre que uma lista for atualizada
+	Run(ctx context.Context, interval time.Duration)
+}
+
+// ScreeningConfig contém os limites de similaridade (0 a 1) por tipo de lista
+type ScreeningConfig struct {
+	SanctionsThreshold float64
+	PepThreshold       float64
+	// MaxMatches limita os acertos gravados por revisão
+	MaxMatches int
+	// RescreenBatchSize é o número de clientes lidos por vez na retriagem
+	RescreenBatchSize int
+	// RescreenLease é o tempo reservado para uma instância concluir a retriagem
+	RescreenLease time.Duration
+}
+
+type screeningService struct {
+	db     *gorm.DB
+	config ScreeningConfig
+
+	mu      sync.RWMutex
+	version string
+	index   *screening.Index
+	entries map[string]screeningEntryInfo
+}
+
+type screeningEntryInfo struct {
+	listCode string
+	listType string
+}
+
+func NewScreeningService(db *gorm.DB, cfg *ScreeningConfig) ScreeningService {
+	if cfg == nil {
+		cfg = &ScreeningConfig{}
+	}
+	if cfg.SanctionsThreshold == 0 {
+		cfg.SanctionsThreshold = config.GetEnvFloat("SCREENING_SANCTIONS_THRESHOLD", 0.88)
+	}
+	if cfg.PepThreshold == 0 {
+		cfg.PepThreshold = config.GetEnvFloat("SCREENING_PEP_THRESHOLD", 0.92)
+	}
+	if cfg.MaxMatches == 0 {
+		cfg.MaxMatches = config.GetEnvInt("SCREENING_MAX_MATCHES", 20)
+	}
+	if cfg.RescreenBatchSize == 0 {
+		cfg.RescreenBatchSize = config.GetEnvInt("SCREENING_RESCREEN_BATCH_SIZE", 500)
+	}
+	if cfg.RescreenLease == 0 {
+		cfg.RescreenLease = config.GetEnvDuration("SCREENING_RESCREEN_LEASE", time.Hour)
+	}
+
+	return &screeningService{
+		db:     db,
+		config: *cfg,
+	}
+}
+
+func (s *screeningService) Import(ctx context.Context, actorUserID string, req *dtos.ImportSanctionsListDTO, file io.Reader) (*dtos.SanctionsListDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	parsed, err := screening.Parse(req.Format, bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSanctionsListInvalid, err)
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("%w: nenhuma entrada", ErrSanctionsListInvalid)
+	}
+
+	var list models.SanctionsList
+	unchanged := false
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("list_code = ?", req.ListCode).First(&list).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		exists := err == nil
+		if exists && list.ListType != req.ListType {
+			return ErrSanctionsListTypeChanged
+		}
+		if exists && list.ContentHash == hash {
+			unchanged = true
+			return nil
+		}
+
+		list.ListCode = req.ListCode
+		list.ListName = req.ListName
+		list.ListType = req.ListType
+		list.ContentHash = hash
+		list.ImportedAt = time.Now()
+		list.ImportedByUserID = nullString(actorUserID)
+		if err := tx.Save(&list).Error; err != nil {
+			return err
+		}
+
+		// as entradas que continuam na lista são reativadas pelo upsert
+		if err := tx.Model(&models.SanctionsEntry{}).Where("list_id = ?", list.ListID).Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		entries := make([]models.SanctionsEntry, 0, len(parsed))
+		seen := make(map[string]bool, len(parsed))
+		for _, e := range parsed {
+			if seen[e.ExternalID] {
+				continue
+			}
+			seen[e.ExternalID] = true
+
+			aliases, err := json.Marshal(e.Aliases)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, models.SanctionsEntry{
+				ListID:      list.ListID,
+				ExternalID:  truncate(e.ExternalID, 100),
+				EntryType:   e.EntryType,
+				PrimaryName: truncate(e.Name, 500),
+				Aliases:     datatypes.JSON(aliases),
+				Country:     nullString(truncate(e.Country, 100)),
+				Program:     nullString(truncate(e.Program, 200)),
+				DateOfBirth: nullString(truncate(e.DateOfBirth, 50)),
+				IsActive:    true,
+			})
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "list_id"}, {Name: "external_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"entry_type", "primary_name", "aliases", "country", "program", "date_of_birth", "is_active", "updated_at"}),
+		}).CreateInBatches(&entries, 500).Error; err != nil {
+			return err
+		}
+
+		list.EntryCount = len(entries)
+		return tx.Model(&list).Update("entry_count", list.EntryCount).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := sanctionsListDTO(&list)
+	response.Unchanged = unchanged
+	return &response, nil
+}
+
+func (s *screeningService) ListLists(ctx context.Context) ([]dtos.SanctionsListDTO, error) {
+	var lists []models.SanctionsList
+	if err := s.db.WithContext(ctx).Order("list_code").Find(&lists).Error; err != nil {
+		return nil, err
+	}
+	response := make([]dtos.SanctionsListDTO, 0, len(lists))
+	for i := range lists {
+		response = append(response, sanctionsListDTO(&lists[i]))
+	}
+	return response, nil
+}
+
+// loadIndex recarrega os nomes das listas quando alguma versão mudou
+func (s *screeningService) loadIndex(ctx context.Context) (*screening.Index, map[string]screeningEntryInfo, error) {
+	var version string
+	if err := s.db.WithContext(ctx).Model(&models.SanctionsList{}).
+		Select("COALESCE(string_agg(list_code || ':' || content_hash, ',' ORDER BY list_code), '')").
+		Scan(&version).Error; err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.RLock()
+	if s.index != nil && s.version == version {
+		index, entries := s.index, s.entries
+		s.mu.RUnlock()
+		return index, entries, nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index != nil && s.version == version {
+		return s.index, s.entries, nil
+	}
+
+	var rows []struct {
+		EntryID     string
+		PrimaryName string
+		Aliases     datatypes.JSON
+		ListCode    string
+		ListType    string
+	}
+	if err := s.db.WithContext(ctx).Model(&models.SanctionsEntry{}).
+		Select("sanctions_entries.entry_id, sanctions_entries.primary_name, sanctions_entries.aliases, sanctions_lists.list_code, sanctions_lists.list_type").
+		Joins("JOIN sanctions_lists ON sanctions_lists.list_id = sanctions_entries.list_id").
+		Where("sanctions_entries.is_active = ?", true).
+		Scan(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	candidates := make([]screening.Candidate, 0, len(rows))
+	entries := make(map[string]screeningEntryInfo, len(rows))
+	for _, row := range rows {
+		entries[row.EntryID] = screeningEntryInfo{listCode: row.ListCode, listType: row.ListType}
+		candidates = append(candidates, screening.Candidate{EntryID: row.EntryID, Name: row.PrimaryName})
+		var aliases []string
+		_ = json.Unmarshal(row.Aliases, &aliases)
+		for _, alias := range aliases {
+			candidates = append(candidates, screening.Candidate{EntryID: row.EntryID, Name: alias})
+		}
+	}
+
+	s.index = screening.NewIndex(candidates)
+	s.entries = entries
+	s.version = version
+	return s.index, s.entries, nil
+}
+
+// search retorna os acertos acima do limite do tipo de cada lista
+func (s *screeningService) search(ctx context.Context, name string) ([]models.ScreeningMatch, error) {
+	index, entries, err := s.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := min(s.config.SanctionsThreshold, s.config.PepThreshold)
+	var matches []models.ScreeningMatch
+	for _, m := range index.Search(name, threshold) {
+		info := entries[m.EntryID]
+		limit := s.config.SanctionsThreshold
+		if info.listType == models.SanctionsListTypePep {
+			limit = s.config.PepThreshold
+		}
+		if m.Score < limit {
+			continue
+		}
+		matches = append(matches, models.ScreeningMatch{
+			EntryID:     m.EntryID,
+			ListCode:    info.listCode,
+			MatchedName: truncate(m.MatchedName, 500),
+			Score:       float64(int(m.Score*10000)) / 10000,
+		})
+		if len(matches) == s.config.MaxMatches {
+			break
+		}
+	}
+	return matches, nil
+}
+
+func (s *screeningService) CustomerStatus(ctx context.Context, tx *gorm.DB, customerID string) (string, error) {
+	if tx == nil {
+		tx = s.db.WithContext(ctx)
+	}
+
+	var statuses []string
+	if err := tx.Model(&models.ScreeningReview{}).
+		Where("customer_id = ? AND review_status IN ?", customerID, []string{models.ScreeningReviewPending, models.ScreeningReviewConfirmed}).
+		Distinct().Pluck("review_status", &statuses).Error; err != nil {
+		return "", err
+	}
+
+	status := ScreeningStatusClear
+	for _, st := range statuses {
+		if st == models.ScreeningReviewConfirmed {
+			return ScreeningStatusConfirmed, nil
+		}
+		status = ScreeningStatusPending
+	}
+	return status, nil
+}
+
+func (s *screeningService) ScreenCustomer(ctx context.Context, tx *gorm.DB, customerID, screeningContext string) (*dtos.ScreeningResultDTO, error) {
+	if tx == nil {
+		tx = s.db.WithContext(ctx)
+	}
+
+	var customer models.Customer
+	if err := tx.First(&customer, "customer_id = ?", customerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, err
+	}
+
+	review, err := s.screen(ctx, tx, &customer, screeningContext, sql.NullString{}, sql.NullString{})
+	if err != nil {
+		return nil, err
+	}
+	status, err := s.CustomerStatus(ctx, tx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dtos.ScreeningResultDTO{CustomerID: customerID, Status: status}
+	if review != nil {
+		dto := screeningReviewDTO(review)
+		result.Review = &dto
+	}
+	return result, nil
+}
+
+// screen compara o nome do cliente com as listas. Acertos já descartados para
+// o cliente são ignorados; os demais entram na revisão pendente do cliente ou
+// em uma nova. Retorna a revisão alterada, ou nil sem acertos novos.
+func (s *screeningService) screen(ctx context.Context, db *gorm.DB, customer *models.Customer, screeningContext string, requestedBy, counterpartyAccount sql.NullString) (*models.ScreeningReview, error) {
+	matches, err := s.search(ctx, customer.CustomerName)
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+
+	entryIDs := make([]string, 0, len(matches))
+	for _, m := range matches {
+		entryIDs = append(entryIDs, m.EntryID)
+	}
+	var known []string
+	if err := db.Model(&models.ScreeningMatch{}).
+		Joins("JOIN screening_reviews ON screening_reviews.review_id = screening_matches.review_id").
+		Where("screening_reviews.customer_id = ? AND screening_matches.entry_id IN ?", customer.CustomerID, entryIDs).
+		Where("screening_reviews.review_status IN ?", []string{models.ScreeningReviewCleared, models.ScreeningReviewPending}).
+		Distinct().Pluck("screening_matches.entry_id", &known).Error; err != nil {
+		return nil, err
+	}
+	skip := make(map[string]bool, len(known))
+	for _, id := range known {
+		skip[id] = true
+	}
+	fresh := matches[:0]
+	for _, m := range matches {
+		if !skip[m.EntryID] {
+			fresh = append(fresh, m)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil, nil
+	}
+
+	var review models.ScreeningReview
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("customer_id = ? AND review_status = ?", customer.CustomerID, models.ScreeningReviewPending).
+			Order("created_at").First(&review).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			review = models.ScreeningReview{
+				CustomerID:            customer.CustomerID,
+				ScreeningContext:      screeningContext,
+				ScreenedName:          truncate(customer.CustomerName, 200),
+				RequestedByCustomerID: requestedBy,
+				CounterpartyAccountID: counterpartyAccount,
+				ReviewStatus:          models.ScreeningReviewPending,
+			}
+			err = tx.Create(&review).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		for i := range fresh {
+			fresh[i].ReviewID = review.ReviewID
+			if fresh[i].Score > review.TopScore {
+				review.TopScore = fresh[i].Score
+			}
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&fresh).Error; err != nil {
+			return err
+		}
+		return tx.Model(&review).Update("top_score", review.TopScore).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	review.Matches = fresh
+	return &review, nil
+}
+
+func (s *screeningService) GuardTransfer(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error {
+	var origin models.Account
+	if err := tx.First(&origin, "account_id = ?", transaction.AccountIDOrigin).Error; err != nil {
+		return err
+	}
+	if err := s.requireClear(ctx, tx, origin.CustomerID); err != nil {
+		return err
+	}
+	if !transaction.AccountIDDest.Valid {
+		return nil
+	}
+
+	var dest models.Account
+	if err := tx.Preload("Customer").First(&dest, "account_id = ?", transaction.AccountIDDest.String).Error; err != nil {
+		return err
+	}
+	if dest.CustomerID == origin.CustomerID || dest.Customer == nil {
+		return nil
+	}
+
+	var previous int64
+	if err := tx.Model(&models.Transaction{}).
+		Where("account_id_origin IN (?)", tx.Model(&models.Account{}).Select("account_id").Where("customer_id = ?", origin.CustomerID)).
+		Where("account_id_dest = ? AND transaction_status = ?", dest.AccountID, models.TransactionStatusCompleted).
+		Limit(1).Count(&previous).Error; err != nil {
+		return err
+	}
+	if previous == 0 {
+		review, err := s.screen(ctx, outsideGuardTx(ctx, s.db), dest.Customer, models.ScreeningContextTransfer,
+			nullString(origin.CustomerID), nullString(dest.AccountID))
+		if err != nil {
+			return err
+		}
+		if review != nil {
+			return ErrScreeningPending
+		}
+	}
+	return s.requireClear(ctx, outsideGuardTx(ctx, s.db), dest.CustomerID)
+}
+
+func (s *screeningService) requireClear(ctx context.Context, tx *gorm.DB, customerID string) error {
+	status, err := s.CustomerStatus(ctx, tx, customerID)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case ScreeningStatusConfirmed:
+		return ErrSanctionsMatch
+	case ScreeningStatusPending:
+		return ErrScreeningPending
+	}
+	return nil
+}
+
+func (s *screeningService) ListReviews(ctx context.Context, req *dtos.ScreeningReviewListRequestDTO) (*dtos.ScreeningReviewListDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.ScreeningReview{})
+	if req.Status != "" {
+		query = query.Where("review_status = ?", req.Status)
+	}
+	if req.Context != "" {
+		query = query.Where("screening_context = ?", req.Context)
+	}
+	if req.CustomerID != "" {
+		query = query.Where("customer_id = ?", req.CustomerID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var reviews []models.ScreeningReview
+	if err := query.Preload("Matches", func(db *gorm.DB) *gorm.DB { return db.Order("score DESC") }).
+		Order("top_score DESC, created_at").Limit(limit).Offset(req.Offset).Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+
+	response := &dtos.ScreeningReviewListDTO{
+		Reviews:    make([]dtos.ScreeningReviewDTO, 0, len(reviews)),
+		TotalCount: int(total),
+		Limit:      limit,
+		Offset:     req.Offset,
+	}
+	for i := range reviews {
+		response.Reviews = append(response.Reviews, screeningReviewDTO(&reviews[i]))
+	}
+	return response, nil
+}
+
+func (s *screeningService) GetReview(ctx context.Context, reviewID string) (*dtos.ScreeningReviewDTO, error) {
+	var review models.ScreeningReview
+	if err := s.db.WithContext(ctx).
+		Preload("Matches", func(db *gorm.DB) *gorm.DB { return db.Order("score DESC") }).
+		First(&review, "review_id = ?", reviewID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScreeningReviewNotFound
+		}
+		return nil, err
+	}
+	response := screeningReviewDTO(&review)
+	return &response, nil
+}
+
+func (s *screeningService) Clear(ctx context.Context, reviewerUserID, reviewID string, req *dtos.ResolveScreeningReviewDTO) (*dtos.ScreeningReviewDTO, error) {
+	return s.resolve(ctx, reviewerUserID, reviewID, req, models.ScreeningReviewCleared)
+}
+
+func (s *screeningService) Confirm(ctx context.Context, reviewerUserID, reviewID string, req *dtos.ResolveScreeningReviewDTO) (*dtos.ScreeningReviewDTO, error) {
+	return s.resolve(ctx, reviewerUserID, reviewID, req, models.ScreeningReviewConfirmed)
+}
+
+func (s *screeningService) resolve(ctx context.Context, reviewerUserID, reviewID string, req *dtos.ResolveScreeningReviewDTO, status string) (*dtos.ScreeningReviewDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	var review models.ScreeningReview
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, "review_id = ?", reviewID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrScreeningReviewNotFound
+			}
+			return err
+		}
+		if review.ReviewStatus != models.ScreeningReviewPending {
+			return ErrScreeningReviewResolved
+		}
+
+		review.ReviewStatus = status
+		review.ReviewedByUserID = nullString(reviewerUserID)
+		review.ReviewedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		review.ReviewNote = nullString(truncate(req.Note, 1000))
+		return tx.Model(&review).Updates(map[string]interface{}{
+			"review_status":       review.ReviewStatus,
+			"reviewed_by_user_id": review.ReviewedByUserID,
+			"reviewed_at":         review.ReviewedAt,
+			"review_note":         review.ReviewNote,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetReview(ctx, review.ReviewID)
+}
+
+func (s *screeningService) Rescreen(ctx context.Context) (int, error) {
+	opened := 0
+	lastID := ""
+	for {
+		var customers []models.Customer
+		query := s.db.WithContext(ctx).Select("customer_id", "customer_name").Order("customer_id").Limit(s.config.RescreenBatchSize)
+		if lastID != "" {
+			query = query.Where("customer_id > ?", lastID)
+		}
+		if err := query.Find(&customers).Error; err != nil {
+			return opened, err
+		}
+		if len(customers) == 0 {
+			return opened, nil
+		}
+
+		for i := range customers {
+			if err := ctx.Err(); err != nil {
+				return opened, err
+			}
+			review, err := s.screen(ctx, s.db.WithContext(ctx), &customers[i], models.ScreeningContextRescreen, sql.NullString{}, sql.NullString{})
+			if err != nil {
+				return opened, err
+			}
+			if review != nil {
+				opened++
+			}
+		}
+		lastID = customers[len(customers)-1].CustomerID
+	}
+}
+
+func (s *screeningService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.rescreenUpdatedLists(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("screening: rescreen failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rescreenUpdatedLists reserva as listas importadas depois da última
+// retriagem e refaz a triagem de todos os clientes. Se a instância cair, a
+// reserva expira e outra instância assume.
+func (s *screeningService) rescreenUpdatedLists(ctx context.Context) error {
+	started := time.Now()
+
+	var lists []models.SanctionsList
+	result := s.db.WithContext(ctx).Model(&lists).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "list_id"}, {Name: "list_code"}}}).
+		Where("rescreened_at IS NULL OR rescreened_at < imported_at").
+		Where("rescreen_lease_until IS NULL OR rescreen_lease_until < ?", started).
+		Update("rescreen_lease_until", started.Add(s.config.RescreenLease))
+	if result.Error != nil || len(lists) == 0 {
+		return result.Error
+	}
+
+	opened, err := s.Rescreen(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(lists))
+	for _, list := range lists {
+		ids = append(ids, list.ListID)
+	}
+	log.Printf("screening: rescreened customers after update of %d list(s), %d review(s) opened", len(lists), opened)
+	return s.db.WithContext(ctx).Model(&models.SanctionsList{}).Where("list_id IN ?", ids).Updates(map[string]interface{}{
+		"rescreened_at":        started,
+		"rescreen_lease_until": nil,
+	}).Error
+}
+
+func sanctionsListDTO(l *models.SanctionsList) dtos.SanctionsListDTO {
+	return dtos.SanctionsListDTO{
+		ListID:       l.ListID,
+		ListCode:     l.ListCode,
+		ListName:     l.ListName,
+		ListType:     l.ListType,
+		ContentHash:  l.ContentHash,
+		EntryCount:   l.EntryCount,
+		ImportedAt:   l.ImportedAt,
+		RescreenedAt: nullTimePtr(l.RescreenedAt),
+	}
+}
+
+func screeningReviewDTO(r *models.ScreeningReview) dtos.ScreeningReviewDTO {
+	response := dtos.ScreeningReviewDTO{
+		ReviewID:              r.ReviewID,
+		CustomerID:            r.CustomerID,
+		ScreeningContext:      r.ScreeningContext,
+		ScreenedName:          r.ScreenedName,
+		RequestedByCustomerID: r.RequestedByCustomerID.String,
+		CounterpartyAccountID: r.CounterpartyAccountID.String,
+		TopScore:              r.TopScore,
+		ReviewStatus:          r.ReviewStatus,
+		ReviewedByUserID:      r.ReviewedByUserID.String,
+		ReviewedAt:            nullTimePtr(r.ReviewedAt),
+		ReviewNote:            r.ReviewNote.String,
+		CreatedAt:             r.CreatedAt,
+	}
+	for _, m := range r.Matches {
+		response.Matches = append(response.Matches, dtos.ScreeningMatchDTO{
+			EntryID:     m.EntryID,
+			ListCode:    m.ListCode,
+			MatchedName: m.MatchedName,
+			Score:       m.Score,
+		})
+	}
+	return response
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+)
+
+type SpendingInsightsService interface {
+	// Spending retorna o gasto mensal por categoria, as principais contrapartes
+	// e a variação mês a mês a partir das categorias gravadas
+	Spending(ctx context.Context, userID string, req *dtos.SpendingInsightsRequestDTO) (*dtos.SpendingInsightsResponseDTO, error)
+}
+
+type spendingInsightsService struct {
+	db *gorm.DB
+}
+
+func NewSpendingInsightsService(db *gorm.DB) SpendingInsightsService {
+	return &spendingInsightsService{
+		db: db,
+	}
+}
+
+type monthlyCategoryRow struct {
+	Month        string
+	CategoryCode string
+	Amount       float64
+	Count        int
+}
+
+type counterpartyRow struct {
+	Counterparty string
+	Amount       float64
+	Count        int
+}
+
+func (s *spendingInsightsService) Spending(ctx context.Context, userID string, req *dtos.SpendingInsightsRequestDTO) (*dtos.SpendingInsightsResponseDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	months := req.Months
+	if months <= 0 {
+		months = 6
+	}
+	top := req.Top
+	if top <= 0 {
+		top = 5
+	}
+
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	from := currentMonth.AddDate(0, -(months - 1), 0)
+
+	var rows []monthlyCategoryRow
+	if err := s.db.WithContext(ctx).Model(&models.TransactionCategory{}).
+		Select("to_char(date_trunc('month', transaction_date), 'YYYY-MM') AS month, category_code, SUM(amount) AS amount, COUNT(*) AS count").
+		Where("customer_id = ? AND direction = ? AND transaction_date >= ?", customerID, models.CategoryDirectionOutflow, from).
+		Group("month, category_code").
+		Order("month, amount DESC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var counterparties []counterpartyRow
+	if err := s.db.WithContext(ctx).Model(&models.TransactionCategory{}).
+		Select("COALESCE(counterparty_name, counterparty_key) AS counterparty, SUM(amount) AS amount, COUNT(*) AS count").
+		Where("customer_id = ? AND direction = ? AND transaction_date >= ? AND counterparty_key IS NOT NULL", customerID, models.CategoryDirectionOutflow, from).
+		Group("counterparty").
+		Order("amount DESC").
+		Limit(top).
+		Scan(&counterparties).Error; err != nil {
+		return nil, err
+	}
+
+	descriptions, err := s.categoryDescriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.SpendingInsightsResponseDTO{
+		From:              from,
+		To:                now,
+		Months:            make([]dtos.MonthlySpendingDTO, 0, months),
+		TopCounterparties: make([]dtos.CounterpartySpendingDTO, 0, len(counterparties)),
+	}
+
+	// todos os meses do período aparecem, mesmo sem gastos
+	byMonth := make(map[string]*dtos.MonthlySpendingDTO, months)
+	for i := 0; i < months; i++ {
+		key := from.AddDate(0, i, 0).Format("2006-01")
+		response.Months = append(response.Months, dtos.MonthlySpendingDTO{
+			Month:      key,
+			Categories: []dtos.CategorySpendingDTO{},
+		})
+	}
+	for i := range response.Months {
+		byMonth[response.Months[i].Month] = &response.Months[i]
+	}
+
+	for _, row := range rows {
+		month, ok := byMonth[row.Month]
+		if !ok {
+			continue
+		}
+		month.Total += row.Amount
+		month.Categories = append(month.Categories, dtos.CategorySpendingDTO{
+			CategoryCode: row.CategoryCode,
+			Description:  descriptions[row.CategoryCode],
+			Amount:       row.Amount,
+			Count:        row.Count,
+		})
+	}
+
+	for _, cp := range counterparties {
+		response.TopCounterparties = append(response.TopCounterparties, dtos.CounterpartySpendingDTO{
+			Counterparty: cp.Counterparty,
+			Amount:       cp.Amount,
+			Count:        cp.Count,
+		})
+	}
+
+	response.MonthOverMonth = monthOverMonth(
+		byMonth[currentMonth.Format("2006-01")],
+		byMonth[currentMonth.AddDate(0, -1, 0).Format("2006-01")],
+	)
+	return response, nil
+}
+
+func (s *spendingInsightsService) categoryDescriptions(ctx context.Context) (map[string]string, error) {
+	var categories []models.RefTransactionCategory
+	if err := s.db.WithContext(ctx).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	descriptions := make(map[string]string, len(categories))
+	for _, c := range categories {
+		descriptions[c.CategoryCode] = c.Description
+	}
+	return descriptions, nil
+}
+
+// monthOverMonth compara o mês atual com o anterior por categoria
+func monthOverMonth(current, previous *dtos.MonthlySpendingDTO) []dtos.CategoryChangeDTO {
+	amounts := map[string][2]float64{}
+	if current != nil {
+		for _, c := range current.Categories {
+			v := amounts[c.CategoryCode]
+			v[0] = c.Amount
+			amounts[c.CategoryCode] = v
+		}
+	}
+	if previous != nil {
+		for _, c := range previous.Categories {
+			v := amounts[c.CategoryCode]
+			v[1] = c.Amount
+			amounts[c.CategoryCode] = v
+		}
+	}
+
+	changes := make([]dtos.CategoryChangeDTO, 0, len(amounts))
+	for code, v := range amounts {
+		change := dtos.CategoryChangeDTO{
+			CategoryCode:  code,
+			CurrentMonth:  v[0],
+			PreviousMonth: v[1],
+			Change:        v[0] - v[1],
+		}
+		if v[1] > 0 {
+			percent := (v[0] - v[1]) / v[1] * 100
+			change.ChangePercent = &percent
+		}
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return math.Abs(changes[i].Change) > math.Abs(changes[j].Change)
+	})
+	return changes
+}
@@ -0,0 +1,439 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"github.com/victor-lima-142/oak-bank/pkg/textutil"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCategoryNotFound            = fmt.Errorf("%w: categoria", ErrNotFound)
+	ErrCategoryRuleNotFound        = fmt.Errorf("%w: regra de categorização", ErrNotFound)
+	ErrTransactionCategoryNotFound = fmt.Errorf("%w: transação categorizada", ErrNotFound)
+	ErrCategoryDirectionMismatch   = fmt.Errorf("%w: categoria não se aplica ao sentido da transação", ErrInvalidInput)
+	ErrInvalidRulePattern          = fmt.Errorf("%w: padrão de descrição inválido", ErrInvalidInput)
+	ErrEmptyRule                   = fmt.Errorf("%w: a regra precisa de ao menos um critério", ErrInvalidInput)
+)
+
+// metadataCounterpartyKeys são as chaves de Transaction.Metadata consultadas
+// para identificar a contraparte de pagamentos sem conta de destino interna
+var metadataCounterpartyKeys = []string{"counterparty_name", "counterparty", "merchant_name", "merchant", "payee"}
+
+type TransactionCategoryService interface {
+	// Categorize grava a categoria da transação para cada cliente envolvido.
+	// Deve ser registrado como listener de conclusão de transação.
+	Categorize(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error
+
+	// ListCategories retorna as categorias disponíveis
+	ListCategories(ctx context.Context) ([]dtos.TransactionCategoryDTO, error)
+
+	// Recategorize altera a categoria de uma transação do cliente e, se
+	// solicitado, lembra a escolha para as próximas transações
+	Recategorize(ctx context.Context, userID, transactionID string, req *dtos.UpdateTransactionCategoryDTO) (*dtos.TransactionCategoryResponseDTO, error)
+
+	ListRules(ctx context.Context) ([]models.CategoryRule, error)
+	CreateRule(ctx context.Context, req *dtos.CategoryRuleDTO) (*models.CategoryRule, error)
+	UpdateRule(ctx context.Context, ruleID string, req *dtos.CategoryRuleDTO) (*models.CategoryRule, error)
+	DeleteRule(ctx context.Context, ruleID string) error
+}
+
+type transactionCategoryService struct {
+	db *gorm.DB
+}
+
+func NewTransactionCategoryService(db *gorm.DB) TransactionCategoryService {
+	return &transactionCategoryService{
+		db: db,
+	}
+}
+
+// categorizationParty é um dos lados da transação a ser categorizado
+type categorizationParty struct {
+	customerID       string
+	direction        string
+	counterpartyKey  string
+	counterpartyName string
+}
+
+func (s *transactionCategoryService) Categorize(ctx context.Context, tx *gorm.DB, transaction *models.Transaction) error {
+	parties, err := s.partiesFor(tx, transaction)
+	if err != nil {
+		return err
+	}
+
+	rules, err := s.activeRules(tx)
+	if err != nil {
+		return err
+	}
+
+	description := textutil.Fold(transaction.Description.String)
+	for _, party := range parties {
+		category := &models.TransactionCategory{
+			TransactionID:    transaction.TransactionID,
+			CustomerID:       party.customerID,
+			Direction:        party.direction,
+			Amount:           transaction.TransactionAmount,
+			CounterpartyKey:  nullString(party.counterpartyKey),
+			CounterpartyName: nullString(party.counterpartyName),
+			TransactionDate:  transaction.TransactionDate,
+		}
+
+		code, source, ruleID, err := s.resolveCategory(tx, party, description, transaction.TransactionTypeCode, rules)
+		if err != nil {
+			return err
+		}
+		category.CategoryCode = code
+		category.CategorySource = source
+		category.RuleID = nullString(ruleID)
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(category).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *transactionCategoryService) ListCategories(ctx context.Context) ([]dtos.TransactionCategoryDTO, error) {
+	var categories []models.RefTransactionCategory
+	if err := s.db.WithContext(ctx).Order("direction DESC, description").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.TransactionCategoryDTO, 0, len(categories))
+	for _, c := range categories {
+		result = append(result, dtos.TransactionCategoryDTO{
+			CategoryCode: c.CategoryCode,
+			Description:  c.Description,
+			Direction:    c.Direction,
+		})
+	}
+	return result, nil
+}
+
+func (s *transactionCategoryService) Recategorize(ctx context.Context, userID, transactionID string, req *dtos.UpdateTransactionCategoryDTO) (*dtos.TransactionCategoryResponseDTO, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var current models.TransactionCategory
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&current, "transaction_id = ? AND customer_id = ?", transactionID, customerID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTransactionCategoryNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var category models.RefTransactionCategory
+		err = tx.First(&category, "category_code = ?", strings.ToUpper(req.CategoryCode)).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCategoryNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if category.Direction != current.Direction {
+			return ErrCategoryDirectionMismatch
+		}
+
+		current.CategoryCode = category.CategoryCode
+		current.CategorySource = models.CategorySourceManual
+		current.RuleID = sql.NullString{}
+		if err := tx.Model(&current).Updates(map[string]interface{}{
+			"category_code":   current.CategoryCode,
+			"category_source": current.CategorySource,
+			"rule_id":         nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		if !req.Remember {
+			return nil
+		}
+		return s.rememberOverride(tx, customerID, &current)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.TransactionCategoryResponseDTO{
+		TransactionID:    current.TransactionID,
+		CategoryCode:     current.CategoryCode,
+		CategorySource:   current.CategorySource,
+		Direction:        current.Direction,
+		Amount:           current.Amount,
+		CounterpartyName: current.CounterpartyName.String,
+		CategorizedAt:    current.CategorizedAt,
+	}, nil
+}
+
+func (s *transactionCategoryService) ListRules(ctx context.Context) ([]models.CategoryRule, error) {
+	var rules []models.CategoryRule
+	err := s.db.WithContext(ctx).Order("priority, created_at").Find(&rules).Error
+	return rules, err
+}
+
+func (s *transactionCategoryService) CreateRule(ctx context.Context, req *dtos.CategoryRuleDTO) (*models.CategoryRule, error) {
+	rule := &models.CategoryRule{IsActive: true}
+	if err := s.applyRule(ctx, rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Create(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *transactionCategoryService) UpdateRule(ctx context.Context, ruleID string, req *dtos.CategoryRuleDTO) (*models.CategoryRule, error) {
+	var rule models.CategoryRule
+	err := s.db.WithContext(ctx).First(&rule, "rule_id = ?", ruleID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCategoryRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyRule(ctx, &rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Save(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (s *transactionCategoryService) DeleteRule(ctx context.Context, ruleID string) error {
+	result := s.db.WithContext(ctx).Delete(&models.CategoryRule{}, "rule_id = ?", ruleID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCategoryRuleNotFound
+	}
+	return nil
+}
+
+func (s *transactionCategoryService) applyRule(ctx context.Context, rule *models.CategoryRule, req *dtos.CategoryRuleDTO) error {
+	if req.DescriptionPattern == "" && req.CounterpartyKey == "" && req.TransactionTypeCode == "" {
+		return ErrEmptyRule
+	}
+	if req.DescriptionPattern != "" {
+		if _, err := regexp.Compile(req.DescriptionPattern); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidRulePattern, err)
+		}
+	}
+
+	var category models.RefTransactionCategory
+	err := s.db.WithContext(ctx).First(&category, "category_code = ?", strings.ToUpper(req.CategoryCode)).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCategoryNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if category.Direction != req.Direction {
+		return ErrCategoryDirectionMismatch
+	}
+
+	rule.CategoryCode = category.CategoryCode
+	rule.Priority = req.Priority
+	rule.Direction = req.Direction
+	rule.DescriptionPattern = nullString(req.DescriptionPattern)
+	rule.CounterpartyKey = nullString(req.CounterpartyKey)
+	rule.TransactionTypeCode = nullString(req.TransactionTypeCode)
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+// partiesFor monta os lados da transação: o cliente de origem (saída) e,
+// se houver conta de destino interna, o cliente de destino (entrada)
+func (s *transactionCategoryService) partiesFor(tx *gorm.DB, transaction *models.Transaction) ([]categorizationParty, error) {
+	ids := []string{transaction.AccountIDOrigin}
+	if transaction.AccountIDDest.Valid {
+		ids = append(ids, transaction.AccountIDDest.String)
+	}
+
+	var accounts []models.Account
+	if err := tx.Preload("Customer").Where("account_id IN ?", ids).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*models.Account, len(accounts))
+	for i := range accounts {
+		byID[accounts[i].AccountID] = &accounts[i]
+	}
+
+	origin, ok := byID[transaction.AccountIDOrigin]
+	if !ok {
+		return nil, fmt.Errorf("%w: origem", ErrAccountNotFound)
+	}
+
+	out := categorizationParty{
+		customerID: origin.CustomerID,
+		direction:  models.CategoryDirectionOutflow,
+	}
+	var parties []categorizationParty
+
+	if dest, ok := byID[transaction.AccountIDDest.String]; ok {
+		out.counterpartyKey = "account:" + dest.AccountID
+		out.counterpartyName = customerName(dest)
+		parties = append(parties, out, categorizationParty{
+			customerID:       dest.CustomerID,
+			direction:        models.CategoryDirectionInflow,
+			counterpartyKey:  "account:" + origin.AccountID,
+			counterpartyName: customerName(origin),
+		})
+		return parties, nil
+	}
+
+	if name := metadataCounterparty(transaction); name != "" {
+		out.counterpartyKey = "name:" + textutil.Fold(name)
+		out.counterpartyName = name
+	}
+	return append(parties, out), nil
+}
+
+// resolveCategory aplica, nesta ordem: preferência lembrada do cliente,
+// regras globais por prioridade e a categoria padrão do sentido
+func (s *transactionCategoryService) resolveCategory(tx *gorm.DB, party categorizationParty, description, typeCode string, rules []models.CategoryRule) (string, string, string, error) {
+	override, err := s.findOverride(tx, party.customerID, party.counterpartyKey, description)
+	if err != nil {
+		return "", "", "", err
+	}
+	if override != nil {
+		return override.CategoryCode, models.CategorySourceOverride, "", nil
+	}
+
+	for _, rule := range rules {
+		if ruleMatches(&rule, party, description, typeCode) {
+			return rule.CategoryCode, models.CategorySourceRule, rule.RuleID, nil
+		}
+	}
+
+	if party.direction == models.CategoryDirectionInflow {
+		return models.DefaultInflowCategory, models.CategorySourceDefault, "", nil
+	}
+	return models.DefaultOutflowCategory, models.CategorySourceDefault, "", nil
+}
+
+func (s *transactionCategoryService) findOverride(tx *gorm.DB, customerID, counterpartyKey, description string) (*models.CustomerCategoryOverride, error) {
+	candidates := []struct{ matchType, value string }{
+		{models.CategoryOverrideMatchCounterparty, counterpartyKey},
+		{models.CategoryOverrideMatchDescription, description},
+	}
+
+	for _, candidate := range candidates {
+		if candidate.value == "" {
+			continue
+		}
+		var override models.CustomerCategoryOverride
+		err := tx.Where("customer_id = ? AND match_type = ? AND match_value = ?", customerID, candidate.matchType, candidate.value).
+			First(&override).Error
+		if err == nil {
+			return &override, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (s *transactionCategoryService) rememberOverride(tx *gorm.DB, customerID string, category *models.TransactionCategory) error {
+	override := &models.CustomerCategoryOverride{
+		CustomerID:   customerID,
+		CategoryCode: category.CategoryCode,
+	}
+
+	switch {
+	case category.CounterpartyKey.Valid:
+		override.MatchType = models.CategoryOverrideMatchCounterparty
+		override.MatchValue = category.CounterpartyKey.String
+	default:
+		var description string
+		if err := tx.Model(&models.Transaction{}).
+			Where("transaction_id = ?", category.TransactionID).
+			Pluck("description", &description).Error; err != nil {
+			return err
+		}
+		description = textutil.Fold(description)
+		if description == "" {
+			// nada que identifique transações futuras
+			return nil
+		}
+		override.MatchType = models.CategoryOverrideMatchDescription
+		override.MatchValue = truncate(description, 200)
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "customer_id"}, {Name: "match_type"}, {Name: "match_value"}},
+		DoUpdates: clause.AssignmentColumns([]string{"category_code", "updated_at"}),
+	}).Create(override).Error
+}
+
+func (s *transactionCategoryService) activeRules(tx *gorm.DB) ([]models.CategoryRule, error) {
+	var rules []models.CategoryRule
+	err := tx.Where("is_active = ?", true).Order("priority, created_at").Find(&rules).Error
+	return rules, err
+}
+
+func ruleMatches(rule *models.CategoryRule, party categorizationParty, description, typeCode string) bool {
+	if rule.Direction != party.direction {
+		return false
+	}
+	if rule.TransactionTypeCode.Valid && rule.TransactionTypeCode.String != typeCode {
+		return false
+	}
+	if rule.CounterpartyKey.Valid && rule.CounterpartyKey.String != party.counterpartyKey {
+		return false
+	}
+	if rule.DescriptionPattern.Valid {
+		pattern, err := regexp.Compile("(?i)" + rule.DescriptionPattern.String)
+		if err != nil || !pattern.MatchString(description) {
+			return false
+		}
+	}
+	return true
+}
+
+func metadataCounterparty(transaction *models.Transaction) string {
+	if len(transaction.Metadata) == 0 {
+		return ""
+	}
+	var metadata map[string]any
+	if err := json.Unmarshal(transaction.Metadata, &metadata); err != nil {
+		return ""
+	}
+	for _, key := range metadataCounterpartyKeys {
+		if value, ok := metadata[key].(string); ok && strings.TrimSpace(value) != "" {
+			return truncate(strings.TrimSpace(value), 200)
+		}
+	}
+	return ""
+}
+
+func customerName(acc *models.Account) string {
+	if acc.Customer != nil {
+		return acc.Customer.CustomerName
+	}
+	return acc.AccountNumber
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
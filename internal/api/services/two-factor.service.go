@@ -0,0 +1,749 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/security"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/internal/notifications"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTwoFactorEnabled          = fmt.Errorf("%w: a verificação em duas etapas já está ativa", ErrConflict)
+	ErrTwoFactorNotEnabled       = fmt.Errorf("%w: a verificação em duas etapas não está ativa", ErrConflict)
+	ErrTwoFactorNoEnrollment     = fmt.Errorf("%w: inicie a configuração da verificação em duas etapas", ErrConflict)
+	ErrTwoFactorCodeInvalid      = fmt.Errorf("%w: código de verificação inválido", ErrInvalidInput)
+	ErrTwoFactorUseAuthenticator = fmt.Errorf("%w: use o código do aplicativo autenticador", ErrConflict)
+	ErrTwoFactorChannel          = fmt.Errorf("%w: o código é enviado apenas pelo método da verificação em duas etapas", ErrInvalidInput)
+	ErrStepUpInvalid             = fmt.Errorf("%w: verificação adicional ausente, inválida ou expirada", ErrForbidden)
+	ErrStepUpLocked              = fmt.Errorf("%w: verificação adicional bloqueada temporariamente por excesso de tentativas", ErrForbidden)
+)
+
+// recoveryCodeAlphabet omite os caracteres confundíveis (0/O, 1/I/L)
+const recoveryCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+type TwoFactorService interface {
+	Status(ctx context.Context, userID string) (*dtos.TwoFactorStatusDTO, error)
+
+	// Enroll gera um novo segredo TOTP, ainda inativo, e retorna os dados
+	// para o aplicativo autenticador. Uma configuração pendente é substituída.
+	Enroll(ctx context.Context, userID string, req *dtos.TwoFactorEnrollDTO) (*dtos.TwoFactorEnrollmentDTO, error)
+
+	// EnrollOTP escolhe o envio de códigos por email ou SMS, ainda inativo, e
+	// envia o código que o confirma. Uma configuração pendente é substituída.
+	EnrollOTP(ctx context.Context, userID string, req *dtos.TwoFactorEnrollOTPDTO) (*dtos.OneTimeCodeSentDTO, error)
+
+	// Confirm ativa a verificação com o primeiro código do aplicativo ou o
+	// código enviado por EnrollOTP e retorna os códigos de recuperação
+	Confirm(ctx context.Context, userID string, req *dtos.TwoFactorConfirmDTO) (*dtos.TwoFactorRecoveryCodesDTO, error)
+
+	// Disable desativa a verificação e apaga o segredo e os códigos de
+	// recuperação. Com email ou SMS o código é o enviado por SendCode. Os
+	// códigos errados contam para o bloqueio como em StepUp.
+	Disable(ctx context.Context, userID string, req *dtos.TwoFactorReauthenticateDTO) error
+
+	// RegenerateRecoveryCodes substitui os códigos de recuperação. Os códigos
+	// errados contam para o bloqueio como em StepUp.
+	RegenerateRecoveryCodes(ctx context.Context, userID string, req *dtos.TwoFactorReauthenticateDTO) (*dtos.TwoFactorRecoveryCodesDTO, error)
+
+	// SendCode envia o código das confirmações de identidade (StepUp,
+	// Disable, RegenerateRecoveryCodes) pelo método do usuário ou, sem
+	// verificação em duas etapas, pelo canal pedido
+	SendCode(ctx context.Context, userID string, req *dtos.TwoFactorVerificationSenderDTO) (*dtos.OneTimeCodeSentDTO, error)
+
+	// StepUp confere o código do aplicativo ou o enviado por SendCode e
+	// retorna o token exigido pelas operações sensíveis. As tentativas ficam
+	// em UserAuthLog e os códigos errados contam para o bloqueio do usuário
+	// como as senhas erradas no login.
+	StepUp(ctx context.Context, userID string, req *dtos.StepUpDTO) (*dtos.StepUpTokenDTO, error)
+
+	// ConsumeStepUpToken confere que o token de StepUp é válido e do usuário
+	// e o marca como usado; cada token vale para uma única requisição
+	ConsumeStepUpToken(ctx context.Context, userID, token string) error
+
+	// SendLoginCode envia, na transação tx, o código da segunda etapa do
+	// login aos usuários com email ou SMS. Retorna nil com o método TOTP.
+	SendLoginCode(ctx context.Context, tx *gorm.DB, user *models.User) (*dtos.OneTimeCodeSentDTO, error)
+
+	// VerifyCode confere, na transação tx, o código TOTP ou o código enviado
+	// para purpose e, com allowRecovery, um código de recuperação, que é
+	// consumido. O usuário deve ter sido lido com FOR UPDATE, para que o
+	// mesmo código não seja aceito duas vezes. Os erros de verifyOneTimeCode
+	// são retornados e a tentativa deve ser confirmada pelo chamador.
+	VerifyCode(ctx context.Context, tx *gorm.DB, user *models.User, purpose, code string, allowRecovery bool) (bool, error)
+}
+
+// TwoFactorConfig contém os parâmetros do TOTP, dos códigos enviados por
+// email ou SMS e dos códigos de recuperação
+type TwoFactorConfig struct {
+	// Issuer é o nome exibido no aplicativo autenticador
+	Issuer string
+	// DriftSteps é quantos passos de 30 segundos antes e depois do atual são
+	// aceitos, para relógios fora de sincronia
+	DriftSteps    int
+	RecoveryCodes int
+	QRCodeSize    int
+	CodeTTL       time.Duration
+	// CodeMaxAttempts é o número de tentativas por código enviado
+	CodeMaxAttempts int
+	// CodeResendCooldown é o intervalo mínimo entre dois envios com a mesma
+	// finalidade
+	CodeResendCooldown time.Duration
+	StepUpTTL          time.Duration
+	// MaxFailedAttempts e LockDuration bloqueiam o usuário após códigos
+	// errados no StepUp, com os mesmos valores do login
+	MaxFailedAttempts int
+	LockDuration      time.Duration
+}
+
+type twoFactorService struct {
+	db            *gorm.DB
+	jwt           security.JwtService
+	passwords     security.PasswordService
+	notifications NotificationService
+	config        TwoFactorConfig
+}
+
+func NewTwoFactorService(db *gorm.DB, jwt security.JwtService, passwords security.PasswordService, notifications NotificationService, cfg *TwoFactorConfig) TwoFactorService {
+	if cfg == nil {
+		cfg = &TwoFactorConfig{}
+	}
+	if cfg.Issuer == "" {
+		cfg.Issuer = config.GetEnv("TWO_FACTOR_ISSUER", "Oak Bank")
+	}
+	if cfg.DriftSteps == 0 {
+		cfg.DriftSteps = config.GetEnvInt("TWO_FACTOR_DRIFT_STEPS", 1)
+	}
+	if cfg.RecoveryCodes == 0 {
+		cfg.RecoveryCodes = config.GetEnvInt("TWO_FACTOR_RECOVERY_CODES", 10)
+	}
+	if cfg.QRCodeSize == 0 {
+		cfg.QRCodeSize = config.GetEnvInt("TWO_FACTOR_QR_CODE_SIZE", 256)
+	}
+	if cfg.CodeTTL == 0 {
+		cfg.CodeTTL = config.GetEnvDuration("TWO_FACTOR_CODE_TTL", 5*time.Minute)
+	}
+	if cfg.CodeMaxAttempts == 0 {
+		cfg.CodeMaxAttempts = config.GetEnvInt("TWO_FACTOR_CODE_MAX_ATTEMPTS", 5)
+	}
+	if cfg.CodeResendCooldown == 0 {
+		cfg.CodeResendCooldown = config.GetEnvDuration("TWO_FACTOR_CODE_RESEND_COOLDOWN", time.Minute)
+	}
+	if cfg.StepUpTTL == 0 {
+		cfg.StepUpTTL = config.GetEnvDuration("JWT_STEP_UP_EXPIRATION", 5*time.Minute)
+	}
+	if cfg.MaxFailedAttempts == 0 {
+		cfg.MaxFailedAttempts = config.GetEnvInt("AUTH_MAX_FAILED_ATTEMPTS", 5)
+	}
+	if cfg.LockDuration == 0 {
+		cfg.LockDuration = config.GetEnvDuration("AUTH_LOCK_DURATION", 15*time.Minute)
+	}
+
+	return &twoFactorService{
+		db:            db,
+		jwt:           jwt,
+		passwords:     passwords,
+		notifications: notifications,
+		config:        *cfg,
+	}
+}
+
+func (s *twoFactorService) Status(ctx context.Context, userID string) (*dtos.TwoFactorStatusDTO, error) {
+	var user models.User
+	err := s.db.WithContext(ctx).Select("user_id", "two_factor_enabled", "two_factor_secret", "two_factor_method").First(&user, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: usuário", ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var remaining int64
+	if user.TwoFactorEnabled {
+		if err := s.db.WithContext(ctx).Model(&models.TwoFactorRecoveryCode{}).
+			Where("user_id = ? AND used_at IS NULL", userID).Count(&remaining).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &dtos.TwoFactorStatusDTO{
+		Enabled:                user.TwoFactorEnabled,
+		Method:                 twoFactorMethod(&user),
+		PendingEnrollment:      pendingTwoFactorMethod(&user) != "",
+		RecoveryCodesRemaining: int(remaining),
+	}, nil
+}
+
+func (s *twoFactorService) Enroll(ctx context.Context, userID string, req *dtos.TwoFactorEnrollDTO) (*dtos.TwoFactorEnrollmentDTO, error) {
+	if err := validateTwoFactorRequest(req); err != nil {
+		return nil, err
+	}
+	if err := reauthenticate(ctx, s.db, s.passwords, userID, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	var user models.User
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockUser(tx, userID, &user); err != nil {
+			return err
+		}
+		if user.TwoFactorEnabled {
+			return ErrTwoFactorEnabled
+		}
+		// a atualização pelo struct passa o segredo pelo serializer que o cifra
+		user.TwoFactorSecret = sql.NullString{String: secret, Valid: true}
+		user.TwoFactorMethod = nullString(models.TwoFactorMethodTOTP)
+		user.TwoFactorLastStep = 0
+		return tx.Model(&user).Select("two_factor_secret", "two_factor_method", "two_factor_last_step").Updates(&user).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uri := security.TOTPProvisioningURI(secret, s.config.Issuer, user.Email)
+	png, err := qrcode.Encode(uri, qrcode.Medium, s.config.QRCodeSize)
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.TwoFactorEnrollmentDTO{
+		Secret:          secret,
+		ProvisioningURI: uri,
+		QRCodePNG:       base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+func (s *twoFactorService) EnrollOTP(ctx context.Context, userID string, req *dtos.TwoFactorEnrollOTPDTO) (*dtos.OneTimeCodeSentDTO, error) {
+	if err := validateTwoFactorRequest(req); err != nil {
+		return nil, err
+	}
+	if err := reauthenticate(ctx, s.db, s.passwords, userID, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+
+	var sent *dtos.OneTimeCodeSentDTO
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := lockUser(tx, userID, &user); err != nil {
+			return err
+		}
+		if user.TwoFactorEnabled {
+			return ErrTwoFactorEnabled
+		}
+		user.TwoFactorSecret = sql.NullString{}
+		user.TwoFactorMethod = nullString(req.Channel)
+		user.TwoFactorLastStep = 0
+		if err := tx.Model(&user).Select("two_factor_secret", "two_factor_method", "two_factor_last_step").Updates(&user).Error; err != nil {
+			return err
+		}
+		var err error
+		sent, err = s.sendCode(ctx, tx, &user, models.OneTimeCodePurposeTwoFactorSetup, req.Channel)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sent, nil
+}
+
+func (s *twoFactorService) Confirm(ctx context.Context, userID string, req *dtos.TwoFactorConfirmDTO) (*dtos.TwoFactorRecoveryCodesDTO, error) {
+	if err := validateTwoFactorRequest(req); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	var codeErr error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := lockUser(tx, userID, &user); err != nil {
+			return err
+		}
+		if user.TwoFactorEnabled {
+			return ErrTwoFactorEnabled
+		}
+
+		method := pendingTwoFactorMethod(&user)
+		updates := map[string]any{
+			"two_factor_enabled": true,
+			"two_factor_method":  method,
+		}
+		switch method {
+		case "":
+			return ErrTwoFactorNoEnrollment
+		case models.TwoFactorMethodTOTP:
+			step, ok, err := security.VerifyTOTP(user.TwoFactorSecret.String, req.Code, time.Now(), s.config.DriftSteps, user.TwoFactorLastStep)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return ErrTwoFactorCodeInvalid
+			}
+			updates["two_factor_last_step"] = step
+		default:
+			if err := verifyOneTimeCode(tx, models.OneTimeCodePurposeTwoFactorSetup, userID, req.Code); err != nil {
+				if isOneTimeCodeError(err) {
+					// a tentativa contada precisa ser confirmada
+					codeErr = err
+					return nil
+				}
+				return err
+			}
+		}
+
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+		var err error
+		if codes, err = s.replaceRecoveryCodes(tx, userID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, &AuditEntry{
+			Table:     models.User{}.TableName(),
+			RecordID:  userID,
+			Operation: AuditOperationUpdate,
+			UserID:    userID,
+			OldValues: map[string]any{"two_factor_enabled": false},
+			NewValues: map[string]any{"two_factor_enabled": true, "two_factor_method": method},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if codeErr != nil {
+		return nil, codeErr
+	}
+	return &dtos.TwoFactorRecoveryCodesDTO{RecoveryCodes: codes}, nil
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, userID string, req *dtos.TwoFactorReauthenticateDTO) error {
+	if err := validateTwoFactorRequest(req); err != nil {
+		return err
+	}
+	if err := reauthenticate(ctx, s.db, s.passwords, userID, req.CurrentPassword); err != nil {
+		return err
+	}
+
+	var refused error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := lockUser(tx, userID, &user); err != nil {
+			return err
+		}
+		if !user.TwoFactorEnabled {
+			return ErrTwoFactorNotEnabled
+		}
+		var err error
+		refused, err = s.checkProtectedCode(ctx, tx, &user, req.Code, true)
+		if err != nil || refused != nil {
+			return err
+		}
+
+		oldMethod := twoFactorMethod(&user)
+		user.TwoFactorEnabled = false
+		user.TwoFactorSecret = sql.NullString{}
+		user.TwoFactorMethod = sql.NullString{}
+		user.TwoFactorLastStep = 0
+		if err := tx.Model(&user).Select("two_factor_enabled", "two_factor_secret", "two_factor_method", "two_factor_last_step").Updates(&user).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, &AuditEntry{
+			Table:     models.User{}.TableName(),
+			RecordID:  userID,
+			Operation: AuditOperationUpdate,
+			UserID:    userID,
+			OldValues: map[string]any{"two_factor_enabled": true, "two_factor_method": oldMethod},
+			NewValues: map[string]any{"two_factor_enabled": false},
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return refused
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID string, req *dtos.TwoFactorReauthenticateDTO) (*dtos.TwoFactorRecoveryCodesDTO, error) {
+	if err := validateTwoFactorRequest(req); err != nil {
+		return nil, err
+	}
+	if err := reauthenticate(ctx, s.db, s.passwords, userID, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	var refused error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := lockUser(tx, userID, &user); err != nil {
+			return err
+		}
+		if !user.TwoFactorEnabled {
+			return ErrTwoFactorNotEnabled
+		}
+		// os códigos atuais serão descartados, então apenas o TOTP ou o
+		// código enviado é aceito
+		var err error
+		refused, err = s.checkProtectedCode(ctx, tx, &user, req.Code, false)
+		if err != nil || refused != nil {
+			return err
+		}
+		codes, err = s.replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if refused != nil {
+		return nil, refused
+	}
+	return &dtos.TwoFactorRecoveryCodesDTO{RecoveryCodes: codes}, nil
+}
+
+func (s *twoFactorService) SendCode(ctx context.Context, userID string, req *dtos.TwoFactorVerificationSenderDTO) (*dtos.OneTimeCodeSentDTO, error) {
+	if err := validateTwoFactorRequest(req); err != nil {
+		return nil, err
+	}
+
+	var sent *dtos.OneTimeCodeSentDTO
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// o bloqueio serializa os pedidos simultâneos durante o intervalo de reenvio
+		var user models.User
+		if err := lockUser(tx, userID, &user); err != nil {
+			return err
+		}
+
+		channel := req.Channel
+		switch method := twoFactorMethod(&user); method {
+		case models.TwoFactorMethodTOTP:
+			return ErrTwoFactorUseAuthenticator
+		case "":
+			if channel == "" {
+				channel = notifications.ChannelEmail
+			}
+		default:
+			// com a verificação ativa o código vai apenas pelo método escolhido
+			if channel != "" && channel != method {
+				return ErrTwoFactorChannel
+			}
+			channel = method
+		}
+
+		var err error
+		sent, err = s.sendCode(ctx, tx, &user, models.OneTimeCodePurposeStepUp, channel)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sent, nil
+}
+
+func (s *twoFactorService) StepUp(ctx context.Context, userID string, req *dtos.StepUpDTO) (*dtos.StepUpTokenDTO, error) {
+	if err := validateTwoFactorRequest(req); err != nil {
+		return nil, err
+	}
+
+	var token *models.StepUpToken
+	var refused error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := lockUser(tx, userID, &user); err != nil {
+			return err
+		}
+		var err error
+		refused, err = s.checkProtectedCode(ctx, tx, &user, req.Code, false)
+		if err != nil || refused != nil {
+			return err
+		}
+		token = &models.StepUpToken{UserID: userID, ExpiresAt: time.Now().Add(s.config.StepUpTTL)}
+		return tx.Create(token).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if refused != nil {
+		return nil, refused
+	}
+
+	signed, err := s.jwt.CreateStepUpToken(map[string]interface{}{
+		"user_id": userID,
+		"jti":     token.TokenID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.StepUpTokenDTO{
+		StepUpToken: signed,
+		ExpiresAt:   token.ExpiresAt,
+	}, nil
+}
+
+func (s *twoFactorService) ConsumeStepUpToken(ctx context.Context, userID, token string) error {
+	claims, err := s.jwt.ValidateStepUpToken(token)
+	if err != nil {
+		return ErrStepUpInvalid
+	}
+	tokenUserID, _ := claims["user_id"].(string)
+	tokenID, _ := claims["jti"].(string)
+	if tokenUserID == "" || tokenUserID != userID || tokenID == "" {
+		return ErrStepUpInvalid
+	}
+
+	result := s.db.WithContext(ctx).Model(&models.StepUpToken{}).
+		Where("token_id = ? AND user_id = ? AND consumed_at IS NULL AND expires_at > ?", tokenID, userID, time.Now()).
+		Update("consumed_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != 1 {
+		return ErrStepUpInvalid
+	}
+	return nil
+}
+
+func (s *twoFactorService) SendLoginCode(ctx context.Context, tx *gorm.DB, user *models.User) (*dtos.OneTimeCodeSentDTO, error) {
+	method := twoFactorMethod(user)
+	if method == "" || method == models.TwoFactorMethodTOTP {
+		return nil, nil
+	}
+	return s.sendCode(ctx, tx, user, models.OneTimeCodePurposeLogin, method)
+}
+
+func (s *twoFactorService) VerifyCode(ctx context.Context, tx *gorm.DB, user *models.User, purpose, code string, allowRecovery bool) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == security.TOTPDigits {
+		if twoFactorMethod(user) != models.TwoFactorMethodTOTP {
+			if err := verifyOneTimeCode(tx, purpose, user.UserID, code); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+		if !user.TwoFactorSecret.Valid {
+			return false, nil
+		}
+		step, ok, err := security.VerifyTOTP(user.TwoFactorSecret.String, code, time.Now(), s.config.DriftSteps, user.TwoFactorLastStep)
+		if err != nil || !ok {
+			return false, err
+		}
+		if err := tx.Model(user).Update("two_factor_last_step", step).Error; err != nil {
+			return false, err
+		}
+		user.TwoFactorLastStep = step
+		return true, nil
+	}
+	if !allowRecovery || !user.TwoFactorEnabled {
+		return false, nil
+	}
+
+	hash, err := models.TwoFactorRecoveryCodeHash(user.UserID, normalizeRecoveryCode(code))
+	if err != nil {
+		return false, err
+	}
+	result := tx.Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.UserID, hash).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// sendCode emite um código para purpose e o envia pelo canal ao contato
+// cadastrado, respeitando o intervalo mínimo entre envios
+func (s *twoFactorService) sendCode(ctx context.Context, tx *gorm.DB, user *models.User, purpose, channel string) (*dtos.OneTimeCodeSentDTO, error) {
+	now := time.Now()
+	resendAt, err := oneTimeCodeResendAt(tx, purpose, user.UserID, s.config.CodeResendCooldown)
+	if err != nil {
+		return nil, err
+	}
+	if now.Before(resendAt) {
+		return nil, fmt.Errorf("%w (%d s)", ErrOneTimeCodeCooldown, int(math.Ceil(resendAt.Sub(now).Seconds())))
+	}
+
+	code, err := issueOneTimeCode(tx, &models.OneTimeCode{
+		UserID:      nullString(user.UserID),
+		Purpose:     purpose,
+		Reference:   nullString(user.UserID),
+		Channel:     channel,
+		MaxAttempts: s.config.CodeMaxAttempts,
+	}, s.config.CodeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.notifications.Enqueue(ctx, tx, &NotificationRequest{
+		CustomerID: user.CustomerID.String,
+		UserID:     user.UserID,
+		Template:   notifications.TemplateTwoFactorCode,
+		Channels:   []string{channel},
+		Data: map[string]string{
+			"code":       code,
+			"expires_in": fmt.Sprintf("%d min", int(s.config.CodeTTL.Minutes())),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.OneTimeCodeSentDTO{
+		Channel:     channel,
+		ExpiresAt:   now.Add(s.config.CodeTTL),
+		ResendAfter: now.Add(s.config.CodeResendCooldown),
+	}, nil
+}
+
+// twoFactorMethod retorna o método da verificação ativa, ou vazio. As
+// verificações ativadas antes da escolha do método usam o TOTP.
+func twoFactorMethod(user *models.User) string {
+	if !user.TwoFactorEnabled {
+		return ""
+	}
+	if user.TwoFactorMethod.Valid {
+		return user.TwoFactorMethod.String
+	}
+	return models.TwoFactorMethodTOTP
+}
+
+// pendingTwoFactorMethod retorna o método de uma configuração iniciada e
+// ainda não confirmada
+func pendingTwoFactorMethod(user *models.User) string {
+	switch {
+	case user.TwoFactorEnabled:
+		return ""
+	case user.TwoFactorMethod.Valid:
+		if user.TwoFactorMethod.String == models.TwoFactorMethodTOTP && !user.TwoFactorSecret.Valid {
+			return ""
+		}
+		return user.TwoFactorMethod.String
+	case user.TwoFactorSecret.Valid:
+		return models.TwoFactorMethodTOTP
+	}
+	return ""
+}
+
+// replaceRecoveryCodes apaga os códigos de recuperação do usuário e grava
+// novos, retornados em claro para serem exibidos uma única vez
+func (s *twoFactorService) replaceRecoveryCodes(tx *gorm.DB, userID string) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, s.config.RecoveryCodes)
+	rows := make([]models.TwoFactorRecoveryCode, 0, s.config.RecoveryCodes)
+	for len(codes) < s.config.RecoveryCodes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		hash, err := models.TwoFactorRecoveryCodeHash(userID, normalizeRecoveryCode(code))
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		rows = append(rows, models.TwoFactorRecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode gera um código de 10 caracteres no formato XXXXX-XXXXX
+func generateRecoveryCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := 0; i < 10; i++ {
+		if i == 5 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// normalizeRecoveryCode aceita o código digitado sem o hífen, com espaços ou
+// em minúsculas
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// checkProtectedCode confere o código de uma operação protegida do usuário já
+// lido com lockUser. O código errado conta para o bloqueio do login, o
+// usuário bloqueado é recusado e toda tentativa fica em UserAuthLog. A recusa
+// é retornada em refused, sem desfazer tx, para que a falha seja gravada.
+func (s *twoFactorService) checkProtectedCode(ctx context.Context, tx *gorm.DB, user *models.User, code string, allowRecovery bool) (refused error, err error) {
+	now := time.Now()
+	if reason := checkUsable(user, now); reason != "" {
+		authLog := newAuthLog(ctx, user.UserID, reason)
+		authLog.AuthEventType = models.AuthEventStepUp
+		return ErrStepUpLocked, tx.Create(authLog).Error
+	}
+
+	ok, err := s.VerifyCode(ctx, tx, user, models.OneTimeCodePurposeStepUp, code, allowRecovery)
+	if isOneTimeCodeError(err) {
+		refused, err = err, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reason := ""
+	if ok {
+		if err := tx.Model(user).Update("failed_login_attempts", 0).Error; err != nil {
+			return nil, err
+		}
+	} else {
+		// sem o contador, quem tem apenas o token de acesso poderia tentar
+		// todos os códigos do aplicativo autenticador
+		reason, err = recordFailure(tx, user, authFailureInvalidMFACode, now, s.config.MaxFailedAttempts, s.config.LockDuration)
+		if err != nil {
+			return nil, err
+		}
+		if refused == nil {
+			refused = ErrTwoFactorCodeInvalid
+		}
+	}
+	authLog := newAuthLog(ctx, user.UserID, reason)
+	authLog.AuthEventType = models.AuthEventStepUp
+	return refused, tx.Create(authLog).Error
+}
+
+// lockUser lê o usuário com FOR UPDATE
+func lockUser(tx *gorm.DB, userID string, user *models.User) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(user, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: usuário", ErrNotFound)
+	}
+	return err
+}
+
+func validateTwoFactorRequest(req any) error {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return &ValidationError{Fields: fields}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,779 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/internal/api/dtos"
+	"github.com/victor-lima-142/oak-bank/internal/api/validation"
+	"github.com/victor-lima-142/oak-bank/internal/webhooks"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrWebhookSubscriptionNotFound = fmt.Errorf("%w: assinatura de webhook", ErrNotFound)
+	ErrWebhookDeliveryNotFound     = fmt.Errorf("%w: entrega de webhook", ErrNotFound)
+	ErrWebhookSubscriptionInactive = fmt.Errorf("%w: assinatura de webhook desativada", ErrConflict)
+	ErrWebhookLimitReached         = fmt.Errorf("%w: limite de assinaturas de webhook atingido", ErrConflict)
+	ErrPartnerNameRequired         = fmt.Errorf("%w: partner_name é obrigatório em assinaturas de parceiro", ErrInvalidInput)
+	ErrWebhookURLNotAllowed        = fmt.Errorf("%w: url de webhook deve usar https e um nome de host público", ErrInvalidInput)
+)
+
+// maxExposedError limita o erro de entrega devolvido ao cliente; o corpo da
+// resposta do endpoint nunca é exposto
+const maxExposedError = 200
+
+// WebhookScope delimita as assinaturas visíveis: as de um cliente ou, com
+// CustomerID vazio, as de parceiros (gerenciadas por administradores)
+type WebhookScope struct {
+	CustomerID string
+}
+
+// PartnerScope é o escopo das assinaturas de parceiros
+var PartnerScope = WebhookScope{}
+
+func (s WebhookScope) isPartner() bool {
+	return s.CustomerID == ""
+}
+
+type WebhookService interface {
+	// Emit grava uma entrega para cada assinatura ativa interessada no evento.
+	// Recebem o evento as assinaturas dos clientes informados e as de parceiros.
+	Emit(ctx context.Context, tx *gorm.DB, eventType string, customerIDs []string, data any) error
+
+	// OnTransactionStatusChanged emite transaction.status_changed para origem e
+	// destino a cada mudança de status: retenção, conclusão e cancelamento
+	OnTransactionStatusChanged(ctx context.Context, tx *gorm.DB, transaction *models.Transaction, previousStatus string) error
+
+	// OnAccountStatusChanged emite account.status_changed a partir da entrada de histórico
+	OnAccountStatusChanged(ctx context.Context, tx *gorm.DB, account *models.Account, history *models.AccountStatusHistory) error
+
+	// OnKYCStatusChanged emite customer.kyc_status_changed
+	OnKYCStatusChanged(ctx context.Context, tx *gorm.DB, customer *models.Customer, previousStatus string) error
+
+	// ProcessPending envia as entregas vencidas. Retorna quantas foram processadas.
+	ProcessPending(ctx context.Context, limit int) (int, error)
+
+	// Run processa a fila de entregas até o contexto ser cancelado
+	Run(ctx context.Context, interval time.Duration)
+
+	// CustomerScope resolve o escopo do cliente do usuário autenticado
+	CustomerScope(ctx context.Context, userID string) (WebhookScope, error)
+
+	ListSubscriptions(ctx context.Context, scope WebhookScope) ([]dtos.WebhookSubscriptionDTO, error)
+	CreateSubscription(ctx context.Context, scope WebhookScope, req *dtos.CreateWebhookSubscriptionDTO) (*dtos.WebhookSubscriptionDTO, error)
+	UpdateSubscription(ctx context.Context, scope WebhookScope, subscriptionID string, req *dtos.UpdateWebhookSubscriptionDTO) (*dtos.WebhookSubscriptionDTO, error)
+	DeleteSubscription(ctx context.Context, scope WebhookScope, subscriptionID string) error
+
+	// RotateSecret gera um novo segredo. O segredo antigo deixa de valer imediatamente.
+	RotateSecret(ctx context.Context, scope WebhookScope, subscriptionID string) (*dtos.WebhookSubscriptionDTO, error)
+
+	ListDeliveries(ctx context.Context, scope WebhookScope, subscriptionID string, req *dtos.WebhookDeliveryListRequestDTO) (*dtos.WebhookDeliveryListDTO, error)
+	GetDelivery(ctx context.Context, scope WebhookScope, subscriptionID, deliveryID string) (*dtos.WebhookDeliveryDTO, error)
+
+	// Redeliver cria uma nova entrega com o mesmo evento (mesmo event_id e payload)
+	Redeliver(ctx context.Context, scope WebhookScope, subscriptionID, deliveryID string) (*dtos.WebhookDeliveryDTO, error)
+}
+
+// WebhookConfig contém as configurações de entrega de webhooks
+type WebhookConfig struct {
+	MaxAttempts int
+	// RetryBackoff é o intervalo da primeira retentativa; dobra a cada falha até MaxBackoff
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+	// Um endpoint é desativado após DisableAfterFailures falhas consecutivas
+	// desde que esteja falhando há pelo menos DisableAfter
+	DisableAfterFailures int
+	DisableAfter         time.Duration
+	// MaxSubscriptions limita as assinaturas por cliente
+	MaxSubscriptions int
+}
+
+type webhookService struct {
+	db     *gorm.DB
+	client *webhooks.Client
+	config WebhookConfig
+}
+
+func NewWebhookService(db *gorm.DB, cfg *WebhookConfig) WebhookService {
+	if cfg == nil {
+		cfg = &WebhookConfig{}
+	}
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = config.GetEnvInt("WEBHOOK_MAX_ATTEMPTS", 10)
+	}
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = config.GetEnvDuration("WEBHOOK_RETRY_BACKOFF", 30*time.Second)
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = config.GetEnvDuration("WEBHOOK_MAX_BACKOFF", 6*time.Hour)
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = config.GetEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second)
+	}
+	if cfg.DisableAfterFailures == 0 {
+		cfg.DisableAfterFailures = config.GetEnvInt("WEBHOOK_DISABLE_AFTER_FAILURES", 20)
+	}
+	if cfg.DisableAfter == 0 {
+		cfg.DisableAfter = config.GetEnvDuration("WEBHOOK_DISABLE_AFTER", 24*time.Hour)
+	}
+	if cfg.MaxSubscriptions == 0 {
+		cfg.MaxSubscriptions = config.GetEnvInt("WEBHOOK_MAX_SUBSCRIPTIONS", 10)
+	}
+
+	return &webhookService{
+		db:     db,
+		client: webhooks.NewClient(cfg.Timeout),
+		config: *cfg,
+	}
+}
+
+// webhookEnvelope é o corpo enviado aos endpoints
+type webhookEnvelope struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+func (s *webhookService) Emit(ctx context.Context, tx *gorm.DB, eventType string, customerIDs []string, data any) error {
+	if tx == nil {
+		tx = s.db.WithContext(ctx)
+	}
+
+	filter, err := json.Marshal([]string{eventType})
+	if err != nil {
+		return err
+	}
+
+	query := tx.Where("is_active = ? AND event_types @> ?::jsonb", true, string(filter))
+	if len(customerIDs) > 0 {
+		query = query.Where("(customer_id IS NULL OR customer_id IN ?)", customerIDs)
+	} else {
+		query = query.Where("customer_id IS NULL")
+	}
+
+	var subscriptions []models.WebhookSubscription
+	if err := query.Find(&subscriptions).Error; err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	eventID := uuid.New().String()
+	payload, err := json.Marshal(webhookEnvelope{
+		ID:        eventID,
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: subscription.SubscriptionID,
+			EventID:        eventID,
+			EventType:      eventType,
+			Payload:        datatypes.JSON(payload),
+			DeliveryStatus: models.WebhookDeliveryStatusPending,
+			MaxAttempts:    s.config.MaxAttempts,
+			NextAttemptAt:  time.Now(),
+		})
+	}
+	return tx.Create(&deliveries).Error
+}
+
+func (s *webhookService) OnTransactionStatusChanged(ctx context.Context, tx *gorm.DB, transaction *models.Transaction, previousStatus string) error {
+	ids := []string{transaction.AccountIDOrigin}
+	if transaction.AccountIDDest.Valid {
+		ids = append(ids, transaction.AccountIDDest.String)
+	}
+
+	var customerIDs []string
+	if err := tx.Model(&models.Account{}).Where("account_id IN ?", ids).Distinct().Pluck("customer_id", &customerIDs).Error; err != nil {
+		return err
+	}
+
+	return s.Emit(ctx, tx, webhooks.EventTransactionStatusChanged, customerIDs, map[string]any{
+		"transaction_id":        transaction.TransactionID,
+		"transaction_type_code": transaction.TransactionTypeCode,
+		"status":                transaction.TransactionStatus,
+		"previous_status":       previousStatus,
+		"amount":                transaction.TransactionAmount,
+		"account_id_origin":     transaction.AccountIDOrigin,
+		"account_id_dest":       transaction.AccountIDDest.String,
+		"transaction_date":      transaction.TransactionDate,
+		"completed_at":          nullTimePtr(transaction.CompletedAt),
+		"external_reference":    transaction.ExternalReference.String,
+	})
+}
+
+func (s *webhookService) OnAccountStatusChanged(ctx context.Context, tx *gorm.DB, account *models.Account, history *models.AccountStatusHistory) error {
+	return s.Emit(ctx, tx, webhooks.EventAccountStatusChanged, []string{account.CustomerID}, map[string]any{
+		"account_id":      account.AccountID,
+		"history_id":      history.HistoryID,
+		"previous_status": history.PreviousStatus.String,
+		"status":          history.NewStatus,
+		"reason":          history.ChangeReason.String,
+		"changed_at":      history.ChangedAt,
+	})
+}
+
+func (s *webhookService) OnKYCStatusChanged(ctx context.Context, tx *gorm.DB, customer *models.Customer, previousStatus string) error {
+	return s.Emit(ctx, tx, webhooks.EventCustomerKYCChanged, []string{customer.CustomerID}, map[string]any{
+		"customer_id":     customer.CustomerID,
+		"previous_status": previousStatus,
+		"status":          customer.KYCStatus,
+		"verified_at":     nullTimePtr(customer.KYCVerifiedAt),
+	})
+}
+
+func (s *webhookService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			processed, err := s.ProcessPending(ctx, 20)
+			if err != nil {
+				log.Printf("webhooks: %v", err)
+				break
+			}
+			if processed == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *webhookService) ProcessPending(ctx context.Context, limit int) (int, error) {
+	batch, err := s.claim(ctx, limit)
+	if err != nil || len(batch) == 0 {
+		return 0, err
+	}
+
+	for i := range batch {
+		if err := s.deliver(ctx, &batch[i]); err != nil {
+			log.Printf("webhooks: delivery %s: %v", batch[i].DeliveryID, err)
+		}
+	}
+	return len(batch), nil
+}
+
+// claim reserva entregas vencidas. O status SENDING com next_attempt_at no
+// futuro funciona como lease: se a instância cair, outra retoma depois.
+func (s *webhookService) claim(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	var batch []models.WebhookDelivery
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("delivery_status IN ? AND next_attempt_at <= ?",
+				[]string{models.WebhookDeliveryStatusPending, models.WebhookDeliveryStatusSending}, time.Now()).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		ids := make([]string, 0, len(batch))
+		for _, d := range batch {
+			ids = append(ids, d.DeliveryID)
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("delivery_id IN ?", ids).
+			Updates(map[string]interface{}{
+				"delivery_status": models.WebhookDeliveryStatusSending,
+				"next_attempt_at": time.Now().Add(2 * s.config.Timeout),
+			}).Error
+	})
+	return batch, err
+}
+
+func (s *webhookService) deliver(ctx context.Context, delivery *models.WebhookDelivery) error {
+	var subscription models.WebhookSubscription
+	if err := s.db.WithContext(ctx).First(&subscription, "subscription_id = ?", delivery.SubscriptionID).Error; err != nil {
+		return err
+	}
+	if !subscription.IsActive {
+		return s.db.WithContext(ctx).Model(delivery).Updates(map[string]interface{}{
+			"delivery_status": models.WebhookDeliveryStatusFailed,
+			"last_error":      "assinatura desativada",
+		}).Error
+	}
+
+	result := s.client.Deliver(ctx, &webhooks.Request{
+		URL:       subscription.URL,
+		Secret:    subscription.Secret,
+		EventID:   delivery.EventID,
+		EventType: delivery.EventType,
+		Body:      delivery.Payload,
+	})
+	attempts := delivery.Attempts + 1
+	now := time.Now()
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		attempt := models.WebhookDeliveryAttempt{
+			DeliveryID:    delivery.DeliveryID,
+			AttemptNumber: attempts,
+			DurationMs:    result.Duration.Milliseconds(),
+			ResponseBody:  nullString(result.ResponseBody),
+		}
+		updates := map[string]interface{}{"attempts": attempts}
+		if result.StatusCode != 0 {
+			attempt.StatusCode = sql.NullInt32{Int32: int32(result.StatusCode), Valid: true}
+			updates["last_status_code"] = result.StatusCode
+		}
+
+		failure := ""
+		switch {
+		case result.Err != nil:
+			failure = result.Err.Error()
+		case !result.Success():
+			failure = fmt.Sprintf("resposta HTTP %d", result.StatusCode)
+		}
+		attempt.ErrorMessage = nullString(truncate(failure, 500))
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+
+		if failure == "" {
+			updates["delivery_status"] = models.WebhookDeliveryStatusSucceeded
+			updates["delivered_at"] = now
+			updates["last_error"] = nil
+			if err := tx.Model(delivery).Updates(updates).Error; err != nil {
+				return err
+			}
+			return tx.Model(&subscription).Updates(map[string]interface{}{
+				"consecutive_failures": 0,
+				"failing_since":        nil,
+				"last_success_at":      now,
+			}).Error
+		}
+
+		updates["last_error"] = truncate(failure, 500)
+		if attempts >= delivery.MaxAttempts {
+			updates["delivery_status"] = models.WebhookDeliveryStatusFailed
+		} else {
+			updates["delivery_status"] = models.WebhookDeliveryStatusPending
+			updates["next_attempt_at"] = now.Add(s.backoff(attempts))
+		}
+		if err := tx.Model(delivery).Updates(updates).Error; err != nil {
+			return err
+		}
+		return s.recordFailure(tx, &subscription, result.StatusCode, now)
+	})
+}
+
+// recordFailure incrementa as falhas consecutivas e desativa o endpoint que
+// falha de forma persistente. 410 Gone desativa imediatamente.
+func (s *webhookService) recordFailure(tx *gorm.DB, subscription *models.WebhookSubscription, statusCode int, now time.Time) error {
+	failures := subscription.ConsecutiveFailures + 1
+	failingSince := now
+	if subscription.FailingSince.Valid {
+		failingSince = subscription.FailingSince.Time
+	}
+
+	updates := map[string]interface{}{
+		"consecutive_failures": failures,
+		"failing_since":        failingSince,
+	}
+
+	reason := ""
+	switch {
+	case statusCode == http.StatusGone:
+		reason = "endpoint respondeu 410 Gone"
+	case failures >= s.config.DisableAfterFailures && now.Sub(failingSince) >= s.config.DisableAfter:
+		reason = fmt.Sprintf("%d falhas consecutivas desde %s", failures, failingSince.Format(time.RFC3339))
+	}
+	if reason != "" {
+		updates["is_active"] = false
+		updates["disabled_at"] = now
+		updates["disabled_reason"] = truncate(reason, 200)
+		log.Printf("webhooks: subscription %s disabled: %s", subscription.SubscriptionID, reason)
+	}
+
+	return tx.Model(subscription).Updates(updates).Error
+}
+
+// backoff cresce exponencialmente a partir de RetryBackoff até MaxBackoff
+func (s *webhookService) backoff(attempts int) time.Duration {
+	delay := time.Duration(float64(s.config.RetryBackoff) * math.Pow(2, float64(attempts-1)))
+	if delay <= 0 || delay > s.config.MaxBackoff {
+		return s.config.MaxBackoff
+	}
+	return delay
+}
+
+func (s *webhookService) CustomerScope(ctx context.Context, userID string) (WebhookScope, error) {
+	customerID, err := customerIDForUser(ctx, s.db, userID)
+	if err != nil {
+		return WebhookScope{}, err
+	}
+	return WebhookScope{CustomerID: customerID}, nil
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context, scope WebhookScope) ([]dtos.WebhookSubscriptionDTO, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := s.scoped(ctx, scope).Order("created_at").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.WebhookSubscriptionDTO, 0, len(subscriptions))
+	for i := range subscriptions {
+		result = append(result, webhookSubscriptionDTO(&subscriptions[i]))
+	}
+	return result, nil
+}
+
+func (s *webhookService) CreateSubscription(ctx context.Context, scope WebhookScope, req *dtos.CreateWebhookSubscriptionDTO) (*dtos.WebhookSubscriptionDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+	if scope.isPartner() && req.PartnerName == "" {
+		return nil, ErrPartnerNameRequired
+	}
+	if err := webhooks.ValidateURL(req.URL); err != nil {
+		return nil, ErrWebhookURLNotAllowed
+	}
+
+	if !scope.isPartner() {
+		var count int64
+		if err := s.scoped(ctx, scope).Model(&models.WebhookSubscription{}).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if int(count) >= s.config.MaxSubscriptions {
+			return nil, ErrWebhookLimitReached
+		}
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	eventTypes, err := json.Marshal(uniqueStrings(req.EventTypes))
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := models.WebhookSubscription{
+		CustomerID:  nullString(scope.CustomerID),
+		URL:         req.URL,
+		EventTypes:  datatypes.JSON(eventTypes),
+		Secret:      secret,
+		Description: nullString(req.Description),
+		IsActive:    true,
+	}
+	if scope.isPartner() {
+		subscription.PartnerName = nullString(req.PartnerName)
+	}
+	if err := s.db.WithContext(ctx).Create(&subscription).Error; err != nil {
+		return nil, err
+	}
+
+	response := webhookSubscriptionDTO(&subscription)
+	response.Secret = secret
+	return &response, nil
+}
+
+func (s *webhookService) UpdateSubscription(ctx context.Context, scope WebhookScope, subscriptionID string, req *dtos.UpdateWebhookSubscriptionDTO) (*dtos.WebhookSubscriptionDTO, error) {
+	if err := validation.Struct(req); err != nil {
+		if fields := validation.FieldErrors(err); fields != nil {
+			return nil, &ValidationError{Fields: fields}
+		}
+		return nil, err
+	}
+
+	subscription, err := s.findSubscription(ctx, scope, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.URL != "" {
+		if err := webhooks.ValidateURL(req.URL); err != nil {
+			return nil, ErrWebhookURLNotAllowed
+		}
+		updates["url"] = req.URL
+	}
+	if len(req.EventTypes) > 0 {
+		eventTypes, err := json.Marshal(uniqueStrings(req.EventTypes))
+		if err != nil {
+			return nil, err
+		}
+		updates["event_types"] = datatypes.JSON(eventTypes)
+	}
+	if req.Description != nil {
+		updates["description"] = nullString(*req.Description)
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+		if *req.IsActive && !subscription.IsActive {
+			// reativação manual: o endpoint recomeça sem histórico de falhas
+			updates["consecutive_failures"] = 0
+			updates["failing_since"] = nil
+			updates["disabled_at"] = nil
+			updates["disabled_reason"] = nil
+		} else if !*req.IsActive && subscription.IsActive {
+			updates["disabled_at"] = time.Now()
+			updates["disabled_reason"] = "desativada manualmente"
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.WithContext(ctx).Model(subscription).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	subscription, err = s.findSubscription(ctx, scope, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	response := webhookSubscriptionDTO(subscription)
+	return &response, nil
+}
+
+func (s *webhookService) DeleteSubscription(ctx context.Context, scope WebhookScope, subscriptionID string) error {
+	subscription, err := s.findSubscription(ctx, scope, subscriptionID)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Delete(subscription).Error
+}
+
+func (s *webhookService) RotateSecret(ctx context.Context, scope WebhookScope, subscriptionID string) (*dtos.WebhookSubscriptionDTO, error) {
+	subscription, err := s.findSubscription(ctx, scope, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.WithContext(ctx).Model(subscription).Update("secret", secret).Error; err != nil {
+		return nil, err
+	}
+
+	response := webhookSubscriptionDTO(subscription)
+	response.Secret = secret
+	return &response, nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, scope WebhookScope, subscriptionID string, req *dtos.WebhookDeliveryListRequestDTO) (*dtos.WebhookDeliveryListDTO, error) {
+	subscription, err := s.findSubscription(ctx, scope, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscription.SubscriptionID)
+	if req.Status != "" {
+		query = query.Where("delivery_status = ?", req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC").Limit(limit).Offset(req.Offset).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	response := &dtos.WebhookDeliveryListDTO{
+		Deliveries: make([]dtos.WebhookDeliveryDTO, 0, len(deliveries)),
+		TotalCount: int(total),
+		Limit:      limit,
+		Offset:     req.Offset,
+	}
+	for i := range deliveries {
+		response.Deliveries = append(response.Deliveries, webhookDeliveryDTO(&deliveries[i]))
+	}
+	return response, nil
+}
+
+func (s *webhookService) GetDelivery(ctx context.Context, scope WebhookScope, subscriptionID, deliveryID string) (*dtos.WebhookDeliveryDTO, error) {
+	delivery, err := s.findDelivery(ctx, scope, subscriptionID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	var attempts []models.WebhookDeliveryAttempt
+	if err := s.db.WithContext(ctx).Where("delivery_id = ?", delivery.DeliveryID).Order("attempt_number").Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+
+	response := webhookDeliveryDTO(delivery)
+	response.AttemptLog = make([]dtos.WebhookDeliveryAttemptDTO, 0, len(attempts))
+	for _, a := range attempts {
+		response.AttemptLog = append(response.AttemptLog, dtos.WebhookDeliveryAttemptDTO{
+			AttemptNumber: a.AttemptNumber,
+			StatusCode:    nullInt32Ptr(a.StatusCode),
+			Error:         truncate(a.ErrorMessage.String, maxExposedError),
+			DurationMs:    a.DurationMs,
+			AttemptedAt:   a.AttemptedAt,
+		})
+	}
+	return &response, nil
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, scope WebhookScope, subscriptionID, deliveryID string) (*dtos.WebhookDeliveryDTO, error) {
+	original, err := s.findDelivery(ctx, scope, subscriptionID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscription models.WebhookSubscription
+	if err := s.db.WithContext(ctx).First(&subscription, "subscription_id = ?", original.SubscriptionID).Error; err != nil {
+		return nil, err
+	}
+	if !subscription.IsActive {
+		return nil, ErrWebhookSubscriptionInactive
+	}
+
+	delivery := models.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		DeliveryStatus: models.WebhookDeliveryStatusPending,
+		MaxAttempts:    s.config.MaxAttempts,
+		NextAttemptAt:  time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+
+	response := webhookDeliveryDTO(&delivery)
+	return &response, nil
+}
+
+func (s *webhookService) scoped(ctx context.Context, scope WebhookScope) *gorm.DB {
+	query := s.db.WithContext(ctx)
+	if scope.isPartner() {
+		return query.Where("customer_id IS NULL")
+	}
+	return query.Where("customer_id = ?", scope.CustomerID)
+}
+
+func (s *webhookService) findSubscription(ctx context.Context, scope WebhookScope, subscriptionID string) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	if err := s.scoped(ctx, scope).First(&subscription, "subscription_id = ?", subscriptionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookSubscriptionNotFound
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (s *webhookService) findDelivery(ctx context.Context, scope WebhookScope, subscriptionID, deliveryID string) (*models.WebhookDelivery, error) {
+	subscription, err := s.findSubscription(ctx, scope, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var delivery models.WebhookDelivery
+	if err := s.db.WithContext(ctx).
+		First(&delivery, "delivery_id = ? AND subscription_id = ?", deliveryID, subscription.SubscriptionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func webhookSubscriptionDTO(ws *models.WebhookSubscription) dtos.WebhookSubscriptionDTO {
+	var eventTypes []string
+	_ = json.Unmarshal(ws.EventTypes, &eventTypes)
+
+	return dtos.WebhookSubscriptionDTO{
+		SubscriptionID:      ws.SubscriptionID,
+		CustomerID:          ws.CustomerID.String,
+		PartnerName:         ws.PartnerName.String,
+		URL:                 ws.URL,
+		EventTypes:          eventTypes,
+		Description:         ws.Description.String,
+		IsActive:            ws.IsActive,
+		ConsecutiveFailures: ws.ConsecutiveFailures,
+		LastSuccessAt:       nullTimePtr(ws.LastSuccessAt),
+		DisabledAt:          nullTimePtr(ws.DisabledAt),
+		DisabledReason:      ws.DisabledReason.String,
+		CreatedAt:           ws.CreatedAt,
+	}
+}
+
+func webhookDeliveryDTO(wd *models.WebhookDelivery) dtos.WebhookDeliveryDTO {
+	response := dtos.WebhookDeliveryDTO{
+		DeliveryID:     wd.DeliveryID,
+		SubscriptionID: wd.SubscriptionID,
+		EventID:        wd.EventID,
+		EventType:      wd.EventType,
+		Status:         wd.DeliveryStatus,
+		Attempts:       wd.Attempts,
+		LastStatusCode: nullInt32Ptr(wd.LastStatusCode),
+		LastError:      truncate(wd.LastError.String, maxExposedError),
+		DeliveredAt:    nullTimePtr(wd.DeliveredAt),
+		CreatedAt:      wd.CreatedAt,
+	}
+	if wd.DeliveryStatus == models.WebhookDeliveryStatusPending {
+		next := wd.NextAttemptAt
+		response.NextAttemptAt = &next
+	}
+	return response
+}
+
+func nullInt32Ptr(v sql.NullInt32) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := int(v.Int32)
+	return &i
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
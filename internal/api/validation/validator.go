@@ -0,0 +1,147 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/victor-lima-142/oak-bank/internal/postal"
+	"github.com/victor-lima-142/oak-bank/pkg/taxid"
+)
+
+var (
+	instance *validator.Validate
+	once     sync.Once
+)
+
+// Validator retorna a instância compartilhada do validator, configurada para
+// reportar os campos pelo nome das tags json/form dos DTOs
+func Validator() *validator.Validate {
+	once.Do(func() {
+		instance = validator.New(validator.WithRequiredStructEnabled())
+		instance.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+				if name != "" && name != "-" {
+					return name
+				}
+			}
+			return field.Name
+		})
+		_ = instance.RegisterValidation("cpf", func(fl validator.FieldLevel) bool {
+			return taxid.ValidCPF(fl.Field().String())
+		})
+		_ = instance.RegisterValidation("cnpj", func(fl validator.FieldLevel) bool {
+			return taxid.ValidCNPJ(fl.Field().String())
+		})
+		_ = instance.RegisterValidation("tax_id", validateTaxID)
+		_ = instance.RegisterValidation("cep", brazilOnly(postal.ValidCEP))
+		_ = instance.RegisterValidation("uf", brazilOnly(func(value string) bool {
+			_, ok := postal.NormalizeUF(value)
+			return ok
+		}))
+	})
+	return instance
+}
+
+// validateTaxID implementa a tag tax_id=Campo: valida como CNPJ quando o
+// campo irmão indicado vale PJ e como CPF nos demais casos. Sem parâmetro,
+// aceita qualquer um dos dois.
+func validateTaxID(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if fl.Param() == "" {
+		return taxid.Valid(value)
+	}
+	field, kind, _, ok := fl.GetStructFieldOKAdvanced2(fl.Parent(), fl.Param())
+	if ok && kind == reflect.String && field.String() == "PJ" {
+		return taxid.ValidCNPJ(value)
+	}
+	return taxid.ValidCPF(value)
+}
+
+// brazilOnly implementa as tags cep=Campo e uf=Campo: a regra só vale quando
+// o campo irmão indicado é o Brasil. Sem parâmetro, vale sempre.
+func brazilOnly(valid func(string) bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		if fl.Param() != "" {
+			field, kind, _, ok := fl.GetStructFieldOKAdvanced2(fl.Parent(), fl.Param())
+			if !ok || kind != reflect.String || !postal.IsBrazil(field.String()) {
+				return true
+			}
+		}
+		return valid(fl.Field().String())
+	}
+}
+
+// Struct valida um DTO usando as tags `validate`
+func Struct(s any) error {
+	return Validator().Struct(s)
+}
+
+// FieldErrors converte um erro de validação em um mapa campo -> mensagem.
+// Retorna nil se o erro não for um erro de validação.
+func FieldErrors(err error) map[string]string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	fields := make(map[string]string, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields[fieldPath(fe)] = message(fe)
+	}
+	return fields
+}
+
+// fieldPath remove o nome da struct raiz do namespace do erro
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if idx := strings.Index(namespace, "."); idx >= 0 {
+		return namespace[idx+1:]
+	}
+	return fe.Field()
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_if", "required_unless":
+		return "campo obrigatório"
+	case "excluded_if", "excluded_unless":
+		return "campo não permitido para este tipo"
+	case "email":
+		return "email inválido"
+	case "uuid4":
+		return "deve ser um UUID v4"
+	case "oneof":
+		return fmt.Sprintf("deve ser um dos valores: %s", fe.Param())
+	case "len":
+		return fmt.Sprintf("deve ter exatamente %s caracteres", fe.Param())
+	case "min":
+		return fmt.Sprintf("deve ser no mínimo %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("deve ser no máximo %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("deve ser maior que %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("deve ser maior ou igual a %s", fe.Param())
+	case "cpf":
+		return "CPF inválido"
+	case "cnpj":
+		return "CNPJ inválido"
+	case "tax_id":
+		return "CPF/CNPJ inválido"
+	case "cep":
+		return "CEP inválido"
+	case "uf":
+		return "UF inválida"
+	case "datetime":
+		return fmt.Sprintf("data inválida, use o formato %s", fe.Param())
+	case "eqfield":
+		return fmt.Sprintf("deve ser igual a %s", fe.Param())
+	default:
+		return fmt.Sprintf("falhou na regra %s", fe.Tag())
+	}
+}
@@ -0,0 +1,87 @@
+// Package coaf gera comunicações de operações suspeitas no leiaute de lote
+// usado para envio ao COAF (Conselho de Controle de Atividades Financeiras).
+package coaf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+const dateLayout = "02/01/2006"
+
+// Lote agrupa as comunicações de um envio
+type Lote struct {
+	XMLName      xml.Name      `xml:"LOTE"`
+	Comunicante  string        `xml:"CPF_CNPJ_COMUNICANTE"`
+	NomeEmpresa  string        `xml:"NOME_COMUNICANTE"`
+	DataGeracao  string        `xml:"DATA_GERACAO"`
+	Comunicacoes []Comunicacao `xml:"COMUNICACOES>COMUNICACAO"`
+}
+
+// Comunicacao é a comunicação de uma ocorrência (um caso confirmado)
+type Comunicacao struct {
+	NumeroOcorrencia string          `xml:"NUMERO_OCORRENCIA"`
+	DataInicio       string          `xml:"DATA_INICIO"`
+	DataFim          string          `xml:"DATA_FIM"`
+	ValorTotal       string          `xml:"VALOR_TOTAL"`
+	Informacoes      string          `xml:"INFORMACOES_ADICIONAIS"`
+	Envolvidos       []Envolvido     `xml:"ENVOLVIDOS>ENVOLVIDO"`
+	Enquadramentos   []Enquadramento `xml:"ENQUADRAMENTOS>ENQUADRAMENTO"`
+	Operacoes        []Operacao      `xml:"OPERACOES>OPERACAO"`
+}
+
+type Envolvido struct {
+	Tipo    string `xml:"TIPO_ENVOLVIDO"`
+	CPFCNPJ string `xml:"CPF_CNPJ"`
+	Nome    string `xml:"NOME"`
+	PEP     string `xml:"PEP"`
+}
+
+type Enquadramento struct {
+	Codigo    string `xml:"CODIGO"`
+	Descricao string `xml:"DESCRICAO"`
+}
+
+type Operacao struct {
+	ID           string `xml:"ID"`
+	Data         string `xml:"DATA"`
+	Tipo         string `xml:"TIPO"`
+	Valor        string `xml:"VALOR"`
+	ContaOrigem  string `xml:"CONTA_ORIGEM"`
+	ContaDestino string `xml:"CONTA_DESTINO,omitempty"`
+}
+
+// Date formata datas no padrão dd/mm/aaaa do leiaute
+func Date(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+// Amount formata valores com duas casas decimais e vírgula como separador
+func Amount(value float64) string {
+	formatted := fmt.Sprintf("%.2f", value)
+	return formatted[:len(formatted)-3] + "," + formatted[len(formatted)-2:]
+}
+
+// PEP converte o indicador de pessoa politicamente exposta para S/N
+func PEP(isPep bool) string {
+	if isPep {
+		return "S"
+	}
+	return "N"
+}
+
+// Encode escreve o lote em XML codificado em UTF-8
+func Encode(w io.Writer, lote *Lote) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(lote); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,31 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+)
+
+// NewPublisherFromEnv monta o Publisher a partir das variáveis de ambiente.
+//
+//	EVENTS_BROKER=log|memory|nats (padrão log)
+//	NATS_URL (padrão nats://localhost:4222)
+//	NATS_JETSTREAM=true aguarda a confirmação do stream
+//	NATS_TIMEOUT (padrão 5s)
+func NewPublisherFromEnv() (Publisher, error) {
+	broker := strings.ToLower(config.GetEnv("EVENTS_BROKER", "log"))
+	switch broker {
+	case "log":
+		return NewLogPublisher(), nil
+	case "memory":
+		return NewMemoryBroker(0), nil
+	case "nats":
+		return NewNATSPublisher(NATSConfig{
+			URL:       config.GetEnv("NATS_URL", "nats://localhost:4222"),
+			JetStream: config.GetEnvBool("NATS_JETSTREAM", false),
+			Timeout:   config.GetEnvDuration("NATS_TIMEOUT", 0),
+		})
+	}
+	return nil, fmt.Errorf("EVENTS_BROKER: broker desconhecido %q", broker)
+}
@@ -0,0 +1,110 @@
+package events
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Handler processa um evento recebido
+type Handler func(ctx context.Context, env *Envelope) error
+
+// MemoryBroker é um broker em memória para testes e desenvolvimento local. A
+// entrega é síncrona, na ordem de publicação, e os assuntos aceitam os
+// curingas do NATS: "*" casa um token e ">" casa o restante do assunto.
+type MemoryBroker struct {
+	mu            sync.RWMutex
+	subscriptions map[int]memorySubscription
+	nextID        int
+	published     []Envelope
+	keep          int
+}
+
+type memorySubscription struct {
+	pattern []string
+	handler Handler
+}
+
+// NewMemoryBroker cria o broker guardando os últimos keep eventos publicados
+// (0 não guarda nenhum)
+func NewMemoryBroker(keep int) *MemoryBroker {
+	return &MemoryBroker{
+		subscriptions: map[int]memorySubscription{},
+		keep:          keep,
+	}
+}
+
+// Subscribe registra handler para os assuntos que casam com pattern. Retorna
+// a função que cancela a assinatura.
+func (b *MemoryBroker) Subscribe(pattern string, handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.subscriptions[id] = memorySubscription{pattern: strings.Split(pattern, "."), handler: handler}
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscriptions, id)
+	}
+}
+
+// Publish entrega o evento aos assinantes. Falhas de um assinante são
+// registradas no log e não afetam o publicador nem os demais assinantes.
+func (b *MemoryBroker) Publish(ctx context.Context, env *Envelope) error {
+	subject := strings.Split(env.Subject(), ".")
+
+	b.mu.Lock()
+	if b.keep > 0 {
+		b.published = append(b.published, *env)
+		if len(b.published) > b.keep {
+			b.published = b.published[len(b.published)-b.keep:]
+		}
+	}
+	var handlers []Handler
+	for _, sub := range b.subscriptions {
+		if subjectMatches(sub.pattern, subject) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, handler := range handlers {
+		if err := handler(ctx, env); err != nil {
+			log.Printf("events: memory subscriber for %s failed: %v", env.Subject(), err)
+		}
+	}
+	return nil
+}
+
+// Published retorna uma cópia dos últimos eventos publicados
+func (b *MemoryBroker) Published() []Envelope {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]Envelope(nil), b.published...)
+}
+
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = map[int]memorySubscription{}
+	return nil
+}
+
+func subjectMatches(pattern, subject []string) bool {
+	for i, token := range pattern {
+		if token == ">" {
+			return len(subject) > i
+		}
+		if i >= len(subject) {
+			return false
+		}
+		if token != "*" && token != subject[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(subject)
+}
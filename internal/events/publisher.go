@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/outbox"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+)
+
+const Source = "oak-bank"
+
+var (
+	ErrUnknownEvent    = errors.New("tipo de evento não registrado")
+	ErrSchemaViolation = errors.New("evento não respeita o schema")
+)
+
+// Envelope é o formato publicado no broker (schemas/envelope.v1.json)
+type Envelope struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	Source        string          `json:"source"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Sequence      int64           `json:"sequence"`
+	Time          time.Time       `json:"time"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Subject retorna o assunto do evento: oakbank.<agregado>.<Tipo>.v<versão>.
+// Consumidores podem assinar todas as versões com oakbank.<agregado>.<Tipo>.*
+func (e *Envelope) Subject() string {
+	return fmt.Sprintf("oakbank.%s.%s.v%d", strings.ToLower(e.AggregateType), e.Type, e.Version)
+}
+
+// Publisher publica eventos em um broker
+type Publisher interface {
+	Publish(ctx context.Context, env *Envelope) error
+	Close() error
+}
+
+type logPublisher struct{}
+
+// NewLogPublisher cria um Publisher que apenas registra os eventos no log
+func NewLogPublisher() Publisher {
+	return logPublisher{}
+}
+
+func (logPublisher) Publish(_ context.Context, env *Envelope) error {
+	log.Printf("events: %s %s %s", env.Subject(), env.ID, env.Data)
+	return nil
+}
+
+func (logPublisher) Close() error {
+	return nil
+}
+
+type outboxPublisher struct {
+	publisher Publisher
+	registry  *Registry
+}
+
+// NewOutboxPublisher adapta um Publisher para o relay do outbox. O id do
+// envelope é o id do evento no outbox, então reentregas chegam com o mesmo id
+// e podem ser descartadas com outbox.Deduplicate.
+func NewOutboxPublisher(publisher Publisher, registry *Registry) outbox.Publisher {
+	return &outboxPublisher{publisher: publisher, registry: registry}
+}
+
+func (p *outboxPublisher) Publish(ctx context.Context, event *models.OutboxEvent) error {
+	version, err := p.registry.CurrentVersion(event.EventType)
+	if err != nil {
+		return err
+	}
+
+	env := &Envelope{
+		ID:            event.EventID,
+		Type:          event.EventType,
+		Version:       version,
+		Source:        Source,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Sequence:      event.Sequence,
+		Time:          event.OccurredAt.UTC(),
+		Data:          json.RawMessage(event.Payload),
+	}
+	if err := p.registry.Validate(env); err != nil {
+		return err
+	}
+	return p.publisher.Publish(ctx, env)
+}
@@ -0,0 +1,195 @@
+package events
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+)
+
+// Os schemas ficam em schemas/ e o índice em schemas/registry.json.
+//
+// Regras de evolução: dentro de uma versão só são permitidas mudanças
+// aditivas (campos opcionais novos). Remover, renomear ou mudar o tipo de um
+// campo exige uma nova versão, registrada em "versions" e promovida em
+// "current" quando os consumidores estiverem prontos.
+//
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+type registryFile struct {
+	Envelope string                   `json:"envelope"`
+	Events   map[string]registryEntry `json:"events"`
+}
+
+type registryEntry struct {
+	Current  int               `json:"current"`
+	Versions map[string]string `json:"versions"`
+}
+
+// Schema é o subconjunto de JSON Schema verificado na publicação: campos
+// obrigatórios e tipos das propriedades de primeiro nível
+type Schema struct {
+	Title      string                    `json:"title"`
+	Required   []string                  `json:"required"`
+	Properties map[string]SchemaProperty `json:"properties"`
+}
+
+type SchemaProperty struct {
+	Type any    `json:"type"`
+	Enum []any  `json:"enum,omitempty"`
+	Ref  string `json:"$ref,omitempty"`
+}
+
+// Registry resolve a versão corrente e o schema de cada tipo de evento
+type Registry struct {
+	events  map[string]registryEntry
+	schemas map[string]*Schema
+}
+
+// LoadRegistry carrega o registro embutido no binário
+func LoadRegistry() (*Registry, error) {
+	raw, err := schemaFS.ReadFile("schemas/registry.json")
+	if err != nil {
+		return nil, err
+	}
+
+	var file registryFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("registry.json: %w", err)
+	}
+
+	registry := &Registry{events: file.Events, schemas: map[string]*Schema{}}
+	for eventType, entry := range file.Events {
+		if _, ok := entry.Versions[strconv.Itoa(entry.Current)]; !ok {
+			return nil, fmt.Errorf("registry.json: %s sem schema para a versão corrente %d", eventType, entry.Current)
+		}
+		for _, name := range entry.Versions {
+			if _, loaded := registry.schemas[name]; loaded {
+				continue
+			}
+			schema, err := loadSchema(name)
+			if err != nil {
+				return nil, err
+			}
+			registry.schemas[name] = schema
+		}
+	}
+	return registry, nil
+}
+
+func loadSchema(name string) (*Schema, error) {
+	raw, err := schemaFS.ReadFile(path.Join("schemas", name))
+	if err != nil {
+		return nil, err
+	}
+	var schema Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &schema, nil
+}
+
+// CurrentVersion retorna a versão publicada do tipo de evento
+func (r *Registry) CurrentVersion(eventType string) (int, error) {
+	entry, ok := r.events[eventType]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownEvent, eventType)
+	}
+	return entry.Current, nil
+}
+
+// Validate confere os dados do envelope contra o schema da versão informada
+func (r *Registry) Validate(env *Envelope) error {
+	entry, ok := r.events[env.Type]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEvent, env.Type)
+	}
+	name, ok := entry.Versions[strconv.Itoa(env.Version)]
+	if !ok {
+		return fmt.Errorf("%w: %s v%d", ErrUnknownEvent, env.Type, env.Version)
+	}
+	return r.schemas[name].validate(env.Data)
+}
+
+func (s *Schema) validate(data json.RawMessage) error {
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("%w: %s: dados não são um objeto JSON", ErrSchemaViolation, s.Title)
+	}
+
+	for _, name := range s.Required {
+		if _, ok := fields[name]; !ok {
+			return fmt.Errorf("%w: %s: campo obrigatório %q ausente", ErrSchemaViolation, s.Title, name)
+		}
+	}
+	for name, value := range fields {
+		property, ok := s.Properties[name]
+		if !ok {
+			continue
+		}
+		if !property.accepts(value) {
+			return fmt.Errorf("%w: %s: campo %q com tipo inválido", ErrSchemaViolation, s.Title, name)
+		}
+		if len(property.Enum) > 0 && !containsValue(property.Enum, value) {
+			return fmt.Errorf("%w: %s: campo %q fora dos valores permitidos", ErrSchemaViolation, s.Title, name)
+		}
+	}
+	return nil
+}
+
+func (p SchemaProperty) accepts(value any) bool {
+	var types []string
+	switch t := p.Type.(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	default:
+		return true
+	}
+
+	for _, t := range types {
+		switch v := value.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && v == float64(int64(v))) {
+				return true
+			}
+		case map[string]any:
+			if t == "object" {
+				return true
+			}
+		case []any:
+			if t == "array" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
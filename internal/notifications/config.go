@@ -0,0 +1,75 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+)
+
+// Senders agrupa os provedores de cada canal
+type Senders struct {
+	Email EmailSender
+	SMS   SMSSender
+	Push  PushSender
+}
+
+// For retorna o provedor do canal
+func (s *Senders) For(channel string) (Sender, bool) {
+	switch channel {
+	case ChannelEmail:
+		return s.Email, s.Email != nil
+	case ChannelSMS:
+		return s.SMS, s.SMS != nil
+	case ChannelPush:
+		return s.Push, s.Push != nil
+	}
+	return nil, false
+}
+
+// NewSendersFromEnv monta os provedores a partir das variáveis de ambiente.
+//
+//	NOTIFICATION_EMAIL_PROVIDER=smtp|file|log (padrão log)
+//	NOTIFICATION_SMS_PROVIDER=file|log (padrão log)
+//	NOTIFICATION_PUSH_PROVIDER=file|log (padrão log)
+//	NOTIFICATION_FILE_DIR=diretório dos provedores file (padrão ./var/notifications)
+//	SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD, SMTP_FROM, SMTP_STARTTLS
+func NewSendersFromEnv() (*Senders, error) {
+	email, err := senderFromEnv(ChannelEmail, "NOTIFICATION_EMAIL_PROVIDER")
+	if err != nil {
+		return nil, err
+	}
+	sms, err := senderFromEnv(ChannelSMS, "NOTIFICATION_SMS_PROVIDER")
+	if err != nil {
+		return nil, err
+	}
+	push, err := senderFromEnv(ChannelPush, "NOTIFICATION_PUSH_PROVIDER")
+	if err != nil {
+		return nil, err
+	}
+	return &Senders{Email: email, SMS: sms, Push: push}, nil
+}
+
+func senderFromEnv(channel, key string) (Sender, error) {
+	provider := strings.ToLower(config.GetEnv(key, "log"))
+	switch provider {
+	case "log":
+		return NewLogSender(channel), nil
+	case "file":
+		dir := config.GetEnv("NOTIFICATION_FILE_DIR", "./var/notifications")
+		return NewFileSender(channel, fmt.Sprintf("%s/%s.jsonl", dir, strings.ToLower(channel)))
+	case "smtp":
+		if channel != ChannelEmail {
+			return nil, fmt.Errorf("%s: provedor smtp só atende email", key)
+		}
+		return NewSMTPSender(SMTPConfig{
+			Host:     config.GetEnv("SMTP_HOST", "localhost"),
+			Port:     config.GetEnv("SMTP_PORT", "1025"),
+			Username: config.GetEnv("SMTP_USERNAME", ""),
+			Password: config.GetEnv("SMTP_PASSWORD", ""),
+			From:     config.GetEnv("SMTP_FROM", "Oak Bank <no-reply@oakbank.local>"),
+			StartTLS: config.GetEnvBool("SMTP_STARTTLS", false),
+		})
+	}
+	return nil, fmt.Errorf("%s: provedor desconhecido %q", key, provider)
+}
@@ -0,0 +1,66 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type logSender struct {
+	channel string
+}
+
+// NewLogSender cria um provedor falso que apenas registra a mensagem no log
+func NewLogSender(channel string) Sender {
+	return &logSender{channel: channel}
+}
+
+func (s *logSender) Send(_ context.Context, msg *Message) (string, error) {
+	log.Printf("notification [%s] to=%s subject=%q body=%q", s.channel, msg.Recipient, msg.Subject, msg.Body)
+	return "log-" + msg.ID, nil
+}
+
+type fileSender struct {
+	channel string
+	path    string
+	mu      sync.Mutex
+}
+
+// NewFileSender cria um provedor falso que grava cada mensagem como uma linha
+// JSON no arquivo informado. Útil para testes e desenvolvimento local.
+func NewFileSender(channel, path string) (Sender, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return &fileSender{channel: channel, path: path}, nil
+}
+
+func (s *fileSender) Send(_ context.Context, msg *Message) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	record := map[string]any{
+		"id":        msg.ID,
+		"channel":   s.channel,
+		"recipient": msg.Recipient,
+		"subject":   msg.Subject,
+		"body":      msg.Body,
+		"data":      msg.Data,
+		"sent_at":   time.Now().UTC(),
+	}
+	if err := json.NewEncoder(f).Encode(record); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("file-%s", msg.ID), nil
+}
@@ -0,0 +1,45 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	ChannelEmail = "EMAIL"
+	ChannelSMS   = "SMS"
+	ChannelPush  = "PUSH"
+)
+
+// ErrPermanent marca falhas que não adianta reenviar (destinatário inválido,
+// mensagem recusada). O serviço de notificações manda direto para dead-letter.
+var ErrPermanent = errors.New("falha permanente no envio")
+
+// Message é uma mensagem já renderizada pronta para envio
+type Message struct {
+	ID        string
+	Recipient string
+	Subject   string
+	Body      string
+	Data      map[string]string
+}
+
+// Sender envia mensagens por um canal. Retorna o identificador atribuído pelo provedor.
+type Sender interface {
+	Send(ctx context.Context, msg *Message) (string, error)
+}
+
+// EmailSender envia emails
+type EmailSender interface {
+	Sender
+}
+
+// SMSSender envia SMS
+type SMSSender interface {
+	Sender
+}
+
+// PushSender envia notificações push para um token de dispositivo
+type PushSender interface {
+	Sender
+}
@@ -0,0 +1,136 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// SMTPConfig contém as configurações do servidor SMTP
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	// StartTLS exige STARTTLS. Sem ele o envio usa TLS apenas se o servidor oferecer.
+	StartTLS bool
+	Timeout  time.Duration
+}
+
+type smtpSender struct {
+	config SMTPConfig
+	from   *mail.Address
+}
+
+// NewSMTPSender cria um EmailSender via SMTP. Em desenvolvimento pode apontar
+// para um sink local (mailpit, MailHog) sem autenticação.
+func NewSMTPSender(config SMTPConfig) (EmailSender, error) {
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("SMTP_FROM inválido: %w", err)
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &smtpSender{config: config, from: from}, nil
+}
+
+func (s *smtpSender) Send(ctx context.Context, msg *Message) (string, error) {
+	to, err := mail.ParseAddress(msg.Recipient)
+	if err != nil {
+		return "", fmt.Errorf("%w: destinatário inválido: %v", ErrPermanent, err)
+	}
+
+	addr := net.JoinHostPort(s.config.Host, s.config.Port)
+	dialer := &net.Dialer{Timeout: s.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(s.config.Timeout))
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return "", err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.config.Host}); err != nil {
+			return "", err
+		}
+	} else if s.config.StartTLS {
+		return "", fmt.Errorf("servidor SMTP %s não suporta STARTTLS", addr)
+	}
+
+	if s.config.Username != "" {
+		auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return "", err
+		}
+	}
+
+	messageID := fmt.Sprintf("<%s@%s>", msg.ID, domainOf(s.from.Address))
+	if err := client.Mail(s.from.Address); err != nil {
+		return "", err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		// 5xx no RCPT indica destinatário recusado
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+			return "", fmt.Errorf("%w: %v", ErrPermanent, err)
+		}
+		return "", err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(buildMIMEMessage(s.from, to, messageID, msg)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	return messageID, client.Quit()
+}
+
+func buildMIMEMessage(from, to *mail.Address, messageID string, msg *Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: %s\r\n", messageID)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+func domainOf(address string) string {
+	if idx := strings.LastIndex(address, "@"); idx >= 0 {
+		return address[idx+1:]
+	}
+	return "localhost"
+}
@@ -0,0 +1,213 @@
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const (
+	LocalePtBR    = "pt-BR"
+	LocaleEnglish = "en"
+	DefaultLocale = LocalePtBR
+)
+
+const (
+	TemplatePasswordReset        = "password_reset"
+	TemplateTwoFactorCode        = "two_factor_code"
+	TemplateTransactionCompleted = "transaction_completed"
+	TemplateTransactionReceived  = "transaction_received"
+	TemplateBudgetAlert          = "budget_alert"
+	TemplateOnboardingResume     = "onboarding_resume"
+	TemplateAddressChanged       = "address_changed"
+	TemplateProfileChanged       = "profile_changed"
+	TemplatePrivacyExportReady   = "privacy_export_ready"
+)
+
+// messageTemplate tem o assunto, o corpo longo (email) e o texto curto (SMS e push)
+type messageTemplate struct {
+	Subject string
+	Body    string
+	Short   string
+}
+
+var templateSources = map[string]map[string]messageTemplate{
+	TemplatePasswordReset: {
+		LocalePtBR: {
+			Subject: "Redefinição de senha",
+			Body:    "Olá {{.name}},\n\nRecebemos um pedido para redefinir sua senha. Use o código abaixo em até {{.expires_in}}:\n\n{{.token}}\n\nSe você não fez esse pedido, ignore esta mensagem.\n\nOak Bank",
+			Short:   "Oak Bank: seu código de redefinição de senha é {{.token}}. Válido por {{.expires_in}}.",
+		},
+		LocaleEnglish: {
+			Subject: "Password reset",
+			Body:    "Hello {{.name}},\n\nWe received a request to reset your password. Use the code below within {{.expires_in}}:\n\n{{.token}}\n\nIf you did not request this, please ignore this message.\n\nOak Bank",
+			Short:   "Oak Bank: your password reset code is {{.token}}. Valid for {{.expires_in}}.",
+		},
+	},
+	TemplateTwoFactorCode: {
+		LocalePtBR: {
+			Subject: "Seu código de verificação",
+			Body:    "Olá {{.name}},\n\nSeu código de verificação é {{.code}}. Ele expira em {{.expires_in}}.\n\nNunca compartilhe este código.\n\nOak Bank",
+			Short:   "Oak Bank: seu código de verificação é {{.code}}. Expira em {{.expires_in}}. Não compartilhe.",
+		},
+		LocaleEnglish: {
+			Subject: "Your verification code",
+			Body:    "Hello {{.name}},\n\nYour verification code is {{.code}}. It expires in {{.expires_in}}.\n\nNever share this code.\n\nOak Bank",
+			Short:   "Oak Bank: your verification code is {{.code}}. Expires in {{.expires_in}}. Do not share it.",
+		},
+	},
+	TemplateTransactionCompleted: {
+		LocalePtBR: {
+			Subject: "Transação concluída",
+			Body:    "Olá {{.name}},\n\nSua transação {{.type}} de R$ {{.amount}} foi concluída em {{.date}}.\nSaldo após a operação: R$ {{.balance}}.\n\nOak Bank",
+			Short:   "Oak Bank: {{.type}} de R$ {{.amount}} concluída. Saldo: R$ {{.balance}}.",
+		},
+		LocaleEnglish: {
+			Subject: "Transaction completed",
+			Body:    "Hello {{.name}},\n\nYour {{.type}} transaction of R$ {{.amount}} was completed on {{.date}}.\nBalance after the operation: R$ {{.balance}}.\n\nOak Bank",
+			Short:   "Oak Bank: {{.type}} of R$ {{.amount}} completed. Balance: R$ {{.balance}}.",
+		},
+	},
+	TemplateTransactionReceived: {
+		LocalePtBR: {
+			Subject: "Você recebeu uma transferência",
+			Body:    "Olá {{.name}},\n\nVocê recebeu R$ {{.amount}} via {{.type}} em {{.date}}.\n\nOak Bank",
+			Short:   "Oak Bank: você recebeu R$ {{.amount}} via {{.type}}.",
+		},
+		LocaleEnglish: {
+			Subject: "You received a transfer",
+			Body:    "Hello {{.name}},\n\nYou received R$ {{.amount}} via {{.type}} on {{.date}}.\n\nOak Bank",
+			Short:   "Oak Bank: you received R$ {{.amount}} via {{.type}}.",
+		},
+	},
+	TemplateOnboardingResume: {
+		LocalePtBR: {
+			Subject: "Continue seu cadastro",
+			Body:    "Olá,\n\nUse o código abaixo para continuar seu cadastro no Oak Bank até {{.expires_at}}:\n\n{{.token}}\n\nO código anterior deixou de valer. Se você não pediu para continuar o cadastro, ignore esta mensagem.\n\nOak Bank",
+			Short:   "Oak Bank: use o código {{.token}} para continuar seu cadastro.",
+		},
+		LocaleEnglish: {
+			Subject: "Continue your sign-up",
+			Body:    "Hello,\n\nUse the code below to continue your Oak Bank sign-up until {{.expires_at}}:\n\n{{.token}}\n\nThe previous code is no longer valid. If you did not ask to continue signing up, please ignore this message.\n\nOak Bank",
+			Short:   "Oak Bank: use the code {{.token}} to continue your sign-up.",
+		},
+	},
+	TemplateAddressChanged: {
+		LocalePtBR: {
+			Subject: "Endereço alterado",
+			Body:    "Olá {{.name}},\n\nOs endereços do seu cadastro foram alterados em {{.date}}. Endereço alterado: {{.city}}/{{.state}}.\n\nSe você não fez essa alteração, entre em contato com o Oak Bank imediatamente.\n\nOak Bank",
+			Short:   "Oak Bank: os endereços do seu cadastro foram alterados. Não reconhece? Fale conosco.",
+		},
+		LocaleEnglish: {
+			Subject: "Address changed",
+			Body:    "Hello {{.name}},\n\nThe addresses on your account were changed on {{.date}}. Changed address: {{.city}}/{{.state}}.\n\nIf you did not make this change, contact Oak Bank immediately.\n\nOak Bank",
+			Short:   "Oak Bank: the addresses on your account were changed. Not you? Contact us.",
+		},
+	},
+	TemplateProfileChanged: {
+		LocalePtBR: {
+			Subject: "Cadastro alterado",
+			Body:    "Olá {{.name}},\n\nO {{if eq .field \"customer_email\"}}email{{else if eq .field \"customer_phone\"}}telefone{{else}}nome{{end}} do seu cadastro foi alterado em {{.date}}.\n\nSe você não fez essa alteração, entre em contato com o Oak Bank imediatamente.\n\nOak Bank",
+			Short:   "Oak Bank: o {{if eq .field \"customer_email\"}}email{{else if eq .field \"customer_phone\"}}telefone{{else}}nome{{end}} do seu cadastro foi alterado. Não reconhece? Fale conosco.",
+		},
+		LocaleEnglish: {
+			Subject: "Profile changed",
+			Body:    "Hello {{.name}},\n\nThe {{if eq .field \"customer_email\"}}email{{else if eq .field \"customer_phone\"}}phone number{{else}}name{{end}} on your account was changed on {{.date}}.\n\nIf you did not make this change, contact Oak Bank immediately.\n\nOak Bank",
+			Short:   "Oak Bank: the {{if eq .field \"customer_email\"}}email{{else if eq .field \"customer_phone\"}}phone number{{else}}name{{end}} on your account was changed. Not you? Contact us.",
+		},
+	},
+	TemplatePrivacyExportReady: {
+		LocalePtBR: {
+			Subject: "Seus dados estão prontos",
+			Body:    "Olá {{.name}},\n\nO arquivo com os dados que o Oak Bank mantém sobre você está disponível em Privacidade até {{.expires_at}}.\n\nSe você não fez esse pedido, entre em contato com o Oak Bank imediatamente.\n\nOak Bank",
+			Short:   "Oak Bank: o arquivo com seus dados está disponível até {{.expires_at}}. Não reconhece? Fale conosco.",
+		},
+		LocaleEnglish: {
+			Subject: "Your data is ready",
+			Body:    "Hello {{.name}},\n\nThe file with the data Oak Bank holds about you is available under Privacy until {{.expires_at}}.\n\nIf you did not make this request, contact Oak Bank immediately.\n\nOak Bank",
+			Short:   "Oak Bank: the file with your data is available until {{.expires_at}}. Not you? Contact us.",
+		},
+	},
+	TemplateBudgetAlert: {
+		LocalePtBR: {
+			Subject: "Seu orçamento atingiu {{.threshold}}%",
+			Body:    "Olá {{.name}},\n\nVocê já usou {{.threshold}}% do orçamento {{.budget}} de {{.period}}: R$ {{.spent}} de R$ {{.limit}}.\n\nOak Bank",
+			Short:   "Oak Bank: orçamento {{.budget}} em {{.threshold}}% (R$ {{.spent}} de R$ {{.limit}}).",
+		},
+		LocaleEnglish: {
+			Subject: "Your budget reached {{.threshold}}%",
+			Body:    "Hello {{.name}},\n\nYou have used {{.threshold}}% of your {{.budget}} budget for {{.period}}: R$ {{.spent}} of R$ {{.limit}}.\n\nOak Bank",
+			Short:   "Oak Bank: {{.budget}} budget at {{.threshold}}% (R$ {{.spent}} of R$ {{.limit}}).",
+		},
+	},
+}
+
+var compiledTemplates = mustCompileTemplates()
+
+type compiledTemplate struct {
+	subject *template.Template
+	body    *template.Template
+	short   *template.Template
+}
+
+func mustCompileTemplates() map[string]compiledTemplate {
+	compiled := make(map[string]compiledTemplate)
+	for key, locales := range templateSources {
+		for locale, src := range locales {
+			name := key + "." + locale
+			compiled[name] = compiledTemplate{
+				subject: template.Must(template.New(name + ".subject").Option("missingkey=zero").Parse(src.Subject)),
+				body:    template.Must(template.New(name + ".body").Option("missingkey=zero").Parse(src.Body)),
+				short:   template.Must(template.New(name + ".short").Option("missingkey=zero").Parse(src.Short)),
+			}
+		}
+	}
+	return compiled
+}
+
+// NormalizeLocale reduz o locale aos suportados (pt-BR e en)
+func NormalizeLocale(locale string) string {
+	if strings.HasPrefix(strings.ToLower(locale), "en") {
+		return LocaleEnglish
+	}
+	return LocalePtBR
+}
+
+// Render renderiza o template para o canal. Email usa o corpo completo;
+// SMS e push usam o texto curto.
+func Render(templateKey, locale, channel string, data map[string]string) (string, string, error) {
+	tmpl, ok := compiledTemplates[templateKey+"."+NormalizeLocale(locale)]
+	if !ok {
+		return "", "", fmt.Errorf("template de notificação desconhecido: %s", templateKey)
+	}
+
+	subject, err := execute(tmpl.subject, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	bodyTemplate := tmpl.short
+	if channel == ChannelEmail {
+		bodyTemplate = tmpl.body
+	}
+	body, err := execute(bodyTemplate, data)
+	if err != nil {
+		return "", "", err
+	}
+	return subject, body, nil
+}
+
+// HasTemplate informa se a chave de template existe
+func HasTemplate(templateKey string) bool {
+	_, ok := templateSources[templateKey]
+	return ok
+}
+
+func execute(tmpl *template.Template, data map[string]string) (string, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
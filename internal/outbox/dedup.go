@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Deduplicate executa handle uma única vez por consumidor e evento. O registro
+// em processed_events e os efeitos de handle são gravados na mesma transação:
+// se handle falhar, o evento continua não processado e pode ser reentregue.
+// Retorna false quando o evento já havia sido processado.
+func Deduplicate(ctx context.Context, db *gorm.DB, consumer, eventID string, handle func(tx *gorm.DB) error) (bool, error) {
+	processed := false
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ProcessedEvent{
+			Consumer: consumer,
+			EventID:  eventID,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := handle(tx); err != nil {
+			return err
+		}
+		processed = true
+		return nil
+	})
+	return processed, err
+}
+
+// IsProcessed informa se o consumidor já processou o evento. Para efeitos
+// fora do banco (chamadas HTTP, emails) prefira Deduplicate com o efeito
+// registrado na transação; IsProcessed sozinho não evita corridas.
+func IsProcessed(ctx context.Context, db *gorm.DB, consumer, eventID string) (bool, error) {
+	var count int64
+	err := db.WithContext(ctx).Model(&models.ProcessedEvent{}).
+		Where("consumer = ? AND event_id = ?", consumer, eventID).
+		Count(&count).Error
+	return count > 0, err
+}
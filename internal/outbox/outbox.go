@@ -0,0 +1,55 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ErrNoTransaction indica uma gravação no outbox fora de uma transação de banco
+var ErrNoTransaction = errors.New("outbox: gravação exige a transação de banco da mudança")
+
+// Event é um evento de domínio a ser gravado no outbox
+type Event struct {
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	Payload       any
+}
+
+// Append grava os eventos no outbox usando tx, que deve ser a mesma
+// transação da mudança de estado. Assim o evento só existe se a mudança for
+// confirmada, e a mudança nunca é confirmada sem o evento.
+//
+// A ordem por agregado segue a ordem de gravação, então quem grava deve
+// manter a linha do agregado bloqueada (SELECT ... FOR UPDATE) na transação.
+func Append(tx *gorm.DB, events ...Event) error {
+	if tx == nil {
+		return ErrNoTransaction
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	rows := make([]models.OutboxEvent, 0, len(events))
+	now := time.Now()
+	for _, event := range events {
+		payload, err := json.Marshal(event.Payload)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, models.OutboxEvent{
+			AggregateType: event.AggregateType,
+			AggregateID:   event.AggregateID,
+			EventType:     event.EventType,
+			Payload:       datatypes.JSON(payload),
+			OutboxStatus:  models.OutboxStatusPending,
+			NextAttemptAt: now,
+		})
+	}
+	return tx.Create(&rows).Error
+}
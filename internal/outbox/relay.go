@@ -0,0 +1,170 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Publisher entrega um evento do outbox ao destino (broker, log...). Deve ser
+// idempotente do ponto de vista do consumidor: o mesmo evento pode ser
+// publicado mais de uma vez se o relay cair entre a publicação e o commit.
+type Publisher interface {
+	Publish(ctx context.Context, event *models.OutboxEvent) error
+}
+
+type logPublisher struct{}
+
+// NewLogPublisher cria um Publisher que apenas registra os eventos no log
+func NewLogPublisher() Publisher {
+	return logPublisher{}
+}
+
+func (logPublisher) Publish(_ context.Context, event *models.OutboxEvent) error {
+	log.Printf("outbox: %s %s/%s #%d %s", event.EventType, event.AggregateType, event.AggregateID, event.Sequence, event.Payload)
+	return nil
+}
+
+// RelayConfig contém as configurações do relay
+type RelayConfig struct {
+	BatchSize    int
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+}
+
+// Relay publica os eventos pendentes do outbox
+type Relay struct {
+	db           *gorm.DB
+	publisher    Publisher
+	batchSize    int
+	retryBackoff time.Duration
+	maxBackoff   time.Duration
+}
+
+func NewRelay(db *gorm.DB, publisher Publisher, cfg *RelayConfig) *Relay {
+	if cfg == nil {
+		cfg = &RelayConfig{}
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = config.GetEnvInt("OUTBOX_BATCH_SIZE", 100)
+	}
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = config.GetEnvDuration("OUTBOX_RETRY_BACKOFF", time.Second)
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = config.GetEnvDuration("OUTBOX_MAX_BACKOFF", 5*time.Minute)
+	}
+
+	return &Relay{
+		db:           db,
+		publisher:    publisher,
+		batchSize:    cfg.BatchSize,
+		retryBackoff: cfg.RetryBackoff,
+		maxBackoff:   cfg.MaxBackoff,
+	}
+}
+
+// Run publica os eventos pendentes até o contexto ser cancelado
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			published, err := r.RelayOnce(ctx)
+			if err != nil {
+				log.Printf("outbox relay: %v", err)
+				break
+			}
+			if published == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publica um lote e retorna quantos eventos foram publicados.
+//
+// Só entra no lote o evento pendente mais antigo de cada agregado, e as linhas
+// ficam bloqueadas (FOR UPDATE SKIP LOCKED) até o commit. Outra instância não
+// consegue pegar o evento seguinte do mesmo agregado enquanto o anterior
+// estiver pendente, o que garante a ordem por agregado com várias instâncias.
+// Uma falha de publicação mantém o evento como cabeça do agregado, segurando
+// os seguintes até a retentativa.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	published := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var events []models.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("outbox_status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, time.Now()).
+			Where(`NOT EXISTS (
+				SELECT 1 FROM outbox_events earlier
+				WHERE earlier.aggregate_type = outbox_events.aggregate_type
+				  AND earlier.aggregate_id = outbox_events.aggregate_id
+				  AND earlier.outbox_status = ?
+				  AND earlier.sequence < outbox_events.sequence)`, models.OutboxStatusPending).
+			Order("sequence").
+			Limit(r.batchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		for i := range events {
+			event := &events[i]
+			if err := r.publisher.Publish(ctx, event); err != nil {
+				attempts := event.Attempts + 1
+				if err := tx.Model(event).Updates(map[string]interface{}{
+					"attempts":        attempts,
+					"next_attempt_at": time.Now().Add(r.backoff(attempts)),
+					"last_error":      truncate(err.Error(), 500),
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := tx.Model(event).Updates(map[string]interface{}{
+				"outbox_status": models.OutboxStatusPublished,
+				"published_at":  time.Now(),
+				"attempts":      event.Attempts + 1,
+				"last_error":    nil,
+			}).Error; err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return published, nil
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := time.Duration(float64(r.retryBackoff) * math.Pow(2, float64(attempts-1)))
+	if delay <= 0 || delay > r.maxBackoff {
+		return r.maxBackoff
+	}
+	return delay
+}
+
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
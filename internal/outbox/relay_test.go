@@ -0,0 +1,148 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestRelayBackoff(t *testing.T) {
+	r := &Relay{retryBackoff: time.Second, maxBackoff: 5 * time.Minute}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{5, 16 * time.Second},
+		{9, 256 * time.Second},
+		// 512s passa do limite
+		{10, 5 * time.Minute},
+		{40, 5 * time.Minute},
+		// 2^1100 estoura o float64 e a conversão para Duration
+		{1100, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := r.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// testDB abre o Postgres de OUTBOX_TEST_DSN em um schema próprio, removido
+// ao fim do teste. Sem a variável o teste é ignorado.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("OUTBOX_TEST_DSN")
+	if dsn == "" {
+		t.Skip("OUTBOX_TEST_DSN não definido")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// uma conexão só, para que o search_path valha em todas as consultas
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	schema := "outbox_test_" + uuid.New().String()[:8]
+	if err := db.Exec(fmt.Sprintf("CREATE SCHEMA %s", schema)).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", schema)) })
+	if err := db.Exec(fmt.Sprintf("SET search_path TO %s, public", schema)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.OutboxEvent{}); err != nil {
+		t.Fatalf("AutoMigrate: %v", err)
+	}
+	return db
+}
+
+// failingPublisher falha os eventos de failing e registra os publicados
+type failingPublisher struct {
+	failing   map[string]bool
+	published []string
+}
+
+func (p *failingPublisher) Publish(_ context.Context, event *models.OutboxEvent) error {
+	if p.failing[event.EventID] {
+		return errors.New("broker indisponível")
+	}
+	p.published = append(p.published, event.AggregateID+"#"+event.EventType)
+	return nil
+}
+
+func TestRelayOnceFailedHeadBlocksAggregate(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return Append(tx,
+			Event{AggregateType: "Account", AggregateID: "a", EventType: "1", Payload: map[string]any{}},
+			Event{AggregateType: "Account", AggregateID: "a", EventType: "2", Payload: map[string]any{}},
+			Event{AggregateType: "Account", AggregateID: "b", EventType: "1", Payload: map[string]any{}},
+		)
+	})
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	var head models.OutboxEvent
+	if err := db.Where("aggregate_id = ? AND event_type = ?", "a", "1").First(&head).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	publisher := &failingPublisher{failing: map[string]bool{head.EventID: true}}
+	relay := NewRelay(db, publisher, &RelayConfig{BatchSize: 10, RetryBackoff: time.Minute, MaxBackoff: time.Hour})
+
+	relayOnce := func(want int, wantPublished ...string) {
+		t.Helper()
+		publisher.published = nil
+		n, err := relay.RelayOnce(ctx)
+		if err != nil {
+			t.Fatalf("RelayOnce: %v", err)
+		}
+		if n != want || strings.Join(publisher.published, ",") != strings.Join(wantPublished, ",") {
+			t.Fatalf("RelayOnce = %d %v, want %d %v", n, publisher.published, want, wantPublished)
+		}
+	}
+
+	// a falha do primeiro evento de "a" segura o segundo; "b" segue
+	relayOnce(1, "b#1")
+	if err := db.First(&head, "event_id = ?", head.EventID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if head.OutboxStatus != models.OutboxStatusPending || head.Attempts != 1 || !head.LastError.Valid {
+		t.Fatalf("evento com falha = %+v", head)
+	}
+	if wait := time.Until(head.NextAttemptAt); wait < 50*time.Second || wait > time.Minute {
+		t.Fatalf("próxima tentativa em %s, want cerca de 1m", wait)
+	}
+
+	// antes da retentativa nada de "a" é publicado
+	relayOnce(0)
+
+	// na retentativa a cabeça é publicada e só depois o evento seguinte
+	delete(publisher.failing, head.EventID)
+	if err := db.Model(&head).Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatal(err)
+	}
+	relayOnce(1, "a#1")
+	relayOnce(1, "a#2")
+	relayOnce(0)
+}
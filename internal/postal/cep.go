@@ -0,0 +1,104 @@
+// Package postal valida CEPs e UFs e consulta o endereço de um CEP na base
+// local ou em um provedor online.
+package postal
+
+import (
+	"strings"
+
+	"github.com/victor-lima-142/oak-bank/pkg/taxid"
+	"github.com/victor-lima-142/oak-bank/pkg/textutil"
+)
+
+// CountryBrazil é o país gravado nos endereços com CEP
+const CountryBrazil = "BR"
+
+// states mapeia a sigla de cada UF para o nome do estado
+var states = map[string]string{
+	"AC": "Acre",
+	"AL": "Alagoas",
+	"AP": "Amapá",
+	"AM": "Amazonas",
+	"BA": "Bahia",
+	"CE": "Ceará",
+	"DF": "Distrito Federal",
+	"ES": "Espírito Santo",
+	"GO": "Goiás",
+	"MA": "Maranhão",
+	"MT": "Mato Grosso",
+	"MS": "Mato Grosso do Sul",
+	"MG": "Minas Gerais",
+	"PA": "Pará",
+	"PB": "Paraíba",
+	"PR": "Paraná",
+	"PE": "Pernambuco",
+	"PI": "Piauí",
+	"RJ": "Rio de Janeiro",
+	"RN": "Rio Grande do Norte",
+	"RS": "Rio Grande do Sul",
+	"RO": "Rondônia",
+	"RR": "Roraima",
+	"SC": "Santa Catarina",
+	"SP": "São Paulo",
+	"SE": "Sergipe",
+	"TO": "Tocantins",
+}
+
+// statesByName mapeia o nome normalizado por textutil.Fold para a sigla
+var statesByName = func() map[string]string {
+	byName := make(map[string]string, len(states))
+	for uf, name := range states {
+		byName[textutil.Fold(name)] = uf
+	}
+	return byName
+}()
+
+// NormalizeCEP remove a pontuação do CEP
+func NormalizeCEP(value string) string {
+	return taxid.OnlyDigits(value)
+}
+
+// ValidCEP confere se o CEP tem oito dígitos, aceitando a pontuação
+// 00.000-000 ou 00000-000
+func ValidCEP(value string) bool {
+	for _, r := range strings.TrimSpace(value) {
+		if (r < '0' || r > '9') && r != '-' && r != '.' {
+			return false
+		}
+	}
+	cep := NormalizeCEP(value)
+	return len(cep) == 8 && cep != "00000000"
+}
+
+// FormatCEP formata o CEP como 00000-000
+func FormatCEP(value string) string {
+	cep := NormalizeCEP(value)
+	if len(cep) != 8 {
+		return value
+	}
+	return cep[:5] + "-" + cep[5:]
+}
+
+// NormalizeUF aceita a sigla ou o nome do estado, com ou sem acentos, e
+// retorna a sigla
+func NormalizeUF(value string) (string, bool) {
+	uf := strings.ToUpper(strings.TrimSpace(value))
+	if _, ok := states[uf]; ok {
+		return uf, true
+	}
+	uf, ok := statesByName[textutil.Fold(value)]
+	return uf, ok
+}
+
+// StateName retorna o nome do estado da UF
+func StateName(uf string) string {
+	return states[strings.ToUpper(uf)]
+}
+
+// IsBrazil indica se o país informado é o Brasil, pela sigla ou pelo nome
+func IsBrazil(country string) bool {
+	switch textutil.Fold(country) {
+	case "br", "bra", "brasil", "brazil":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package postal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"gorm.io/gorm"
+)
+
+// NewProviderFromEnv monta os provedores de CEP a partir das variáveis de ambiente.
+//
+//	POSTAL_CODE_PROVIDERS lista separada por vírgula, consultada em ordem:
+//	  dataset (base local) e viacep (padrão dataset)
+//	VIACEP_URL (padrão https://viacep.com.br)
+//	POSTAL_CODE_TIMEOUT (padrão 5s)
+func NewProviderFromEnv(db *gorm.DB) (Provider, error) {
+	var chain Chain
+	for _, name := range strings.Split(config.GetEnv("POSTAL_CODE_PROVIDERS", ProviderDataset), ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "":
+			continue
+		case ProviderDataset:
+			chain = append(chain, NewDatasetProvider(db))
+		case ProviderViaCEP:
+			chain = append(chain, NewViaCEPProvider(
+				config.GetEnv("VIACEP_URL", "https://viacep.com.br"),
+				config.GetEnvDuration("POSTAL_CODE_TIMEOUT", 5*time.Second),
+			))
+		default:
+			return nil, fmt.Errorf("POSTAL_CODE_PROVIDERS: provedor desconhecido %q", name)
+		}
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("POSTAL_CODE_PROVIDERS: nenhum provedor configurado")
+	}
+	if len(chain) == 1 {
+		return chain[0], nil
+	}
+	return chain, nil
+}
@@ -0,0 +1,167 @@
+package postal
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const ProviderDataset = "dataset"
+
+var ErrInvalidDatasetFile = errors.New("arquivo de CEPs inválido")
+
+// DatasetProvider consulta a base local de CEPs (ref_postal_codes), carregada
+// com Import
+type DatasetProvider struct {
+	db *gorm.DB
+}
+
+func NewDatasetProvider(db *gorm.DB) *DatasetProvider {
+	return &DatasetProvider{db: db}
+}
+
+func (p *DatasetProvider) Name() string {
+	return ProviderDataset
+}
+
+func (p *DatasetProvider) Lookup(ctx context.Context, cep string) (*Address, error) {
+	var row models.RefPostalCode
+	err := p.db.WithContext(ctx).First(&row, "postal_code = ?", cep).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCEPNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Address{
+		CEP:          row.PostalCode,
+		Street:       row.Street,
+		Neighborhood: row.Neighborhood,
+		City:         row.City,
+		State:        row.State,
+	}, nil
+}
+
+// Import grava os CEPs de um CSV com cabeçalho, separado por vírgula ou
+// ponto e vírgula, em lotes de batchSize. As colunas cep, cidade (ou city) e
+// uf (ou state) são obrigatórias; logradouro (street) e bairro
+// (neighborhood) são opcionais. CEPs já existentes são atualizados.
+func (p *DatasetProvider) Import(ctx context.Context, r io.Reader, batchSize int) (int, error) {
+	reader, columns, err := datasetReader(r)
+	if err != nil {
+		return 0, err
+	}
+	field := func(record []string, names ...string) string {
+		for _, name := range names {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+		}
+		return ""
+	}
+
+	imported := 0
+	batch := make([]models.RefPostalCode, 0, batchSize)
+	// o mesmo CEP duas vezes no lote faria o upsert falhar; vale a última linha
+	positions := make(map[string]int, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := p.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "postal_code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"street", "neighborhood", "city", "state", "updated_at"}),
+		}).Create(&batch).Error
+		if err != nil {
+			return err
+		}
+		imported += len(batch)
+		batch = batch[:0]
+		clear(positions)
+		return nil
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return imported, fmt.Errorf("%w: linha %d: %v", ErrInvalidDatasetFile, line, err)
+		}
+
+		cep := field(record, "cep", "postal_code")
+		if !ValidCEP(cep) {
+			return imported, fmt.Errorf("%w: linha %d: CEP %q inválido", ErrInvalidDatasetFile, line, cep)
+		}
+		uf, ok := NormalizeUF(field(record, "uf", "state"))
+		if !ok {
+			return imported, fmt.Errorf("%w: linha %d: UF inválida", ErrInvalidDatasetFile, line)
+		}
+		city := field(record, "cidade", "city", "localidade")
+		if city == "" {
+			return imported, fmt.Errorf("%w: linha %d sem cidade", ErrInvalidDatasetFile, line)
+		}
+
+		row := models.RefPostalCode{
+			PostalCode:   NormalizeCEP(cep),
+			Street:       field(record, "logradouro", "street"),
+			Neighborhood: field(record, "bairro", "neighborhood"),
+			City:         city,
+			State:        uf,
+		}
+		if i, ok := positions[row.PostalCode]; ok {
+			batch[i] = row
+			continue
+		}
+		positions[row.PostalCode] = len(batch)
+		batch = append(batch, row)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return imported, err
+			}
+		}
+	}
+	return imported, flush()
+}
+
+// datasetReader lê o cabeçalho e detecta o separador pela primeira linha.
+// O restante do arquivo é lido sob demanda.
+func datasetReader(r io.Reader) (*csv.Reader, map[string]int, error) {
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+		_, _ = buffered.Discard(3)
+	}
+	buffer, _ := buffered.Peek(buffered.Buffered())
+	head, _, _ := strings.Cut(string(buffer), "\n")
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if strings.Count(head, ";") > strings.Count(head, ",") {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: cabeçalho: %v", ErrInvalidDatasetFile, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["cep"]; !ok {
+		if _, ok := columns["postal_code"]; !ok {
+			return nil, nil, fmt.Errorf("%w: coluna cep ausente", ErrInvalidDatasetFile)
+		}
+	}
+	return reader, columns, nil
+}
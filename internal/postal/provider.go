@@ -0,0 +1,51 @@
+package postal
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrCEPNotFound = errors.New("CEP não encontrado")
+	ErrInvalidCEP  = errors.New("CEP inválido")
+)
+
+// Address é o endereço de um CEP. State é sempre a sigla da UF.
+type Address struct {
+	CEP          string
+	Street       string
+	Neighborhood string
+	City         string
+	State        string
+}
+
+// Provider consulta o endereço de um CEP
+type Provider interface {
+	// Lookup recebe o CEP normalizado e retorna ErrCEPNotFound quando ele não existe
+	Lookup(ctx context.Context, cep string) (*Address, error)
+	// Name identifica o provedor nos logs
+	Name() string
+}
+
+// Chain consulta os provedores em ordem, passando ao seguinte quando o CEP
+// não é encontrado ou o provedor falha. Retorna o último erro.
+type Chain []Provider
+
+func (c Chain) Lookup(ctx context.Context, cep string) (*Address, error) {
+	err := ErrCEPNotFound
+	for _, provider := range c {
+		var address *Address
+		address, err = provider.Lookup(ctx, cep)
+		if err == nil {
+			return address, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, err
+}
+
+func (c Chain) Name() string {
+	return "chain"
+}
@@ -0,0 +1,88 @@
+package postal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const ProviderViaCEP = "viacep"
+
+// ViaCEPProvider consulta o serviço público ViaCEP
+// (GET <baseURL>/ws/<cep>/json/)
+type ViaCEPProvider struct {
+	baseURL string
+	http    *http.Client
+}
+
+func NewViaCEPProvider(baseURL string, timeout time.Duration) *ViaCEPProvider {
+	return &ViaCEPProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *ViaCEPProvider) Name() string {
+	return ProviderViaCEP
+}
+
+func (p *ViaCEPProvider) Lookup(ctx context.Context, cep string) (*Address, error) {
+	if !ValidCEP(cep) {
+		return nil, ErrInvalidCEP
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/ws/"+NormalizeCEP(cep)+"/json/", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "oak-bank-postal/1")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<10))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound:
+		return nil, ErrCEPNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("viacep: status %d", resp.StatusCode)
+	}
+
+	// o ViaCEP responde 200 com {"erro": true} (ou "true") para CEPs inexistentes
+	var payload struct {
+		CEP        string          `json:"cep"`
+		Logradouro string          `json:"logradouro"`
+		Bairro     string          `json:"bairro"`
+		Localidade string          `json:"localidade"`
+		UF         string          `json:"uf"`
+		Erro       json.RawMessage `json:"erro"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("viacep: resposta inválida: %w", err)
+	}
+	if len(payload.Erro) > 0 && string(payload.Erro) != "false" {
+		return nil, ErrCEPNotFound
+	}
+	uf, ok := NormalizeUF(payload.UF)
+	if !ok || payload.Localidade == "" {
+		return nil, fmt.Errorf("viacep: resposta incompleta para o CEP %s", cep)
+	}
+
+	return &Address{
+		CEP:          NormalizeCEP(payload.CEP),
+		Street:       payload.Logradouro,
+		Neighborhood: payload.Bairro,
+		City:         payload.Localidade,
+		State:        uf,
+	}, nil
+}
@@ -0,0 +1,125 @@
+// Package retention aplica as políticas de retenção das tabelas que crescem
+// sem limite (acessos, auditoria, filas). Cada política remove, arquiva e
+// remove, ou anonimiza as linhas mais antigas que o prazo, em lotes pequenos.
+package retention
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+const (
+	// ActionDelete remove as linhas vencidas
+	ActionDelete = "delete"
+	// ActionArchiveDelete grava as linhas vencidas em JSONL comprimido no
+	// object store antes de removê-las
+	ActionArchiveDelete = "archive_delete"
+	// ActionAnonymize substitui as colunas de Anonymize nas linhas vencidas,
+	// mantendo o registro
+	ActionAnonymize = "anonymize"
+)
+
+// As políticas padrão ficam em policies.json; RETENTION_POLICIES_FILE aponta
+// para outro arquivo no mesmo formato.
+//
+//go:embed policies.json
+var defaultPolicies []byte
+
+type policyFile struct {
+	Policies []Policy `json:"policies"`
+}
+
+// Policy declara a retenção de uma tabela. KeepDays é contado a partir de
+// TimestampColumn; linhas com o timestamp nulo nunca vencem. Filter é uma
+// condição SQL adicional (ex.: apenas notificações enviadas). Em Anonymize,
+// o valor nulo grava NULL na coluna.
+type Policy struct {
+	Name            string             `json:"name"`
+	Table           string             `json:"table"`
+	KeyColumn       string             `json:"key_column"`
+	TimestampColumn string             `json:"timestamp_column"`
+	KeepDays        int                `json:"keep_days"`
+	Action          string             `json:"action"`
+	Anonymize       map[string]*string `json:"anonymize,omitempty"`
+	Filter          string             `json:"filter,omitempty"`
+}
+
+// identifierPattern restringe nomes de tabelas e colunas, que entram no SQL sem parâmetros
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// LoadPolicies lê as políticas do arquivo informado ou, com path vazio, as
+// políticas padrão embutidas no binário
+func LoadPolicies(path string) ([]Policy, error) {
+	raw := defaultPolicies
+	name := "policies.json"
+	if path != "" {
+		var err error
+		if raw, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+		name = path
+	}
+
+	var file policyFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	seen := map[string]bool{}
+	for i := range file.Policies {
+		policy := &file.Policies[i]
+		if err := policy.validate(); err != nil {
+			return nil, fmt.Errorf("%s: política %q: %w", name, policy.Name, err)
+		}
+		if seen[policy.Name] {
+			return nil, fmt.Errorf("%s: política %q repetida", name, policy.Name)
+		}
+		seen[policy.Name] = true
+	}
+	return file.Policies, nil
+}
+
+func (p *Policy) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name é obrigatório")
+	}
+	for field, value := range map[string]string{"table": p.Table, "key_column": p.KeyColumn, "timestamp_column": p.TimestampColumn} {
+		if !identifierPattern.MatchString(value) {
+			return fmt.Errorf("%s inválido: %q", field, value)
+		}
+	}
+	if p.KeepDays <= 0 {
+		return fmt.Errorf("keep_days deve ser positivo")
+	}
+	switch p.Action {
+	case ActionDelete, ActionArchiveDelete:
+		if len(p.Anonymize) > 0 {
+			return fmt.Errorf("anonymize só é aceito com a ação %s", ActionAnonymize)
+		}
+	case ActionAnonymize:
+		if len(p.Anonymize) == 0 {
+			return fmt.Errorf("anonymize é obrigatório com a ação %s", ActionAnonymize)
+		}
+		for column := range p.Anonymize {
+			if !identifierPattern.MatchString(column) {
+				return fmt.Errorf("coluna inválida em anonymize: %q", column)
+			}
+		}
+	default:
+		return fmt.Errorf("ação desconhecida %q", p.Action)
+	}
+	return nil
+}
+
+// anonymizedColumns retorna as colunas de Anonymize em ordem estável
+func (p *Policy) anonymizedColumns() []string {
+	columns := make([]string, 0, len(p.Anonymize))
+	for column := range p.Anonymize {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
@@ -0,0 +1,119 @@
+package retention
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func validPolicy() Policy {
+	return Policy{
+		Name:            "auth-logs-delete",
+		Table:           "user_auth_log",
+		KeyColumn:       "log_id",
+		TimestampColumn: "auth_timestamp",
+		KeepDays:        30,
+		Action:          ActionDelete,
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	null := (*string)(nil)
+	masked := "***"
+
+	tests := []struct {
+		name    string
+		change  func(p *Policy)
+		wantErr string
+	}{
+		{"válida", func(p *Policy) {}, ""},
+		{"arquivamento", func(p *Policy) { p.Action = ActionArchiveDelete }, ""},
+		{"anonimização", func(p *Policy) {
+			p.Action = ActionAnonymize
+			p.Anonymize = map[string]*string{"ip_address": null, "user_agent": &masked}
+		}, ""},
+		{"sem nome", func(p *Policy) { p.Name = "" }, "name"},
+		{"tabela vazia", func(p *Policy) { p.Table = "" }, "table"},
+		{"tabela com schema", func(p *Policy) { p.Table = "public.user_auth_log" }, "table"},
+		{"tabela com aspas", func(p *Policy) { p.Table = `user_auth_log"; DROP TABLE users; --` }, "table"},
+		{"tabela com maiúsculas", func(p *Policy) { p.Table = "UserAuthLog" }, "table"},
+		{"tabela começando com dígito", func(p *Policy) { p.Table = "1_logs" }, "table"},
+		{"coluna chave com espaço", func(p *Policy) { p.KeyColumn = "log id" }, "key_column"},
+		{"coluna de timestamp com expressão", func(p *Policy) { p.TimestampColumn = "now()" }, "timestamp_column"},
+		{"prazo zero", func(p *Policy) { p.KeepDays = 0 }, "keep_days"},
+		{"prazo negativo", func(p *Policy) { p.KeepDays = -1 }, "keep_days"},
+		{"ação desconhecida", func(p *Policy) { p.Action = "truncate" }, "ação"},
+		{"anonymize sem a ação", func(p *Policy) { p.Anonymize = map[string]*string{"ip_address": null} }, "anonymize"},
+		{"anonimização sem colunas", func(p *Policy) { p.Action = ActionAnonymize }, "anonymize"},
+		{"coluna anonimizada inválida", func(p *Policy) {
+			p.Action = ActionAnonymize
+			p.Anonymize = map[string]*string{"ip_address = NULL, password_hash": null}
+		}, "coluna"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := validPolicy()
+			tt.change(&policy)
+			err := policy.validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validate err = %v, want erro com %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPolicies(t *testing.T) {
+	policies, err := LoadPolicies("")
+	if err != nil {
+		t.Fatalf("políticas padrão: %v", err)
+	}
+	if len(policies) == 0 {
+		t.Fatal("políticas padrão vazias")
+	}
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	policy := `{"name": "a", "table": "t", "key_column": "id", "timestamp_column": "created_at", "keep_days": 1, "action": "delete"}`
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"uma política", `{"policies": [` + policy + `]}`, ""},
+		{"repetida", `{"policies": [` + policy + `, ` + policy + `]}`, "repetida"},
+		{"inválida", `{"policies": [{"name": "b", "table": "t;", "key_column": "id", "timestamp_column": "created_at", "keep_days": 1, "action": "delete"}]}`, "table"},
+		{"JSON malformado", `{"policies": [`, "policies.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPolicies(write("policies.json", tt.content))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadPolicies: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadPolicies err = %v, want erro com %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LoadPolicies(filepath.Join(dir, "inexistente.json")); err == nil {
+		t.Fatal("esperava erro para arquivo inexistente")
+	}
+}
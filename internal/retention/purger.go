@@ -0,0 +1,356 @@
+package retention
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/internal/storage"
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+	"gorm.io/gorm"
+)
+
+// PurgerConfig contém o tamanho dos lotes e os limites de cada execução
+type PurgerConfig struct {
+	BatchSize int
+	// Pause é a espera entre lotes, que dá espaço às transações da API
+	Pause time.Duration
+	// MaxBatches limita os lotes por política em cada execução; o restante
+	// fica para a próxima
+	MaxBatches int
+	// ArchivePrefix é o prefixo das chaves dos arquivos no object store
+	ArchivePrefix string
+}
+
+// Report resume uma política em uma execução. Em DryRun, Matched é o total
+// de linhas vencidas e nada é alterado.
+type Report struct {
+	Policy    string
+	Table     string
+	Action    string
+	Cutoff    time.Time
+	DryRun    bool
+	Matched   int64
+	Oldest    *time.Time
+	Processed int64
+	Archives  []string
+	// Complete indica que não restaram linhas vencidas ao fim da execução
+	Complete bool
+}
+
+func (r *Report) String() string {
+	if r.DryRun {
+		oldest := "-"
+		if r.Oldest != nil {
+			oldest = r.Oldest.Format(time.RFC3339)
+		}
+		return fmt.Sprintf("%s: would %s %d rows of %s older than %s (oldest %s)",
+			r.Policy, r.Action, r.Matched, r.Table, r.Cutoff.Format(time.RFC3339), oldest)
+	}
+	return fmt.Sprintf("%s: %s %d rows of %s older than %s in %d archives (complete: %t)",
+		r.Policy, r.Action, r.Processed, r.Table, r.Cutoff.Format(time.RFC3339), len(r.Archives), r.Complete)
+}
+
+// Purger aplica as políticas de retenção
+type Purger struct {
+	db       *gorm.DB
+	store    storage.ObjectStore
+	cipher   *storage.Cipher
+	policies []Policy
+	config   PurgerConfig
+}
+
+// NewPurger cria o Purger. store e cipher podem ser nulos quando nenhuma
+// política arquiva; as políticas archive_delete falham sem eles. Os arquivos
+// contêm IPs e ids de usuário e são cifrados como os documentos de clientes.
+func NewPurger(db *gorm.DB, store storage.ObjectStore, cipher *storage.Cipher, policies []Policy, cfg *PurgerConfig) *Purger {
+	if cfg == nil {
+		cfg = &PurgerConfig{}
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = config.GetEnvInt("RETENTION_BATCH_SIZE", 500)
+	}
+	if cfg.Pause == 0 {
+		cfg.Pause = config.GetEnvDuration("RETENTION_BATCH_PAUSE", 200*time.Millisecond)
+	}
+	if cfg.MaxBatches == 0 {
+		cfg.MaxBatches = config.GetEnvInt("RETENTION_MAX_BATCHES", 200)
+	}
+	if cfg.ArchivePrefix == "" {
+		cfg.ArchivePrefix = config.GetEnv("RETENTION_ARCHIVE_PREFIX", "retention")
+	}
+
+	return &Purger{
+		db:       db,
+		store:    store,
+		cipher:   cipher,
+		policies: policies,
+		config:   *cfg,
+	}
+}
+
+// Run aplica as políticas periodicamente até o contexto ser cancelado. Com
+// dryRun apenas registra no log o que seria alterado.
+func (p *Purger) Run(ctx context.Context, interval time.Duration, dryRun bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		reports, err := p.Purge(ctx, dryRun)
+		for i := range reports {
+			if reports[i].Matched > 0 || reports[i].Processed > 0 {
+				log.Printf("retention: %s", reports[i].String())
+			}
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Printf("retention: purge failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge aplica cada política uma vez. Uma política com erro não impede as
+// seguintes; o primeiro erro é retornado junto com os relatórios.
+func (p *Purger) Purge(ctx context.Context, dryRun bool) ([]Report, error) {
+	reports := make([]Report, 0, len(p.policies))
+	var firstErr error
+	for i := range p.policies {
+		if ctx.Err() != nil {
+			return reports, ctx.Err()
+		}
+		report, err := p.apply(ctx, &p.policies[i], dryRun)
+		reports = append(reports, *report)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", p.policies[i].Name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			log.Printf("retention: %v", err)
+		}
+	}
+	return reports, firstErr
+}
+
+func (p *Purger) apply(ctx context.Context, policy *Policy, dryRun bool) (*Report, error) {
+	report := &Report{
+		Policy: policy.Name,
+		Table:  policy.Table,
+		Action: policy.Action,
+		Cutoff: time.Now().AddDate(0, 0, -policy.KeepDays),
+		DryRun: dryRun,
+	}
+	condition, args := p.condition(policy, report.Cutoff)
+
+	if dryRun {
+		var result struct {
+			Matched int64
+			Oldest  *time.Time
+		}
+		err := p.db.WithContext(ctx).Raw(
+			fmt.Sprintf("SELECT COUNT(*) AS matched, MIN(%s) AS oldest FROM %s WHERE %s",
+				quote(policy.TimestampColumn), quote(policy.Table), condition),
+			args...).Scan(&result).Error
+		report.Matched = result.Matched
+		report.Oldest = result.Oldest
+		return report, err
+	}
+
+	if policy.Action == ActionArchiveDelete && p.store == nil {
+		return report, fmt.Errorf("object store não configurado para arquivamento")
+	}
+	if policy.Action == ActionArchiveDelete && p.cipher == nil {
+		return report, fmt.Errorf("criptografia não configurada para arquivamento")
+	}
+
+	for batch := 0; batch < p.config.MaxBatches; batch++ {
+		n, archive, err := p.batch(ctx, policy, condition, args)
+		report.Processed += n
+		if archive != "" {
+			report.Archives = append(report.Archives, archive)
+		}
+		if err != nil {
+			return report, err
+		}
+		if n < int64(p.config.BatchSize) {
+			report.Complete = true
+			return report, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return report, ctx.Err()
+		case <-time.After(p.config.Pause):
+		}
+	}
+	return report, nil
+}
+
+// condition monta o filtro das linhas vencidas. Na anonimização ficam de fora
+// as linhas já anonimizadas, para que cada execução avance.
+func (p *Purger) condition(policy *Policy, cutoff time.Time) (string, []any) {
+	conditions := []string{quote(policy.TimestampColumn) + " < ?"}
+	args := []any{cutoff}
+	if policy.Filter != "" {
+		conditions = append(conditions, "("+policy.Filter+")")
+	}
+	if policy.Action == ActionAnonymize {
+		pending := make([]string, 0, len(policy.Anonymize))
+		for _, column := range policy.anonymizedColumns() {
+			if value := policy.Anonymize[column]; value != nil {
+				pending = append(pending, quote(column)+" IS DISTINCT FROM ?")
+				args = append(args, *value)
+			} else {
+				pending = append(pending, quote(column)+" IS NOT NULL")
+			}
+		}
+		conditions = append(conditions, "("+strings.Join(pending, " OR ")+")")
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// batch processa um lote em uma transação. As linhas são bloqueadas com SKIP
+// LOCKED, de modo que várias instâncias não disputam as mesmas linhas e as
+// transações da API não esperam pelo lote. Retorna as linhas processadas e a
+// chave do arquivo gravado.
+func (p *Purger) batch(ctx context.Context, policy *Policy, condition string, args []any) (int64, string, error) {
+	selectKeys := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT %d FOR UPDATE SKIP LOCKED",
+		quote(policy.KeyColumn), quote(policy.Table), condition, quote(policy.TimestampColumn), p.config.BatchSize)
+
+	switch policy.Action {
+	case ActionDelete:
+		result := p.db.WithContext(ctx).Exec(
+			fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", quote(policy.Table), quote(policy.KeyColumn), selectKeys),
+			args...)
+		return result.RowsAffected, "", result.Error
+
+	case ActionAnonymize:
+		columns := policy.anonymizedColumns()
+		assignments := make([]string, 0, len(columns))
+		values := make([]any, 0, len(columns)+len(args))
+		for _, column := range columns {
+			assignments = append(assignments, quote(column)+" = ?")
+			if value := policy.Anonymize[column]; value != nil {
+				values = append(values, *value)
+			} else {
+				values = append(values, nil)
+			}
+		}
+		result := p.db.WithContext(ctx).Exec(
+			fmt.Sprintf("UPDATE %s SET %s WHERE %s IN (%s)",
+				quote(policy.Table), strings.Join(assignments, ", "), quote(policy.KeyColumn), selectKeys),
+			append(values, args...)...)
+		return result.RowsAffected, "", result.Error
+	}
+
+	var processed int64
+	var key string
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []map[string]any
+		if err := tx.Raw(
+			fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY %s LIMIT %d FOR UPDATE SKIP LOCKED",
+				quote(policy.Table), condition, quote(policy.TimestampColumn), p.config.BatchSize),
+			args...).Scan(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		keys := make([]any, 0, len(rows))
+		for _, row := range rows {
+			keys = append(keys, row[policy.KeyColumn])
+		}
+		data, err := archive(rows)
+		if err != nil {
+			return err
+		}
+		keyID, ciphertext, err := p.cipher.Seal(data, []byte(policy.Table))
+		if err != nil {
+			return err
+		}
+		key = archiveKey(p.config.ArchivePrefix, policy.Table, keyID, time.Now())
+		if err := p.store.Put(ctx, key, ciphertext, "application/octet-stream"); err != nil {
+			key = ""
+			return err
+		}
+
+		result := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s IN ?", quote(policy.Table), quote(policy.KeyColumn)), keys)
+		processed = result.RowsAffected
+		return result.Error
+	})
+	if err != nil && key != "" {
+		// o arquivo de um lote desfeito seria duplicado na próxima execução
+		if delErr := p.store.Delete(context.WithoutCancel(ctx), key); delErr != nil {
+			log.Printf("retention: failed to remove archive %s of rolled back batch: %v", key, delErr)
+		} else {
+			key = ""
+		}
+		processed = 0
+	}
+	return processed, key, err
+}
+
+// archiveSuffix termina as chaves dos arquivos, nomeadas
+// <uuid>.<id da chave de criptografia>.jsonl.gz.enc
+const archiveSuffix = ".jsonl.gz.enc"
+
+// archiveKey monta a chave de um arquivo novo no object store
+func archiveKey(prefix, table, keyID string, now time.Time) string {
+	return fmt.Sprintf("%s/%s/%s/%s.%s%s", prefix, table, now.UTC().Format("2006/01/02"), uuid.New().String(), keyID, archiveSuffix)
+}
+
+// OpenArchive decifra um arquivo gravado pelo Purger, devolvendo o JSON Lines
+// comprimido com gzip. table é a tabela da política que gerou o arquivo.
+func OpenArchive(cipher *storage.Cipher, key, table string, data []byte) ([]byte, error) {
+	name, ok := strings.CutSuffix(path.Base(key), archiveSuffix)
+	if !ok {
+		return nil, fmt.Errorf("chave de arquivo de retenção inválida: %s", key)
+	}
+	dot := strings.LastIndexByte(name, '.')
+	if dot < 0 {
+		return nil, fmt.Errorf("chave de arquivo de retenção inválida: %s", key)
+	}
+	return cipher.Open(name[dot+1:], data, []byte(table))
+}
+
+// archive serializa as linhas em JSON Lines comprimido com gzip
+func archive(rows []map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(zw)
+	for _, row := range rows {
+		for column, value := range row {
+			// jsonb e textos chegam como []byte; o JSON é mantido como objeto
+			if raw, ok := value.([]byte); ok {
+				if json.Valid(raw) {
+					row[column] = json.RawMessage(raw)
+				} else {
+					row[column] = string(raw)
+				}
+			}
+		}
+		if err := encoder.Encode(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func quote(identifier string) string {
+	return `"` + identifier + `"`
+}
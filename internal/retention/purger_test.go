@@ -0,0 +1,160 @@
+package retention
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victor-lima-142/oak-bank/internal/storage"
+)
+
+func TestPurgerCondition(t *testing.T) {
+	cutoff := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	masked := "***"
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		want     string
+		wantArgs []any
+	}{
+		{
+			"remoção",
+			Policy{TimestampColumn: "created_at", Action: ActionDelete},
+			`"created_at" < ?`,
+			[]any{cutoff},
+		},
+		{
+			"com filtro",
+			Policy{TimestampColumn: "created_at", Action: ActionArchiveDelete, Filter: "status = 'SENT'"},
+			`"created_at" < ? AND (status = 'SENT')`,
+			[]any{cutoff},
+		},
+		{
+			// nulo é comparado com IS NOT NULL e valores com IS DISTINCT FROM,
+			// para que as linhas já anonimizadas não sejam selecionadas de novo
+			"anonimização",
+			Policy{TimestampColumn: "auth_timestamp", Action: ActionAnonymize, Anonymize: map[string]*string{
+				"user_agent": &masked,
+				"ip_address": nil,
+			}},
+			`"auth_timestamp" < ? AND ("ip_address" IS NOT NULL OR "user_agent" IS DISTINCT FROM ?)`,
+			[]any{cutoff, "***"},
+		},
+	}
+	purger := &Purger{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args := purger.condition(&tt.policy, cutoff)
+			if got != tt.want {
+				t.Fatalf("condition = %s, want %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func testCipher(t *testing.T, b byte) *storage.Cipher {
+	t.Helper()
+	c, err := storage.NewCipher(bytes.Repeat([]byte{b}, 32))
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	return c
+}
+
+func TestOpenArchive(t *testing.T) {
+	rows := []map[string]any{
+		{"log_id": "1", "ip_address": "203.0.113.7", "details": []byte(`{"reason":"INVALID_PASSWORD"}`)},
+		{"log_id": "2", "ip_address": nil, "user_agent": []byte("curl/8.0")},
+	}
+	data, err := archive(rows)
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	cipher := testCipher(t, 0x01)
+	keyID, sealed, err := cipher.Seal(data, []byte("user_auth_log"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	key := archiveKey("retention", "user_auth_log", keyID, time.Date(2025, 3, 4, 23, 0, 0, 0, time.FixedZone("BRT", -3*3600)))
+	if !strings.HasPrefix(key, "retention/user_auth_log/2025/03/05/") || !strings.HasSuffix(key, "."+keyID+archiveSuffix) {
+		t.Fatalf("archiveKey = %s", key)
+	}
+
+	opened, err := OpenArchive(cipher, key, "user_auth_log", sealed)
+	if err != nil {
+		t.Fatalf("OpenArchive: %v", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(opened))
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	var lines []map[string]any
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("linha inválida %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	want := []map[string]any{
+		// jsonb continua objeto e textos em []byte viram string
+		{"log_id": "1", "ip_address": "203.0.113.7", "details": map[string]any{"reason": "INVALID_PASSWORD"}},
+		{"log_id": "2", "ip_address": nil, "user_agent": "curl/8.0"},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("linhas = %v, want %v", lines, want)
+	}
+
+	// a chave anterior continua abrindo os arquivos após a rotação
+	rotated, err := storage.NewCipher(bytes.Repeat([]byte{0x02}, 32), bytes.Repeat([]byte{0x01}, 32))
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		cipher  *storage.Cipher
+		key     string
+		table   string
+		data    []byte
+		wantErr error
+	}{
+		{"após a rotação", rotated, key, "user_auth_log", sealed, nil},
+		{"outra tabela", cipher, key, "audit_log", sealed, storage.ErrDecryptFailed},
+		{"adulterado", cipher, key, "user_auth_log", append(bytes.Clone(sealed[:len(sealed)-1]), sealed[len(sealed)-1]^0xFF), storage.ErrDecryptFailed},
+		{"chave desconhecida", testCipher(t, 0x03), key, "user_auth_log", sealed, storage.ErrUnknownKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := OpenArchive(tt.cipher, tt.key, tt.table, tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("OpenArchive err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenArchiveInvalidKey(t *testing.T) {
+	cipher := testCipher(t, 0x01)
+	for _, key := range []string{
+		"retention/user_auth_log/2025/03/05/abc.jsonl.gz",
+		"retention/user_auth_log/2025/03/05/abc.jsonl.gz.enc.bak",
+		"retention/user_auth_log/2025/03/05/abc" + archiveSuffix,
+	} {
+		if _, err := OpenArchive(cipher, key, "user_auth_log", []byte("x")); err == nil {
+			t.Errorf("OpenArchive(%q) deveria falhar", key)
+		}
+	}
+}
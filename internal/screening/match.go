@@ -0,0 +1,195 @@
+package screening
+
+import (
+	"sort"
+	"strings"
+)
+
+// Candidate é um nome (principal ou alias) de uma entrada de lista
+type Candidate struct {
+	EntryID string
+	Name    string
+}
+
+// Match é um nome da lista com similaridade acima do limite
+type Match struct {
+	EntryID     string
+	MatchedName string
+	Score       float64
+}
+
+// Index mantém os nomes das listas em memória para a triagem
+type Index struct {
+	candidates []indexedCandidate
+}
+
+type indexedCandidate struct {
+	Candidate
+	tokens []string
+	sorted string
+}
+
+// NewIndex cria o índice normalizando os nomes com Normalize
+func NewIndex(candidates []Candidate) *Index {
+	index := &Index{candidates: make([]indexedCandidate, 0, len(candidates))}
+	for _, c := range candidates {
+		tokens := strings.Fields(Normalize(c.Name))
+		if len(tokens) == 0 {
+			continue
+		}
+		index.candidates = append(index.candidates, indexedCandidate{
+			Candidate: c,
+			tokens:    tokens,
+			sorted:    sortedJoin(tokens),
+		})
+	}
+	return index
+}
+
+// Len retorna o número de nomes indexados
+func (i *Index) Len() int {
+	return len(i.candidates)
+}
+
+// Search compara name com todos os nomes indexados e retorna, por entrada, o
+// melhor nome com pontuação maior ou igual a threshold, do maior para o menor
+func (i *Index) Search(name string, threshold float64) []Match {
+	tokens := strings.Fields(Normalize(name))
+	if len(tokens) == 0 {
+		return nil
+	}
+	sorted := sortedJoin(tokens)
+
+	best := map[string]Match{}
+	for _, c := range i.candidates {
+		score := similarity(tokens, sorted, c.tokens, c.sorted)
+		if score < threshold {
+			continue
+		}
+		if current, ok := best[c.EntryID]; !ok || score > current.Score {
+			best[c.EntryID] = Match{EntryID: c.EntryID, MatchedName: c.Name, Score: score}
+		}
+	}
+
+	matches := make([]Match, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return matches[a].EntryID < matches[b].EntryID
+	})
+	return matches
+}
+
+// Similarity compara dois nomes quaisquer, de 0 a 1
+func Similarity(a, b string) float64 {
+	ta, tb := strings.Fields(Normalize(a)), strings.Fields(Normalize(b))
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	return similarity(ta, sortedJoin(ta), tb, sortedJoin(tb))
+}
+
+// similarity é o maior entre a comparação dos nomes com tokens ordenados
+// (tolera inversão de nome e sobrenome) e a média da melhor correspondência
+// de cada token do nome mais curto, penalizada pelos tokens sem par
+func similarity(ta []string, sa string, tb []string, sb string) float64 {
+	full := JaroWinkler(sa, sb)
+	if len(ta) == 1 || len(tb) == 1 {
+		return full
+	}
+
+	short, long := ta, tb
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	total := 0.0
+	for _, s := range short {
+		bestToken := 0.0
+		for _, l := range long {
+			if score := JaroWinkler(s, l); score > bestToken {
+				bestToken = score
+			}
+		}
+		total += bestToken
+	}
+	coverage := float64(len(short)) / float64(len(long))
+	tokenScore := total / float64(len(short)) * (0.85 + 0.15*coverage)
+
+	if tokenScore > full {
+		return tokenScore
+	}
+	return full
+}
+
+func sortedJoin(tokens []string) string {
+	sorted := append([]string(nil), tokens...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, " ")
+}
+
+// JaroWinkler calcula a similaridade de Jaro-Winkler entre duas strings
+func JaroWinkler(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	la, lb := len(ra), len(rb)
+	if la == 0 || lb == 0 {
+		return 0
+	}
+
+	window := max(la, lb)/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matchedA := make([]bool, la)
+	matchedB := make([]bool, lb)
+	matches := 0
+	for i := 0; i < la; i++ {
+		start := max(0, i-window)
+		end := min(lb, i+window+1)
+		for j := start; j < end; j++ {
+			if matchedB[j] || ra[i] != rb[j] {
+				continue
+			}
+			matchedA[i], matchedB[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	k := 0
+	for i := 0; i < la; i++ {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[k] {
+			k++
+		}
+		if ra[i] != rb[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(la) + m/float64(lb) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for i := 0; i < min(4, la, lb); i++ {
+		if ra[i] != rb[i] {
+			break
+		}
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,123 @@
+package screening
+
+import (
+	"math"
+	"testing"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	// valores de referência do artigo de Winkler e de implementações conhecidas
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"martha", "marhta", 0.9611},
+		{"dwayne", "duane", 0.8400},
+		{"dixon", "dicksonx", 0.8133},
+		{"jellyfish", "smellyfish", 0.8963},
+		{"iguais", "iguais", 1},
+		{"abc", "xyz", 0},
+		{"", "abc", 0},
+		{"abc", "", 0},
+		// a comparação é por rune, não por byte
+		{"joão", "joao", 0.8667},
+	}
+	for _, tt := range tests {
+		got := JaroWinkler(tt.a, tt.b)
+		if math.Abs(got-tt.want) > 0.0001 {
+			t.Errorf("JaroWinkler(%q, %q) = %.4f, want %.4f", tt.a, tt.b, got, tt.want)
+		}
+		if other := JaroWinkler(tt.b, tt.a); math.Abs(other-got) > 1e-9 {
+			t.Errorf("JaroWinkler não é simétrica para %q e %q: %.4f e %.4f", tt.a, tt.b, got, other)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"iguais", "Maria Silva", "Maria Silva", 1, 1},
+		{"sobrenome antes do nome", "Silva Maria", "Maria Silva", 1, 1},
+		{"inversão com vírgula", "SILVA, Maria", "Maria Silva", 1, 1},
+		{"acentos e caixa", "José Conceição", "JOSE CONCEICAO", 1, 1},
+		{"partículas ignoradas", "Maria da Silva", "Maria Silva", 1, 1},
+		{"transliteração", "Владимир Путин", "Vladimir Putin", 1, 1},
+		{"erro de digitação", "Maria Slva", "Maria Silva", 0.95, 0.99},
+		// o nome curto casa com dois de cinco tokens: 0,85 + 0,15 * 2/5
+		{"nome curto contra alias longo", "Maria Silva", "Maria Aparecida Souza Silva Santos", 0.91, 0.91},
+		{"nomes diferentes", "Maria Silva", "Carlos Pereira", 0, 0.7},
+		{"vazio", "", "Maria Silva", 0, 0},
+		{"só partículas", "de da", "Maria Silva", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(tt.a, tt.b)
+			if got < tt.min-0.0001 || got > tt.max+0.0001 {
+				t.Fatalf("Similarity(%q, %q) = %.4f, want entre %.2f e %.2f", tt.a, tt.b, got, tt.min, tt.max)
+			}
+			if other := Similarity(tt.b, tt.a); math.Abs(other-got) > 1e-9 {
+				t.Fatalf("Similarity não é simétrica: %.4f e %.4f", got, other)
+			}
+		})
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	index := NewIndex([]Candidate{
+		{EntryID: "1", Name: "Maria Silva"},
+		{EntryID: "1", Name: "Maria S."},
+		{EntryID: "2", Name: "Maria Aparecida Souza Silva Santos"},
+		{EntryID: "3", Name: "Carlos Pereira"},
+		// sem tokens após a normalização: fica fora do índice
+		{EntryID: "4", Name: "Ltda"},
+	})
+	if index.Len() != 4 {
+		t.Fatalf("Len = %d, want 4", index.Len())
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		threshold float64
+		want      []string
+	}{
+		{"nome exato", "Maria Silva", 0.95, []string{"1"}},
+		{"inversão e acentos", "SÍLVA, María", 0.95, []string{"1"}},
+		// o alias longo só entra com limite abaixo da penalidade de cobertura
+		{"alias longo abaixo do limite", "Maria Silva", 0.92, []string{"1"}},
+		{"alias longo acima do limite", "Maria Silva", 0.90, []string{"1", "2"}},
+		{"sem acerto", "João Souza", 0.95, nil},
+		{"consulta vazia", "  ", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := index.Search(tt.query, tt.threshold)
+			if len(matches) != len(tt.want) {
+				t.Fatalf("Search = %+v, want entradas %v", matches, tt.want)
+			}
+			for i, m := range matches {
+				if m.EntryID != tt.want[i] {
+					t.Fatalf("Search[%d] = %s, want %s (%+v)", i, m.EntryID, tt.want[i], matches)
+				}
+				if m.Score < tt.threshold {
+					t.Fatalf("Search[%d] com pontuação %.4f abaixo do limite", i, m.Score)
+				}
+			}
+		})
+	}
+
+	// por entrada vale o melhor nome
+	matches := index.Search("Maria Silva", 0.5)
+	if matches[0].EntryID != "1" || matches[0].MatchedName != "Maria Silva" || matches[0].Score != 1 {
+		t.Fatalf("melhor acerto = %+v, want o nome principal da entrada 1", matches[0])
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Score > matches[i-1].Score {
+			t.Fatalf("Search fora de ordem: %+v", matches)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Package screening contém a normalização, a comparação aproximada de nomes e
+// a leitura dos arquivos de listas restritivas (sanções e PEP).
+package screening
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/victor-lima-142/oak-bank/pkg/textutil"
+)
+
+// transliteration converte letras sem decomposição Unicode para o alfabeto latino
+var transliteration = map[rune]string{
+	// latinas especiais
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i",
+	// cirílico
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia", 'і': "i", 'ї': "i", 'є': "ie", 'ґ': "g",
+	// grego
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i",
+	'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s",
+	'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// stopwords são partículas e sufixos societários que não distinguem nomes
+var stopwords = map[string]bool{
+	"de": true, "da": true, "do": true, "das": true, "dos": true, "e": true,
+	"ltda": true, "me": true, "eireli": true, "sa": true, "inc": true, "llc": true, "ltd": true, "co": true,
+}
+
+// Normalize remove acentos, translitera para o alfabeto latino, troca
+// pontuação por espaço e descarta partículas. O resultado é usado tanto na
+// gravação das listas quanto na comparação.
+func Normalize(name string) string {
+	folded := textutil.Fold(name)
+
+	var b strings.Builder
+	for _, r := range folded {
+		if latin, ok := transliteration[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			continue
+		}
+		// apóstrofos unem as partes (O'Brien, D'Ávila)
+		if r == '\'' || r == '’' {
+			continue
+		}
+		b.WriteRune(' ')
+	}
+
+	tokens := strings.Fields(b.String())
+	kept := tokens[:0]
+	for _, token := range tokens {
+		if !stopwords[token] {
+			kept = append(kept, token)
+		}
+	}
+	return strings.Join(kept, " ")
+}
@@ -0,0 +1,266 @@
+package screening
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	EntryTypeIndividual = "INDIVIDUAL"
+	EntryTypeEntity     = "ENTITY"
+)
+
+var ErrInvalidListFile = errors.New("arquivo de lista inválido")
+
+// Entry é uma entrada lida de um arquivo de lista
+type Entry struct {
+	ExternalID  string
+	EntryType   string
+	Name        string
+	Aliases     []string
+	Country     string
+	Program     string
+	DateOfBirth string
+}
+
+// Parse lê o arquivo no formato indicado ("csv" ou "xml"). Com formato vazio,
+// o conteúdo é inspecionado: arquivos que começam com "<" são tratados como XML.
+func Parse(format string, r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = "csv"
+		if bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), []byte("<")) {
+			format = "xml"
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case "csv":
+		return ParseCSV(bytes.NewReader(data))
+	case "xml":
+		return ParseXML(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("%w: formato %q não suportado", ErrInvalidListFile, format)
+	}
+}
+
+// ParseCSV lê um CSV com cabeçalho. As colunas id e name são obrigatórias;
+// aliases (separados por ";"), type, country, program e date_of_birth são
+// opcionais.
+func ParseCSV(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cabeçalho: %v", ErrInvalidListFile, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["id"]; !ok {
+		return nil, fmt.Errorf("%w: coluna id ausente", ErrInvalidListFile)
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("%w: coluna name ausente", ErrInvalidListFile)
+	}
+
+	field := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var entries []Entry
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: linha %d: %v", ErrInvalidListFile, line, err)
+		}
+
+		entry := Entry{
+			ExternalID:  field(record, "id"),
+			EntryType:   strings.ToUpper(field(record, "type")),
+			Name:        field(record, "name"),
+			Country:     field(record, "country"),
+			Program:     field(record, "program"),
+			DateOfBirth: field(record, "date_of_birth"),
+		}
+		for _, alias := range strings.Split(field(record, "aliases"), ";") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				entry.Aliases = append(entry.Aliases, alias)
+			}
+		}
+		if entry.ExternalID == "" || entry.Name == "" {
+			return nil, fmt.Errorf("%w: linha %d sem id ou nome", ErrInvalidListFile, line)
+		}
+		entries = append(entries, normalizeEntryType(entry))
+	}
+	return entries, nil
+}
+
+// ParseXML lê a lista consolidada do Conselho de Segurança da ONU
+// (CONSOLIDATED_LIST) ou o formato simples
+// <LIST><ENTRY id="" type=""><NAME/><ALIAS/><COUNTRY/><PROGRAM/><DATE_OF_BIRTH/></ENTRY></LIST>
+func ParseXML(r io.Reader) ([]Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidListFile, err)
+	}
+
+	switch root.XMLName.Local {
+	case "CONSOLIDATED_LIST":
+		return parseUNList(data)
+	case "LIST":
+		return parseSimpleXML(data)
+	default:
+		return nil, fmt.Errorf("%w: elemento raiz %q não reconhecido", ErrInvalidListFile, root.XMLName.Local)
+	}
+}
+
+type unName struct {
+	DataID      string   `xml:"DATAID"`
+	First       string   `xml:"FIRST_NAME"`
+	Second      string   `xml:"SECOND_NAME"`
+	Third       string   `xml:"THIRD_NAME"`
+	Fourth      string   `xml:"FOURTH_NAME"`
+	ListType    string   `xml:"UN_LIST_TYPE"`
+	Nationality []string `xml:"NATIONALITY>VALUE"`
+}
+
+func (n unName) fullName() string {
+	return strings.Join(strings.Fields(strings.Join([]string{n.First, n.Second, n.Third, n.Fourth}, " ")), " ")
+}
+
+func parseUNList(data []byte) ([]Entry, error) {
+	var list struct {
+		Individuals []struct {
+			unName
+			Aliases []string `xml:"INDIVIDUAL_ALIAS>ALIAS_NAME"`
+			Births  []struct {
+				Date string `xml:"DATE"`
+				Year string `xml:"YEAR"`
+			} `xml:"INDIVIDUAL_DATE_OF_BIRTH"`
+		} `xml:"INDIVIDUALS>INDIVIDUAL"`
+		Entities []struct {
+			unName
+			Aliases []string `xml:"ENTITY_ALIAS>ALIAS_NAME"`
+		} `xml:"ENTITIES>ENTITY"`
+	}
+	if err := xml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidListFile, err)
+	}
+
+	entries := make([]Entry, 0, len(list.Individuals)+len(list.Entities))
+	for _, individual := range list.Individuals {
+		entry := Entry{
+			ExternalID: individual.DataID,
+			EntryType:  EntryTypeIndividual,
+			Name:       individual.fullName(),
+			Aliases:    nonEmpty(individual.Aliases),
+			Program:    individual.ListType,
+		}
+		if len(individual.Nationality) > 0 {
+			entry.Country = individual.Nationality[0]
+		}
+		if len(individual.Births) > 0 {
+			entry.DateOfBirth = individual.Births[0].Date
+			if entry.DateOfBirth == "" {
+				entry.DateOfBirth = individual.Births[0].Year
+			}
+		}
+		if entry.ExternalID == "" || entry.Name == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	for _, entity := range list.Entities {
+		entry := Entry{
+			ExternalID: entity.DataID,
+			EntryType:  EntryTypeEntity,
+			Name:       entity.fullName(),
+			Aliases:    nonEmpty(entity.Aliases),
+			Program:    entity.ListType,
+		}
+		if entry.ExternalID == "" || entry.Name == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func parseSimpleXML(data []byte) ([]Entry, error) {
+	var list struct {
+		Entries []struct {
+			ID          string   `xml:"id,attr"`
+			Type        string   `xml:"type,attr"`
+			Name        string   `xml:"NAME"`
+			Aliases     []string `xml:"ALIAS"`
+			Country     string   `xml:"COUNTRY"`
+			Program     string   `xml:"PROGRAM"`
+			DateOfBirth string   `xml:"DATE_OF_BIRTH"`
+		} `xml:"ENTRY"`
+	}
+	if err := xml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidListFile, err)
+	}
+
+	entries := make([]Entry, 0, len(list.Entries))
+	for i, e := range list.Entries {
+		entry := Entry{
+			ExternalID:  strings.TrimSpace(e.ID),
+			EntryType:   strings.ToUpper(strings.TrimSpace(e.Type)),
+			Name:        strings.TrimSpace(e.Name),
+			Aliases:     nonEmpty(e.Aliases),
+			Country:     strings.TrimSpace(e.Country),
+			Program:     strings.TrimSpace(e.Program),
+			DateOfBirth: strings.TrimSpace(e.DateOfBirth),
+		}
+		if entry.ExternalID == "" || entry.Name == "" {
+			return nil, fmt.Errorf("%w: entrada %d sem id ou nome", ErrInvalidListFile, i+1)
+		}
+		entries = append(entries, normalizeEntryType(entry))
+	}
+	return entries, nil
+}
+
+func normalizeEntryType(entry Entry) Entry {
+	if entry.EntryType != EntryTypeEntity {
+		entry.EntryType = EntryTypeIndividual
+	}
+	return entry
+}
+
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
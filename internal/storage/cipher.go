@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrUnknownKey    = errors.New("chave de criptografia desconhecida")
+	ErrDecryptFailed = errors.New("falha ao descriptografar objeto")
+)
+
+// Cipher cifra objetos com AES-256-GCM. O nonce vai no início do conteúdo
+// cifrado. Chaves anteriores continuam disponíveis para leitura, permitindo a
+// rotação: objetos novos usam sempre a chave atual.
+type Cipher struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewCipher cria o Cipher com a chave atual e as chaves anteriores, todas com 32 bytes
+func NewCipher(current []byte, previous ...[]byte) (*Cipher, error) {
+	c := &Cipher{keys: map[string]cipher.AEAD{}}
+	for i, key := range append([][]byte{current}, previous...) {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("chave de criptografia deve ter 32 bytes, recebida com %d", len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		id := KeyID(key)
+		if i == 0 {
+			c.current = id
+		}
+		c.keys[id] = gcm
+	}
+	return c, nil
+}
+
+// KeyID identifica a chave sem expô-la: os primeiros 8 bytes do SHA-256
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Seal cifra plaintext com a chave atual. additionalData é autenticado mas
+// não cifrado; deve ser repetido em Open.
+func (c *Cipher) Seal(plaintext, additionalData []byte) (string, []byte, error) {
+	gcm := c.keys[c.current]
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", nil, err
+	}
+	return c.current, gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func (c *Cipher) Open(keyID string, ciphertext, additionalData []byte) ([]byte, error) {
+	gcm, ok := c.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, ErrDecryptFailed
+	}
+	nonce, data := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, data, additionalData)
+	if err != nil {
+		return nil, ErrDecryptFailed
+	}
+	return plaintext, nil
+}
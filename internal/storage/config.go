@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/victor-lima-142/oak-bank/pkg/config"
+)
+
+// NewObjectStoreFromEnv monta o ObjectStore de documentos a partir das variáveis de ambiente.
+//
+//	DOCUMENT_STORE=local|s3 (padrão local)
+//	DOCUMENT_STORE_DIR (padrão ./var/documents)
+//	S3_ENDPOINT, S3_REGION (padrão us-east-1), S3_BUCKET
+//	S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY
+//	S3_PATH_STYLE=true para o MinIO (padrão true)
+//	S3_TIMEOUT (padrão 30s)
+func NewObjectStoreFromEnv() (ObjectStore, error) {
+	backend := strings.ToLower(config.GetEnv("DOCUMENT_STORE", BackendLocal))
+	switch backend {
+	case BackendLocal:
+		return NewLocalStore(config.GetEnv("DOCUMENT_STORE_DIR", "./var/documents"))
+	case BackendS3:
+		return NewS3Store(S3Config{
+			Endpoint:  config.GetEnv("S3_ENDPOINT", "http://localhost:9000"),
+			Region:    config.GetEnv("S3_REGION", "us-east-1"),
+			Bucket:    config.GetEnv("S3_BUCKET", "oak-documents"),
+			AccessKey: config.GetEnv("S3_ACCESS_KEY_ID", ""),
+			SecretKey: config.GetEnv("S3_SECRET_ACCESS_KEY", ""),
+			PathStyle: config.GetEnvBool("S3_PATH_STYLE", true),
+			Timeout:   config.GetEnvDuration("S3_TIMEOUT", 0),
+		})
+	}
+	return nil, fmt.Errorf("DOCUMENT_STORE: backend desconhecido %q", backend)
+}
+
+// NewCipherFromEnv monta o Cipher a partir das chaves em base64.
+//
+//	DOCUMENT_ENCRYPTION_KEY chave atual com 32 bytes (obrigatória)
+//	DOCUMENT_ENCRYPTION_PREVIOUS_KEYS chaves anteriores separadas por vírgula
+func NewCipherFromEnv() (*Cipher, error) {
+	encoded := config.GetEnv("DOCUMENT_ENCRYPTION_KEY", "")
+	if encoded == "" {
+		return nil, fmt.Errorf("DOCUMENT_ENCRYPTION_KEY é obrigatória")
+	}
+	current, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("DOCUMENT_ENCRYPTION_KEY inválida: %w", err)
+	}
+
+	var previous [][]byte
+	for _, value := range strings.Split(config.GetEnv("DOCUMENT_ENCRYPTION_PREVIOUS_KEYS", ""), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("DOCUMENT_ENCRYPTION_PREVIOUS_KEYS inválida: %w", err)
+		}
+		previous = append(previous, key)
+	}
+	return NewCipher(current, previous...)
+}
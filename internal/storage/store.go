@@ -0,0 +1,39 @@
+// Package storage guarda arquivos em um object store (sistema de arquivos
+// local ou serviço compatível com S3) e cifra o conteúdo antes do envio.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrObjectNotFound = errors.New("objeto não encontrado")
+	ErrInvalidKey     = errors.New("chave de objeto inválida")
+)
+
+// ObjectStore grava e lê objetos por chave. Chaves usam "/" como separador.
+type ObjectStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get retorna ErrObjectNotFound quando a chave não existe
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete não retorna erro quando a chave não existe
+	Delete(ctx context.Context, key string) error
+	// Backend identifica a implementação gravada nos metadados do objeto
+	Backend() string
+}
+
+// validKey recusa chaves vazias, absolutas ou com segmentos relativos
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	EventTransactionStatusChanged = "transaction.status_changed"
+	EventAccountStatusChanged     = "account.status_changed"
+	EventCustomerKYCChanged       = "customer.kyc_status_changed"
+)
+
+// EventTypes lista os eventos aceitos em assinaturas
+var EventTypes = []string{
+	EventTransactionStatusChanged,
+	EventAccountStatusChanged,
+	EventCustomerKYCChanged,
+}
+
+// maxResponseBody limita o quanto da resposta do parceiro é lido e registrado
+const maxResponseBody = 4 << 10
+
+// Request é uma entrega a ser enviada
+type Request struct {
+	URL       string
+	Secret    string
+	EventID   string
+	EventType string
+	Body      []byte
+}
+
+// Result registra o resultado de uma tentativa de entrega
+type Result struct {
+	StatusCode   int
+	ResponseBody string
+	Duration     time.Duration
+	Err          error
+}
+
+// Success indica se o parceiro aceitou a entrega (2xx)
+func (r *Result) Success() bool {
+	return r.Err == nil && r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Client envia entregas assinadas
+type Client struct {
+	http *http.Client
+	now  func() time.Time
+}
+
+// NewClient cria um cliente com o timeout informado. Redirecionamentos não são
+// seguidos: a URL cadastrada é a única autorizada a receber o payload. O
+// transporte não usa proxy e só conecta a endereços públicos.
+func NewClient(timeout time.Duration) *Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: dialControl,
+	}
+	return &Client{
+		http: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// Deliver envia o corpo com os cabeçalhos de identificação e assinatura
+func (c *Client) Deliver(ctx context.Context, req *Request) *Result {
+	start := c.now()
+	timestamp := start.Unix()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return &Result{Err: err}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "oak-bank-webhooks/1")
+	httpReq.Header.Set(HeaderEventID, req.EventID)
+	httpReq.Header.Set(HeaderEventType, req.EventType)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return &Result{Err: err, Duration: c.now().Sub(start)}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	return &Result{
+		StatusCode:   resp.StatusCode,
+		ResponseBody: string(body),
+		Duration:     c.now().Sub(start),
+	}
+}
@@ -0,0 +1,62 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+var (
+	ErrURLNotAllowed     = errors.New("url de webhook deve usar https e um nome de host público")
+	ErrAddressNotAllowed = errors.New("endereço de destino do webhook não permitido")
+)
+
+// ValidateURL aceita apenas URLs https com nome de host. IPs literais são
+// recusados no cadastro; nomes que resolvem para redes internas são barrados
+// na conexão por dialControl.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return ErrURLNotAllowed
+	}
+	host := u.Hostname()
+	if host == "" || strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return ErrURLNotAllowed
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return ErrURLNotAllowed
+	}
+	return nil
+}
+
+// dialControl roda depois da resolução de DNS, com o IP que de fato será
+// conectado, e recusa destinos internos
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !publicAddr(addr.Unmap()) {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, addr)
+	}
+	return nil
+}
+
+func publicAddr(addr netip.Addr) bool {
+	return addr.IsValid() &&
+		!addr.IsUnspecified() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast()
+}
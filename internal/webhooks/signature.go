@@ -0,0 +1,71 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderEventID   = "X-Oak-Event-Id"
+	HeaderEventType = "X-Oak-Event-Type"
+	HeaderTimestamp = "X-Oak-Timestamp"
+	HeaderSignature = "X-Oak-Signature"
+
+	signatureVersion = "v1"
+	secretPrefix     = "whsec_"
+)
+
+var (
+	ErrInvalidSignature = errors.New("assinatura do webhook inválida")
+	ErrTimestampExpired = errors.New("timestamp do webhook fora da tolerância")
+)
+
+// GenerateSecret gera o segredo compartilhado de uma assinatura
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(buf), nil
+}
+
+// Sign calcula a assinatura HMAC-SHA256 de "<timestamp>.<corpo>". Incluir o
+// timestamp no conteúdo assinado impede a reutilização de entregas antigas.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify confere os cabeçalhos de uma entrega recebida. É o mesmo algoritmo
+// que os parceiros devem implementar do lado deles.
+func Verify(secret, timestampHeader, signatureHeader string, body []byte, tolerance time.Duration, now time.Time) error {
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: timestamp ausente", ErrInvalidSignature)
+	}
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrTimestampExpired
+		}
+	}
+
+	expected := Sign(secret, timestamp, body)
+	// durante a rotação de segredo o cabeçalho pode trazer mais de uma assinatura
+	for _, candidate := range strings.Split(signatureHeader, ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(candidate)), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
@@ -0,0 +1,86 @@
+package webhooks
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testSecret    = "whsec_test"
+	testTimestamp = int64(1700000000)
+	testBody      = `{"event_id":"evt_1"}`
+)
+
+func TestSign(t *testing.T) {
+	// calculado de forma independente: HMAC-SHA256("whsec_test", "1700000000.<corpo>")
+	want := "v1=0344289cae2f5eb6d2640d79205fa65017efc017e224155b7ef95f784061172c"
+	if got := Sign(testSecret, testTimestamp, []byte(testBody)); got != want {
+		t.Fatalf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(testTimestamp, 0)
+	timestamp := strconv.FormatInt(testTimestamp, 10)
+	signature := Sign(testSecret, testTimestamp, []byte(testBody))
+	oldSignature := Sign("whsec_old", testTimestamp, []byte(testBody))
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      string
+		now       time.Time
+		wantErr   error
+	}{
+		{"válida", testSecret, timestamp, signature, testBody, now, nil},
+		{"dentro da tolerância", testSecret, timestamp, signature, testBody, now.Add(4 * time.Minute), nil},
+		{"corpo adulterado", testSecret, timestamp, signature, `{"event_id":"evt_2"}`, now, ErrInvalidSignature},
+		{"timestamp adulterado", testSecret, strconv.FormatInt(testTimestamp+1, 10), signature, testBody, now.Add(time.Second), ErrInvalidSignature},
+		{"outro segredo", "whsec_other", timestamp, signature, testBody, now, ErrInvalidSignature},
+		{"expirado", testSecret, timestamp, signature, testBody, now.Add(6 * time.Minute), ErrTimestampExpired},
+		{"no futuro", testSecret, timestamp, signature, testBody, now.Add(-6 * time.Minute), ErrTimestampExpired},
+		{"sem timestamp", testSecret, "", signature, testBody, now, ErrInvalidSignature},
+		{"sem assinatura", testSecret, timestamp, "", testBody, now, ErrInvalidSignature},
+		{"sem versão", testSecret, timestamp, strings.TrimPrefix(signature, "v1="), testBody, now, ErrInvalidSignature},
+		// durante a rotação o cabeçalho traz a assinatura com o segredo antigo e com o novo
+		{"rotação, nova por último", testSecret, timestamp, oldSignature + "," + signature, testBody, now, nil},
+		{"rotação, nova primeiro", testSecret, timestamp, signature + ", " + oldSignature, testBody, now, nil},
+		{"rotação, segredo antigo", "whsec_old", timestamp, oldSignature + "," + signature, testBody, now, nil},
+		{"rotação sem a assinatura do segredo", "whsec_other", timestamp, oldSignature + "," + signature, testBody, now, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.timestamp, tt.signature, []byte(tt.body), 5*time.Minute, tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyWithoutTolerance(t *testing.T) {
+	// sem tolerância a idade do timestamp não é conferida
+	signature := Sign(testSecret, testTimestamp, []byte(testBody))
+	later := time.Unix(testTimestamp, 0).Add(365 * 24 * time.Hour)
+	if err := Verify(testSecret, strconv.FormatInt(testTimestamp, 10), signature, []byte(testBody), 0, later); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	if !strings.HasPrefix(secret, secretPrefix) || len(secret) != len(secretPrefix)+64 {
+		t.Fatalf("GenerateSecret = %q", secret)
+	}
+	if other, _ := GenerateSecret(); other == secret {
+		t.Fatal("GenerateSecret deve gerar segredos diferentes")
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetEnv retorna a variável de ambiente ou o valor padrão se estiver vazia
+func GetEnv(key, def string) string {
+	return getenvOr(key, def)
+}
+
+// GetEnvInt retorna a variável de ambiente convertida para int ou o valor padrão
+func GetEnvInt(key string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return v
+	}
+	return def
+}
+
+// GetEnvFloat retorna a variável de ambiente convertida para float64 ou o valor padrão
+func GetEnvFloat(key string, def float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return v
+	}
+	return def
+}
+
+// GetEnvBool retorna a variável de ambiente convertida para bool ou o valor padrão
+func GetEnvBool(key string, def bool) bool {
+	if v, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return v
+	}
+	return def
+}
+
+// GetEnvDuration aceita durações Go (15m, 1h), dias (7d) ou segundos
+func GetEnvDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return d
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if d, err := strconv.Atoi(days); err == nil {
+			return time.Duration(d) * 24 * time.Hour
+		}
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return def
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"github.com/victor-lima-142/oak-bank/pkg/fieldcrypt"
+	"gorm.io/gorm"
+)
+
+// Migrate cria ou atualiza as tabelas do domínio. A ordem respeita as
+// dependências de chave estrangeira entre os modelos.
+func Migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(
+		&fieldcrypt.DataKey{},
+		&models.RefAccountType{},
+		&models.RefAddressType{},
+		&models.RefPostalCode{},
+		&models.RefTransactionType{},
+		&models.Customer{},
+		&models.CustomerCompany{},
+		&models.CustomerLegalRepresentative{},
+		&models.User{},
+		&models.Address{},
+		&models.CustomerAddress{},
+		&models.Account{},
+		&models.AccountStatusHistory{},
+		&models.Transaction{},
+		&models.UserAuthLog{},
+		&models.TwoFactorRecoveryCode{},
+		&models.AuditLog{},
+		&models.PaymentBatch{},
+		&models.PaymentBatchRow{},
+		&models.RefTransactionCategory{},
+		&models.CategoryRule{},
+		&models.CustomerCategoryOverride{},
+		&models.TransactionCategory{},
+		&models.Budget{},
+		&models.BudgetAlert{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.PushDevice{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.WebhookDeliveryAttempt{},
+		&models.OutboxEvent{},
+		&models.ProcessedEvent{},
+		&models.FraudDecision{},
+		&models.AmlCase{},
+		&models.AmlAlert{},
+		&models.AmlCaseNote{},
+		&models.SanctionsList{},
+		&models.SanctionsEntry{},
+		&models.ScreeningReview{},
+		&models.ScreeningMatch{},
+		&models.KYCSubmission{},
+		&models.KYCStatusHistory{},
+		&models.CustomerDocument{},
+		&models.CustomerRiskOverride{},
+		&models.CustomerRiskAssessment{},
+		&models.OnboardingSession{},
+		&models.OneTimeCode{},
+		&models.StepUpToken{},
+		&models.ProfileChangeRequest{},
+		&models.CustomerProfileVersion{},
+		&models.DataSubjectRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range customIndexes {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return Seed(db)
+}
+
+// customIndexes são índices que as tags do GORM não conseguem expressar
+// (expressões e índices parciais) e os ajustes de dados que os precedem
+var customIndexes = []string{
+	// um orçamento por categoria e um único orçamento de saídas totais por cliente
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_customer_scope ON budgets (customer_id, COALESCE(category_code, ''))`,
+	// o relay procura, por agregado, o evento pendente mais antigo
+	`CREATE INDEX IF NOT EXISTS idx_outbox_pending_aggregate ON outbox_events (aggregate_type, aggregate_id, sequence) WHERE outbox_status = 'PENDING'`,
+	// no máximo um caso de AML não encerrado por cliente
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_aml_cases_open_customer ON aml_cases (customer_id) WHERE case_status <> 'CLOSED'`,
+	// no máximo um envio de KYC aguardando análise por cliente
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_kyc_submissions_pending_customer ON kyc_submissions (customer_id) WHERE submission_status = 'PENDING'`,
+	// no máximo uma revisão manual de risco não revogada por cliente
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_risk_overrides_active_customer ON customer_risk_overrides (customer_id) WHERE revoked_at IS NULL`,
+	// vínculos anteriores ao histórico de endereços: a vigência começa na
+	// criação do vínculo e o mais antigo vigente de cada tipo passa a ser o principal
+	`UPDATE customer_addresses SET effective_from = created_at WHERE effective_from > created_at`,
+	`UPDATE customer_addresses ca SET is_primary = true
+		FROM (SELECT DISTINCT ON (customer_id, address_type) customer_id, address_id
+			FROM customer_addresses WHERE effective_to IS NULL
+			ORDER BY customer_id, address_type, effective_from, created_at) oldest
+		WHERE ca.customer_id = oldest.customer_id AND ca.address_id = oldest.address_id
+			AND NOT EXISTS (SELECT 1 FROM customer_addresses p
+				WHERE p.customer_id = ca.customer_id AND p.address_type = ca.address_type
+					AND p.is_primary AND p.effective_to IS NULL)`,
+	// no máximo um endereço principal vigente por tipo
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_customer_addresses_primary ON customer_addresses (customer_id, address_type) WHERE is_primary AND effective_to IS NULL`,
+	// no máximo um pedido de alteração em aberto por campo do cadastro
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_profile_change_requests_open_field ON profile_change_requests (customer_id, field_name) WHERE request_status IN ('PENDING_VERIFICATION', 'PENDING_REVIEW')`,
+	// no máximo um pedido do titular em aberto por tipo
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_data_subject_requests_open_type ON data_subject_requests (customer_id, request_type) WHERE request_status IN ('PENDING', 'SCHEDULED', 'FAILED')`,
+	// uma única chave de dados ativa
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_encryption_data_keys_active ON encryption_data_keys (key_status) WHERE key_status = 'ACTIVE'`,
+	// tax_id passou a ser cifrado; a unicidade fica com idx_customers_taxId_hash
+	`DROP INDEX IF EXISTS "idx_customers_taxId"`,
+}
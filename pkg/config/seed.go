@@ -0,0 +1,91 @@
+package config
+
+import (
+	"database/sql"
+
+	"github.com/victor-lima-142/oak-bank/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Seed insere os dados de referência necessários. Registros existentes não
+// são sobrescritos, então ajustes feitos em produção são preservados.
+func Seed(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := seedAccountTypes(tx); err != nil {
+			return err
+		}
+		if err := seedAddressTypes(tx); err != nil {
+			return err
+		}
+		if err := seedTransactionCategories(tx); err != nil {
+			return err
+		}
+		return seedCategoryRules(tx)
+	})
+}
+
+func seedAccountTypes(tx *gorm.DB) error {
+	types := []models.RefAccountType{
+		{AccountTypeCode: "CHECKING", Description: "Conta corrente", AllowsOverdraft: true},
+		{AccountTypeCode: "SAVINGS", Description: "Conta poupança"},
+		{AccountTypeCode: "PAYMENT", Description: "Conta de pagamento"},
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&types).Error
+}
+
+func seedAddressTypes(tx *gorm.DB) error {
+	types := []models.RefAddressType{
+		{AddressTypeCode: "residential", Description: "Residencial"},
+		{AddressTypeCode: "commercial", Description: "Comercial"},
+		{AddressTypeCode: "billing", Description: "Correspondência"},
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&types).Error
+}
+
+func seedTransactionCategories(tx *gorm.DB) error {
+	categories := []models.RefTransactionCategory{
+		{CategoryCode: "FOOD", Description: "Alimentação", Direction: models.CategoryDirectionOutflow},
+		{CategoryCode: "GROCERIES", Description: "Mercado", Direction: models.CategoryDirectionOutflow},
+		{CategoryCode: "TRANSPORT", Description: "Transporte", Direction: models.CategoryDirectionOutflow},
+		{CategoryCode: "HOUSING", Description: "Moradia", Direction: models.CategoryDirectionOutflow},
+		{CategoryCode: "UTILITIES", Description: "Contas de consumo", Direction: models.CategoryDirectionOutflow},
+		{CategoryCode: "HEALTH", Description: "Saúde", Direction: models.CategoryDirectionOutflow},
+		{CategoryCode: "EDUCATION", Description: "Educação", Direction: models.CategoryDirectionOutflow},
+		{CategoryCode: "ENTERTAINMENT", Description: "Lazer", Direction: models.CategoryDirectionOutflow},
+		{CategoryCode: "SHOPPING", Description: "Compras", Direction: models.CategoryDirectionOutflow},
+		{CategoryCode: "TAXES", Description: "Impostos e taxas", Direction: models.CategoryDirectionOutflow},
+		{CategoryCode: "TRANSFERS", Description: "Transferências", Direction: models.CategoryDirectionOutflow},
+		{CategoryCode: models.DefaultOutflowCategory, Description: "Outros", Direction: models.CategoryDirectionOutflow},
+		{CategoryCode: "SALARY", Description: "Salário", Direction: models.CategoryDirectionInflow},
+		{CategoryCode: models.DefaultInflowCategory, Description: "Entradas", Direction: models.CategoryDirectionInflow},
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&categories).Error
+}
+
+func seedCategoryRules(tx *gorm.DB) error {
+	var count int64
+	if err := tx.Model(&models.CategoryRule{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	pattern := func(p string) sql.NullString { return sql.NullString{String: p, Valid: true} }
+	rules := []models.CategoryRule{
+		{CategoryCode: "SALARY", Priority: 10, Direction: models.CategoryDirectionInflow, DescriptionPattern: pattern(`sal[aá]rio|folha de pagamento|proventos`)},
+		{CategoryCode: "FOOD", Priority: 20, Direction: models.CategoryDirectionOutflow, DescriptionPattern: pattern(`ifood|restaurante|lanchonete|padaria|rappi`)},
+		{CategoryCode: "GROCERIES", Priority: 20, Direction: models.CategoryDirectionOutflow, DescriptionPattern: pattern(`mercado|supermercado|atacad[aã]o|hortifruti`)},
+		{CategoryCode: "TRANSPORT", Priority: 20, Direction: models.CategoryDirectionOutflow, DescriptionPattern: pattern(`\buber\b|\b99\b|t[aá]xi|combust[ií]vel|posto|estacionamento|ped[aá]gio`)},
+		{CategoryCode: "HOUSING", Priority: 20, Direction: models.CategoryDirectionOutflow, DescriptionPattern: pattern(`aluguel|condom[ií]nio|iptu`)},
+		{CategoryCode: "UTILITIES", Priority: 20, Direction: models.CategoryDirectionOutflow, DescriptionPattern: pattern(`energia|\bluz\b|[aá]gua|internet|telefone|celular|g[aá]s`)},
+		{CategoryCode: "HEALTH", Priority: 20, Direction: models.CategoryDirectionOutflow, DescriptionPattern: pattern(`farm[aá]cia|drogaria|hospital|cl[ií]nica|plano de sa[uú]de|consulta`)},
+		{CategoryCode: "EDUCATION", Priority: 20, Direction: models.CategoryDirectionOutflow, DescriptionPattern: pattern(`escola|faculdade|curso|mensalidade`)},
+		{CategoryCode: "ENTERTAINMENT", Priority: 20, Direction: models.CategoryDirectionOutflow, DescriptionPattern: pattern(`netflix|spotify|cinema|show|ingresso|streaming`)},
+		{CategoryCode: "TAXES", Priority: 20, Direction: models.CategoryDirectionOutflow, DescriptionPattern: pattern(`imposto|darf|tributo|taxa`)},
+		{CategoryCode: "TRANSFERS", Priority: 90, Direction: models.CategoryDirectionOutflow, TransactionTypeCode: pattern("TED")},
+		{CategoryCode: "TRANSFERS", Priority: 90, Direction: models.CategoryDirectionOutflow, TransactionTypeCode: pattern("TRANSFER")},
+	}
+	return tx.Create(&rules).Error
+}
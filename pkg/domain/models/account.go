@@ -13,6 +13,12 @@ import (
 // ACCOUNTS
 // ===========================
 
+const (
+	AccountStatusActive  = "ACTIVE"
+	AccountStatusBlocked = "BLOCKED"
+	AccountStatusClosed  = "CLOSED"
+)
+
 type Account struct {
 	AccountID        string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"account_id"`
 	CustomerID       string         `gorm:"type:uuid;uniqueIndex;index:idx_accounts_customer_id;not null" json:"customer_id"`
@@ -16,6 +16,7 @@ type Address struct {
 	AddressID    string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"address_id"`
 	AddressLine1 string         `gorm:"type:varchar(200);not null" json:"address_line_1"`
 	AddressLine2 sql.NullString `gorm:"type:varchar(200)" json:"address_line_2"`
+	Neighborhood sql.NullString `gorm:"type:varchar(100)" json:"neighborhood"`
 	City         string         `gorm:"type:varchar(100);not null" json:"city"`
 	State        string         `gorm:"type:varchar(50);not null" json:"state"`
 	Country      string         `gorm:"type:varchar(50);not null" json:"country"`
@@ -49,3 +50,18 @@ type RefAddressType struct {
 func (RefAddressType) TableName() string {
 	return "ref_address_types"
 }
+
+// RefPostalCode é um CEP da base local, carregada por cmd/cep-import.
+// PostalCode tem apenas os dígitos e State a sigla da UF.
+type RefPostalCode struct {
+	PostalCode   string    `gorm:"type:varchar(8);primaryKey" json:"postal_code"`
+	Street       string    `gorm:"type:varchar(200)" json:"street"`
+	Neighborhood string    `gorm:"type:varchar(100)" json:"neighborhood"`
+	City         string    `gorm:"type:varchar(100);not null" json:"city"`
+	State        string    `gorm:"type:varchar(2);not null" json:"state"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime;not null" json:"updated_at"`
+}
+
+func (RefPostalCode) TableName() string {
+	return "ref_postal_codes"
+}
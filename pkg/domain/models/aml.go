@@ -0,0 +1,128 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// AML
+// ===========================
+
+const (
+	AmlRuleStructuring    = "STRUCTURING"
+	AmlRuleRapidMovement  = "RAPID_MOVEMENT"
+	AmlRuleUnusualVolume  = "UNUSUAL_VOLUME"
+	AmlRuleProfileChanges = "PROFILE_CHANGES"
+
+	AmlCaseStatusOpen      = "OPEN"
+	AmlCaseStatusInReview  = "IN_REVIEW"
+	AmlCaseStatusEscalated = "ESCALATED"
+	AmlCaseStatusClosed    = "CLOSED"
+
+	AmlDispositionConfirmed     = "CONFIRMED_SUSPICIOUS"
+	AmlDispositionFalsePositive = "FALSE_POSITIVE"
+	AmlDispositionInsufficient  = "INSUFFICIENT_EVIDENCE"
+
+	AmlNoteComment      = "COMMENT"
+	AmlNoteStatusChange = "STATUS_CHANGE"
+	AmlNoteAssignment   = "ASSIGNMENT"
+	AmlNoteAlert        = "ALERT"
+)
+
+// AmlAlert é uma detecção de uma regra de monitoramento. DedupKey impede que a
+// mesma regra gere alertas repetidos para o cliente no mesmo período.
+type AmlAlert struct {
+	AlertID        string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"alert_id"`
+	CaseID         string         `gorm:"type:uuid;index:idx_aml_alerts_case_id;not null" json:"case_id"`
+	CustomerID     string         `gorm:"type:uuid;index:idx_aml_alerts_customer_id;not null" json:"customer_id"`
+	RuleCode       string         `gorm:"type:varchar(30);not null" json:"rule_code"`
+	DedupKey       string         `gorm:"type:varchar(150);uniqueIndex:idx_aml_alerts_dedup_key;not null" json:"dedup_key"`
+	Description    string         `gorm:"type:varchar(500);not null" json:"description"`
+	TotalAmount    float64        `gorm:"type:decimal(15,2);not null" json:"total_amount"`
+	WindowStart    time.Time      `gorm:"not null" json:"window_start"`
+	WindowEnd      time.Time      `gorm:"not null" json:"window_end"`
+	TransactionIDs datatypes.JSON `gorm:"type:jsonb;not null" json:"transaction_ids"`
+	Details        datatypes.JSON `gorm:"type:jsonb" json:"details"`
+	StrictProfile  bool           `gorm:"default:false;not null" json:"strict_profile"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+
+	// Relations
+	Case     *AmlCase  `gorm:"foreignKey:CaseID;references:CaseID;constraint:OnDelete:CASCADE" json:"case,omitempty"`
+	Customer *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+}
+
+func (a *AmlAlert) BeforeCreate(tx *gorm.DB) error {
+	if a.AlertID == "" {
+		a.AlertID = uuid.New().String()
+	}
+	return nil
+}
+
+func (AmlAlert) TableName() string {
+	return "aml_alerts"
+}
+
+// AmlCase agrupa os alertas de um cliente para análise da área de compliance.
+// Cada cliente tem no máximo um caso não encerrado (idx_aml_cases_open_customer).
+type AmlCase struct {
+	CaseID            string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"case_id"`
+	CaseNumber        int64          `gorm:"type:bigserial;autoIncrement;uniqueIndex:idx_aml_cases_case_number" json:"case_number"`
+	CustomerID        string         `gorm:"type:uuid;index:idx_aml_cases_customer_id;not null" json:"customer_id"`
+	CaseStatus        string         `gorm:"type:varchar(20);default:'OPEN';index:idx_aml_cases_status;not null" json:"case_status"`
+	AssignedToUserID  sql.NullString `gorm:"type:uuid;index:idx_aml_cases_assigned_to" json:"assigned_to_user_id"`
+	Disposition       sql.NullString `gorm:"type:varchar(30)" json:"disposition"`
+	DispositionReason sql.NullString `gorm:"type:text" json:"disposition_reason"`
+	ClosedByUserID    sql.NullString `gorm:"type:uuid" json:"closed_by_user_id"`
+	ClosedAt          sql.NullTime   `json:"closed_at"`
+	ReportedAt        sql.NullTime   `json:"reported_at"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Customer   *Customer     `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+	AssignedTo *User         `gorm:"foreignKey:AssignedToUserID;references:UserID;constraint:OnDelete:SET NULL" json:"assigned_to,omitempty"`
+	ClosedBy   *User         `gorm:"foreignKey:ClosedByUserID;references:UserID;constraint:OnDelete:SET NULL" json:"closed_by,omitempty"`
+	Alerts     []AmlAlert    `gorm:"foreignKey:CaseID" json:"alerts,omitempty"`
+	Notes      []AmlCaseNote `gorm:"foreignKey:CaseID" json:"notes,omitempty"`
+}
+
+func (c *AmlCase) BeforeCreate(tx *gorm.DB) error {
+	if c.CaseID == "" {
+		c.CaseID = uuid.New().String()
+	}
+	return nil
+}
+
+func (AmlCase) TableName() string {
+	return "aml_cases"
+}
+
+// AmlCaseNote registra comentários e o histórico de atribuições e status do caso
+type AmlCaseNote struct {
+	NoteID       string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"note_id"`
+	CaseID       string         `gorm:"type:uuid;index:idx_aml_case_notes_case_date,priority:1;not null" json:"case_id"`
+	AuthorUserID sql.NullString `gorm:"type:uuid" json:"author_user_id"`
+	NoteType     string         `gorm:"type:varchar(20);not null" json:"note_type"`
+	Note         string         `gorm:"type:text;not null" json:"note"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime;index:idx_aml_case_notes_case_date,priority:2;not null" json:"created_at"`
+
+	// Relations
+	Case   *AmlCase `gorm:"foreignKey:CaseID;references:CaseID;constraint:OnDelete:CASCADE" json:"case,omitempty"`
+	Author *User    `gorm:"foreignKey:AuthorUserID;references:UserID;constraint:OnDelete:SET NULL" json:"author,omitempty"`
+}
+
+func (n *AmlCaseNote) BeforeCreate(tx *gorm.DB) error {
+	if n.NoteID == "" {
+		n.NoteID = uuid.New().String()
+	}
+	return nil
+}
+
+func (AmlCaseNote) TableName() string {
+	return "aml_case_notes"
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// PAYMENT BATCHES
+// ===========================
+
+const (
+	PaymentBatchStatusValidated  = "VALIDATED"
+	PaymentBatchStatusApproved   = "APPROVED"
+	PaymentBatchStatusProcessing = "PROCESSING"
+	PaymentBatchStatusCompleted  = "COMPLETED"
+	PaymentBatchStatusCancelled  = "CANCELLED"
+
+	PaymentBatchRowStatusValid     = "VALID"
+	PaymentBatchRowStatusInvalid   = "INVALID"
+	PaymentBatchRowStatusSucceeded = "SUCCEEDED"
+	PaymentBatchRowStatusFailed    = "FAILED"
+	PaymentBatchRowStatusOnHold    = "ON_HOLD"
+	PaymentBatchRowStatusSkipped   = "SKIPPED"
+)
+
+type PaymentBatch struct {
+	BatchID          string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"batch_id"`
+	CustomerID       string         `gorm:"type:uuid;index:idx_batches_customer_id;not null" json:"customer_id"`
+	CreatedByUserID  string         `gorm:"type:uuid;not null" json:"created_by_user_id"`
+	ApprovedByUserID sql.NullString `gorm:"type:uuid" json:"approved_by_user_id"`
+	FileName         string         `gorm:"type:varchar(255);not null" json:"file_name"`
+	FileSHA256       string         `gorm:"type:varchar(64);index:idx_batches_file_hash;not null" json:"file_sha256"`
+	BatchStatus      string         `gorm:"type:varchar(20);default:'VALIDATED';index:idx_batches_status;not null" json:"batch_status"`
+	TotalRows        int            `gorm:"default:0;not null" json:"total_rows"`
+	ValidRows        int            `gorm:"default:0;not null" json:"valid_rows"`
+	InvalidRows      int            `gorm:"default:0;not null" json:"invalid_rows"`
+	ProcessedRows    int            `gorm:"default:0;not null" json:"processed_rows"`
+	SucceededRows    int            `gorm:"default:0;not null" json:"succeeded_rows"`
+	FailedRows       int            `gorm:"default:0;not null" json:"failed_rows"`
+	TotalAmount      float64        `gorm:"type:decimal(15,2);default:0;not null" json:"total_amount"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+	ApprovedAt       sql.NullTime   `json:"approved_at"`
+	StartedAt        sql.NullTime   `json:"started_at"`
+	FinishedAt       sql.NullTime   `json:"finished_at"`
+
+	// Relations
+	Customer       *Customer         `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:RESTRICT" json:"customer,omitempty"`
+	CreatedByUser  *User             `gorm:"foreignKey:CreatedByUserID;references:UserID;constraint:OnDelete:RESTRICT" json:"created_by_user,omitempty"`
+	ApprovedByUser *User             `gorm:"foreignKey:ApprovedByUserID;references:UserID;constraint:OnDelete:SET NULL" json:"approved_by_user,omitempty"`
+	Rows           []PaymentBatchRow `gorm:"foreignKey:BatchID;constraint:OnDelete:CASCADE" json:"rows,omitempty"`
+}
+
+func (b *PaymentBatch) BeforeCreate(tx *gorm.DB) error {
+	if b.BatchID == "" {
+		b.BatchID = uuid.New().String()
+	}
+	return nil
+}
+
+func (PaymentBatch) TableName() string {
+	return "payment_batches"
+}
+
+type PaymentBatchRow struct {
+	RowID               string          `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"row_id"`
+	BatchID             string          `gorm:"type:uuid;uniqueIndex:idx_batch_rows_line,priority:1;not null" json:"batch_id"`
+	LineNumber          int             `gorm:"uniqueIndex:idx_batch_rows_line,priority:2;not null" json:"line_number"`
+	TransactionTypeCode string          `gorm:"type:varchar(20)" json:"transaction_type_code"`
+	AccountIDOrigin     string          `gorm:"type:varchar(36)" json:"account_id_origin"`
+	AccountIDDest       sql.NullString  `gorm:"type:varchar(36)" json:"account_id_dest"`
+	Amount              float64         `gorm:"type:decimal(15,2);default:0;not null" json:"amount"`
+	Description         sql.NullString  `gorm:"type:varchar(500)" json:"description"`
+	ExternalReference   sql.NullString  `gorm:"type:varchar(100)" json:"external_reference"`
+	IdempotencyKey      string          `gorm:"type:varchar(100);index:idx_batch_rows_idempotency_key;not null" json:"idempotency_key"`
+	RowStatus           string          `gorm:"type:varchar(20);index:idx_batch_rows_status;not null" json:"row_status"`
+	ValidationErrors    datatypes.JSON  `gorm:"type:jsonb" json:"validation_errors"`
+	TransactionID       sql.NullString  `gorm:"type:uuid" json:"transaction_id"`
+	ErrorMessage        sql.NullString  `gorm:"type:varchar(500)" json:"error_message"`
+	BalanceAfter        sql.NullFloat64 `gorm:"type:decimal(15,2)" json:"balance_after"`
+	ProcessedAt         sql.NullTime    `json:"processed_at"`
+
+	// Relations
+	Batch       *PaymentBatch `gorm:"foreignKey:BatchID;references:BatchID;constraint:OnDelete:CASCADE" json:"batch,omitempty"`
+	Transaction *Transaction  `gorm:"foreignKey:TransactionID;references:TransactionID;constraint:OnDelete:SET NULL" json:"transaction,omitempty"`
+}
+
+func (r *PaymentBatchRow) BeforeCreate(tx *gorm.DB) error {
+	if r.RowID == "" {
+		r.RowID = uuid.New().String()
+	}
+	return nil
+}
+
+func (PaymentBatchRow) TableName() string {
+	return "payment_batch_rows"
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// BUDGETS
+// ===========================
+
+const (
+	BudgetAlertStatusPending  = "PENDING"
+	BudgetAlertStatusNotified = "NOTIFIED"
+)
+
+// BudgetThresholds são os percentuais de uso que disparam alertas
+var BudgetThresholds = []int{50, 80, 100}
+
+// Budget é o orçamento mensal do cliente. CategoryCode nulo indica orçamento
+// sobre o total de saídas.
+type Budget struct {
+	BudgetID     string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"budget_id"`
+	CustomerID   string         `gorm:"type:uuid;index:idx_budgets_customer_id;not null" json:"customer_id"`
+	CategoryCode sql.NullString `gorm:"type:varchar(30)" json:"category_code"`
+	MonthlyLimit float64        `gorm:"type:decimal(15,2);not null" json:"monthly_limit"`
+	IsActive     bool           `gorm:"default:true;not null" json:"is_active"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+	UpdatedAt    time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Customer *Customer               `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+	Category *RefTransactionCategory `gorm:"foreignKey:CategoryCode;references:CategoryCode" json:"category,omitempty"`
+	Alerts   []BudgetAlert           `gorm:"foreignKey:BudgetID;constraint:OnDelete:CASCADE" json:"alerts,omitempty"`
+}
+
+func (b *Budget) BeforeCreate(tx *gorm.DB) error {
+	if b.BudgetID == "" {
+		b.BudgetID = uuid.New().String()
+	}
+	return nil
+}
+
+func (Budget) TableName() string {
+	return "budgets"
+}
+
+// BudgetAlert é gerado uma única vez por orçamento, mês e limiar atingido
+type BudgetAlert struct {
+	AlertID       string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"alert_id"`
+	BudgetID      string         `gorm:"type:uuid;uniqueIndex:idx_budget_alerts_period,priority:1;not null" json:"budget_id"`
+	CustomerID    string         `gorm:"type:uuid;index:idx_budget_alerts_customer_id;not null" json:"customer_id"`
+	PeriodMonth   string         `gorm:"type:varchar(7);uniqueIndex:idx_budget_alerts_period,priority:2;not null" json:"period_month"`
+	Threshold     int            `gorm:"uniqueIndex:idx_budget_alerts_period,priority:3;not null" json:"threshold"`
+	CategoryCode  sql.NullString `gorm:"type:varchar(30)" json:"category_code"`
+	SpentAmount   float64        `gorm:"type:decimal(15,2);not null" json:"spent_amount"`
+	LimitAmount   float64        `gorm:"type:decimal(15,2);not null" json:"limit_amount"`
+	TransactionID sql.NullString `gorm:"type:uuid" json:"transaction_id"`
+	AlertStatus   string         `gorm:"type:varchar(20);default:'PENDING';index:idx_budget_alerts_status;not null" json:"alert_status"`
+	TriggeredAt   time.Time      `gorm:"autoCreateTime;not null" json:"triggered_at"`
+	NotifiedAt    sql.NullTime   `json:"notified_at"`
+
+	// Relations
+	Budget   *Budget   `gorm:"foreignKey:BudgetID;references:BudgetID;constraint:OnDelete:CASCADE" json:"budget,omitempty"`
+	Customer *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+}
+
+func (a *BudgetAlert) BeforeCreate(tx *gorm.DB) error {
+	if a.AlertID == "" {
+		a.AlertID = uuid.New().String()
+	}
+	return nil
+}
+
+func (BudgetAlert) TableName() string {
+	return "budget_alerts"
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// TRANSACTION CATEGORIES
+// ===========================
+
+const (
+	CategoryDirectionOutflow = "OUT"
+	CategoryDirectionInflow  = "IN"
+
+	CategorySourceRule     = "RULE"
+	CategorySourceOverride = "OVERRIDE"
+	CategorySourceManual   = "MANUAL"
+	CategorySourceDefault  = "DEFAULT"
+
+	CategoryOverrideMatchCounterparty = "COUNTERPARTY"
+	CategoryOverrideMatchDescription  = "DESCRIPTION"
+
+	DefaultOutflowCategory = "OTHER"
+	DefaultInflowCategory  = "INCOME"
+)
+
+type RefTransactionCategory struct {
+	CategoryCode string `gorm:"type:varchar(30);primaryKey" json:"category_code"`
+	Description  string `gorm:"type:varchar(100);not null" json:"description"`
+	Direction    string `gorm:"type:varchar(3);not null" json:"direction"`
+}
+
+func (RefTransactionCategory) TableName() string {
+	return "ref_transaction_categories"
+}
+
+// CategoryRule é uma regra global de categorização. Campos nulos não
+// restringem a regra; a regra de menor prioridade que casar vence.
+type CategoryRule struct {
+	RuleID              string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"rule_id"`
+	CategoryCode        string         `gorm:"type:varchar(30);not null" json:"category_code"`
+	Priority            int            `gorm:"default:100;index:idx_category_rules_priority;not null" json:"priority"`
+	Direction           string         `gorm:"type:varchar(3);not null" json:"direction"`
+	DescriptionPattern  sql.NullString `gorm:"type:varchar(200)" json:"description_pattern"`
+	CounterpartyKey     sql.NullString `gorm:"type:varchar(200)" json:"counterparty_key"`
+	TransactionTypeCode sql.NullString `gorm:"type:varchar(20)" json:"transaction_type_code"`
+	IsActive            bool           `gorm:"default:true;not null" json:"is_active"`
+	CreatedAt           time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+	UpdatedAt           time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Category *RefTransactionCategory `gorm:"foreignKey:CategoryCode;references:CategoryCode" json:"category,omitempty"`
+}
+
+func (r *CategoryRule) BeforeCreate(tx *gorm.DB) error {
+	if r.RuleID == "" {
+		r.RuleID = uuid.New().String()
+	}
+	return nil
+}
+
+func (CategoryRule) TableName() string {
+	return "category_rules"
+}
+
+// CustomerCategoryOverride guarda a escolha do cliente para aplicá-la às
+// próximas transações com a mesma contraparte ou descrição
+type CustomerCategoryOverride struct {
+	OverrideID   string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"override_id"`
+	CustomerID   string    `gorm:"type:uuid;uniqueIndex:idx_category_overrides_match,priority:1;not null" json:"customer_id"`
+	MatchType    string    `gorm:"type:varchar(20);uniqueIndex:idx_category_overrides_match,priority:2;not null" json:"match_type"`
+	MatchValue   string    `gorm:"type:varchar(200);uniqueIndex:idx_category_overrides_match,priority:3;not null" json:"match_value"`
+	CategoryCode string    `gorm:"type:varchar(30);not null" json:"category_code"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;not null" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Customer *Customer               `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+	Category *RefTransactionCategory `gorm:"foreignKey:CategoryCode;references:CategoryCode" json:"category,omitempty"`
+}
+
+func (o *CustomerCategoryOverride) BeforeCreate(tx *gorm.DB) error {
+	if o.OverrideID == "" {
+		o.OverrideID = uuid.New().String()
+	}
+	return nil
+}
+
+func (CustomerCategoryOverride) TableName() string {
+	return "customer_category_overrides"
+}
+
+// TransactionCategory é o retrato da categorização de uma transação do ponto
+// de vista de um cliente. Relatórios leem daqui, então mudanças nas regras não
+// alteram o histórico.
+type TransactionCategory struct {
+	TransactionID    string         `gorm:"type:uuid;primaryKey" json:"transaction_id"`
+	CustomerID       string         `gorm:"type:uuid;primaryKey;index:idx_trans_categories_customer_date,priority:1" json:"customer_id"`
+	Direction        string         `gorm:"type:varchar(3);not null" json:"direction"`
+	CategoryCode     string         `gorm:"type:varchar(30);index:idx_trans_categories_category;not null" json:"category_code"`
+	CategorySource   string         `gorm:"type:varchar(20);not null" json:"category_source"`
+	RuleID           sql.NullString `gorm:"type:uuid" json:"rule_id"`
+	Amount           float64        `gorm:"type:decimal(15,2);not null" json:"amount"`
+	CounterpartyKey  sql.NullString `gorm:"type:varchar(200)" json:"counterparty_key"`
+	CounterpartyName sql.NullString `gorm:"type:varchar(200)" json:"counterparty_name"`
+	TransactionDate  time.Time      `gorm:"index:idx_trans_categories_customer_date,priority:2;not null" json:"transaction_date"`
+	CategorizedAt    time.Time      `gorm:"autoUpdateTime;not null" json:"categorized_at"`
+
+	// Relations
+	Transaction *Transaction            `gorm:"foreignKey:TransactionID;references:TransactionID;constraint:OnDelete:CASCADE" json:"transaction,omitempty"`
+	Customer    *Customer               `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+	Category    *RefTransactionCategory `gorm:"foreignKey:CategoryCode;references:CategoryCode" json:"category,omitempty"`
+}
+
+func (TransactionCategory) TableName() string {
+	return "transaction_categories"
+}
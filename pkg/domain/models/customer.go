@@ -2,9 +2,12 @@ package models
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/pkg/fieldcrypt"
+	"github.com/victor-lima-142/oak-bank/pkg/taxid"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
@@ -13,25 +16,56 @@ import (
 // CUSTOMERS
 // ===========================
 
+const (
+	CustomerTypeIndividual = "PF"
+	CustomerTypeBusiness   = "PJ"
+
+	KYCStatusPending       = "PENDING"
+	KYCStatusApproved      = "APPROVED"
+	KYCStatusRejected      = "REJECTED"
+	KYCStatusNeedsMoreInfo = "NEEDS_MORE_INFO"
+
+	RiskRatingLow    = "LOW"
+	RiskRatingMedium = "MEDIUM"
+	RiskRatingHigh   = "HIGH"
+)
+
 type Customer struct {
 	CustomerID     string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"customer_id"`
-	TaxID          string         `gorm:"type:varchar(11);uniqueIndex:idx_customers_taxId;not null" json:"taxId"`
+	CustomerType   string         `gorm:"type:varchar(2);default:'PF';not null" json:"customer_type"`
+	TaxID          string         `gorm:"type:text;serializer:encrypted;not null" json:"taxId"`
 	TaxIDHash      string         `gorm:"type:varchar(64);uniqueIndex:idx_customers_taxId_hash;not null" json:"taxId_hash"`
 	CustomerName   string         `gorm:"type:varchar(200);not null" json:"customer_name"`
-	CustomerPhone  sql.NullString `gorm:"type:varchar(20)" json:"customer_phone"`
-	CustomerEmail  sql.NullString `gorm:"type:varchar(100)" json:"customer_email"`
-	DateOfBirth    time.Time      `gorm:"not null" json:"date_of_birth"`
+	CustomerPhone  sql.NullString `gorm:"type:text;serializer:encrypted" json:"customer_phone"`
+	PhoneHash      sql.NullString `gorm:"type:varchar(64);index:idx_customers_phone_hash" json:"-"`
+	CustomerEmail  sql.NullString `gorm:"type:text;serializer:encrypted" json:"customer_email"`
+	EmailHash      sql.NullString `gorm:"type:varchar(64);index:idx_customers_email_hash" json:"-"`
+	DateOfBirth    sql.NullTime   `json:"date_of_birth"`
 	DateRegistered time.Time      `gorm:"autoCreateTime;not null" json:"date_registered"`
 	IsPep          bool           `gorm:"default:false;not null" json:"is_pep"`
 	KYCStatus      string         `gorm:"type:varchar(20);default:'PENDING';not null" json:"kyc_status"`
 	KYCVerifiedAt  sql.NullTime   `json:"kyc_verified_at"`
 	RiskRating     sql.NullString `gorm:"type:varchar(10)" json:"risk_rating"`
+	RiskScore      sql.NullInt32  `json:"risk_score"`
+	RiskAssessedAt sql.NullTime   `json:"risk_assessed_at"`
+	RiskReassessAt sql.NullTime   `gorm:"index:idx_customers_risk_reassess_at" json:"-"`
 	OtherDetails   datatypes.JSON `gorm:"type:jsonb" json:"other_details"`
+	Timezone       string         `gorm:"type:varchar(50);default:'America/Sao_Paulo';not null" json:"timezone"`
+	Locale         string         `gorm:"type:varchar(10);default:'pt-BR';not null" json:"locale"`
+	// AnonymizedAt indica que os dados pessoais foram removidos a pedido do
+	// titular; os registros financeiros continuam ligados ao cliente
+	AnonymizedAt sql.NullTime `json:"anonymized_at"`
 
 	// Relations
-	Users             []User            `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"users,omitempty"`
-	Account           *Account          `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"account,omitempty"`
-	CustomerAddresses []CustomerAddress `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"customer_addresses,omitempty"`
+	Users             []User                   `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"users,omitempty"`
+	Account           *Account                 `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"account,omitempty"`
+	CustomerAddresses []CustomerAddress        `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"customer_addresses,omitempty"`
+	Documents         []CustomerDocument       `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"documents,omitempty"`
+	RiskAssessments   []CustomerRiskAssessment `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"risk_assessments,omitempty"`
+
+	// Apenas PJ
+	Company              *CustomerCompany              `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"company,omitempty"`
+	LegalRepresentatives []CustomerLegalRepresentative `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"legal_representatives,omitempty"`
 }
 
 func (c *Customer) BeforeCreate(tx *gorm.DB) error {
@@ -41,16 +75,66 @@ func (c *Customer) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// BeforeSave recalcula os índices cegos dos campos cifrados preenchidos
+func (c *Customer) BeforeSave(tx *gorm.DB) error {
+	if c.TaxID != "" {
+		hash, err := CustomerTaxIDHash(c.TaxID)
+		if err != nil {
+			return err
+		}
+		c.TaxIDHash = hash
+	}
+	if c.CustomerPhone.Valid {
+		hash, err := CustomerPhoneHash(c.CustomerPhone.String)
+		if err != nil {
+			return err
+		}
+		c.PhoneHash = sql.NullString{String: hash, Valid: true}
+	}
+	if c.CustomerEmail.Valid {
+		hash, err := CustomerEmailHash(c.CustomerEmail.String)
+		if err != nil {
+			return err
+		}
+		c.EmailHash = sql.NullString{String: hash, Valid: true}
+	}
+	return nil
+}
+
 func (Customer) TableName() string {
 	return "customers"
 }
 
+// CustomerTaxIDHash é o índice cego para buscar clientes por CPF/CNPJ, com ou sem pontuação
+func CustomerTaxIDHash(taxID string) (string, error) {
+	return fieldcrypt.BlindIndex("customers.tax_id", taxid.NormalizeCNPJ(taxID))
+}
+
+// CustomerEmailHash é o índice cego para buscar clientes por email
+func CustomerEmailHash(email string) (string, error) {
+	return fieldcrypt.BlindIndex("customers.customer_email", strings.ToLower(strings.TrimSpace(email)))
+}
+
+// CustomerPhoneHash é o índice cego para buscar clientes por telefone, considerando apenas os dígitos
+func CustomerPhoneHash(phone string) (string, error) {
+	return fieldcrypt.BlindIndex("customers.customer_phone", taxid.OnlyDigits(phone))
+}
+
+// CustomerAddress liga o cliente a um endereço durante um período. Uma
+// mudança de endereço encerra a vigência (EffectiveTo) do vínculo anterior e
+// cria outro, preservando o histórico; os endereços não são alterados. Entre
+// os vínculos vigentes, no máximo um por tipo é o principal
+// (idx_customer_addresses_primary).
 type CustomerAddress struct {
-	CustomerID  string    `gorm:"type:uuid;primaryKey" json:"customer_id"`
-	AddressID   string    `gorm:"type:uuid;primaryKey" json:"address_id"`
-	AddressType string    `gorm:"type:varchar(20);not null" json:"address_type"`
-	CreatedAt   time.Time `gorm:"autoCreateTime;not null" json:"created_at"`
-	UpdatedAt   time.Time `gorm:"autoUpdateTime;not null" json:"updated_at"`
+	CustomerID      string         `gorm:"type:uuid;primaryKey" json:"customer_id"`
+	AddressID       string         `gorm:"type:uuid;primaryKey" json:"address_id"`
+	AddressType     string         `gorm:"type:varchar(20);not null" json:"address_type"`
+	IsPrimary       bool           `gorm:"default:false;not null" json:"is_primary"`
+	EffectiveFrom   time.Time      `gorm:"default:CURRENT_TIMESTAMP;not null" json:"effective_from"`
+	EffectiveTo     sql.NullTime   `json:"effective_to"`
+	ChangedByUserID sql.NullString `gorm:"type:uuid" json:"changed_by_user_id"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
 
 	// Relations
 	Customer       *Customer       `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
@@ -61,3 +145,78 @@ type CustomerAddress struct {
 func (CustomerAddress) TableName() string {
 	return "customer_addresses"
 }
+
+// ===========================
+// BUSINESS CUSTOMERS
+// ===========================
+
+const (
+	LegalRepresentativeAdministrator = "ADMINISTRATOR"
+	LegalRepresentativePartner       = "PARTNER"
+	LegalRepresentativeAttorney      = "ATTORNEY"
+)
+
+// CustomerCompany guarda os dados de constituição de clientes PJ. Para PJ,
+// Customer.CustomerName repete a razão social e DateOfBirth fica nulo.
+type CustomerCompany struct {
+	CustomerID        string         `gorm:"type:uuid;primaryKey" json:"customer_id"`
+	LegalName         string         `gorm:"type:varchar(200);not null" json:"legal_name"`
+	TradeName         sql.NullString `gorm:"type:varchar(200)" json:"trade_name"`
+	IncorporationDate time.Time      `gorm:"type:date;not null" json:"incorporation_date"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Customer *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+}
+
+func (CustomerCompany) TableName() string {
+	return "customer_companies"
+}
+
+// CustomerLegalRepresentative é uma pessoa física que responde pelo cliente
+// PJ. O CPF é único por cliente pelo índice cego; TaxIDHash só fica vazio em
+// linhas gravadas antes da cifragem, até a recifragem do rotate-keys.
+type CustomerLegalRepresentative struct {
+	RepresentativeID   string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"representative_id"`
+	CustomerID         string         `gorm:"type:uuid;uniqueIndex:idx_legal_representatives_customer_tax_id_hash,priority:1;not null" json:"customer_id"`
+	RepresentativeName string         `gorm:"type:varchar(200);not null" json:"representative_name"`
+	TaxID              string         `gorm:"type:text;serializer:encrypted;not null" json:"tax_id"`
+	TaxIDHash          string         `gorm:"type:varchar(64);uniqueIndex:idx_legal_representatives_customer_tax_id_hash,priority:2" json:"-"`
+	DateOfBirth        time.Time      `gorm:"type:date;not null" json:"date_of_birth"`
+	Role               string         `gorm:"type:varchar(20);not null" json:"role"`
+	IsPep              bool           `gorm:"default:false;not null" json:"is_pep"`
+	Email              sql.NullString `gorm:"type:text;serializer:encrypted" json:"email"`
+	CreatedAt          time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+
+	// Relations
+	Customer *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+}
+
+func (lr *CustomerLegalRepresentative) BeforeCreate(tx *gorm.DB) error {
+	if lr.RepresentativeID == "" {
+		lr.RepresentativeID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeSave recalcula o índice cego do CPF
+func (lr *CustomerLegalRepresentative) BeforeSave(tx *gorm.DB) error {
+	if lr.TaxID != "" {
+		hash, err := LegalRepresentativeTaxIDHash(lr.TaxID)
+		if err != nil {
+			return err
+		}
+		lr.TaxIDHash = hash
+	}
+	return nil
+}
+
+func (CustomerLegalRepresentative) TableName() string {
+	return "customer_legal_representatives"
+}
+
+// LegalRepresentativeTaxIDHash é o índice cego do CPF do representante, com ou sem pontuação
+func LegalRepresentativeTaxIDHash(taxID string) (string, error) {
+	return fieldcrypt.BlindIndex("customer_legal_representatives.tax_id", taxid.OnlyDigits(taxID))
+}
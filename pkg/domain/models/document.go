@@ -0,0 +1,59 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// CUSTOMER DOCUMENTS
+// ===========================
+
+const (
+	DocumentTypeRG             = "RG"
+	DocumentTypeCNH            = "CNH"
+	DocumentTypePassport       = "PASSPORT"
+	DocumentTypeRNE            = "RNE"
+	DocumentTypeProofOfAddress = "PROOF_OF_ADDRESS"
+	DocumentTypeProofOfIncome  = "PROOF_OF_INCOME"
+	DocumentTypeSelfie         = "SELFIE"
+)
+
+// CustomerDocument é o metadado de um arquivo guardado no object store. O
+// conteúdo é cifrado com a chave EncryptionKeyID; SHA256 é calculado sobre o
+// arquivo original e conferido a cada leitura. Após RetainUntil o objeto é
+// removido e PurgedAt preenchido, mantendo o registro.
+type CustomerDocument struct {
+	DocumentID       string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"document_id"`
+	CustomerID       string         `gorm:"type:uuid;index:idx_customer_documents_customer_id;not null" json:"customer_id"`
+	DocumentType     string         `gorm:"type:varchar(30);not null" json:"document_type"`
+	FileName         string         `gorm:"type:varchar(255);not null" json:"file_name"`
+	ContentType      string         `gorm:"type:varchar(100);not null" json:"content_type"`
+	SizeBytes        int64          `gorm:"not null" json:"size_bytes"`
+	SHA256           string         `gorm:"column:sha256;type:varchar(64);not null" json:"sha256"`
+	StorageBackend   string         `gorm:"type:varchar(20);not null" json:"storage_backend"`
+	StorageKey       string         `gorm:"type:varchar(500);not null" json:"-"`
+	EncryptionKeyID  string         `gorm:"type:varchar(32);not null" json:"encryption_key_id"`
+	UploadedByUserID sql.NullString `gorm:"type:uuid" json:"uploaded_by_user_id"`
+	RetainUntil      time.Time      `gorm:"index:idx_customer_documents_retain_until;not null" json:"retain_until"`
+	PurgedAt         sql.NullTime   `json:"purged_at"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+
+	// Relations
+	Customer   *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+	UploadedBy *User     `gorm:"foreignKey:UploadedByUserID;references:UserID;constraint:OnDelete:SET NULL" json:"uploaded_by,omitempty"`
+}
+
+func (cd *CustomerDocument) BeforeCreate(tx *gorm.DB) error {
+	if cd.DocumentID == "" {
+		cd.DocumentID = uuid.New().String()
+	}
+	return nil
+}
+
+func (CustomerDocument) TableName() string {
+	return "customer_documents"
+}
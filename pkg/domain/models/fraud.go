@@ -0,0 +1,64 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// FRAUD
+// ===========================
+
+const (
+	FraudActionAllow  = "ALLOW"
+	FraudActionStepUp = "STEP_UP"
+	FraudActionHold   = "HOLD"
+	FraudActionBlock  = "BLOCK"
+
+	FraudReviewPending  = "PENDING"
+	FraudReviewApproved = "APPROVED"
+	FraudReviewRejected = "REJECTED"
+)
+
+// FraudDecision registra cada avaliação de risco de uma transação com as
+// regras disparadas. Decisões de bloqueio e step-up são gravadas fora da
+// transação recusada, que é desfeita; nesses casos TransactionID fica nulo e
+// os dados da tentativa ficam copiados na decisão.
+type FraudDecision struct {
+	DecisionID        string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"decision_id"`
+	TransactionID     sql.NullString `gorm:"type:uuid;index:idx_fraud_decisions_transaction_id" json:"transaction_id"`
+	IdempotencyKey    string         `gorm:"type:varchar(100);not null" json:"idempotency_key"`
+	CustomerID        string         `gorm:"type:uuid;index:idx_fraud_decisions_customer_date,priority:1;not null" json:"customer_id"`
+	UserID            sql.NullString `gorm:"type:uuid" json:"user_id"`
+	AccountIDOrigin   string         `gorm:"type:uuid;not null" json:"account_id_origin"`
+	AccountIDDest     sql.NullString `gorm:"type:uuid" json:"account_id_dest"`
+	Amount            float64        `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Score             int            `gorm:"not null" json:"score"`
+	Action            string         `gorm:"type:varchar(10);index:idx_fraud_decisions_action_review,priority:1;not null" json:"action"`
+	RulesFired        datatypes.JSON `gorm:"type:jsonb;not null" json:"rules_fired"`
+	DeviceFingerprint sql.NullString `gorm:"type:varchar(255)" json:"device_fingerprint"`
+	StepUpVerified    bool           `gorm:"default:false;not null" json:"step_up_verified"`
+	ReviewStatus      sql.NullString `gorm:"type:varchar(20);index:idx_fraud_decisions_action_review,priority:2" json:"review_status"`
+	ReviewedByUserID  sql.NullString `gorm:"type:uuid" json:"reviewed_by_user_id"`
+	ReviewedAt        sql.NullTime   `json:"reviewed_at"`
+	ReviewNote        sql.NullString `gorm:"type:varchar(500)" json:"review_note"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime;index:idx_fraud_decisions_customer_date,priority:2;not null" json:"created_at"`
+
+	// Relations
+	Customer *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+}
+
+func (fd *FraudDecision) BeforeCreate(tx *gorm.DB) error {
+	if fd.DecisionID == "" {
+		fd.DecisionID = uuid.New().String()
+	}
+	return nil
+}
+
+func (FraudDecision) TableName() string {
+	return "fraud_decisions"
+}
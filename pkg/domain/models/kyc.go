@@ -0,0 +1,87 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// KYC
+// ===========================
+
+const (
+	KYCCheckTaxID     = "TAX_ID"
+	KYCCheckAge       = "AGE"
+	KYCCheckSanctions = "SANCTIONS"
+
+	// Apenas PJ
+	KYCCheckCompany         = "COMPANY"
+	KYCCheckRepresentatives = "LEGAL_REPRESENTATIVES"
+)
+
+// KYCSubmission é um envio de dados e documentos do cliente para verificação.
+// O status acompanha Customer.KYCStatus enquanto o envio é o mais recente.
+type KYCSubmission struct {
+	SubmissionID      string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"submission_id"`
+	CustomerID        string         `gorm:"type:uuid;index:idx_kyc_submissions_customer_id;not null" json:"customer_id"`
+	SubmittedByUserID sql.NullString `gorm:"type:uuid" json:"submitted_by_user_id"`
+	SubmissionStatus  string         `gorm:"type:varchar(20);default:'PENDING';index:idx_kyc_submissions_status_date,priority:1;not null" json:"submission_status"`
+	SubmittedData     datatypes.JSON `gorm:"type:jsonb" json:"submitted_data"`
+	Documents         datatypes.JSON `gorm:"type:jsonb" json:"documents"`
+	CheckResults      datatypes.JSON `gorm:"type:jsonb" json:"check_results"`
+	ChecksPassed      bool           `gorm:"default:false;not null" json:"checks_passed"`
+	ChecksRunAt       sql.NullTime   `json:"checks_run_at"`
+	AssignedToUserID  sql.NullString `gorm:"type:uuid;index:idx_kyc_submissions_assigned_to" json:"assigned_to_user_id"`
+	DecidedByUserID   sql.NullString `gorm:"type:uuid" json:"decided_by_user_id"`
+	DecidedAt         sql.NullTime   `json:"decided_at"`
+	DecisionReason    sql.NullString `gorm:"type:varchar(1000)" json:"decision_reason"`
+	SubmittedAt       time.Time      `gorm:"autoCreateTime;index:idx_kyc_submissions_status_date,priority:2;not null" json:"submitted_at"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Customer   *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+	AssignedTo *User     `gorm:"foreignKey:AssignedToUserID;references:UserID;constraint:OnDelete:SET NULL" json:"assigned_to,omitempty"`
+}
+
+func (ks *KYCSubmission) BeforeCreate(tx *gorm.DB) error {
+	if ks.SubmissionID == "" {
+		ks.SubmissionID = uuid.New().String()
+	}
+	return nil
+}
+
+func (KYCSubmission) TableName() string {
+	return "kyc_submissions"
+}
+
+// KYCStatusHistory registra cada transição de Customer.KYCStatus
+type KYCStatusHistory struct {
+	HistoryID       string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"history_id"`
+	CustomerID      string         `gorm:"type:uuid;index:idx_kyc_history_customer_date,priority:1;not null" json:"customer_id"`
+	SubmissionID    sql.NullString `gorm:"type:uuid" json:"submission_id"`
+	PreviousStatus  string         `gorm:"type:varchar(20);not null" json:"previous_status"`
+	NewStatus       string         `gorm:"type:varchar(20);not null" json:"new_status"`
+	Reason          sql.NullString `gorm:"type:varchar(1000)" json:"reason"`
+	ChangedByUserID sql.NullString `gorm:"type:uuid" json:"changed_by_user_id"`
+	ChangedAt       time.Time      `gorm:"autoCreateTime;index:idx_kyc_history_customer_date,priority:2;not null" json:"changed_at"`
+
+	// Relations
+	Customer   *Customer      `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+	Submission *KYCSubmission `gorm:"foreignKey:SubmissionID;references:SubmissionID;constraint:OnDelete:SET NULL" json:"submission,omitempty"`
+	ChangedBy  *User          `gorm:"foreignKey:ChangedByUserID;references:UserID;constraint:OnDelete:SET NULL" json:"changed_by,omitempty"`
+}
+
+func (kh *KYCStatusHistory) BeforeCreate(tx *gorm.DB) error {
+	if kh.HistoryID == "" {
+		kh.HistoryID = uuid.New().String()
+	}
+	return nil
+}
+
+func (KYCStatusHistory) TableName() string {
+	return "kyc_status_history"
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// NOTIFICATIONS
+// ===========================
+
+const (
+	NotificationStatusPending = "PENDING"
+	NotificationStatusSending = "SENDING"
+	NotificationStatusSent    = "SENT"
+	NotificationStatusDead    = "DEAD"
+
+	// NotificationEventDefault é a preferência aplicada a eventos sem preferência própria
+	NotificationEventDefault = "*"
+)
+
+type Notification struct {
+	NotificationID     string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"notification_id"`
+	CustomerID         sql.NullString `gorm:"type:uuid;index:idx_notifications_customer_id" json:"customer_id"`
+	UserID             sql.NullString `gorm:"type:uuid" json:"user_id"`
+	Channel            string         `gorm:"type:varchar(10);not null" json:"channel"`
+	Recipient          string         `gorm:"type:varchar(255);not null" json:"recipient"`
+	TemplateKey        string         `gorm:"type:varchar(50);not null" json:"template_key"`
+	Locale             string         `gorm:"type:varchar(10);not null" json:"locale"`
+	Subject            string         `gorm:"type:varchar(255);not null" json:"subject"`
+	Body               string         `gorm:"type:text;not null" json:"body"`
+	NotificationStatus string         `gorm:"type:varchar(20);default:'PENDING';index:idx_notifications_status_next,priority:1;not null" json:"notification_status"`
+	Attempts           int            `gorm:"default:0;not null" json:"attempts"`
+	MaxAttempts        int            `gorm:"default:5;not null" json:"max_attempts"`
+	NextAttemptAt      time.Time      `gorm:"index:idx_notifications_status_next,priority:2;not null" json:"next_attempt_at"`
+	LastError          sql.NullString `gorm:"type:varchar(500)" json:"last_error"`
+	ProviderMessageID  sql.NullString `gorm:"type:varchar(255)" json:"provider_message_id"`
+	SentAt             sql.NullTime   `json:"sent_at"`
+	CreatedAt          time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+	UpdatedAt          time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Customer *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+	User     *User     `gorm:"foreignKey:UserID;references:UserID;constraint:OnDelete:SET NULL" json:"user,omitempty"`
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.NotificationID == "" {
+		n.NotificationID = uuid.New().String()
+	}
+	return nil
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationPreference define os canais habilitados por tipo de evento.
+// A linha com EventType "*" vale para os eventos sem preferência própria.
+type NotificationPreference struct {
+	CustomerID   string    `gorm:"type:uuid;primaryKey" json:"customer_id"`
+	EventType    string    `gorm:"type:varchar(50);primaryKey" json:"event_type"`
+	EmailEnabled bool      `gorm:"default:true;not null" json:"email_enabled"`
+	SMSEnabled   bool      `gorm:"default:false;not null" json:"sms_enabled"`
+	PushEnabled  bool      `gorm:"default:true;not null" json:"push_enabled"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Customer *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+type PushDevice struct {
+	DeviceID   string    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"device_id"`
+	UserID     string    `gorm:"type:uuid;index:idx_push_devices_user_id;not null" json:"user_id"`
+	Token      string    `gorm:"type:varchar(500);uniqueIndex;not null" json:"token"`
+	Platform   string    `gorm:"type:varchar(20);not null" json:"platform"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;not null" json:"created_at"`
+	LastSeenAt time.Time `gorm:"not null" json:"last_seen_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;references:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+func (d *PushDevice) BeforeCreate(tx *gorm.DB) error {
+	if d.DeviceID == "" {
+		d.DeviceID = uuid.New().String()
+	}
+	return nil
+}
+
+func (PushDevice) TableName() string {
+	return "push_devices"
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/pkg/fieldcrypt"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// ONBOARDING
+// ===========================
+
+const (
+	OnboardingStatusInProgress = "IN_PROGRESS"
+	OnboardingStatusCompleted  = "COMPLETED"
+)
+
+// OnboardingSession guarda um cadastro em andamento para ser continuado
+// depois, inclusive em outro dispositivo. Draft tem as seções salvas em JSON,
+// cifrado por conter dados pessoais, com apenas o hash da senha gerado pelo
+// PasswordService. O acesso é feito pelo token de retomada, guardado apenas
+// como hash; EmailHash permite reenviar o token ao email do cadastro.
+type OnboardingSession struct {
+	SessionID       string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"session_id"`
+	ResumeTokenHash string         `gorm:"type:varchar(64);uniqueIndex:idx_onboarding_sessions_token;not null" json:"-"`
+	EmailHash       sql.NullString `gorm:"type:varchar(64);index:idx_onboarding_sessions_email_hash" json:"-"`
+	Draft           string         `gorm:"type:text;serializer:encrypted;not null" json:"-"`
+	SessionStatus   string         `gorm:"type:varchar(20);default:'IN_PROGRESS';not null" json:"session_status"`
+	UserID          sql.NullString `gorm:"type:uuid" json:"user_id"`
+	CustomerID      sql.NullString `gorm:"type:uuid" json:"customer_id"`
+	AccountID       sql.NullString `gorm:"type:uuid" json:"account_id"`
+	ExpiresAt       time.Time      `gorm:"index:idx_onboarding_sessions_expires_at;not null" json:"expires_at"`
+	CompletedAt     sql.NullTime   `json:"completed_at"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Customer *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:SET NULL" json:"customer,omitempty"`
+}
+
+func (s *OnboardingSession) BeforeCreate(tx *gorm.DB) error {
+	if s.SessionID == "" {
+		s.SessionID = uuid.New().String()
+	}
+	return nil
+}
+
+func (OnboardingSession) TableName() string {
+	return "onboarding_sessions"
+}
+
+// OnboardingEmailHash é o índice cego do email informado na seção de usuário
+func OnboardingEmailHash(email string) (string, error) {
+	return fieldcrypt.BlindIndex("onboarding_sessions.email", strings.ToLower(strings.TrimSpace(email)))
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/pkg/fieldcrypt"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// ONE-TIME CODES
+// ===========================
+
+const (
+	OneTimeCodePurposeProfileChange  = "PROFILE_CHANGE"
+	OneTimeCodePurposeLogin          = "LOGIN"
+	OneTimeCodePurposeStepUp         = "STEP_UP"
+	OneTimeCodePurposeTwoFactorSetup = "TWO_FACTOR_SETUP"
+)
+
+// OneTimeCode é um código numérico de uso único enviado por email ou SMS.
+// Apenas o índice cego do código é guardado. Reference liga o código ao
+// registro que ele confirma; um código novo para a mesma referência invalida
+// os anteriores.
+type OneTimeCode struct {
+	CodeID      string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"code_id"`
+	UserID      sql.NullString `gorm:"type:uuid;index:idx_one_time_codes_user_id" json:"user_id"`
+	Purpose     string         `gorm:"type:varchar(30);index:idx_one_time_codes_reference,priority:1;not null" json:"purpose"`
+	Reference   sql.NullString `gorm:"type:varchar(100);index:idx_one_time_codes_reference,priority:2" json:"reference"`
+	Channel     string         `gorm:"type:varchar(10);not null" json:"channel"`
+	Destination sql.NullString `gorm:"type:text;serializer:encrypted" json:"-"`
+	CodeHash    string         `gorm:"type:varchar(64);not null" json:"-"`
+	Attempts    int            `gorm:"default:0;not null" json:"attempts"`
+	MaxAttempts int            `gorm:"not null" json:"max_attempts"`
+	ExpiresAt   time.Time      `gorm:"index:idx_one_time_codes_expires_at;not null" json:"expires_at"`
+	ConsumedAt  sql.NullTime   `json:"consumed_at"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;references:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+func (c *OneTimeCode) BeforeCreate(tx *gorm.DB) error {
+	if c.CodeID == "" {
+		c.CodeID = uuid.New().String()
+	}
+	return nil
+}
+
+func (OneTimeCode) TableName() string {
+	return "one_time_codes"
+}
+
+// OneTimeCodeHash é o índice cego do código, amarrado ao registro para que o
+// mesmo código em registros diferentes não tenha o mesmo hash
+func OneTimeCodeHash(codeID, code string) (string, error) {
+	return fieldcrypt.BlindIndex("one_time_codes.code", codeID+":"+code)
+}
+
+// StepUpToken registra o token emitido por uma verificação adicional. O
+// TokenID é o jti do JWT; o token vale para uma única requisição e é
+// consumido ao ser apresentado.
+type StepUpToken struct {
+	TokenID    string       `gorm:"type:uuid;primaryKey" json:"token_id"`
+	UserID     string       `gorm:"type:uuid;index:idx_step_up_tokens_user_id;not null" json:"user_id"`
+	ExpiresAt  time.Time    `gorm:"index:idx_step_up_tokens_expires_at;not null" json:"expires_at"`
+	ConsumedAt sql.NullTime `json:"consumed_at"`
+	CreatedAt  time.Time    `gorm:"autoCreateTime;not null" json:"created_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;references:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+func (t *StepUpToken) BeforeCreate(tx *gorm.DB) error {
+	if t.TokenID == "" {
+		t.TokenID = uuid.New().String()
+	}
+	return nil
+}
+
+func (StepUpToken) TableName() string {
+	return "step_up_tokens"
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// OUTBOX
+// ===========================
+
+const (
+	OutboxStatusPending   = "PENDING"
+	OutboxStatusPublished = "PUBLISHED"
+
+	OutboxAggregateTransaction = "Transaction"
+	OutboxAggregateAccount     = "Account"
+	OutboxAggregateCustomer    = "Customer"
+	OutboxAggregateUser        = "User"
+)
+
+// OutboxEvent é um evento de domínio gravado na mesma transação de banco que
+// a mudança que o originou. Sequence define a ordem de publicação dentro do agregado.
+type OutboxEvent struct {
+	EventID       string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"event_id"`
+	Sequence      int64          `gorm:"type:bigserial;autoIncrement;uniqueIndex:idx_outbox_sequence;not null" json:"sequence"`
+	AggregateType string         `gorm:"type:varchar(50);index:idx_outbox_aggregate,priority:1;not null" json:"aggregate_type"`
+	AggregateID   string         `gorm:"type:varchar(100);index:idx_outbox_aggregate,priority:2;not null" json:"aggregate_id"`
+	EventType     string         `gorm:"type:varchar(100);not null" json:"event_type"`
+	Payload       datatypes.JSON `gorm:"type:jsonb;not null" json:"payload"`
+	OutboxStatus  string         `gorm:"type:varchar(20);default:'PENDING';index:idx_outbox_status_next,priority:1;not null" json:"outbox_status"`
+	Attempts      int            `gorm:"default:0;not null" json:"attempts"`
+	NextAttemptAt time.Time      `gorm:"index:idx_outbox_status_next,priority:2;not null" json:"next_attempt_at"`
+	LastError     sql.NullString `gorm:"type:varchar(500)" json:"last_error"`
+	OccurredAt    time.Time      `gorm:"autoCreateTime;not null" json:"occurred_at"`
+	PublishedAt   sql.NullTime   `json:"published_at"`
+}
+
+func (e *OutboxEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.EventID == "" {
+		e.EventID = uuid.New().String()
+	}
+	return nil
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// ProcessedEvent registra os eventos já tratados por um consumidor, para
+// descartar as reentregas da semântica at-least-once
+type ProcessedEvent struct {
+	Consumer    string    `gorm:"type:varchar(100);primaryKey" json:"consumer"`
+	EventID     string    `gorm:"type:uuid;primaryKey" json:"event_id"`
+	ProcessedAt time.Time `gorm:"autoCreateTime;index:idx_processed_events_processed_at;not null" json:"processed_at"`
+}
+
+func (ProcessedEvent) TableName() string {
+	return "processed_events"
+}
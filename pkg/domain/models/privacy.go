@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// DATA SUBJECT REQUESTS (LGPD)
+// ===========================
+
+const (
+	DataSubjectRequestExport        = "EXPORT"
+	DataSubjectRequestAnonymization = "ANONYMIZATION"
+
+	DataSubjectRequestPending   = "PENDING"
+	DataSubjectRequestScheduled = "SCHEDULED"
+	DataSubjectRequestCompleted = "COMPLETED"
+	DataSubjectRequestRejected  = "REJECTED"
+	DataSubjectRequestFailed    = "FAILED"
+)
+
+// DataSubjectRequest é um pedido do titular com base na LGPD: a exportação
+// dos dados ou a anonimização do cadastro. DueAt é o prazo legal de resposta.
+// A anonimização de quem ainda está no prazo de guarda dos registros fica
+// SCHEDULED até ScheduledFor. O arquivo da exportação é cifrado no object
+// store e removido após ArtifactExpiresAt, mantendo o registro do pedido.
+type DataSubjectRequest struct {
+	RequestID         string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"request_id"`
+	CustomerID        string         `gorm:"type:uuid;index:idx_data_subject_requests_customer_id;not null" json:"customer_id"`
+	RequestType       string         `gorm:"type:varchar(20);not null" json:"request_type"`
+	RequestStatus     string         `gorm:"type:varchar(20);default:'PENDING';index:idx_data_subject_requests_status_due,priority:1;not null" json:"request_status"`
+	RequestedByUserID sql.NullString `gorm:"type:uuid" json:"requested_by_user_id"`
+	Reason            sql.NullString `gorm:"type:varchar(500)" json:"reason"`
+	DueAt             time.Time      `gorm:"index:idx_data_subject_requests_status_due,priority:2;not null" json:"due_at"`
+	ScheduledFor      sql.NullTime   `json:"scheduled_for"`
+	Attempts          int            `gorm:"default:0;not null" json:"attempts"`
+	LastError         sql.NullString `gorm:"type:varchar(500)" json:"last_error"`
+	ArtifactKey       sql.NullString `gorm:"type:varchar(500)" json:"-"`
+	ArtifactKeyID     sql.NullString `gorm:"type:varchar(32)" json:"-"`
+	ArtifactSHA256    sql.NullString `gorm:"column:artifact_sha256;type:varchar(64)" json:"artifact_sha256"`
+	ArtifactSizeBytes sql.NullInt64  `json:"artifact_size_bytes"`
+	ArtifactExpiresAt sql.NullTime   `json:"artifact_expires_at"`
+	HandledByUserID   sql.NullString `gorm:"type:uuid" json:"handled_by_user_id"`
+	ResolutionNote    sql.NullString `gorm:"type:varchar(1000)" json:"resolution_note"`
+	CompletedAt       sql.NullTime   `json:"completed_at"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Customer *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+}
+
+func (r *DataSubjectRequest) BeforeCreate(tx *gorm.DB) error {
+	if r.RequestID == "" {
+		r.RequestID = uuid.New().String()
+	}
+	return nil
+}
+
+func (DataSubjectRequest) TableName() string {
+	return "data_subject_requests"
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// CUSTOMER PROFILE CHANGES
+// ===========================
+
+// Campos do cadastro alterados pelo fluxo de pedidos. Os valores são os nomes
+// das colunas em customers.
+const (
+	ProfileFieldName  = "customer_name"
+	ProfileFieldEmail = "customer_email"
+	ProfileFieldPhone = "customer_phone"
+
+	ProfileChangePendingVerification = "PENDING_VERIFICATION"
+	ProfileChangePendingReview       = "PENDING_REVIEW"
+	ProfileChangeApplied             = "APPLIED"
+	ProfileChangeRejected            = "REJECTED"
+	ProfileChangeCancelled           = "CANCELLED"
+	ProfileChangeExpired             = "EXPIRED"
+)
+
+// ProfileChangeRequest é um pedido de alteração de um campo do cadastro. Email
+// e telefone são confirmados por código enviado ao novo contato
+// (PENDING_VERIFICATION); o nome exige documentos e a aprovação do compliance
+// (PENDING_REVIEW). Os valores são cifrados por serem dados pessoais.
+type ProfileChangeRequest struct {
+	RequestID         string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"request_id"`
+	CustomerID        string         `gorm:"type:uuid;index:idx_profile_change_requests_customer_id;not null" json:"customer_id"`
+	RequestedByUserID sql.NullString `gorm:"type:uuid" json:"requested_by_user_id"`
+	FieldName         string         `gorm:"type:varchar(30);not null" json:"field_name"`
+	OldValue          sql.NullString `gorm:"type:text;serializer:encrypted" json:"old_value"`
+	NewValue          string         `gorm:"type:text;serializer:encrypted;not null" json:"new_value"`
+	Reason            sql.NullString `gorm:"type:varchar(500)" json:"reason"`
+	Documents         datatypes.JSON `gorm:"type:jsonb" json:"documents"`
+	RequestStatus     string         `gorm:"type:varchar(25);index:idx_profile_change_requests_status_date,priority:1;not null" json:"request_status"`
+	ExpiresAt         sql.NullTime   `json:"expires_at"`
+	ReviewedByUserID  sql.NullString `gorm:"type:uuid" json:"reviewed_by_user_id"`
+	ReviewedAt        sql.NullTime   `json:"reviewed_at"`
+	ReviewNote        sql.NullString `gorm:"type:varchar(1000)" json:"review_note"`
+	AppliedAt         sql.NullTime   `json:"applied_at"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime;index:idx_profile_change_requests_status_date,priority:2;not null" json:"created_at"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Customer *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+}
+
+func (r *ProfileChangeRequest) BeforeCreate(tx *gorm.DB) error {
+	if r.RequestID == "" {
+		r.RequestID = uuid.New().String()
+	}
+	return nil
+}
+
+func (ProfileChangeRequest) TableName() string {
+	return "profile_change_requests"
+}
+
+// CustomerProfileVersion é uma versão de um campo do cadastro, vigente de
+// ValidFrom até ValidTo. A versão 1 guarda o valor do cadastro original e é
+// criada na primeira alteração do campo.
+type CustomerProfileVersion struct {
+	VersionID       string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"version_id"`
+	CustomerID      string         `gorm:"type:uuid;uniqueIndex:idx_customer_profile_versions_field,priority:1;not null" json:"customer_id"`
+	FieldName       string         `gorm:"type:varchar(30);uniqueIndex:idx_customer_profile_versions_field,priority:2;not null" json:"field_name"`
+	Version         int            `gorm:"uniqueIndex:idx_customer_profile_versions_field,priority:3;not null" json:"version"`
+	Value           sql.NullString `gorm:"type:text;serializer:encrypted" json:"value"`
+	ValidFrom       time.Time      `gorm:"not null" json:"valid_from"`
+	ValidTo         sql.NullTime   `json:"valid_to"`
+	ChangeRequestID sql.NullString `gorm:"type:uuid" json:"change_request_id"`
+	ChangedByUserID sql.NullString `gorm:"type:uuid" json:"changed_by_user_id"`
+
+	// Relations
+	Customer      *Customer             `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+	ChangeRequest *ProfileChangeRequest `gorm:"foreignKey:ChangeRequestID;references:RequestID;constraint:OnDelete:SET NULL" json:"change_request,omitempty"`
+}
+
+func (v *CustomerProfileVersion) BeforeCreate(tx *gorm.DB) error {
+	if v.VersionID == "" {
+		v.VersionID = uuid.New().String()
+	}
+	return nil
+}
+
+func (CustomerProfileVersion) TableName() string {
+	return "customer_profile_versions"
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// CUSTOMER RISK RATING
+// ===========================
+
+const (
+	RiskTriggerEvent     = "EVENT"
+	RiskTriggerScheduled = "SCHEDULED"
+	RiskTriggerManual    = "MANUAL"
+	RiskTriggerOverride  = "OVERRIDE"
+
+	RiskFactorPep              = "PEP"
+	RiskFactorScreening        = "SCREENING"
+	RiskFactorOccupation       = "OCCUPATION"
+	RiskFactorIncome           = "INCOME"
+	RiskFactorGeography        = "GEOGRAPHY"
+	RiskFactorProduct          = "PRODUCT"
+	RiskFactorTransactions     = "TRANSACTIONS"
+	RiskFactorAml              = "AML"
+	RiskFactorCustomerType     = "CUSTOMER_TYPE"
+	RiskFactorRelationshipTime = "RELATIONSHIP_TIME"
+)
+
+// CustomerRiskAssessment registra cada cálculo da classificação de risco com
+// os fatores que a compõem. RiskRating é a classificação em vigor após o
+// cálculo: igual a ComputedRating, exceto quando há uma revisão manual ativa.
+type CustomerRiskAssessment struct {
+	AssessmentID     string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"assessment_id"`
+	CustomerID       string         `gorm:"type:uuid;index:idx_risk_assessments_customer_date,priority:1;not null" json:"customer_id"`
+	RiskScore        int            `gorm:"not null" json:"risk_score"`
+	ComputedRating   string         `gorm:"type:varchar(10);not null" json:"computed_rating"`
+	RiskRating       string         `gorm:"type:varchar(10);not null" json:"risk_rating"`
+	Factors          datatypes.JSON `gorm:"type:jsonb;not null" json:"factors"`
+	TriggerType      string         `gorm:"type:varchar(20);not null" json:"trigger_type"`
+	OverrideID       sql.NullString `gorm:"type:uuid" json:"override_id"`
+	AssessedByUserID sql.NullString `gorm:"type:uuid" json:"assessed_by_user_id"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime;index:idx_risk_assessments_customer_date,priority:2;not null" json:"created_at"`
+
+	// Relations
+	Customer *Customer             `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+	Override *CustomerRiskOverride `gorm:"foreignKey:OverrideID;references:OverrideID;constraint:OnDelete:SET NULL" json:"override,omitempty"`
+}
+
+func (ra *CustomerRiskAssessment) BeforeCreate(tx *gorm.DB) error {
+	if ra.AssessmentID == "" {
+		ra.AssessmentID = uuid.New().String()
+	}
+	return nil
+}
+
+func (CustomerRiskAssessment) TableName() string {
+	return "customer_risk_assessments"
+}
+
+// CustomerRiskOverride é a classificação definida pelo compliance, que
+// prevalece sobre o cálculo automático até expirar ou ser revogada. Há no
+// máximo uma revisão não revogada por cliente (idx_risk_overrides_active_customer).
+type CustomerRiskOverride struct {
+	OverrideID       string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"override_id"`
+	CustomerID       string         `gorm:"type:uuid;index:idx_risk_overrides_customer_id;not null" json:"customer_id"`
+	RiskRating       string         `gorm:"type:varchar(10);not null" json:"risk_rating"`
+	Justification    string         `gorm:"type:varchar(2000);not null" json:"justification"`
+	CreatedByUserID  string         `gorm:"type:uuid;not null" json:"created_by_user_id"`
+	ExpiresAt        sql.NullTime   `json:"expires_at"`
+	RevokedAt        sql.NullTime   `json:"revoked_at"`
+	RevokedByUserID  sql.NullString `gorm:"type:uuid" json:"revoked_by_user_id"`
+	RevocationReason sql.NullString `gorm:"type:varchar(1000)" json:"revocation_reason"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+
+	// Relations
+	Customer  *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+	CreatedBy *User     `gorm:"foreignKey:CreatedByUserID;references:UserID;constraint:OnDelete:RESTRICT" json:"created_by,omitempty"`
+}
+
+func (ro *CustomerRiskOverride) BeforeCreate(tx *gorm.DB) error {
+	if ro.OverrideID == "" {
+		ro.OverrideID = uuid.New().String()
+	}
+	return nil
+}
+
+// Active informa se a revisão ainda prevalece sobre o cálculo em now
+func (ro *CustomerRiskOverride) Active(now time.Time) bool {
+	return !ro.RevokedAt.Valid && (!ro.ExpiresAt.Valid || ro.ExpiresAt.Time.After(now))
+}
+
+func (CustomerRiskOverride) TableName() string {
+	return "customer_risk_overrides"
+}
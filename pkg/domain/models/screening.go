@@ -0,0 +1,145 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// SANCTIONS SCREENING
+// ===========================
+
+const (
+	SanctionsListTypeSanctions = "SANCTIONS"
+	SanctionsListTypePep       = "PEP"
+
+	ScreeningContextOnboarding    = "ONBOARDING"
+	ScreeningContextTransfer      = "TRANSFER"
+	ScreeningContextRescreen      = "RESCREEN"
+	ScreeningContextManual        = "MANUAL"
+	ScreeningContextProfileChange = "PROFILE_CHANGE"
+
+	ScreeningReviewPending   = "PENDING"
+	ScreeningReviewCleared   = "CLEARED"
+	ScreeningReviewConfirmed = "CONFIRMED"
+)
+
+// SanctionsList é uma lista restritiva importada. ContentHash identifica a
+// versão do arquivo; uma importação com conteúdo novo dispara a retriagem de
+// todos os clientes (RescreenedAt anterior a ImportedAt).
+type SanctionsList struct {
+	ListID             string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"list_id"`
+	ListCode           string         `gorm:"type:varchar(50);uniqueIndex:idx_sanctions_lists_code;not null" json:"list_code"`
+	ListName           string         `gorm:"type:varchar(200);not null" json:"list_name"`
+	ListType           string         `gorm:"type:varchar(20);not null" json:"list_type"`
+	ContentHash        string         `gorm:"type:varchar(64);not null" json:"content_hash"`
+	EntryCount         int            `gorm:"not null" json:"entry_count"`
+	ImportedAt         time.Time      `gorm:"not null" json:"imported_at"`
+	ImportedByUserID   sql.NullString `gorm:"type:uuid" json:"imported_by_user_id"`
+	RescreenedAt       sql.NullTime   `json:"rescreened_at"`
+	RescreenLeaseUntil sql.NullTime   `json:"rescreen_lease_until"`
+	CreatedAt          time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+
+	// Relations
+	Entries []SanctionsEntry `gorm:"foreignKey:ListID" json:"entries,omitempty"`
+}
+
+func (sl *SanctionsList) BeforeCreate(tx *gorm.DB) error {
+	if sl.ListID == "" {
+		sl.ListID = uuid.New().String()
+	}
+	return nil
+}
+
+func (SanctionsList) TableName() string {
+	return "sanctions_lists"
+}
+
+// SanctionsEntry é uma pessoa ou entidade da lista. Entradas que saem da lista
+// em uma nova importação são desativadas, não apagadas, para preservar as
+// revisões que apontam para elas.
+type SanctionsEntry struct {
+	EntryID     string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"entry_id"`
+	ListID      string         `gorm:"type:uuid;uniqueIndex:idx_sanctions_entries_list_external,priority:1;not null" json:"list_id"`
+	ExternalID  string         `gorm:"type:varchar(100);uniqueIndex:idx_sanctions_entries_list_external,priority:2;not null" json:"external_id"`
+	EntryType   string         `gorm:"type:varchar(20);not null" json:"entry_type"`
+	PrimaryName string         `gorm:"type:varchar(500);not null" json:"primary_name"`
+	Aliases     datatypes.JSON `gorm:"type:jsonb" json:"aliases"`
+	Country     sql.NullString `gorm:"type:varchar(100)" json:"country"`
+	Program     sql.NullString `gorm:"type:varchar(200)" json:"program"`
+	DateOfBirth sql.NullString `gorm:"type:varchar(50)" json:"date_of_birth"`
+	IsActive    bool           `gorm:"default:true;not null" json:"is_active"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	List *SanctionsList `gorm:"foreignKey:ListID;references:ListID;constraint:OnDelete:CASCADE" json:"list,omitempty"`
+}
+
+func (se *SanctionsEntry) BeforeCreate(tx *gorm.DB) error {
+	if se.EntryID == "" {
+		se.EntryID = uuid.New().String()
+	}
+	return nil
+}
+
+func (SanctionsEntry) TableName() string {
+	return "sanctions_entries"
+}
+
+// ScreeningReview é um possível acerto aguardando análise. Enquanto estiver
+// PENDING, o cliente não conclui o onboarding nem movimenta a conta, e
+// transferências para ele ficam bloqueadas; CONFIRMED bloqueia em definitivo.
+//
+// CounterpartyAccountID não tem chave estrangeira: revisões de transferência
+// são gravadas fora da transação de banco que mantém as contas bloqueadas.
+type ScreeningReview struct {
+	ReviewID              string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"review_id"`
+	CustomerID            string         `gorm:"type:uuid;index:idx_screening_reviews_customer_status,priority:1;not null" json:"customer_id"`
+	ScreeningContext      string         `gorm:"type:varchar(20);not null" json:"screening_context"`
+	ScreenedName          string         `gorm:"type:varchar(200);not null" json:"screened_name"`
+	RequestedByCustomerID sql.NullString `gorm:"type:uuid" json:"requested_by_customer_id"`
+	CounterpartyAccountID sql.NullString `gorm:"type:uuid" json:"counterparty_account_id"`
+	TopScore              float64        `gorm:"type:decimal(5,4);not null" json:"top_score"`
+	ReviewStatus          string         `gorm:"type:varchar(20);default:'PENDING';index:idx_screening_reviews_status;index:idx_screening_reviews_customer_status,priority:2;not null" json:"review_status"`
+	ReviewedByUserID      sql.NullString `gorm:"type:uuid" json:"reviewed_by_user_id"`
+	ReviewedAt            sql.NullTime   `json:"reviewed_at"`
+	ReviewNote            sql.NullString `gorm:"type:varchar(1000)" json:"review_note"`
+	CreatedAt             time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+
+	// Relations
+	Customer *Customer        `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+	Matches  []ScreeningMatch `gorm:"foreignKey:ReviewID" json:"matches,omitempty"`
+}
+
+func (sr *ScreeningReview) BeforeCreate(tx *gorm.DB) error {
+	if sr.ReviewID == "" {
+		sr.ReviewID = uuid.New().String()
+	}
+	return nil
+}
+
+func (ScreeningReview) TableName() string {
+	return "screening_reviews"
+}
+
+// ScreeningMatch liga a revisão às entradas encontradas. Entradas de revisões
+// CLEARED não voltam a gerar revisão para o mesmo cliente.
+type ScreeningMatch struct {
+	ReviewID    string  `gorm:"type:uuid;primaryKey" json:"review_id"`
+	EntryID     string  `gorm:"type:uuid;primaryKey;index:idx_screening_matches_entry_id" json:"entry_id"`
+	ListCode    string  `gorm:"type:varchar(50);not null" json:"list_code"`
+	MatchedName string  `gorm:"type:varchar(500);not null" json:"matched_name"`
+	Score       float64 `gorm:"type:decimal(5,4);not null" json:"score"`
+
+	// Relations
+	Review *ScreeningReview `gorm:"foreignKey:ReviewID;references:ReviewID;constraint:OnDelete:CASCADE" json:"review,omitempty"`
+	Entry  *SanctionsEntry  `gorm:"foreignKey:EntryID;references:EntryID;constraint:OnDelete:CASCADE" json:"entry,omitempty"`
+}
+
+func (ScreeningMatch) TableName() string {
+	return "screening_matches"
+}
@@ -13,6 +13,14 @@ import (
 // TRANSACTIONS
 // ===========================
 
+const (
+	TransactionStatusPending   = "PENDING"
+	TransactionStatusCompleted = "COMPLETED"
+	TransactionStatusOnHold    = "ON_HOLD"
+	TransactionStatusFailed    = "FAILED"
+	TransactionStatusCancelled = "CANCELLED"
+)
+
 type Transaction struct {
 	TransactionID       string          `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"transaction_id"`
 	AccountIDOrigin     string          `gorm:"type:uuid;index:idx_trans_account_origin,priority:1;index:idx_trans_account_date,priority:1;not null" json:"account_id_origin"`
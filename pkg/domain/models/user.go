@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/victor-lima-142/oak-bank/pkg/fieldcrypt"
 	"gorm.io/gorm"
 )
 
@@ -12,21 +13,41 @@ import (
 // USERS & AUTHENTICATION
 // ===========================
 
+// Tipos de evento de UserAuthLog
+const (
+	AuthEventLogin        = "LOGIN"
+	AuthEventMFAChallenge = "MFA_CHALLENGE"
+	AuthEventStepUp       = "STEP_UP"
+	AuthEventReauth       = "REAUTHENTICATION"
+)
+
+// Métodos da verificação em duas etapas. Com EMAIL e SMS o código é enviado
+// ao contato cadastrado a cada uso.
+const (
+	TwoFactorMethodTOTP  = "TOTP"
+	TwoFactorMethodEmail = "EMAIL"
+	TwoFactorMethodSMS   = "SMS"
+)
+
 type User struct {
 	UserID              string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"user_id"`
 	CustomerID          sql.NullString `gorm:"type:uuid;index:idx_users_customer_id" json:"customer_id"`
 	Username            string         `gorm:"type:varchar(50);uniqueIndex:idx_users_username;not null" json:"username"`
 	Email               string         `gorm:"type:varchar(100);uniqueIndex:idx_users_email;not null" json:"email"`
 	PasswordHash        string         `gorm:"type:varchar(255);not null" json:"password_hash"`
+	PasswordChangedAt   sql.NullTime   `json:"password_changed_at"`
 	UserRole            string         `gorm:"type:varchar(20);not null" json:"user_role"`
 	IsActive            bool           `gorm:"default:true;not null" json:"is_active"`
 	IsLocked            bool           `gorm:"default:false;not null" json:"is_locked"`
 	FailedLoginAttempts int            `gorm:"default:0;not null" json:"failed_login_attempts"`
+	LockedUntil         sql.NullTime   `json:"locked_until"`
 	LastLogin           sql.NullTime   `json:"last_login"`
 	CreatedAt           time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
 	UpdatedAt           time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
 	TwoFactorEnabled    bool           `gorm:"default:false;not null" json:"two_factor_enabled"`
-	TwoFactorSecret     sql.NullString `gorm:"type:varchar(255)" json:"two_factor_secret"`
+	TwoFactorSecret     sql.NullString `gorm:"type:text;serializer:encrypted" json:"two_factor_secret"`
+	TwoFactorMethod     sql.NullString `gorm:"type:varchar(10)" json:"two_factor_method"`
+	TwoFactorLastStep   int64          `gorm:"default:0;not null" json:"-"`
 
 	// Relations
 	Customer             *Customer              `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
@@ -75,3 +96,33 @@ func (ual *UserAuthLog) BeforeCreate(tx *gorm.DB) error {
 func (UserAuthLog) TableName() string {
 	return "user_auth_log"
 }
+
+// TwoFactorRecoveryCode é um código de recuperação de uso único, aceito no
+// lugar do código TOTP. Apenas o índice cego do código é guardado.
+type TwoFactorRecoveryCode struct {
+	CodeID    string       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"code_id"`
+	UserID    string       `gorm:"type:uuid;index:idx_two_factor_recovery_codes_user_id;not null" json:"user_id"`
+	CodeHash  string       `gorm:"type:varchar(64);uniqueIndex:idx_two_factor_recovery_codes_hash;not null" json:"-"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `gorm:"autoCreateTime;not null" json:"created_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;references:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+func (c *TwoFactorRecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if c.CodeID == "" {
+		c.CodeID = uuid.New().String()
+	}
+	return nil
+}
+
+func (TwoFactorRecoveryCode) TableName() string {
+	return "two_factor_recovery_codes"
+}
+
+// TwoFactorRecoveryCodeHash é o índice cego do código de recuperação,
+// amarrado ao usuário
+func TwoFactorRecoveryCodeHash(userID, code string) (string, error) {
+	return fieldcrypt.BlindIndex("two_factor_recovery_codes.code", userID+":"+code)
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ===========================
+// WEBHOOKS
+// ===========================
+
+const (
+	WebhookDeliveryStatusPending   = "PENDING"
+	WebhookDeliveryStatusSending   = "SENDING"
+	WebhookDeliveryStatusSucceeded = "SUCCEEDED"
+	WebhookDeliveryStatusFailed    = "FAILED"
+)
+
+// WebhookSubscription é um endpoint que recebe eventos. Assinaturas de cliente
+// recebem apenas eventos do próprio cliente; assinaturas de parceiro (sem
+// CustomerID) recebem os eventos de todos os clientes.
+type WebhookSubscription struct {
+	SubscriptionID      string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"subscription_id"`
+	CustomerID          sql.NullString `gorm:"type:uuid;index:idx_webhook_subscriptions_customer_id" json:"customer_id"`
+	PartnerName         sql.NullString `gorm:"type:varchar(100)" json:"partner_name"`
+	URL                 string         `gorm:"type:varchar(500);not null" json:"url"`
+	EventTypes          datatypes.JSON `gorm:"type:jsonb;not null" json:"event_types"`
+	Secret              string         `gorm:"type:varchar(100);not null" json:"-"`
+	Description         sql.NullString `gorm:"type:varchar(200)" json:"description"`
+	IsActive            bool           `gorm:"default:true;not null" json:"is_active"`
+	ConsecutiveFailures int            `gorm:"default:0;not null" json:"consecutive_failures"`
+	LastSuccessAt       sql.NullTime   `json:"last_success_at"`
+	FailingSince        sql.NullTime   `json:"failing_since"`
+	DisabledAt          sql.NullTime   `json:"disabled_at"`
+	DisabledReason      sql.NullString `gorm:"type:varchar(200)" json:"disabled_reason"`
+	CreatedAt           time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+	UpdatedAt           time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Customer *Customer `gorm:"foreignKey:CustomerID;references:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
+}
+
+func (ws *WebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if ws.SubscriptionID == "" {
+		ws.SubscriptionID = uuid.New().String()
+	}
+	return nil
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// WebhookDelivery é a entrega de um evento a uma assinatura. O EventID é o
+// mesmo em todas as assinaturas e em reenvios, para deduplicação no parceiro.
+type WebhookDelivery struct {
+	DeliveryID     string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"delivery_id"`
+	SubscriptionID string         `gorm:"type:uuid;index:idx_webhook_deliveries_subscription_id;not null" json:"subscription_id"`
+	EventID        string         `gorm:"type:uuid;index:idx_webhook_deliveries_event_id;not null" json:"event_id"`
+	EventType      string         `gorm:"type:varchar(50);not null" json:"event_type"`
+	Payload        datatypes.JSON `gorm:"type:jsonb;not null" json:"payload"`
+	DeliveryStatus string         `gorm:"type:varchar(20);default:'PENDING';index:idx_webhook_deliveries_status_next,priority:1;not null" json:"delivery_status"`
+	Attempts       int            `gorm:"default:0;not null" json:"attempts"`
+	MaxAttempts    int            `gorm:"default:10;not null" json:"max_attempts"`
+	NextAttemptAt  time.Time      `gorm:"index:idx_webhook_deliveries_status_next,priority:2;not null" json:"next_attempt_at"`
+	LastStatusCode sql.NullInt32  `json:"last_status_code"`
+	LastError      sql.NullString `gorm:"type:varchar(500)" json:"last_error"`
+	DeliveredAt    sql.NullTime   `json:"delivered_at"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime;not null" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime;not null" json:"updated_at"`
+
+	// Relations
+	Subscription *WebhookSubscription     `gorm:"foreignKey:SubscriptionID;references:SubscriptionID;constraint:OnDelete:CASCADE" json:"subscription,omitempty"`
+	AttemptLog   []WebhookDeliveryAttempt `gorm:"foreignKey:DeliveryID;constraint:OnDelete:CASCADE" json:"attempt_log,omitempty"`
+}
+
+func (wd *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if wd.DeliveryID == "" {
+		wd.DeliveryID = uuid.New().String()
+	}
+	return nil
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookDeliveryAttempt registra cada tentativa de entrega com a resposta recebida
+type WebhookDeliveryAttempt struct {
+	AttemptID     string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"attempt_id"`
+	DeliveryID    string         `gorm:"type:uuid;index:idx_webhook_attempts_delivery_id;not null" json:"delivery_id"`
+	AttemptNumber int            `gorm:"not null" json:"attempt_number"`
+	StatusCode    sql.NullInt32  `json:"status_code"`
+	ResponseBody  sql.NullString `gorm:"type:text" json:"response_body"`
+	ErrorMessage  sql.NullString `gorm:"type:varchar(500)" json:"error_message"`
+	DurationMs    int64          `gorm:"not null" json:"duration_ms"`
+	AttemptedAt   time.Time      `gorm:"autoCreateTime;not null" json:"attempted_at"`
+
+	// Relations
+	Delivery *WebhookDelivery `gorm:"foreignKey:DeliveryID;references:DeliveryID;constraint:OnDelete:CASCADE" json:"delivery,omitempty"`
+}
+
+func (wa *WebhookDeliveryAttempt) BeforeCreate(tx *gorm.DB) error {
+	if wa.AttemptID == "" {
+		wa.AttemptID = uuid.New().String()
+	}
+	return nil
+}
+
+func (WebhookDeliveryAttempt) TableName() string {
+	return "webhook_delivery_attempts"
+}
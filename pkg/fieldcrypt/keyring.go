@@ -0,0 +1,296 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DataKeyStatusActive  = "ACTIVE"
+	DataKeyStatusRetired = "RETIRED"
+
+	// valuePrefix marca valores cifrados: enc:v1:<id da chave de dados>:<base64>.
+	// Valores sem o prefixo são texto puro legado e são lidos como estão.
+	valuePrefix = "enc:v1:"
+)
+
+var (
+	ErrNotConfigured  = errors.New("criptografia de campos não configurada")
+	ErrUnknownDataKey = errors.New("chave de dados desconhecida")
+	ErrDecryptFailed  = errors.New("falha ao decifrar campo")
+)
+
+// DataKey é uma chave de dados cifrada pela chave mestra MasterKeyID. Apenas
+// uma chave fica ACTIVE e é usada nas gravações; as RETIRED continuam
+// disponíveis para leitura até a recifragem das linhas.
+type DataKey struct {
+	KeyID       string    `gorm:"type:varchar(16);primaryKey" json:"key_id"`
+	WrappedKey  []byte    `gorm:"type:bytea;not null" json:"-"`
+	MasterKeyID string    `gorm:"type:varchar(32);not null" json:"master_key_id"`
+	KeyStatus   string    `gorm:"type:varchar(10);not null" json:"key_status"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;not null" json:"created_at"`
+}
+
+func (DataKey) TableName() string {
+	return "encryption_data_keys"
+}
+
+// Keyring mantém as chaves de dados decifradas em memória
+type Keyring struct {
+	db       *gorm.DB
+	provider KeyProvider
+
+	mu     sync.RWMutex
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+var defaultKeyring atomic.Pointer[Keyring]
+
+// Use define o Keyring usado pelo serializer "encrypted" e por BlindIndex
+func Use(k *Keyring) {
+	defaultKeyring.Store(k)
+}
+
+// NewKeyring carrega as chaves de dados e cria a primeira se não houver uma ativa
+func NewKeyring(ctx context.Context, db *gorm.DB, provider KeyProvider) (*Keyring, error) {
+	k := &Keyring{db: db, provider: provider, keys: map[string]cipher.AEAD{}}
+	if err := k.load(ctx); err != nil {
+		return nil, err
+	}
+	if k.ActiveKeyID() == "" {
+		if _, err := k.RotateDataKey(ctx); err != nil {
+			// outra instância pode ter criado a chave ao mesmo tempo
+			if loadErr := k.load(ctx); loadErr != nil || k.ActiveKeyID() == "" {
+				return nil, err
+			}
+		}
+	}
+	return k, nil
+}
+
+func (k *Keyring) load(ctx context.Context) error {
+	var rows []DataKey
+	if err := k.db.WithContext(ctx).Order("created_at").Find(&rows).Error; err != nil {
+		return err
+	}
+
+	keys := make(map[string]cipher.AEAD, len(rows))
+	active := ""
+	for _, row := range rows {
+		dataKey, err := k.provider.Unwrap(ctx, row.MasterKeyID, row.WrappedKey)
+		if err != nil {
+			return fmt.Errorf("chave de dados %s: %w", row.KeyID, err)
+		}
+		gcm, err := newGCM(dataKey)
+		if err != nil {
+			return fmt.Errorf("chave de dados %s: %w", row.KeyID, err)
+		}
+		keys[row.KeyID] = gcm
+		if row.KeyStatus == DataKeyStatusActive {
+			active = row.KeyID
+		}
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.active = active
+	k.mu.Unlock()
+	return nil
+}
+
+// Run recarrega as chaves periodicamente para que uma rotação feita por
+// outro processo passe a valer nas gravações desta instância
+func (k *Keyring) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := k.load(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("fieldcrypt: keyring reload failed: %v", err)
+		}
+	}
+}
+
+// ActiveKeyID retorna a chave de dados usada nas gravações
+func (k *Keyring) ActiveKeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active
+}
+
+// RotateDataKey cria uma nova chave de dados ativa e aposenta a anterior.
+// Outras instâncias passam a usá-la ao encontrar o novo id em uma leitura;
+// até lá continuam gravando com a anterior, que segue válida.
+func (k *Keyring) RotateDataKey(ctx context.Context) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	masterKeyID, wrapped, err := k.provider.Wrap(ctx, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	row := &DataKey{
+		KeyID:       hex.EncodeToString(id),
+		WrappedKey:  wrapped,
+		MasterKeyID: masterKeyID,
+		KeyStatus:   DataKeyStatusActive,
+	}
+	err = k.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&DataKey{}).
+			Where("key_status = ?", DataKeyStatusActive).
+			Update("key_status", DataKeyStatusRetired).Error; err != nil {
+			return err
+		}
+		return tx.Create(row).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return row.KeyID, k.load(ctx)
+}
+
+// Rewrap cifra novamente as chaves de dados com a chave mestra atual. É a
+// rotação da chave mestra: as linhas não precisam ser regravadas.
+func (k *Keyring) Rewrap(ctx context.Context) (int, error) {
+	current := k.provider.MasterKeyID()
+
+	var rows []DataKey
+	if err := k.db.WithContext(ctx).Where("master_key_id <> ?", current).Find(&rows).Error; err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		dataKey, err := k.provider.Unwrap(ctx, row.MasterKeyID, row.WrappedKey)
+		if err != nil {
+			return 0, fmt.Errorf("chave de dados %s: %w", row.KeyID, err)
+		}
+		masterKeyID, wrapped, err := k.provider.Wrap(ctx, dataKey)
+		if err != nil {
+			return 0, err
+		}
+		if err := k.db.WithContext(ctx).Model(&row).Updates(map[string]interface{}{
+			"wrapped_key":   wrapped,
+			"master_key_id": masterKeyID,
+		}).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(rows), nil
+}
+
+// Encrypt cifra value com a chave de dados ativa. additionalData associa o
+// valor à coluna, impedindo que seja copiado para outra.
+func (k *Keyring) Encrypt(value, additionalData string) (string, error) {
+	k.mu.RLock()
+	id, gcm := k.active, k.keys[k.active]
+	k.mu.RUnlock()
+	if gcm == nil {
+		return "", ErrNotConfigured
+	}
+
+	sealed, err := seal(gcm, []byte(value), []byte(additionalData))
+	if err != nil {
+		return "", err
+	}
+	return valuePrefix + id + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decifra um valor gravado por Encrypt. Valores legados em texto puro
+// são devolvidos sem alteração.
+func (k *Keyring) Decrypt(ctx context.Context, value, additionalData string) (string, error) {
+	rest, ok := strings.CutPrefix(value, valuePrefix)
+	if !ok {
+		return value, nil
+	}
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", ErrDecryptFailed
+	}
+
+	gcm, err := k.key(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrDecryptFailed
+	}
+	plaintext, err := open(gcm, sealed, []byte(additionalData))
+	if err != nil {
+		return "", ErrDecryptFailed
+	}
+	return string(plaintext), nil
+}
+
+// key busca a chave de dados e recarrega o chaveiro quando ela foi criada
+// por outra instância
+func (k *Keyring) key(ctx context.Context, id string) (cipher.AEAD, error) {
+	k.mu.RLock()
+	gcm := k.keys[id]
+	k.mu.RUnlock()
+	if gcm != nil {
+		return gcm, nil
+	}
+
+	if err := k.load(ctx); err != nil {
+		return nil, err
+	}
+	k.mu.RLock()
+	gcm = k.keys[id]
+	k.mu.RUnlock()
+	if gcm == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDataKey, id)
+	}
+	return gcm, nil
+}
+
+// IsCurrent informa se value já está cifrado com a chave de dados ativa
+func (k *Keyring) IsCurrent(value string) bool {
+	return strings.HasPrefix(value, valuePrefix+k.ActiveKeyID()+":")
+}
+
+// BlindIndex calcula o índice cego de value: HMAC-SHA256 com a chave do
+// provedor, separado por purpose para que o mesmo valor em colunas
+// diferentes gere índices diferentes. O chamador normaliza value.
+func (k *Keyring) BlindIndex(purpose, value string) string {
+	mac := hmac.New(sha256.New, k.provider.BlindIndexKey())
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// BlindIndex usa o Keyring definido em Use
+func BlindIndex(purpose, value string) (string, error) {
+	k := defaultKeyring.Load()
+	if k == nil {
+		return "", ErrNotConfigured
+	}
+	return k.BlindIndex(purpose, value), nil
+}
@@ -0,0 +1,234 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func testProvider(t *testing.T, current []byte, previous ...[]byte) KeyProvider {
+	t.Helper()
+	p, err := NewLocalKeyProvider(current, previous, testKey(0xB1))
+	if err != nil {
+		t.Fatalf("NewLocalKeyProvider: %v", err)
+	}
+	return p
+}
+
+// testKeyring monta um Keyring em memória com as chaves de dados informadas,
+// a última ativa, sem passar pelo banco
+func testKeyring(t *testing.T, provider KeyProvider, dataKeys ...[]byte) *Keyring {
+	t.Helper()
+	k := &Keyring{provider: provider, keys: map[string]cipher.AEAD{}}
+	for i, dataKey := range dataKeys {
+		gcm, err := newGCM(dataKey)
+		if err != nil {
+			t.Fatalf("newGCM: %v", err)
+		}
+		id := string(rune('a' + i))
+		k.keys[id] = gcm
+		k.active = id
+	}
+	return k
+}
+
+func TestLocalKeyProviderWrapUnwrap(t *testing.T) {
+	old := testKey(0x01)
+	current := testKey(0x02)
+	dataKey := testKey(0xDD)
+
+	oldProvider := testProvider(t, old)
+	oldID, wrappedOld, err := oldProvider.Wrap(context.Background(), dataKey)
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+
+	p := testProvider(t, current, old)
+	id, wrapped, err := p.Wrap(context.Background(), dataKey)
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	if id != p.MasterKeyID() || id == oldID {
+		t.Fatalf("Wrap usou a chave mestra %s, want %s", id, p.MasterKeyID())
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		wrapped []byte
+		wantErr error
+	}{
+		{"chave atual", id, wrapped, nil},
+		{"chave anterior", oldID, wrappedOld, nil},
+		{"chave desconhecida", masterKeyID(testKey(0x03)), wrapped, ErrUnknownMasterKey},
+		{"id trocado", oldID, wrapped, ErrUnwrapFailed},
+		{"truncada", id, wrapped[:8], ErrUnwrapFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Unwrap(context.Background(), tt.id, tt.wrapped)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Unwrap err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unwrap: %v", err)
+			}
+			if !bytes.Equal(got, dataKey) {
+				t.Fatalf("Unwrap devolveu outra chave de dados")
+			}
+		})
+	}
+}
+
+func TestNewLocalKeyProviderRejectsShortKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    []byte
+		previous   [][]byte
+		blindIndex []byte
+	}{
+		{"chave mestra curta", testKey(0x01)[:16], nil, testKey(0xB1)},
+		{"chave anterior curta", testKey(0x01), [][]byte{testKey(0x02)[:31]}, testKey(0xB1)},
+		{"índice cego curto", testKey(0x01), nil, testKey(0xB1)[:16]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLocalKeyProvider(tt.current, tt.previous, tt.blindIndex); err == nil {
+				t.Fatal("esperava erro")
+			}
+		})
+	}
+}
+
+func TestKeyringEncryptDecrypt(t *testing.T) {
+	k := testKeyring(t, testProvider(t, testKey(0x01)), testKey(0xD1))
+	ctx := context.Background()
+
+	encrypted, err := k.Encrypt("529.982.247-25", "customers.tax_id")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !strings.HasPrefix(encrypted, valuePrefix+"a:") {
+		t.Fatalf("Encrypt = %q, sem o prefixo da chave ativa", encrypted)
+	}
+	if strings.Contains(encrypted, "529") {
+		t.Fatalf("Encrypt expôs o texto puro: %q", encrypted)
+	}
+	again, _ := k.Encrypt("529.982.247-25", "customers.tax_id")
+	if again == encrypted {
+		t.Fatal("Encrypt deve usar nonce aleatório")
+	}
+
+	prefix, encoded, _ := strings.Cut(strings.TrimPrefix(encrypted, valuePrefix), ":")
+	raw, _ := base64.RawStdEncoding.DecodeString(encoded)
+	raw[len(raw)-1] ^= 0xFF
+	tampered := valuePrefix + prefix + ":" + base64.RawStdEncoding.EncodeToString(raw)
+
+	tests := []struct {
+		name           string
+		value          string
+		additionalData string
+		want           string
+		wantErr        error
+	}{
+		{"round-trip", encrypted, "customers.tax_id", "529.982.247-25", nil},
+		{"texto puro legado", "texto legado", "customers.tax_id", "texto legado", nil},
+		{"outra coluna", encrypted, "customers.full_name", "", ErrDecryptFailed},
+		{"adulterado", tampered, "customers.tax_id", "", ErrDecryptFailed},
+		{"sem separador", valuePrefix + "a", "customers.tax_id", "", ErrDecryptFailed},
+		{"base64 inválido", valuePrefix + "a:***", "customers.tax_id", "", ErrDecryptFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := k.Decrypt(ctx, tt.value, tt.additionalData)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decrypt err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Decrypt = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyringRetiredKeyStillDecrypts(t *testing.T) {
+	provider := testProvider(t, testKey(0x01))
+	before := testKeyring(t, provider, testKey(0xD1))
+	encrypted, err := before.Encrypt("Maria da Silva", "customers.full_name")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	// após a rotação "a" fica aposentada e "b" passa a ser a ativa
+	after := testKeyring(t, provider, testKey(0xD1), testKey(0xD2))
+	if after.IsCurrent(encrypted) {
+		t.Fatal("valor cifrado com a chave aposentada não deve ser atual")
+	}
+	got, err := after.Decrypt(context.Background(), encrypted, "customers.full_name")
+	if err != nil || got != "Maria da Silva" {
+		t.Fatalf("Decrypt = %q, %v", got, err)
+	}
+
+	rotated, err := after.Encrypt(got, "customers.full_name")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !after.IsCurrent(rotated) {
+		t.Fatalf("valor recifrado deve usar a chave ativa: %q", rotated)
+	}
+}
+
+func TestKeyringEncryptWithoutActiveKey(t *testing.T) {
+	k := testKeyring(t, testProvider(t, testKey(0x01)))
+	if _, err := k.Encrypt("valor", "customers.tax_id"); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("Encrypt err = %v, want %v", err, ErrNotConfigured)
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	k := testKeyring(t, testProvider(t, testKey(0x01)), testKey(0xD1))
+	base := k.BlindIndex("customers.tax_id", "52998224725")
+	if len(base) != 64 {
+		t.Fatalf("BlindIndex tem %d caracteres, want 64", len(base))
+	}
+
+	other, err := NewLocalKeyProvider(testKey(0x01), nil, testKey(0xB2))
+	if err != nil {
+		t.Fatalf("NewLocalKeyProvider: %v", err)
+	}
+	otherKeyring := testKeyring(t, other, testKey(0xD1))
+	// a chave de dados não participa do índice cego
+	rotated := testKeyring(t, testProvider(t, testKey(0x01)), testKey(0xD1), testKey(0xD2))
+
+	tests := []struct {
+		name  string
+		got   string
+		equal bool
+	}{
+		{"determinístico", k.BlindIndex("customers.tax_id", "52998224725"), true},
+		{"estável na rotação da chave de dados", rotated.BlindIndex("customers.tax_id", "52998224725"), true},
+		{"outro valor", k.BlindIndex("customers.tax_id", "11144477735"), false},
+		{"outra finalidade", k.BlindIndex("customers.email", "52998224725"), false},
+		{"outra chave de índice", otherKeyring.BlindIndex("customers.tax_id", "52998224725"), false},
+		// o separador impede que finalidade e valor se confundam
+		{"fronteira entre finalidade e valor", k.BlindIndex("customers.tax_id5", "2998224725"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.got == base) != tt.equal {
+				t.Fatalf("BlindIndex igual = %v, want %v", tt.got == base, tt.equal)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Package fieldcrypt cifra colunas com dados pessoais usando envelope
+// encryption: cada valor é cifrado com uma chave de dados (DEK) guardada no
+// banco, e as chaves de dados são cifradas pela chave mestra de um
+// KeyProvider. Buscas usam índices cegos (HMAC) em colunas separadas.
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	ErrUnknownMasterKey = errors.New("chave mestra desconhecida")
+	ErrUnwrapFailed     = errors.New("falha ao decifrar chave de dados")
+)
+
+// KeyProvider guarda as chaves mestras. Implementações para KMS/HSM só
+// precisam cifrar e decifrar chaves de dados; a chave mestra não sai do provedor.
+type KeyProvider interface {
+	// MasterKeyID identifica a chave mestra usada em Wrap
+	MasterKeyID() string
+	Wrap(ctx context.Context, dataKey []byte) (masterKeyID string, wrapped []byte, err error)
+	Unwrap(ctx context.Context, masterKeyID string, wrapped []byte) ([]byte, error)
+	// BlindIndexKey é a chave HMAC dos índices cegos. Ela não participa da
+	// rotação: trocá-la exige recalcular todos os índices.
+	BlindIndexKey() []byte
+}
+
+// localKeyProvider mantém as chaves mestras em memória, lidas do ambiente ou
+// de arquivo. Chaves anteriores continuam disponíveis para Unwrap.
+type localKeyProvider struct {
+	current       string
+	keys          map[string]cipher.AEAD
+	blindIndexKey []byte
+}
+
+// NewLocalKeyProvider cria um KeyProvider com a chave mestra atual, as
+// anteriores e a chave dos índices cegos, todas com 32 bytes
+func NewLocalKeyProvider(current []byte, previous [][]byte, blindIndexKey []byte) (KeyProvider, error) {
+	if len(blindIndexKey) < 32 {
+		return nil, fmt.Errorf("chave de índice cego deve ter ao menos 32 bytes")
+	}
+	p := &localKeyProvider{keys: map[string]cipher.AEAD{}, blindIndexKey: blindIndexKey}
+	for i, key := range append([][]byte{current}, previous...) {
+		gcm, err := newGCM(key)
+		if err != nil {
+			return nil, fmt.Errorf("chave mestra: %w", err)
+		}
+		id := masterKeyID(key)
+		if i == 0 {
+			p.current = id
+		}
+		p.keys[id] = gcm
+	}
+	return p, nil
+}
+
+func (p *localKeyProvider) MasterKeyID() string {
+	return p.current
+}
+
+func (p *localKeyProvider) Wrap(_ context.Context, dataKey []byte) (string, []byte, error) {
+	sealed, err := seal(p.keys[p.current], dataKey, []byte(p.current))
+	return p.current, sealed, err
+}
+
+func (p *localKeyProvider) Unwrap(_ context.Context, id string, wrapped []byte) ([]byte, error) {
+	gcm, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMasterKey, id)
+	}
+	dataKey, err := open(gcm, wrapped, []byte(id))
+	if err != nil {
+		return nil, ErrUnwrapFailed
+	}
+	return dataKey, nil
+}
+
+func (p *localKeyProvider) BlindIndexKey() []byte {
+	return p.blindIndexKey
+}
+
+// NewKeyProviderFromEnv monta o KeyProvider a partir das variáveis de ambiente.
+// As chaves são codificadas em base64; as variáveis *_FILE apontam para um
+// arquivo com o mesmo conteúdo e têm precedência.
+//
+//	FIELD_ENCRYPTION_PROVIDER=local (padrão local)
+//	FIELD_ENCRYPTION_MASTER_KEY / FIELD_ENCRYPTION_MASTER_KEY_FILE (obrigatória)
+//	FIELD_ENCRYPTION_PREVIOUS_MASTER_KEYS chaves anteriores separadas por vírgula
+//	FIELD_BLIND_INDEX_KEY / FIELD_BLIND_INDEX_KEY_FILE (obrigatória)
+func NewKeyProviderFromEnv() (KeyProvider, error) {
+	provider := strings.ToLower(envOr("FIELD_ENCRYPTION_PROVIDER", "local"))
+	if provider != "local" {
+		return nil, fmt.Errorf("FIELD_ENCRYPTION_PROVIDER: provedor desconhecido %q", provider)
+	}
+
+	current, err := keyFromEnv("FIELD_ENCRYPTION_MASTER_KEY")
+	if err != nil {
+		return nil, err
+	}
+	blindIndexKey, err := keyFromEnv("FIELD_BLIND_INDEX_KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	var previous [][]byte
+	for _, value := range strings.Split(os.Getenv("FIELD_ENCRYPTION_PREVIOUS_MASTER_KEYS"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("FIELD_ENCRYPTION_PREVIOUS_MASTER_KEYS inválida: %w", err)
+		}
+		previous = append(previous, key)
+	}
+	return NewLocalKeyProvider(current, previous, blindIndexKey)
+}
+
+func keyFromEnv(name string) ([]byte, error) {
+	encoded := os.Getenv(name)
+	if path := os.Getenv(name + "_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		encoded = string(content)
+	}
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, fmt.Errorf("%s é obrigatória", name)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%s inválida: %w", name, err)
+	}
+	return key, nil
+}
+
+func envOr(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// masterKeyID identifica a chave sem expô-la: os primeiros 8 bytes do SHA-256
+func masterKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("deve ter 32 bytes, recebida com %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal cifra com AES-GCM e coloca o nonce no início do resultado
+func seal(gcm cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(gcm cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("conteúdo cifrado truncado")
+	}
+	nonce, data := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, data, additionalData)
+}
@@ -0,0 +1,101 @@
+package fieldcrypt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReencryptOptions controla a recifragem em lotes
+type ReencryptOptions struct {
+	BatchSize int
+	// Pause entre lotes, para limitar a carga no banco
+	Pause time.Duration
+	// ExtraColumns são gravadas junto com as colunas cifradas, como os
+	// índices cegos recalculados pelos hooks do modelo
+	ExtraColumns []string
+}
+
+// Reencrypt regrava as linhas de model cujas colunas cifradas não estão com a
+// chave de dados ativa, incluindo valores legados em texto puro. Cada lote é
+// uma transação com SKIP LOCKED, então a aplicação continua operando e várias
+// execuções podem rodar em paralelo. Retorna o total de linhas regravadas.
+func Reencrypt(ctx context.Context, db *gorm.DB, model any, opts ReencryptOptions) (int, error) {
+	k := defaultKeyring.Load()
+	if k == nil {
+		return 0, ErrNotConfigured
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return 0, err
+	}
+	sch := stmt.Schema
+	if sch.PrioritizedPrimaryField == nil {
+		return 0, fmt.Errorf("%s: chave primária simples obrigatória", sch.Table)
+	}
+
+	var columns, conditions []string
+	var args []interface{}
+	prefix := valuePrefix + k.ActiveKeyID() + ":%"
+	for _, field := range sch.Fields {
+		if _, ok := field.Serializer.(Serializer); !ok {
+			continue
+		}
+		columns = append(columns, field.DBName)
+		conditions = append(conditions, fmt.Sprintf("(%s IS NOT NULL AND %s <> '' AND %s NOT LIKE ?)", field.DBName, field.DBName, field.DBName))
+		args = append(args, prefix)
+	}
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("%s: nenhuma coluna cifrada", sch.Table)
+	}
+	columns = append(columns, opts.ExtraColumns...)
+	where := strings.Join(conditions, " OR ")
+
+	total := 0
+	for {
+		rows := reflect.New(reflect.SliceOf(sch.ModelType))
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(model).
+				Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where(where, args...).
+				Order(sch.PrioritizedPrimaryField.DBName).
+				Limit(opts.BatchSize).
+				Find(rows.Interface()).Error; err != nil {
+				return err
+			}
+
+			// as linhas foram decifradas na leitura; Updates cifra com a chave ativa
+			for i := 0; i < rows.Elem().Len(); i++ {
+				row := rows.Elem().Index(i).Addr().Interface()
+				if err := tx.Model(row).Select(columns).Updates(row).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+
+		n := rows.Elem().Len()
+		total += n
+		if n < opts.BatchSize {
+			return total, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(opts.Pause):
+		}
+	}
+}
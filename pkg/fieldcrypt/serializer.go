@@ -0,0 +1,80 @@
+package fieldcrypt
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName é o nome usado na tag: `gorm:"serializer:encrypted"`
+const SerializerName = "encrypted"
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// Serializer cifra campos string e sql.NullString com o Keyring definido em
+// Use. O dado autenticado é tabela.coluna.
+type Serializer struct{}
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored sql.NullString
+	if err := stored.Scan(dbValue); err != nil {
+		return err
+	}
+
+	plaintext := stored.String
+	if stored.Valid && stored.String != "" {
+		k := defaultKeyring.Load()
+		if k == nil {
+			return ErrNotConfigured
+		}
+		var err error
+		plaintext, err = k.Decrypt(ctx, stored.String, additionalData(field))
+		if err != nil {
+			return fmt.Errorf("%s: %w", additionalData(field), err)
+		}
+	}
+
+	target := field.ReflectValueOf(ctx, dst)
+	switch target.Interface().(type) {
+	case string:
+		target.SetString(plaintext)
+	case sql.NullString:
+		target.Set(reflect.ValueOf(sql.NullString{String: plaintext, Valid: stored.Valid}))
+	default:
+		return fmt.Errorf("serializer %s não suporta %s", SerializerName, field.FieldType)
+	}
+	return nil
+}
+
+func (Serializer) Value(_ context.Context, field *schema.Field, _ reflect.Value, fieldValue interface{}) (interface{}, error) {
+	var plaintext string
+	switch v := fieldValue.(type) {
+	case string:
+		plaintext = v
+	case sql.NullString:
+		if !v.Valid {
+			return nil, nil
+		}
+		plaintext = v.String
+	default:
+		return nil, fmt.Errorf("serializer %s não suporta %T", SerializerName, fieldValue)
+	}
+	if plaintext == "" {
+		return plaintext, nil
+	}
+
+	k := defaultKeyring.Load()
+	if k == nil {
+		return nil, ErrNotConfigured
+	}
+	return k.Encrypt(plaintext, additionalData(field))
+}
+
+func additionalData(field *schema.Field) string {
+	return field.Schema.Table + "." + field.DBName
+}
@@ -0,0 +1,70 @@
+package taxid
+
+import "strings"
+
+// NormalizeCNPJ remove a pontuação e converte letras para maiúsculas. Desde
+// julho de 2026 a Receita emite CNPJs alfanuméricos: as doze primeiras
+// posições aceitam letras e apenas os dígitos verificadores são numéricos.
+func NormalizeCNPJ(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(value) {
+		if (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ValidCNPJ confere o tamanho e os dígitos verificadores do CNPJ, numérico ou
+// alfanumérico, com ou sem pontuação
+func ValidCNPJ(value string) bool {
+	cnpj := NormalizeCNPJ(value)
+	if len(cnpj) != 14 || repeated(cnpj) {
+		return false
+	}
+	for i := 12; i < 14; i++ {
+		if cnpj[i] < '0' || cnpj[i] > '9' {
+			return false
+		}
+	}
+	return cnpjCheckDigit(cnpj[:12]) == cnpj[12] && cnpjCheckDigit(cnpj[:13]) == cnpj[13]
+}
+
+// FormatCNPJ formata o CNPJ como 00.000.000/0000-00
+func FormatCNPJ(value string) string {
+	cnpj := NormalizeCNPJ(value)
+	if len(cnpj) != 14 {
+		return value
+	}
+	return cnpj[:2] + "." + cnpj[2:5] + "." + cnpj[5:8] + "/" + cnpj[8:12] + "-" + cnpj[12:]
+}
+
+// Valid aceita CPF ou CNPJ conforme o tamanho do documento normalizado
+func Valid(value string) bool {
+	switch len(NormalizeCNPJ(value)) {
+	case 11:
+		return ValidCPF(value)
+	case 14:
+		return ValidCNPJ(value)
+	}
+	return false
+}
+
+// cnpjCheckDigit calcula o dígito do módulo 11 com pesos de 2 a 9 da direita
+// para a esquerda. Cada caractere vale seu código ASCII menos 48, o que
+// mantém o valor dos dígitos e atribui 17 a 42 às letras.
+func cnpjCheckDigit(chars string) byte {
+	sum, weight := 0, 2
+	for i := len(chars) - 1; i >= 0; i-- {
+		sum += int(chars[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	rest := sum % 11
+	if rest < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - rest)
+}
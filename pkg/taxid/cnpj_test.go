@@ -0,0 +1,67 @@
+package taxid
+
+import "testing"
+
+func TestValidCNPJ(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{"formatado", "11.222.333/0001-81", true},
+		{"sem pontuação", "11444777000161", true},
+		// exemplo da Receita Federal para o CNPJ alfanumérico
+		{"alfanumérico", "12.ABC.345/01DE-35", true},
+		{"alfanumérico minúsculo", "12abc34501de35", true},
+		{"segundo dígito errado", "11.222.333/0001-82", false},
+		{"primeiro dígito errado", "11.222.333/0001-91", false},
+		{"alfanumérico com dígito errado", "12.ABC.345/01DE-36", false},
+		{"letra no dígito verificador", "12.ABC.345/01DE-3A", false},
+		{"sequência repetida", "11.111.111/1111-11", false},
+		{"curto", "11.222.333/0001-8", false},
+		{"vazio", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidCNPJ(tt.value); got != tt.want {
+				t.Errorf("ValidCNPJ(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"529.982.247-25", true},
+		{"11.222.333/0001-81", true},
+		{"12.ABC.345/01DE-35", true},
+		{"529.982.247-24", false},
+		{"11.222.333/0001-80", false},
+		{"1234567890123", false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.value); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCNPJ(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"11222333000181", "11.222.333/0001-81"},
+		{"12abc34501de35", "12.ABC.345/01DE-35"},
+		// tamanho inválido: devolvido sem alteração
+		{"1122233300018", "1122233300018"},
+	}
+	for _, tt := range tests {
+		if got := FormatCNPJ(tt.value); got != tt.want {
+			t.Errorf("FormatCNPJ(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Package taxid valida e formata documentos fiscais brasileiros
+package taxid
+
+import "strings"
+
+// OnlyDigits remove pontuação e espaços do documento
+func OnlyDigits(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ValidCPF confere o tamanho e os dígitos verificadores do CPF. Aceita o
+// documento com ou sem pontuação; sequências repetidas (000.000.000-00) são inválidas.
+func ValidCPF(value string) bool {
+	cpf := OnlyDigits(value)
+	if len(cpf) != 11 || repeated(cpf) {
+		return false
+	}
+	return checkDigit(cpf[:9], 10) == cpf[9] && checkDigit(cpf[:10], 11) == cpf[10]
+}
+
+// FormatCPF formata o CPF como 000.000.000-00
+func FormatCPF(value string) string {
+	cpf := OnlyDigits(value)
+	if len(cpf) != 11 {
+		return value
+	}
+	return cpf[:3] + "." + cpf[3:6] + "." + cpf[6:9] + "-" + cpf[9:]
+}
+
+// checkDigit calcula o dígito do módulo 11 com pesos decrescentes a partir de weight
+func checkDigit(digits string, weight int) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		sum += int(digits[i]-'0') * (weight - i)
+	}
+	rest := sum % 11
+	if rest < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - rest)
+}
+
+func repeated(digits string) bool {
+	for i := 1; i < len(digits); i++ {
+		if digits[i] != digits[0] {
+			return false
+		}
+	}
+	return true
+}
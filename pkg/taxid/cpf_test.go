@@ -0,0 +1,53 @@
+package taxid
+
+import "testing"
+
+func TestValidCPF(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{"formatado", "529.982.247-25", true},
+		{"sem pontuação", "52998224725", true},
+		{"com espaços", " 111 444 777 35 ", true},
+		{"primeiro dígito verificador zero", "123.456.789-09", true},
+		{"segundo dígito errado", "529.982.247-24", false},
+		{"primeiro dígito errado", "529.982.247-35", false},
+		{"sequência repetida", "111.111.111-11", false},
+		{"zeros", "000.000.000-00", false},
+		{"curto", "529.982.247-2", false},
+		{"longo", "529.982.247-250", false},
+		{"vazio", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidCPF(tt.value); got != tt.want {
+				t.Errorf("ValidCPF(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatCPF(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"52998224725", "529.982.247-25"},
+		{"529.982.247-25", "529.982.247-25"},
+		// tamanho inválido: devolvido sem alteração
+		{"5299822472", "5299822472"},
+	}
+	for _, tt := range tests {
+		if got := FormatCPF(tt.value); got != tt.want {
+			t.Errorf("FormatCPF(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestOnlyDigits(t *testing.T) {
+	if got := OnlyDigits(" 529.982.247-25 "); got != "52998224725" {
+		t.Errorf("OnlyDigits = %q, want %q", got, "52998224725")
+	}
+}
@@ -0,0 +1,21 @@
+package textutil
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Fold normaliza um texto para comparação: remove acentos, converte para
+// minúsculas e colapsa espaços em branco
+func Fold(value string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, value)
+	if err != nil {
+		folded = value
+	}
+	return strings.Join(strings.Fields(strings.ToLower(folded)), " ")
+}